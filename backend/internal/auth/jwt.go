@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -22,6 +23,18 @@ type JWTClaims struct {
 	IsAdmin  bool   `json:"is_admin"`
 }
 
+// GuestAudience is the audience of guest tokens, which are never accepted as access or refresh tokens
+const GuestAudience = "retrotro-guest"
+
+// GuestClaims represents the claims in a guest token. A guest link token only carries the retro
+// and the share link it was issued for; once a guest joined, their session token also carries
+// the guest's user ID as subject.
+type GuestClaims struct {
+	jwt.RegisteredClaims
+	RetroID string `json:"retro_id"`
+	ShareID string `json:"share_id"`
+}
+
 // TokenPair represents an access and refresh token pair
 type TokenPair struct {
 	AccessToken  string    `json:"accessToken"`
@@ -110,7 +123,7 @@ func (m *JWTManager) ValidateAccessToken(tokenString string) (*JWTClaims, error)
 	}
 
 	claims, ok := token.Claims.(*JWTClaims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || slices.Contains(claims.Audience, GuestAudience) {
 		return nil, ErrInvalidToken
 	}
 
@@ -134,7 +147,7 @@ func (m *JWTManager) ValidateRefreshToken(tokenString string) (uuid.UUID, error)
 	}
 
 	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || slices.Contains(claims.Audience, GuestAudience) {
 		return uuid.Nil, ErrInvalidToken
 	}
 
@@ -145,3 +158,55 @@ func (m *JWTManager) ValidateRefreshToken(tokenString string) (uuid.UUID, error)
 
 	return userID, nil
 }
+
+// GenerateGuestToken signs a guest token for the retro's share link, valid until expiresAt.
+// guestID is uuid.Nil for a guest link, or the guest's user ID for their session.
+func (m *JWTManager) GenerateGuestToken(retroID, shareID, guestID uuid.UUID, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := GuestClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{GuestAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+		RetroID: retroID.String(),
+		ShareID: shareID.String(),
+	}
+	if guestID != uuid.Nil {
+		claims.Subject = guestID.String()
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+// ValidateGuestToken validates a guest token and returns the claims
+func (m *JWTManager) ValidateGuestToken(tokenString string) (*GuestClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &GuestClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return m.secret, nil
+	}, jwt.WithAudience(GuestAudience))
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*GuestClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if _, err := uuid.Parse(claims.RetroID); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if _, err := uuid.Parse(claims.ShareID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/oauth2"
@@ -112,3 +113,42 @@ func (p *OIDCProvider) GetIssuer() string {
 func (p *OIDCProvider) IsConfigured() bool {
 	return p.provider != nil
 }
+
+// redactedClaimKeys are ID token claims that bind the token to a session and must never be persisted
+var redactedClaimKeys = map[string]bool{
+	"at_hash": true,
+	"c_hash":  true,
+	"s_hash":  true,
+	"nonce":   true,
+	"sid":     true,
+}
+
+// RedactClaims returns a copy of the raw claims with secret-bearing values replaced, recursing into nested objects and arrays
+func RedactClaims(raw map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		lower := strings.ToLower(key)
+		if redactedClaimKeys[lower] || strings.Contains(lower, "token") || strings.Contains(lower, "secret") || strings.Contains(lower, "password") {
+			redacted[key] = "[redacted]"
+			continue
+		}
+		redacted[key] = redactClaimValue(value)
+	}
+	return redacted
+}
+
+// redactClaimValue redacts the objects found in a claim value, at any depth
+func redactClaimValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return RedactClaims(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, elem := range v {
+			redacted[i] = redactClaimValue(elem)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactClaims(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "top level",
+			raw:  map[string]interface{}{"sub": "42", "nonce": "n", "SID": "s", "refresh_token": "r", "client_secret": "c"},
+			want: map[string]interface{}{"sub": "42", "nonce": "[redacted]", "SID": "[redacted]", "refresh_token": "[redacted]", "client_secret": "[redacted]"},
+		},
+		{
+			name: "nested object",
+			raw: map[string]interface{}{
+				"profile": map[string]interface{}{"name": "Alice", "id_token": "t", "session": map[string]interface{}{"sid": "s"}},
+			},
+			want: map[string]interface{}{
+				"profile": map[string]interface{}{"name": "Alice", "id_token": "[redacted]", "session": map[string]interface{}{"sid": "[redacted]"}},
+			},
+		},
+		{
+			name: "array",
+			raw: map[string]interface{}{
+				"groups":     []interface{}{"dev", "ops"},
+				"identities": []interface{}{map[string]interface{}{"provider": "github", "access_token": "t"}, []interface{}{map[string]interface{}{"nonce": "n", "appSecretKey": "k"}}},
+			},
+			want: map[string]interface{}{
+				"groups":     []interface{}{"dev", "ops"},
+				"identities": []interface{}{map[string]interface{}{"provider": "github", "access_token": "[redacted]"}, []interface{}{map[string]interface{}{"nonce": "[redacted]", "appSecretKey": "[redacted]"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactClaims(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactClaims() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package bus_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	"github.com/jycamier/retrotro/backend/internal/models"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// retroStateOf200Items is a retro_state-sized message
+func retroStateOf200Items() ws.Message {
+	items := make([]*models.Item, 200)
+	for i := range items {
+		items[i] = &models.Item{
+			ID:        uuid.New(),
+			RetroID:   uuid.New(),
+			ColumnID:  "went-well",
+			Content:   "An item with a sentence or two of content, as written during a brainstorm phase.",
+			AuthorID:  uuid.New(),
+			Position:  i,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			VoteCount: i % 5,
+			Author:    &models.User{ID: uuid.New(), DisplayName: "Participant"},
+		}
+	}
+	return ws.Message{Type: "retro_state", Payload: map[string]interface{}{"items": items}}
+}
+
+func BenchmarkBroadcastRetroState(b *testing.B) {
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	pubSub := gochannel.NewGoChannel(gochannel.Config{OutputChannelBuffer: 1024}, watermill.NopLogger{})
+	b.Cleanup(func() { _ = pubSub.Close() })
+
+	hub := ws.NewHub()
+	go hub.Run()
+	relay := bus.NewWatermillBus(hub, pubSub, pubSub)
+	if err := relay.Start(context.Background()); err != nil {
+		b.Fatalf("start bus: %v", err)
+	}
+	b.Cleanup(relay.Stop)
+
+	roomID := uuid.NewString()
+	msg := retroStateOf200Items()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		relay.BroadcastToRoom(roomID, msg)
+	}
+}
@@ -3,6 +3,7 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/google/uuid"
 
@@ -15,19 +16,31 @@ type MessageBus interface {
 	BroadcastToRoomExcept(roomID string, msg websocket.Message, exclude *websocket.Client)
 	GetRoomClients(roomID string) []*websocket.Client
 	IsUserInRoom(roomID string, userID uuid.UUID) bool
-	PublishPresenceJoin(roomID string, userID uuid.UUID, userName string)
+	PublishPresenceJoin(roomID string, userID uuid.UUID, userName string, spectator, guest bool)
 	PublishPresenceLeave(roomID string, userID uuid.UUID)
 	PublishToRemotePods(roomID string, msg websocket.Message)
+	// RelayRaw broadcasts an already marshaled websocket.Message to the room on every pod.
+	// Its type is unknown, so it is counted as a "raw" broadcast.
+	RelayRaw(roomID string, data []byte)
 	Hub() *websocket.Hub
 	Start(ctx context.Context) error
 	Stop()
+	// Healthy returns an error when the cross-pod relay cannot deliver messages.
+	Healthy() error
+	// Stats returns the relay counters of this pod, keyed by topic.
+	Stats() map[string]TopicStats
 }
 
+// ErrBusNotStarted is returned by Healthy before Start or after Stop.
+var ErrBusNotStarted = errors.New("bus: not started")
+
 // RemoteUser represents a user connected on another pod.
 type RemoteUser struct {
-	UserID   uuid.UUID
-	UserName string
-	PodID    string
+	UserID    uuid.UUID
+	UserName  string
+	PodID     string
+	Spectator bool
+	Guest     bool
 }
 
 // roomMessage is the envelope for room broadcasts between pods.
@@ -39,9 +52,11 @@ type roomMessage struct {
 
 // presenceMessage is the envelope for presence events between pods.
 type presenceMessage struct {
-	PodID    string    `json:"podId"`
-	RoomID   string    `json:"roomId"`
-	UserID   uuid.UUID `json:"userId"`
-	UserName string    `json:"userName,omitempty"`
-	Action   string    `json:"action"`
+	PodID     string    `json:"podId"`
+	RoomID    string    `json:"roomId"`
+	UserID    uuid.UUID `json:"userId"`
+	UserName  string    `json:"userName,omitempty"`
+	Spectator bool      `json:"spectator,omitempty"`
+	Guest     bool      `json:"guest,omitempty"`
+	Action    string    `json:"action"`
 }
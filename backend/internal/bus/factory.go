@@ -26,6 +26,9 @@ var Module = fx.Module("bus",
 // NewMessageBusFx creates a MessageBus and registers lifecycle hooks with fx.
 func NewMessageBusFx(lc fx.Lifecycle, hub *websocket.Hub, pool *pgxpool.Pool, cfg *config.Config) (MessageBus, error) {
 	switch cfg.BusType {
+	case "local", "":
+		slog.Info("bus: using local bus (single pod, no cross-pod relay)")
+		return NewLocalBus(hub), nil
 	case "nats":
 		return newNATSBus(lc, hub, cfg)
 	default:
@@ -94,7 +97,7 @@ func newWatermillBus(lc fx.Lifecycle, hub *websocket.Hub, pool *pgxpool.Pool, cf
 // createPubSub builds the Watermill Publisher and Subscriber for non-NATS backends.
 func createPubSub(cfg *config.Config, pool *pgxpool.Pool, logger watermill.LoggerAdapter) (message.Publisher, message.Subscriber, error) {
 	switch cfg.BusType {
-	case "gochannel", "":
+	case "gochannel":
 		ch := gochannel.NewGoChannel(
 			gochannel.Config{OutputChannelBuffer: 256},
 			logger,
@@ -140,6 +143,6 @@ func createPubSub(cfg *config.Config, pool *pgxpool.Pool, logger watermill.Logge
 		return pub, sub, nil
 
 	default:
-		return nil, nil, fmt.Errorf("bus: unknown BusType %q (valid: gochannel, nats, sql)", cfg.BusType)
+		return nil, nil, fmt.Errorf("bus: unknown BusType %q (valid: local, gochannel, nats, sql)", cfg.BusType)
 	}
 }
@@ -0,0 +1,104 @@
+package bus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+const (
+	// defaultPodHeartbeatInterval is how often a pod announces it is alive
+	defaultPodHeartbeatInterval = 10 * time.Second
+	// defaultPodTimeout is how long a silent pod's users stay in the presence list
+	defaultPodTimeout = 30 * time.Second
+)
+
+// Presence actions besides join and leave
+const (
+	presenceHeartbeat = "heartbeat"
+	presencePodDown   = "pod_down"
+)
+
+// podLiveness records when each remote pod was last heard from, so the users
+// of a pod that crashed without sending leaves can be evicted.
+type podLiveness struct {
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func newPodLiveness() *podLiveness {
+	return &podLiveness{
+		lastSeen: make(map[string]time.Time),
+		interval: defaultPodHeartbeatInterval,
+		timeout:  defaultPodTimeout,
+	}
+}
+
+// seen records that a message from the pod was just received.
+func (l *podLiveness) seen(podID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSeen[podID] = time.Now()
+}
+
+// forget drops the pod, e.g. after it announced it is shutting down.
+func (l *podLiveness) forget(podID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.lastSeen, podID)
+}
+
+// expired returns and forgets the pods not heard from within the timeout.
+func (l *podLiveness) expired(now time.Time) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var pods []string
+	for podID, seen := range l.lastSeen {
+		if now.Sub(seen) > l.timeout {
+			pods = append(pods, podID)
+			delete(l.lastSeen, podID)
+		}
+	}
+	return pods
+}
+
+// removePodUsers deletes every remote user of the pod and returns them by room.
+// The caller must hold the lock guarding remoteUsers.
+func removePodUsers(remoteUsers map[string]map[string]RemoteUser, podID string) map[string][]RemoteUser {
+	removed := make(map[string][]RemoteUser)
+	for roomID, room := range remoteUsers {
+		for key, ru := range room {
+			if ru.PodID != podID {
+				continue
+			}
+			removed[roomID] = append(removed[roomID], ru)
+			delete(room, key)
+		}
+		if len(room) == 0 {
+			delete(remoteUsers, roomID)
+		}
+	}
+	return removed
+}
+
+// announceLeft tells local clients that evicted remote users left, unless they
+// are still connected to this pod. The dead pod cannot send participant_left itself.
+func announceLeft(hub *websocket.Hub, removed map[string][]RemoteUser) {
+	for roomID, users := range removed {
+		for _, ru := range users {
+			if hub.IsUserInRoom(roomID, ru.UserID) {
+				continue
+			}
+			hub.BroadcastToRoom(roomID, websocket.Message{
+				Type: "participant_left",
+				Payload: map[string]interface{}{
+					"userId": ru.UserID,
+				},
+			})
+		}
+	}
+}
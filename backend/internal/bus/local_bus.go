@@ -0,0 +1,80 @@
+package bus
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// Compile-time check that LocalBus implements MessageBus.
+var _ MessageBus = (*LocalBus)(nil)
+
+// LocalBus implements MessageBus for a single pod. It broadcasts through the
+// local websocket.Hub only: there is no cross-pod relay and no remote users.
+type LocalBus struct {
+	hub *websocket.Hub
+}
+
+// NewLocalBus creates a new LocalBus over the given hub.
+func NewLocalBus(hub *websocket.Hub) *LocalBus {
+	return &LocalBus{hub: hub}
+}
+
+// Hub returns the underlying websocket.Hub.
+func (b *LocalBus) Hub() *websocket.Hub {
+	return b.hub
+}
+
+// Start is a no-op; there is nothing to subscribe to.
+func (b *LocalBus) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (b *LocalBus) Stop() {}
+
+// BroadcastToRoom broadcasts a message to all local clients in the room.
+func (b *LocalBus) BroadcastToRoom(roomID string, msg websocket.Message) {
+	b.hub.BroadcastToRoom(roomID, msg)
+}
+
+// BroadcastToRoomExcept broadcasts to all local clients in the room except one.
+func (b *LocalBus) BroadcastToRoomExcept(roomID string, msg websocket.Message, exclude *websocket.Client) {
+	b.hub.BroadcastToRoomExcept(roomID, msg, exclude)
+}
+
+// RelayRaw broadcasts pre-serialized data to all local clients in the room.
+func (b *LocalBus) RelayRaw(roomID string, data []byte) {
+	b.hub.BroadcastData(roomID, "raw", data, nil)
+}
+
+// PublishToRemotePods is a no-op since there are no remote pods.
+func (b *LocalBus) PublishToRemotePods(_ string, _ websocket.Message) {}
+
+// GetRoomClients returns the local clients in a room.
+func (b *LocalBus) GetRoomClients(roomID string) []*websocket.Client {
+	return b.hub.GetRoomClients(roomID)
+}
+
+// IsUserInRoom returns true if the user is connected locally to the room.
+func (b *LocalBus) IsUserInRoom(roomID string, userID uuid.UUID) bool {
+	return b.hub.IsUserInRoom(roomID, userID)
+}
+
+// PublishPresenceJoin is a no-op; presence is tracked by the hub.
+func (b *LocalBus) PublishPresenceJoin(_ string, _ uuid.UUID, _ string, _, _ bool) {}
+
+// PublishPresenceLeave is a no-op; presence is tracked by the hub.
+func (b *LocalBus) PublishPresenceLeave(_ string, _ uuid.UUID) {}
+
+// Healthy always succeeds since there is no relay to fail.
+func (b *LocalBus) Healthy() error {
+	return nil
+}
+
+// Stats returns no counters since nothing is relayed.
+func (b *LocalBus) Stats() map[string]TopicStats {
+	return map[string]TopicStats{}
+}
@@ -0,0 +1,52 @@
+package bus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func TestLocalBusBroadcastsWithinPod(t *testing.T) {
+	pod := testenv.NewLocalPod(t)
+	roomID := uuid.NewString()
+
+	alice := pod.Connect(t, uuid.New(), "Alice", roomID)
+	bob := pod.Connect(t, uuid.New(), "Bob", roomID)
+	outsider := pod.Connect(t, uuid.New(), "Carol", uuid.NewString())
+
+	pod.Bus.BroadcastToRoom(roomID, ws.Message{Type: "item_created"})
+	alice.Expect(t, "item_created", wait)
+	bob.Expect(t, "item_created", wait)
+	outsider.ExpectNone(t, "item_created", 0)
+
+	pod.Bus.BroadcastToRoomExcept(roomID, ws.Message{Type: "draft_typing"}, alice.Client)
+	bob.Expect(t, "draft_typing", wait)
+	alice.ExpectNone(t, "draft_typing", 100*time.Millisecond)
+
+	// Remote-only messages have nowhere to go
+	pod.Bus.PublishToRemotePods(roomID, ws.Message{Type: "remote_only"})
+	bob.ExpectNone(t, "remote_only", 100*time.Millisecond)
+}
+
+func TestLocalBusTracksOnlyLocalUsers(t *testing.T) {
+	pod := testenv.NewLocalPod(t)
+	roomID := uuid.NewString()
+
+	alice := pod.Connect(t, uuid.New(), "Alice", roomID)
+	remote := uuid.New()
+	pod.Bus.PublishPresenceJoin(roomID, remote, "Remote", false, false)
+
+	testenv.Eventually(t, wait, func() bool {
+		return pod.Bus.IsUserInRoom(roomID, alice.UserID)
+	}, "alice never appeared in the room")
+	if pod.Bus.IsUserInRoom(roomID, remote) {
+		t.Error("a presence join on the local bus should not create a remote user")
+	}
+	if users := roomUsers(pod.Bus.GetRoomClients(roomID)); len(users) != 1 || !users[alice.UserID] {
+		t.Errorf("room clients = %v, want only alice", users)
+	}
+}
@@ -3,8 +3,11 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
@@ -23,9 +26,11 @@ type natsEnvelope struct {
 
 // natsPresenceMessage is published on presence subjects.
 type natsPresenceMessage struct {
-	PodID    string    `json:"podId"`
-	UserID   uuid.UUID `json:"userId"`
-	UserName string    `json:"userName,omitempty"`
+	PodID     string    `json:"podId"`
+	UserID    uuid.UUID `json:"userId"`
+	UserName  string    `json:"userName,omitempty"`
+	Spectator bool      `json:"spectator,omitempty"`
+	Guest     bool      `json:"guest,omitempty"`
 }
 
 // NATSDirectBus implements MessageBus using native NATS connections (no Watermill).
@@ -37,8 +42,25 @@ type NATSDirectBus struct {
 	mu          sync.RWMutex
 	remoteUsers map[string]map[string]RemoteUser // roomID -> userID -> RemoteUser
 	subs        []*nats.Subscription
+	liveness    *podLiveness
+	stats       *busStats
+	started     atomic.Bool
+	done        chan struct{}
 }
 
+// Pod liveness subjects, shared by all rooms
+const (
+	natsSubjectHeartbeat = "retrotro.pod.heartbeat"
+	natsSubjectPodDown   = "retrotro.pod.down"
+)
+
+// natsResyncType is relayed in place of a room message too large for the server; clients
+// that receive it reload the retro
+const natsResyncType = "resync"
+
+// natsHealthTimeout bounds the round trip to the NATS server in Healthy
+const natsHealthTimeout = 2 * time.Second
+
 // NewNATSDirectBus creates a new bus backed by a native NATS connection.
 func NewNATSDirectBus(hub *websocket.Hub, conn *nats.Conn) *NATSDirectBus {
 	return &NATSDirectBus{
@@ -46,9 +68,18 @@ func NewNATSDirectBus(hub *websocket.Hub, conn *nats.Conn) *NATSDirectBus {
 		conn:        conn,
 		podID:       uuid.New().String(),
 		remoteUsers: make(map[string]map[string]RemoteUser),
+		liveness:    newPodLiveness(),
+		stats:       newBusStats(),
 	}
 }
 
+// SetPodTimeouts changes how often this pod sends heartbeats and how long the users
+// of a silent remote pod are kept. It must be called before Start.
+func (b *NATSDirectBus) SetPodTimeouts(heartbeat, timeout time.Duration) {
+	b.liveness.interval = heartbeat
+	b.liveness.timeout = timeout
+}
+
 // Hub returns the underlying websocket.Hub.
 func (b *NATSDirectBus) Hub() *websocket.Hub {
 	return b.hub
@@ -74,12 +105,52 @@ func (b *NATSDirectBus) Start(_ context.Context) error {
 	}
 	b.subs = append(b.subs, sub)
 
+	sub, err = b.conn.Subscribe(natsSubjectHeartbeat, b.handlePodHeartbeat)
+	if err != nil {
+		return err
+	}
+	b.subs = append(b.subs, sub)
+
+	sub, err = b.conn.Subscribe(natsSubjectPodDown, b.handlePodDown)
+	if err != nil {
+		return err
+	}
+	b.subs = append(b.subs, sub)
+
+	b.done = make(chan struct{})
+	go b.runLiveness(b.done)
+
+	b.started.Store(true)
 	slog.Info("nats direct bus: subscribed", "podId", b.podID)
 	return nil
 }
 
-// Stop unsubscribes and drains the NATS connection.
+// Healthy checks the NATS connection and does a round trip to the server.
+func (b *NATSDirectBus) Healthy() error {
+	if !b.started.Load() {
+		return ErrBusNotStarted
+	}
+	if !b.conn.IsConnected() {
+		return fmt.Errorf("bus: NATS connection is %s", b.conn.Status())
+	}
+	if err := b.conn.FlushTimeout(natsHealthTimeout); err != nil {
+		return fmt.Errorf("bus: NATS ping: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the relay counters of this pod, keyed by topic.
+func (b *NATSDirectBus) Stats() map[string]TopicStats {
+	return b.stats.snapshot()
+}
+
+// Stop announces the shutdown to remote pods, unsubscribes and drains the NATS connection.
 func (b *NATSDirectBus) Stop() {
+	if b.started.Swap(false) {
+		close(b.done)
+		b.publishPodSignal(natsSubjectPodDown)
+		_ = b.conn.FlushTimeout(natsHealthTimeout)
+	}
 	for _, sub := range b.subs {
 		_ = sub.Unsubscribe()
 	}
@@ -89,16 +160,28 @@ func (b *NATSDirectBus) Stop() {
 	}
 }
 
-// BroadcastToRoom broadcasts locally and publishes to NATS.
+// BroadcastToRoom broadcasts locally and publishes to NATS, marshaling the message once.
 func (b *NATSDirectBus) BroadcastToRoom(roomID string, msg websocket.Message) {
-	b.hub.BroadcastToRoom(roomID, msg)
-	b.publishToNATS(roomID, msg)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		b.stats.dropped(topicRoom)
+		slog.Error("nats: failed to marshal message", "error", err)
+		return
+	}
+	b.hub.BroadcastData(roomID, msg.Type, data, nil)
+	b.publishRawToNATS(roomID, msg.Type, data)
 }
 
 // BroadcastToRoomExcept broadcasts locally with exclude and publishes to NATS.
 func (b *NATSDirectBus) BroadcastToRoomExcept(roomID string, msg websocket.Message, exclude *websocket.Client) {
-	b.hub.BroadcastToRoomExcept(roomID, msg, exclude)
-	b.publishToNATS(roomID, msg)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		b.stats.dropped(topicRoom)
+		slog.Error("nats: failed to marshal message", "error", err)
+		return
+	}
+	b.hub.BroadcastData(roomID, msg.Type, data, exclude)
+	b.publishRawToNATS(roomID, msg.Type, data)
 }
 
 // PublishToRemotePods sends a message only to remote pods.
@@ -106,6 +189,12 @@ func (b *NATSDirectBus) PublishToRemotePods(roomID string, msg websocket.Message
 	b.publishToNATS(roomID, msg)
 }
 
+// RelayRaw broadcasts pre-serialized data locally and publishes it to NATS as is.
+func (b *NATSDirectBus) RelayRaw(roomID string, data []byte) {
+	b.hub.BroadcastData(roomID, "raw", data, nil)
+	b.publishRawToNATS(roomID, "raw", data)
+}
+
 // GetRoomClients returns local clients merged with remote users.
 func (b *NATSDirectBus) GetRoomClients(roomID string) []*websocket.Client {
 	locals := b.hub.GetRoomClients(roomID)
@@ -115,17 +204,19 @@ func (b *NATSDirectBus) GetRoomClients(roomID string) []*websocket.Client {
 		localUserIDs[c.UserID] = true
 	}
 
+	// The inner map is mutated by the presence handler, so iterate it under the lock
 	b.mu.RLock()
-	remotes := b.remoteUsers[roomID]
-	b.mu.RUnlock()
+	defer b.mu.RUnlock()
 
-	for _, ru := range remotes {
+	for _, ru := range b.remoteUsers[roomID] {
 		if !localUserIDs[ru.UserID] {
 			locals = append(locals, &websocket.Client{
-				ID:       "remote-" + ru.UserID.String(),
-				UserID:   ru.UserID,
-				UserName: ru.UserName,
-				RoomID:   roomID,
+				ID:        "remote-" + ru.UserID.String(),
+				UserID:    ru.UserID,
+				UserName:  ru.UserName,
+				RoomID:    roomID,
+				Spectator: ru.Spectator,
+				Guest:     ru.Guest,
 			})
 		}
 	}
@@ -148,7 +239,7 @@ func (b *NATSDirectBus) IsUserInRoom(roomID string, userID uuid.UUID) bool {
 }
 
 // PublishPresenceJoin publishes a presence join event to NATS.
-func (b *NATSDirectBus) PublishPresenceJoin(roomID string, userID uuid.UUID, userName string) {
+func (b *NATSDirectBus) PublishPresenceJoin(roomID string, userID uuid.UUID, userName string, spectator, guest bool) {
 	b.mu.Lock()
 	if room, ok := b.remoteUsers[roomID]; ok {
 		delete(room, userID.String())
@@ -159,18 +250,24 @@ func (b *NATSDirectBus) PublishPresenceJoin(roomID string, userID uuid.UUID, use
 	b.mu.Unlock()
 
 	msg := natsPresenceMessage{
-		PodID:    b.podID,
-		UserID:   userID,
-		UserName: userName,
+		PodID:     b.podID,
+		UserID:    userID,
+		UserName:  userName,
+		Spectator: spectator,
+		Guest:     guest,
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
+		b.stats.dropped(topicPresence)
 		slog.Error("nats: failed to marshal presence join", "error", err)
 		return
 	}
 	if err := b.conn.Publish("retrotro.presence.join."+roomID, data); err != nil {
+		b.stats.dropped(topicPresence)
 		slog.Error("nats: failed to publish presence join", "error", err)
+		return
 	}
+	b.stats.published(topicPresence)
 }
 
 // PublishPresenceLeave publishes a presence leave event to NATS.
@@ -181,12 +278,16 @@ func (b *NATSDirectBus) PublishPresenceLeave(roomID string, userID uuid.UUID) {
 	}
 	data, err := json.Marshal(msg)
 	if err != nil {
+		b.stats.dropped(topicPresence)
 		slog.Error("nats: failed to marshal presence leave", "error", err)
 		return
 	}
 	if err := b.conn.Publish("retrotro.presence.leave."+roomID, data); err != nil {
+		b.stats.dropped(topicPresence)
 		slog.Error("nats: failed to publish presence leave", "error", err)
+		return
 	}
+	b.stats.published(topicPresence)
 }
 
 // --- internal ---
@@ -194,28 +295,57 @@ func (b *NATSDirectBus) PublishPresenceLeave(roomID string, userID uuid.UUID) {
 func (b *NATSDirectBus) publishToNATS(roomID string, msg websocket.Message) {
 	msgData, err := json.Marshal(msg)
 	if err != nil {
+		b.stats.dropped(topicRoom)
 		slog.Error("nats: failed to marshal message", "error", err)
 		return
 	}
+	b.publishRawToNATS(roomID, msg.Type, msgData)
+}
 
+// publishRawToNATS relays a marshaled websocket message; msgType is only logged
+func (b *NATSDirectBus) publishRawToNATS(roomID, msgType string, msgData []byte) {
 	env := natsEnvelope{
 		PodID:   b.podID,
 		Message: msgData,
 	}
 	data, err := json.Marshal(env)
 	if err != nil {
+		b.stats.dropped(topicRoom)
 		slog.Error("nats: failed to marshal envelope", "error", err)
 		return
 	}
 
+	// The server rejects messages above its max_payload. Local clients already got the message, so
+	// relay a small resync instead and let the remote clients reload the retro.
+	if maxPayload := b.conn.MaxPayload(); maxPayload > 0 && int64(len(data)) > maxPayload {
+		b.stats.dropped(topicRoom)
+		slog.Warn("nats: room message exceeds the server max_payload, relaying a resync instead",
+			"roomId", roomID,
+			"msgType", msgType,
+			"size", len(data),
+			"maxPayload", maxPayload,
+		)
+		if msgType != natsResyncType {
+			b.publishToNATS(roomID, websocket.Message{
+				Type:    natsResyncType,
+				Payload: map[string]string{"reason": "message_too_large", "messageType": msgType},
+			})
+		}
+		return
+	}
+
 	if err := b.conn.Publish("retrotro.room."+roomID, data); err != nil {
+		b.stats.dropped(topicRoom)
 		slog.Error("nats: failed to publish room message", "error", err, "roomId", roomID)
+		return
 	}
+	b.stats.published(topicRoom)
 }
 
 func (b *NATSDirectBus) handleRoomMessage(msg *nats.Msg) {
 	var env natsEnvelope
 	if err := json.Unmarshal(msg.Data, &env); err != nil {
+		b.stats.dropped(topicRoom)
 		slog.Error("nats: failed to unmarshal room envelope", "error", err)
 		return
 	}
@@ -223,6 +353,8 @@ func (b *NATSDirectBus) handleRoomMessage(msg *nats.Msg) {
 	if env.PodID == b.podID {
 		return
 	}
+	b.stats.received(topicRoom)
+	b.liveness.seen(env.PodID)
 
 	// Extract roomID from subject: retrotro.room.<roomID>
 	roomID := msg.Subject[len("retrotro.room."):]
@@ -238,6 +370,7 @@ func (b *NATSDirectBus) handleRoomMessage(msg *nats.Msg) {
 func (b *NATSDirectBus) handlePresenceJoin(msg *nats.Msg) {
 	var pm natsPresenceMessage
 	if err := json.Unmarshal(msg.Data, &pm); err != nil {
+		b.stats.dropped(topicPresence)
 		slog.Error("nats: failed to unmarshal presence join", "error", err)
 		return
 	}
@@ -245,6 +378,8 @@ func (b *NATSDirectBus) handlePresenceJoin(msg *nats.Msg) {
 	if pm.PodID == b.podID {
 		return
 	}
+	b.stats.received(topicPresence)
+	b.liveness.seen(pm.PodID)
 
 	roomID := msg.Subject[len("retrotro.presence.join."):]
 
@@ -262,9 +397,11 @@ func (b *NATSDirectBus) handlePresenceJoin(msg *nats.Msg) {
 		b.remoteUsers[roomID] = make(map[string]RemoteUser)
 	}
 	b.remoteUsers[roomID][pm.UserID.String()] = RemoteUser{
-		UserID:   pm.UserID,
-		UserName: pm.UserName,
-		PodID:    pm.PodID,
+		UserID:    pm.UserID,
+		UserName:  pm.UserName,
+		PodID:     pm.PodID,
+		Spectator: pm.Spectator,
+		Guest:     pm.Guest,
 	}
 	b.mu.Unlock()
 }
@@ -272,6 +409,7 @@ func (b *NATSDirectBus) handlePresenceJoin(msg *nats.Msg) {
 func (b *NATSDirectBus) handlePresenceLeave(msg *nats.Msg) {
 	var pm natsPresenceMessage
 	if err := json.Unmarshal(msg.Data, &pm); err != nil {
+		b.stats.dropped(topicPresence)
 		slog.Error("nats: failed to unmarshal presence leave", "error", err)
 		return
 	}
@@ -279,6 +417,8 @@ func (b *NATSDirectBus) handlePresenceLeave(msg *nats.Msg) {
 	if pm.PodID == b.podID {
 		return
 	}
+	b.stats.received(topicPresence)
+	b.liveness.seen(pm.PodID)
 
 	roomID := msg.Subject[len("retrotro.presence.leave."):]
 
@@ -297,3 +437,75 @@ func (b *NATSDirectBus) handlePresenceLeave(msg *nats.Msg) {
 	}
 	b.mu.Unlock()
 }
+
+// runLiveness sends this pod's heartbeats and evicts the users of remote pods
+// that stopped sending theirs, until done is closed.
+func (b *NATSDirectBus) runLiveness(done <-chan struct{}) {
+	ticker := time.NewTicker(b.liveness.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			b.publishPodSignal(natsSubjectHeartbeat)
+			for _, podID := range b.liveness.expired(now) {
+				slog.Warn("nats: remote pod timed out, evicting its users", "podId", podID)
+				b.evictPod(podID)
+			}
+		}
+	}
+}
+
+func (b *NATSDirectBus) publishPodSignal(subject string) {
+	data, err := json.Marshal(natsPresenceMessage{PodID: b.podID})
+	if err != nil {
+		b.stats.dropped(topicPresence)
+		slog.Error("nats: failed to marshal pod signal", "error", err)
+		return
+	}
+	if err := b.conn.Publish(subject, data); err != nil {
+		b.stats.dropped(topicPresence)
+		slog.Error("nats: failed to publish pod signal", "error", err, "subject", subject)
+		return
+	}
+	b.stats.published(topicPresence)
+}
+
+func (b *NATSDirectBus) handlePodHeartbeat(msg *nats.Msg) {
+	var pm natsPresenceMessage
+	if err := json.Unmarshal(msg.Data, &pm); err != nil {
+		b.stats.dropped(topicPresence)
+		slog.Error("nats: failed to unmarshal pod heartbeat", "error", err)
+		return
+	}
+	if pm.PodID == b.podID {
+		return
+	}
+	b.stats.received(topicPresence)
+	b.liveness.seen(pm.PodID)
+}
+
+func (b *NATSDirectBus) handlePodDown(msg *nats.Msg) {
+	var pm natsPresenceMessage
+	if err := json.Unmarshal(msg.Data, &pm); err != nil {
+		b.stats.dropped(topicPresence)
+		slog.Error("nats: failed to unmarshal pod down", "error", err)
+		return
+	}
+	if pm.PodID == b.podID {
+		return
+	}
+	b.stats.received(topicPresence)
+	slog.Info("nats: remote pod shut down, evicting its users", "podId", pm.PodID)
+	b.liveness.forget(pm.PodID)
+	b.evictPod(pm.PodID)
+}
+
+// evictPod removes the remote users of a pod that is gone and tells local clients they left.
+func (b *NATSDirectBus) evictPod(podID string) {
+	b.mu.Lock()
+	removed := removePodUsers(b.remoteUsers, podID)
+	b.mu.Unlock()
+	announceLeft(b.hub, removed)
+}
@@ -0,0 +1,121 @@
+package bus_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// fakeNATSServer speaks just enough of the NATS protocol for a client to connect
+// with the given max_payload; the payloads of published messages are sent on the channel.
+func fakeNATSServer(t *testing.T, maxPayload int) (string, <-chan []byte) {
+	t.Helper()
+	published := make(chan []byte, 16)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer func() { _ = conn.Close() }()
+				_, _ = conn.Write([]byte(`INFO {"server_id":"fake","version":"2.10.0","proto":1,"max_payload":` +
+					strconv.Itoa(maxPayload) + "}\r\n"))
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch {
+					case strings.HasPrefix(line, "PING"):
+						_, _ = conn.Write([]byte("PONG\r\n"))
+					case strings.HasPrefix(line, "PUB "):
+						// PUB <subject> [reply-to] <size>, followed by the payload and CRLF
+						fields := strings.Fields(line)
+						size, err := strconv.Atoi(fields[len(fields)-1])
+						if err != nil {
+							return
+						}
+						payload := make([]byte, size+2)
+						if _, err := io.ReadFull(r, payload); err != nil {
+							return
+						}
+						published <- payload[:size]
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return "nats://" + ln.Addr().String(), published
+}
+
+func TestNATSOversizedRoomMessageIsRelayedAsResync(t *testing.T) {
+	url, published := fakeNATSServer(t, 1024)
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	hub := ws.NewHub()
+	go hub.Run()
+	b := bus.NewNATSDirectBus(hub, conn)
+	if err := b.Start(t.Context()); err != nil {
+		t.Fatalf("start bus: %v", err)
+	}
+	t.Cleanup(b.Stop)
+
+	roomID := uuid.NewString()
+	b.PublishToRemotePods(roomID, ws.Message{Type: "item_created"})
+	b.PublishToRemotePods(roomID, ws.Message{Type: "retro_state", Payload: strings.Repeat("x", 2048)})
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	var types []string
+	for len(types) < 2 {
+		select {
+		case data := <-published:
+			var env struct {
+				Message ws.Message `json:"message"`
+			}
+			if err := json.Unmarshal(data, &env); err != nil {
+				t.Fatalf("decode published envelope: %v", err)
+			}
+			types = append(types, env.Message.Type)
+			if env.Message.Type == "resync" {
+				payload, _ := env.Message.Payload.(map[string]any)
+				if payload["messageType"] != "retro_state" || payload["reason"] != "message_too_large" {
+					t.Errorf("resync payload = %v, want the dropped retro_state named", env.Message.Payload)
+				}
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("published %v, want item_created then resync", types)
+		}
+	}
+	if types[0] != "item_created" || types[1] != "resync" {
+		t.Errorf("published %v, want item_created then resync", types)
+	}
+
+	if got := b.Stats()["retrotro.room"]; got.Published != 2 || got.Dropped != 1 {
+		t.Errorf("room stats = %+v, want the small message and the resync published and the large one dropped", got)
+	}
+}
@@ -0,0 +1,67 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// TopicStats counts the relay traffic of one topic on this pod. Received only
+// counts messages from other pods; dropped counts messages that could not be
+// published or decoded.
+type TopicStats struct {
+	Published uint64 `json:"published"`
+	Received  uint64 `json:"received"`
+	Dropped   uint64 `json:"dropped"`
+}
+
+type topicCounters struct {
+	published atomic.Uint64
+	received  atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// busStats holds the per-topic counters of a bus implementation.
+type busStats struct {
+	mu     sync.RWMutex
+	topics map[string]*topicCounters
+}
+
+func newBusStats() *busStats {
+	return &busStats{topics: make(map[string]*topicCounters)}
+}
+
+func (s *busStats) topic(name string) *topicCounters {
+	s.mu.RLock()
+	c, ok := s.topics[name]
+	s.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok = s.topics[name]; !ok {
+		c = &topicCounters{}
+		s.topics[name] = c
+	}
+	return c
+}
+
+func (s *busStats) published(topic string) { s.topic(topic).published.Add(1) }
+func (s *busStats) received(topic string)  { s.topic(topic).received.Add(1) }
+func (s *busStats) dropped(topic string)   { s.topic(topic).dropped.Add(1) }
+
+// snapshot returns a copy of the counters keyed by topic.
+func (s *busStats) snapshot() map[string]TopicStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]TopicStats, len(s.topics))
+	for name, c := range s.topics {
+		out[name] = TopicStats{
+			Published: c.published.Load(),
+			Received:  c.received.Load(),
+			Dropped:   c.dropped.Load(),
+		}
+	}
+	return out
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
@@ -33,9 +35,17 @@ type WatermillBus struct {
 	remoteUsers map[string]map[string]RemoteUser // roomID -> userID -> RemoteUser
 	mu          sync.RWMutex
 
+	liveness  *podLiveness
+	stats     *busStats
+	started   atomic.Bool
+	consumers atomic.Int32 // running consumer goroutines
+
 	cancel context.CancelFunc
 }
 
+// watermillConsumers is the number of consumer goroutines started by Start
+const watermillConsumers = 2
+
 // NewWatermillBus creates a new WatermillBus. The podID uniquely identifies
 // this process instance so that messages published by this pod are ignored
 // when received back from the message broker.
@@ -46,9 +56,18 @@ func NewWatermillBus(hub *websocket.Hub, pub message.Publisher, sub message.Subs
 		sub:         sub,
 		podID:       watermill.NewUUID(),
 		remoteUsers: make(map[string]map[string]RemoteUser),
+		liveness:    newPodLiveness(),
+		stats:       newBusStats(),
 	}
 }
 
+// SetPodTimeouts changes how often this pod sends heartbeats and how long the users
+// of a silent remote pod are kept. It must be called before Start.
+func (b *WatermillBus) SetPodTimeouts(heartbeat, timeout time.Duration) {
+	b.liveness.interval = heartbeat
+	b.liveness.timeout = timeout
+}
+
 // Hub returns the underlying websocket.Hub.
 func (b *WatermillBus) Hub() *websocket.Hub {
 	return b.hub
@@ -73,14 +92,40 @@ func (b *WatermillBus) Start(ctx context.Context) error {
 		return fmt.Errorf("bus: subscribe to %s: %w", topicPresence, err)
 	}
 
+	b.consumers.Add(watermillConsumers)
+	b.started.Store(true)
 	go b.consumeRoomMessages(ctx, roomMsgs)
 	go b.consumePresenceMessages(ctx, presenceMsgs)
+	go b.runLiveness(ctx)
 
 	return nil
 }
 
-// Stop cancels the internal context, closes the publisher and subscriber.
+// Healthy reports whether both topic consumers are still running. A consumer
+// stops when its subscription channel is closed, e.g. when the broker connection is lost.
+func (b *WatermillBus) Healthy() error {
+	switch running := b.consumers.Load(); {
+	case !b.started.Load():
+		return ErrBusNotStarted
+	case running < watermillConsumers:
+		return fmt.Errorf("bus: %d of %d consumers stopped", watermillConsumers-running, watermillConsumers)
+	}
+	return nil
+}
+
+// Stats returns the relay counters of this pod, keyed by topic.
+func (b *WatermillBus) Stats() map[string]TopicStats {
+	return b.stats.snapshot()
+}
+
+// Stop announces the shutdown to remote pods, cancels the internal context,
+// closes the publisher and subscriber.
 func (b *WatermillBus) Stop() {
+	if b.started.Swap(false) {
+		if err := b.publishPresence(presenceMessage{PodID: b.podID, Action: presencePodDown}); err != nil {
+			slog.Warn("bus: failed to announce pod shutdown", "err", err)
+		}
+	}
 	if b.cancel != nil {
 		b.cancel()
 	}
@@ -93,13 +138,21 @@ func (b *WatermillBus) Stop() {
 }
 
 // BroadcastToRoom broadcasts a message to all local clients in the room and
-// relays it to remote pods via Watermill.
+// relays it to remote pods via Watermill. The message is marshaled once and the
+// bytes are shared by the local fan-out and the relay.
 func (b *WatermillBus) BroadcastToRoom(roomID string, msg websocket.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		b.stats.dropped(topicRoom)
+		slog.Error("bus: failed to marshal room message", "roomId", roomID, "err", err)
+		return
+	}
+
 	// Local broadcast.
-	b.hub.BroadcastToRoom(roomID, msg)
+	b.hub.BroadcastData(roomID, msg.Type, data, nil)
 
 	// Cross-pod relay.
-	if err := b.publishRoomMessage(roomID, msg); err != nil {
+	if err := b.publishRoomData(roomID, msg.Type, data); err != nil {
 		slog.Error("bus: failed to publish room message", "roomId", roomID, "err", err)
 	}
 }
@@ -107,11 +160,18 @@ func (b *WatermillBus) BroadcastToRoom(roomID string, msg websocket.Message) {
 // BroadcastToRoomExcept broadcasts to all local clients except one, and relays
 // to remote pods via Watermill.
 func (b *WatermillBus) BroadcastToRoomExcept(roomID string, msg websocket.Message, exclude *websocket.Client) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		b.stats.dropped(topicRoom)
+		slog.Error("bus: failed to marshal room message (except)", "roomId", roomID, "err", err)
+		return
+	}
+
 	// Local broadcast (excluding the given client).
-	b.hub.BroadcastToRoomExcept(roomID, msg, exclude)
+	b.hub.BroadcastData(roomID, msg.Type, data, exclude)
 
 	// Cross-pod relay (remote pods have no concept of the excluded client).
-	if err := b.publishRoomMessage(roomID, msg); err != nil {
+	if err := b.publishRoomData(roomID, msg.Type, data); err != nil {
 		slog.Error("bus: failed to publish room message (except)", "roomId", roomID, "err", err)
 	}
 }
@@ -124,14 +184,25 @@ func (b *WatermillBus) PublishToRemotePods(roomID string, msg websocket.Message)
 	}
 }
 
+// RelayRaw broadcasts pre-serialized data to local clients in the room and
+// relays it to remote pods without marshaling it again.
+func (b *WatermillBus) RelayRaw(roomID string, data []byte) {
+	b.hub.BroadcastData(roomID, "raw", data, nil)
+
+	if err := b.publishRoomData(roomID, "raw", data); err != nil {
+		slog.Error("bus: failed to publish raw room message", "roomId", roomID, "err", err)
+	}
+}
+
 // GetRoomClients returns local + remote users in a room.
 func (b *WatermillBus) GetRoomClients(roomID string) []*websocket.Client {
 	localClients := b.hub.GetRoomClients(roomID)
 
+	// The inner map is mutated by the presence consumer, so iterate it under the lock
 	b.mu.RLock()
-	remoteRoom, exists := b.remoteUsers[roomID]
-	b.mu.RUnlock()
+	defer b.mu.RUnlock()
 
+	remoteRoom, exists := b.remoteUsers[roomID]
 	if !exists || len(remoteRoom) == 0 {
 		return localClients
 	}
@@ -144,8 +215,10 @@ func (b *WatermillBus) GetRoomClients(roomID string) []*websocket.Client {
 	for _, ru := range remoteRoom {
 		if !localUserIDs[ru.UserID] {
 			localClients = append(localClients, &websocket.Client{
-				UserID:   ru.UserID,
-				UserName: ru.UserName,
+				UserID:    ru.UserID,
+				UserName:  ru.UserName,
+				Spectator: ru.Spectator,
+				Guest:     ru.Guest,
 			})
 		}
 	}
@@ -171,7 +244,7 @@ func (b *WatermillBus) IsUserInRoom(roomID string, userID uuid.UUID) bool {
 // PublishPresenceJoin publishes a presence-join event to remote pods.
 // It also removes the user from remoteUsers if they were previously tracked as remote
 // (handles the case where a user reconnects to this pod after being on another).
-func (b *WatermillBus) PublishPresenceJoin(roomID string, userID uuid.UUID, userName string) {
+func (b *WatermillBus) PublishPresenceJoin(roomID string, userID uuid.UUID, userName string, spectator, guest bool) {
 	b.mu.Lock()
 	if room, exists := b.remoteUsers[roomID]; exists {
 		delete(room, userID.String())
@@ -182,11 +255,13 @@ func (b *WatermillBus) PublishPresenceJoin(roomID string, userID uuid.UUID, user
 	b.mu.Unlock()
 
 	env := presenceMessage{
-		PodID:    b.podID,
-		RoomID:   roomID,
-		UserID:   userID,
-		UserName: userName,
-		Action:   "join",
+		PodID:     b.podID,
+		RoomID:    roomID,
+		UserID:    userID,
+		UserName:  userName,
+		Spectator: spectator,
+		Guest:     guest,
+		Action:    "join",
 	}
 	if err := b.publishPresence(env); err != nil {
 		slog.Error("bus: failed to publish presence join", "roomId", roomID, "userId", userID, "err", err)
@@ -211,8 +286,14 @@ func (b *WatermillBus) PublishPresenceLeave(roomID string, userID uuid.UUID) {
 func (b *WatermillBus) publishRoomMessage(roomID string, msg websocket.Message) error {
 	payload, err := json.Marshal(msg)
 	if err != nil {
+		b.stats.dropped(topicRoom)
 		return fmt.Errorf("marshal websocket message: %w", err)
 	}
+	return b.publishRoomData(roomID, msg.Type, payload)
+}
+
+// publishRoomData relays a marshaled websocket message; msgType is only logged
+func (b *WatermillBus) publishRoomData(roomID, msgType string, payload []byte) error {
 	env := roomMessage{
 		PodID:   b.podID,
 		RoomID:  roomID,
@@ -220,39 +301,53 @@ func (b *WatermillBus) publishRoomMessage(roomID string, msg websocket.Message)
 	}
 	data, err := json.Marshal(env)
 	if err != nil {
+		b.stats.dropped(topicRoom)
 		return fmt.Errorf("marshal room envelope: %w", err)
 	}
 	wm := message.NewMessage(watermill.NewUUID(), data)
 	slog.Info("bus: publishing room message to NATS",
 		"roomId", roomID,
 		"podId", b.podID,
-		"msgType", msg.Type,
+		"msgType", msgType,
 		"topic", topicRoom,
 	)
-	err = b.pub.Publish(topicRoom, wm)
-	if err != nil {
+	if err := b.pub.Publish(topicRoom, wm); err != nil {
+		b.stats.dropped(topicRoom)
 		slog.Error("bus: NATS publish failed", "err", err, "roomId", roomID)
+		return err
 	}
-	return err
+	b.stats.published(topicRoom)
+	return nil
 }
 
 func (b *WatermillBus) publishPresence(env presenceMessage) error {
 	data, err := json.Marshal(env)
 	if err != nil {
+		b.stats.dropped(topicPresence)
 		return fmt.Errorf("marshal presence envelope: %w", err)
 	}
 	wm := message.NewMessage(watermill.NewUUID(), data)
-	slog.Info("bus: publishing presence message to NATS",
+	level := slog.LevelInfo
+	if env.Action == presenceHeartbeat {
+		level = slog.LevelDebug
+	}
+	slog.Log(context.Background(), level, "bus: publishing presence message to NATS",
 		"action", env.Action,
 		"roomId", env.RoomID,
 		"userId", env.UserID,
 		"podId", b.podID,
 		"topic", topicPresence,
 	)
-	return b.pub.Publish(topicPresence, wm)
+	if err := b.pub.Publish(topicPresence, wm); err != nil {
+		b.stats.dropped(topicPresence)
+		return err
+	}
+	b.stats.published(topicPresence)
+	return nil
 }
 
 func (b *WatermillBus) consumeRoomMessages(ctx context.Context, msgs <-chan *message.Message) {
+	defer b.consumers.Add(-1)
 	for {
 		select {
 		case <-ctx.Done():
@@ -265,6 +360,7 @@ func (b *WatermillBus) consumeRoomMessages(ctx context.Context, msgs <-chan *mes
 
 			var env roomMessage
 			if err := json.Unmarshal(wm.Payload, &env); err != nil {
+				b.stats.dropped(topicRoom)
 				slog.Warn("bus: failed to unmarshal room message", "err", err)
 				continue
 			}
@@ -272,6 +368,8 @@ func (b *WatermillBus) consumeRoomMessages(ctx context.Context, msgs <-chan *mes
 			if env.PodID == b.podID {
 				continue
 			}
+			b.stats.received(topicRoom)
+			b.liveness.seen(env.PodID)
 			localClients := b.hub.GetRoomClients(env.RoomID)
 			slog.Info("bus: received remote room message",
 				"roomId", env.RoomID,
@@ -286,6 +384,7 @@ func (b *WatermillBus) consumeRoomMessages(ctx context.Context, msgs <-chan *mes
 }
 
 func (b *WatermillBus) consumePresenceMessages(ctx context.Context, msgs <-chan *message.Message) {
+	defer b.consumers.Add(-1)
 	for {
 		select {
 		case <-ctx.Done():
@@ -298,6 +397,7 @@ func (b *WatermillBus) consumePresenceMessages(ctx context.Context, msgs <-chan
 
 			var env presenceMessage
 			if err := json.Unmarshal(wm.Payload, &env); err != nil {
+				b.stats.dropped(topicPresence)
 				slog.Warn("bus: failed to unmarshal presence message", "err", err)
 				continue
 			}
@@ -305,6 +405,7 @@ func (b *WatermillBus) consumePresenceMessages(ctx context.Context, msgs <-chan
 			if env.PodID == b.podID {
 				continue
 			}
+			b.stats.received(topicPresence)
 			slog.Debug("bus: received remote presence message",
 				"action", env.Action,
 				"roomId", env.RoomID,
@@ -317,6 +418,10 @@ func (b *WatermillBus) consumePresenceMessages(ctx context.Context, msgs <-chan
 }
 
 func (b *WatermillBus) handleRemotePresence(env presenceMessage) {
+	if env.Action != presencePodDown {
+		b.liveness.seen(env.PodID)
+	}
+
 	switch env.Action {
 	case "join":
 		b.mu.Lock()
@@ -324,9 +429,11 @@ func (b *WatermillBus) handleRemotePresence(env presenceMessage) {
 			b.remoteUsers[env.RoomID] = make(map[string]RemoteUser)
 		}
 		b.remoteUsers[env.RoomID][env.UserID.String()] = RemoteUser{
-			UserID:   env.UserID,
-			UserName: env.UserName,
-			PodID:    env.PodID,
+			UserID:    env.UserID,
+			UserName:  env.UserName,
+			PodID:     env.PodID,
+			Spectator: env.Spectator,
+			Guest:     env.Guest,
 		}
 		b.mu.Unlock()
 		// Cancel any local pending-disconnect timer so we don't emit a spurious
@@ -343,7 +450,43 @@ func (b *WatermillBus) handleRemotePresence(env presenceMessage) {
 		}
 		b.mu.Unlock()
 
+	case presenceHeartbeat:
+		// Only refreshes the pod's liveness
+
+	case presencePodDown:
+		b.liveness.forget(env.PodID)
+		b.evictPod(env.PodID)
+
 	default:
 		slog.Warn("bus: unknown presence action", "action", env.Action)
 	}
 }
+
+// runLiveness sends this pod's heartbeats and evicts the users of remote pods
+// that stopped sending theirs, until the context is cancelled.
+func (b *WatermillBus) runLiveness(ctx context.Context) {
+	ticker := time.NewTicker(b.liveness.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := b.publishPresence(presenceMessage{PodID: b.podID, Action: presenceHeartbeat}); err != nil {
+				slog.Warn("bus: failed to publish heartbeat", "err", err)
+			}
+			for _, podID := range b.liveness.expired(now) {
+				slog.Warn("bus: remote pod timed out, evicting its users", "podId", podID)
+				b.evictPod(podID)
+			}
+		}
+	}
+}
+
+// evictPod removes the remote users of a pod that is gone and tells local clients they left.
+func (b *WatermillBus) evictPod(podID string) {
+	b.mu.Lock()
+	removed := removePodUsers(b.remoteUsers, podID)
+	b.mu.Unlock()
+	announceLeft(b.hub, removed)
+}
@@ -0,0 +1,235 @@
+package bus_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+const wait = 2 * time.Second
+
+func roomUsers(clients []*ws.Client) map[uuid.UUID]bool {
+	users := make(map[uuid.UUID]bool, len(clients))
+	for _, c := range clients {
+		users[c.UserID] = true
+	}
+	return users
+}
+
+func TestBroadcastReachesOtherPodExactlyOnce(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	alice := podA.Connect(t, uuid.New(), "Alice", roomID)
+	bob := podB.Connect(t, uuid.New(), "Bob", roomID)
+	outsider := podB.Connect(t, uuid.New(), "Carol", uuid.NewString())
+
+	podA.Bus.BroadcastToRoom(roomID, ws.Message{Type: "item_created"})
+
+	alice.Expect(t, "item_created", wait)
+	bob.Expect(t, "item_created", wait)
+
+	// Neither the local echo nor the relay may deliver a second copy
+	alice.ExpectNone(t, "item_created", 200*time.Millisecond)
+	bob.ExpectNone(t, "item_created", 200*time.Millisecond)
+	outsider.ExpectNone(t, "item_created", 0)
+}
+
+func TestBroadcastExceptSkipsOnlyLocalSender(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	alice := podA.Connect(t, uuid.New(), "Alice", roomID)
+	aliceTab := podA.Connect(t, alice.UserID, "Alice", roomID)
+	bob := podB.Connect(t, uuid.New(), "Bob", roomID)
+
+	podA.Bus.BroadcastToRoomExcept(roomID, ws.Message{Type: "draft_typing"}, alice.Client)
+
+	aliceTab.Expect(t, "draft_typing", wait)
+	bob.Expect(t, "draft_typing", wait)
+	alice.ExpectNone(t, "draft_typing", 200*time.Millisecond)
+}
+
+func TestPresencePropagatesBetweenPods(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	alice := podA.Connect(t, uuid.New(), "Alice", roomID)
+	bob := podB.Connect(t, uuid.New(), "Bob", roomID)
+	podA.Bus.PublishPresenceJoin(roomID, alice.UserID, alice.UserName, false, false)
+	podB.Bus.PublishPresenceJoin(roomID, bob.UserID, bob.UserName, false, false)
+
+	for name, pod := range map[string]*testenv.Pod{"A": podA, "B": podB} {
+		testenv.Eventually(t, wait, func() bool {
+			users := roomUsers(pod.Bus.GetRoomClients(roomID))
+			return len(users) == 2 && users[alice.UserID] && users[bob.UserID]
+		}, "pod %s does not list both users in the room", name)
+	}
+	if !podB.Bus.IsUserInRoom(roomID, alice.UserID) {
+		t.Fatal("pod B does not see Alice as in the room")
+	}
+
+	// Remote users are merged with, not duplicated over, local connections
+	podB.Bus.PublishPresenceJoin(roomID, bob.UserID, bob.UserName, false, false)
+	if n := len(podA.Bus.GetRoomClients(roomID)); n != 2 {
+		t.Fatalf("pod A lists %d room clients after a repeated join, want 2", n)
+	}
+
+	alice.Disconnect()
+	podA.Bus.PublishPresenceLeave(roomID, alice.UserID)
+
+	testenv.Eventually(t, wait, func() bool {
+		return !podB.Bus.IsUserInRoom(roomID, alice.UserID)
+	}, "pod B still sees Alice after she left")
+	if users := roomUsers(podB.Bus.GetRoomClients(roomID)); len(users) != 1 || !users[bob.UserID] {
+		t.Fatalf("pod B room clients after leave: %v", users)
+	}
+}
+
+func TestRemoteJoinCancelsPendingDisconnect(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	alice := podA.Connect(t, uuid.New(), "Alice", roomID)
+	observer := podA.Connect(t, uuid.New(), "Bob", roomID)
+
+	// Alice drops from pod A, which schedules participant_left after the grace period...
+	alice.Disconnect()
+	testenv.Eventually(t, wait, func() bool {
+		return podA.Hub.HasPendingDisconnect(roomID, alice.UserID)
+	}, "pod A did not schedule a pending disconnect")
+
+	// ...and reconnects through pod B before it expires
+	podB.Connect(t, alice.UserID, "Alice", roomID)
+	podB.Bus.PublishPresenceJoin(roomID, alice.UserID, "Alice", false, false)
+
+	testenv.Eventually(t, wait, func() bool {
+		return !podA.Hub.HasPendingDisconnect(roomID, alice.UserID)
+	}, "remote join did not cancel the pending disconnect on pod A")
+	if !podA.Bus.IsUserInRoom(roomID, alice.UserID) {
+		t.Fatal("pod A does not see Alice as in the room after her remote join")
+	}
+	observer.ExpectNone(t, "participant_left", 100*time.Millisecond)
+}
+
+func TestRelayStatsAndHealth(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	bob := podB.Connect(t, uuid.New(), "Bob", roomID)
+	podA.Bus.BroadcastToRoom(roomID, ws.Message{Type: "item_created"})
+	bob.Expect(t, "item_created", wait)
+
+	if got := podA.Bus.Stats()["retrotro.room"]; got.Published != 1 || got.Received != 0 {
+		t.Errorf("sender room stats = %+v, want 1 published", got)
+	}
+	testenv.Eventually(t, wait, func() bool {
+		return podB.Bus.Stats()["retrotro.room"].Received == 1
+	}, "receiver never counted the relayed message: %+v", podB.Bus.Stats())
+
+	if err := podA.Bus.Healthy(); err != nil {
+		t.Errorf("started bus unhealthy: %v", err)
+	}
+	podA.Bus.Stop()
+	if err := podA.Bus.Healthy(); err == nil {
+		t.Error("stopped bus reported healthy")
+	}
+}
+
+func TestSilentPodUsersAreEvicted(t *testing.T) {
+	pubSub := gochannel.NewGoChannel(gochannel.Config{OutputChannelBuffer: 256}, watermill.NewSlogLogger(slog.Default()))
+	t.Cleanup(func() { _ = pubSub.Close() })
+
+	hub := ws.NewHub()
+	go hub.Run()
+	b := bus.NewWatermillBus(hub, pubSub, pubSub)
+	b.SetPodTimeouts(20*time.Millisecond, 150*time.Millisecond)
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("start bus: %v", err)
+	}
+	t.Cleanup(b.Stop)
+	pod := &testenv.Pod{Hub: hub, Bus: b}
+
+	roomID := uuid.NewString()
+	observer := pod.Connect(t, uuid.New(), "Alice", roomID)
+
+	// A pod announces a user, then crashes without a leave or any heartbeat
+	ghost := uuid.New()
+	data, err := json.Marshal(map[string]interface{}{
+		"podId": "crashed-pod", "roomId": roomID, "userId": ghost, "userName": "Ghost", "action": "join",
+	})
+	if err != nil {
+		t.Fatalf("marshal presence: %v", err)
+	}
+	if err := pubSub.Publish("retrotro.presence", message.NewMessage(watermill.NewUUID(), data)); err != nil {
+		t.Fatalf("publish presence: %v", err)
+	}
+
+	testenv.Eventually(t, wait, func() bool {
+		return b.IsUserInRoom(roomID, ghost)
+	}, "the remote user never joined")
+	testenv.Eventually(t, wait, func() bool {
+		return !b.IsUserInRoom(roomID, ghost)
+	}, "the silent pod's user was never evicted")
+	observer.Expect(t, "participant_left", wait)
+}
+
+func TestPodShutdownEvictsItsUsers(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	alice := podA.Connect(t, uuid.New(), "Alice", roomID)
+	bob := podB.Connect(t, uuid.New(), "Bob", roomID)
+	podB.Bus.PublishPresenceJoin(roomID, bob.UserID, bob.UserName, false, false)
+	testenv.Eventually(t, wait, func() bool {
+		return podA.Bus.IsUserInRoom(roomID, bob.UserID)
+	}, "pod A never saw Bob")
+
+	podB.Bus.Stop()
+
+	testenv.Eventually(t, wait, func() bool {
+		return !podA.Bus.IsUserInRoom(roomID, bob.UserID)
+	}, "pod A still lists Bob after pod B shut down")
+	alice.Expect(t, "participant_left", wait)
+}
+
+func TestRelayRawReachesEveryPodOnce(t *testing.T) {
+	cluster := testenv.NewCluster(t)
+	podA, podB := cluster.NewPod(t), cluster.NewPod(t)
+	roomID := uuid.NewString()
+
+	alice := podA.Connect(t, uuid.New(), "Alice", roomID)
+	bob := podB.Connect(t, uuid.New(), "Bob", roomID)
+
+	data, err := json.Marshal(ws.Message{Type: "retro_state", Payload: map[string]string{"cached": "yes"}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	podA.Bus.RelayRaw(roomID, data)
+
+	for _, c := range []*testenv.Client{alice, bob} {
+		payload := c.Expect(t, "retro_state", wait)
+		var body map[string]string
+		if err := json.Unmarshal(payload, &body); err != nil || body["cached"] != "yes" {
+			t.Errorf("%s got payload %s (%v)", c.UserName, payload, err)
+		}
+		c.ExpectNone(t, "retro_state", 200*time.Millisecond)
+	}
+}
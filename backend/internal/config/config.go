@@ -14,9 +14,34 @@ type Config struct {
 	DevMode     bool
 	OIDC        OIDCConfig
 	JWT         JWTConfig
+	Webhooks    WebhookConfig
 	BusType         string
 	NatsURL         string
 	NatsCredentials string
+	// FacilitatorReassign is "auto" to promote someone when the facilitator leaves an active retro, or "manual"
+	FacilitatorReassign string
+	// WSStateSnapshotThreshold is the retro_state size in bytes above which clients fetch it over REST (0 disables)
+	WSStateSnapshotThreshold int
+	// WSAllowQueryToken accepts the deprecated ?token= query parameter on WebSocket connections
+	WSAllowQueryToken bool
+	// WSSendBufferSize is the number of outgoing messages queued per client before it is disconnected as too slow
+	WSSendBufferSize int
+	// WSDraftTypingInterval is the least number of milliseconds between two relayed draft_typing of a client in a column (0 relays all)
+	WSDraftTypingInterval int
+	// WSVoteBatchWindow is how many milliseconds vote changes are coalesced in retros with batchVoteUpdates (0 disables batching)
+	WSVoteBatchWindow int
+	// WSTokenRecheckInterval is how many seconds apart the tokens of open WebSocket connections are re-validated (0 disables)
+	WSTokenRecheckInterval int
+	// WSTokenClockSkew is how many seconds past its expiry a WebSocket connection's token is still accepted
+	WSTokenClockSkew int
+	// WSMaxConnections is the most WebSocket connections a pod accepts at once (0 disables the limit)
+	WSMaxConnections int
+	// WSMaxRooms is the most retrospective rooms a pod keeps open at once (0 disables the limit)
+	WSMaxRooms int
+	// ScheduledStartGrace is how many seconds before scheduledAt a scheduled retro is started
+	ScheduledStartGrace int
+	// RetroReminderMinutes is how long before scheduledAt the retro.reminder webhook fires (0 disables)
+	RetroReminderMinutes int
 }
 
 // OIDCConfig holds OIDC provider configuration
@@ -27,6 +52,9 @@ type OIDCConfig struct {
 	RedirectURL  string
 	Scopes       []string
 	JIT          JITConfig
+	// DebugClaims stores the last redacted ID token claims per user for admins to inspect
+	DebugClaims    bool
+	DebugClaimsTTL int // minutes
 }
 
 // JITConfig holds Just-In-Time provisioning configuration
@@ -41,11 +69,20 @@ type JITConfig struct {
 	RemoveStaleMembers bool
 }
 
+// WebhookConfig holds outbound webhook configuration
+type WebhookConfig struct {
+	// AllowedNetworks are CIDRs or IPs webhooks may reach even though they are not public (e.g. an internal chat server)
+	AllowedNetworks []string
+	// DeniedNetworks are CIDRs or IPs webhooks may never reach
+	DeniedNetworks []string
+}
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
 	Secret          string
 	AccessTokenTTL  int // minutes
 	RefreshTokenTTL int // hours
+	GuestTokenTTL   int // minutes a guest link stays valid
 }
 
 // Load loads configuration from environment variables
@@ -53,6 +90,21 @@ func Load() (*Config, error) {
 	port, _ := strconv.Atoi(getEnv("PORT", "8080"))
 	accessTTL, _ := strconv.Atoi(getEnv("JWT_ACCESS_TOKEN_TTL", "15"))
 	refreshTTL, _ := strconv.Atoi(getEnv("JWT_REFRESH_TOKEN_TTL", "168")) // 7 days
+	guestTTL, _ := strconv.Atoi(getEnv("GUEST_TOKEN_TTL", "120"))
+	debugClaimsTTL, _ := strconv.Atoi(getEnv("DEBUG_OIDC_CLAIMS_TTL", "60"))
+	snapshotThreshold, _ := strconv.Atoi(getEnv("WS_STATE_SNAPSHOT_THRESHOLD", "524288")) // 512 KiB
+	sendBufferSize, _ := strconv.Atoi(getEnv("WS_SEND_BUFFER_SIZE", "256"))
+	if sendBufferSize < 1 {
+		sendBufferSize = 256
+	}
+	draftTypingInterval, _ := strconv.Atoi(getEnv("WS_DRAFT_TYPING_INTERVAL", "500"))
+	voteBatchWindow, _ := strconv.Atoi(getEnv("WS_VOTE_BATCH_WINDOW", "300"))
+	tokenRecheckInterval, _ := strconv.Atoi(getEnv("WS_TOKEN_RECHECK_INTERVAL", "60"))
+	tokenClockSkew, _ := strconv.Atoi(getEnv("WS_TOKEN_CLOCK_SKEW", "30"))
+	maxConnections, _ := strconv.Atoi(getEnv("WS_MAX_CONNECTIONS", "10000"))
+	maxRooms, _ := strconv.Atoi(getEnv("WS_MAX_ROOMS", "2000"))
+	scheduledStartGrace, _ := strconv.Atoi(getEnv("SCHEDULED_START_GRACE", "60"))
+	reminderMinutes, _ := strconv.Atoi(getEnv("RETRO_REMINDER_MINUTES", "15"))
 
 	return &Config{
 		Port:        port,
@@ -75,15 +127,34 @@ func Load() (*Config, error) {
 				SyncOnLogin:        getEnv("OIDC_JIT_SYNC_ON_LOGIN", "true") == "true",
 				RemoveStaleMembers: getEnv("OIDC_JIT_REMOVE_STALE_MEMBERS", "false") == "true",
 			},
+			DebugClaims:    getEnv("DEBUG_OIDC_CLAIMS", "false") == "true",
+			DebugClaimsTTL: debugClaimsTTL,
 		},
 		JWT: JWTConfig{
 			Secret:          getEnv("JWT_SECRET", "change-me-in-production"),
 			AccessTokenTTL:  accessTTL,
 			RefreshTokenTTL: refreshTTL,
+			GuestTokenTTL:   guestTTL,
+		},
+		Webhooks: WebhookConfig{
+			AllowedNetworks: strings.Split(getEnv("WEBHOOK_ALLOWED_NETWORKS", ""), ","),
+			DeniedNetworks:  strings.Split(getEnv("WEBHOOK_DENIED_NETWORKS", ""), ","),
 		},
-		BusType:         getEnv("BUS_TYPE", "gochannel"),
+		BusType:         getEnv("BUS_TYPE", "local"),
 		NatsURL:         getEnv("NATS_URL", ""),
 		NatsCredentials: getEnv("NATS_CREDENTIALS", ""),
+		FacilitatorReassign: getEnv("FACILITATOR_REASSIGN", "auto"),
+		WSStateSnapshotThreshold: snapshotThreshold,
+		WSAllowQueryToken: getEnv("WS_ALLOW_QUERY_TOKEN", "true") == "true",
+		WSSendBufferSize:  sendBufferSize,
+		WSDraftTypingInterval: draftTypingInterval,
+		WSVoteBatchWindow: voteBatchWindow,
+		WSTokenRecheckInterval: tokenRecheckInterval,
+		WSTokenClockSkew: tokenClockSkew,
+		WSMaxConnections: maxConnections,
+		WSMaxRooms:       maxRooms,
+		ScheduledStartGrace: scheduledStartGrace,
+		RetroReminderMinutes: reminderMinutes,
 	}, nil
 }
 
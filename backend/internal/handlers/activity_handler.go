@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+)
+
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+)
+
+// ActivityHandler handles team activity endpoints
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(activityService *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{activityService: activityService}
+}
+
+// parseActivityFilter extracts date range and pagination parameters from query string
+func parseActivityFilter(r *http.Request) (*models.ActivityFilter, error) {
+	query := r.URL.Query()
+	filter := &models.ActivityFilter{Limit: defaultActivityLimit}
+
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.From = &from
+	}
+
+	if toStr := query.Get("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.To = &to
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = min(limit, maxActivityLimit)
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, nil
+}
+
+// GetTeamActivity returns the activity timeline of a team
+func (h *ActivityHandler) GetTeamActivity(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseActivityFilter(r)
+	if err != nil {
+		http.Error(w, `{"error": "invalid date format, expected RFC3339"}`, http.StatusBadRequest)
+		return
+	}
+
+	events, total, err := h.activityService.GetTeamActivity(ctx, userID, teamID, filter)
+	if err != nil {
+		if err == services.ErrNotTeamMember {
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	_ = json.NewEncoder(w).Encode(events)
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -15,14 +17,20 @@ type AdminHandler struct {
 	userRepo       *postgres.UserRepository
 	teamRepo       *postgres.TeamRepository
 	teamMemberRepo *postgres.TeamMemberRepository
+	oidcDebugRepo  *postgres.OIDCDebugRepository
+	auditRepo      *postgres.ConnectionAuditRepository
+	oidcDebug      bool
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(userRepo *postgres.UserRepository, teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository) *AdminHandler {
+func NewAdminHandler(userRepo *postgres.UserRepository, teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository, oidcDebugRepo *postgres.OIDCDebugRepository, auditRepo *postgres.ConnectionAuditRepository, oidcDebug bool) *AdminHandler {
 	return &AdminHandler{
 		userRepo:       userRepo,
 		teamRepo:       teamRepo,
 		teamMemberRepo: teamMemberRepo,
+		oidcDebugRepo:  oidcDebugRepo,
+		auditRepo:      auditRepo,
+		oidcDebug:      oidcDebug,
 	}
 }
 
@@ -89,3 +97,55 @@ func (h *AdminHandler) GetTeamMembers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(members)
 }
+
+// GetUserOIDCDebug returns the redacted ID token claims captured at the user's last login
+func (h *AdminHandler) GetUserOIDCDebug(w http.ResponseWriter, r *http.Request) {
+	if !h.oidcDebug {
+		http.Error(w, `{"error": "OIDC claims debugging is disabled"}`, http.StatusNotFound)
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims, err := h.oidcDebugRepo.FindByUser(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			http.Error(w, `{"error": "no claims captured for this user"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(claims)
+}
+
+// ListRetroConnections returns the most recent WebSocket connections that joined a retrospective
+func (h *AdminHandler) ListRetroConnections(w http.ResponseWriter, r *http.Request) {
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	entries, err := h.auditRepo.ListByRetro(r.Context(), retroID, limit)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
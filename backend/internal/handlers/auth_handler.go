@@ -4,9 +4,15 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/jycamier/retrotro/backend/internal/config"
 	"github.com/jycamier/retrotro/backend/internal/middleware"
 	"github.com/jycamier/retrotro/backend/internal/services"
@@ -189,8 +195,15 @@ func (h *AuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
 	_ = user // User info could be included in response if needed
 }
 
-// Logout handles logout
+// Logout handles logout. The access token sent along is revoked, which also closes
+// the WebSocket connections opened with it.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		if err := h.authService.RevokeToken(r.Context(), token); err != nil {
+			slog.Warn("logout: failed to revoke access token", "error", err)
+		}
+	}
+
 	// Clear refresh token cookie
 	http.SetCookie(w, &http.Cookie{
 		Name:     "refresh_token",
@@ -292,6 +305,40 @@ func (h *AuthHandler) GetDevUsers(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// DevResetRequest represents a dev reset request; an empty body resets every team
+type DevResetRequest struct {
+	TeamID *uuid.UUID `json:"teamId"`
+}
+
+// DevReset deletes the retrospectives of a team or of every team and re-seeds the dev
+// users, so that e2e runs start from the same state
+func (h *AuthHandler) DevReset(w http.ResponseWriter, r *http.Request) {
+	if !h.devMode {
+		http.Error(w, `{"error": "dev mode not enabled"}`, http.StatusForbidden)
+		return
+	}
+
+	if h.devSeeder == nil {
+		http.Error(w, `{"error": "dev seeder not initialized"}`, http.StatusInternalServerError)
+		return
+	}
+
+	var req DevResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.devSeeder.Reset(r.Context(), req.TeamID)
+	if err != nil {
+		http.Error(w, `{"error": "failed to reset: `+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(summary)
+}
+
 // generateState generates a random state string
 func generateState() string {
 	b := make([]byte, 32)
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"time"
+
 	"go.uber.org/fx"
 
 	"github.com/jycamier/retrotro/backend/internal/bus"
@@ -19,6 +22,10 @@ var Module = fx.Module("handler",
 		NewStatsHandler,
 		NewAdminHandlerFx,
 		NewWebhookHandlerFx,
+		NewActivityHandler,
+		NewRecurringRetroHandler,
+		NewHealthHandler,
+		NewIdempotencyHandler,
 	),
 )
 
@@ -28,8 +35,12 @@ func NewAuthHandlerFx(authService *services.AuthService, cfg *config.Config, dev
 }
 
 // NewRetrospectiveHandlerFx creates the retrospective handler for fx
-func NewRetrospectiveHandlerFx(retroService *services.RetrospectiveService, timerService *services.TimerService, leanCoffeeService *services.LeanCoffeeService, analysisService *services.AnalysisService) *RetrospectiveHandler {
-	return NewRetrospectiveHandler(retroService, timerService, leanCoffeeService, analysisService)
+func NewRetrospectiveHandlerFx(retroService *services.RetrospectiveService, timerService *services.TimerService, leanCoffeeService *services.LeanCoffeeService, analysisService *services.AnalysisService, surveyService *services.SurveyService, snapshotService *services.SnapshotService, authService *services.AuthService, bridge bus.MessageBus, cfg *config.Config) *RetrospectiveHandler {
+	var frontendURL string
+	if len(cfg.CORSOrigins) > 0 {
+		frontendURL = cfg.CORSOrigins[0]
+	}
+	return NewRetrospectiveHandler(retroService, timerService, leanCoffeeService, analysisService, surveyService, snapshotService, authService, bridge, frontendURL)
 }
 
 // NewWebSocketHandlerFx creates the WebSocket handler for fx
@@ -40,15 +51,39 @@ func NewWebSocketHandlerFx(
 	timerService *services.TimerService,
 	authService *services.AuthService,
 	leanCoffeeService *services.LeanCoffeeService,
+	surveyService *services.SurveyService,
 	teamMemberRepo *postgres.TeamMemberRepository,
 	attendeeRepo *postgres.AttendeeRepository,
+	auditRepo *postgres.ConnectionAuditRepository,
+	transferRepo *postgres.FacilitatorTransferRepository,
+	snapshotService *services.SnapshotService,
+	cfg *config.Config,
+	lc fx.Lifecycle,
 ) *WebSocketHandler {
-	return NewWebSocketHandler(hub, bridge, retroService, timerService, authService, leanCoffeeService, teamMemberRepo, attendeeRepo)
+	h := NewWebSocketHandler(hub, bridge, retroService, timerService, authService, leanCoffeeService, surveyService, teamMemberRepo, attendeeRepo, auditRepo, transferRepo, snapshotService, cfg.FacilitatorReassign == "auto", cfg.WSStateSnapshotThreshold, cfg.CORSOrigins, cfg.DevMode, cfg.WSAllowQueryToken, cfg.WSSendBufferSize, time.Duration(cfg.WSDraftTypingInterval)*time.Millisecond, time.Duration(cfg.WSVoteBatchWindow)*time.Millisecond, time.Duration(cfg.WSTokenRecheckInterval)*time.Second, time.Duration(cfg.WSTokenClockSkew)*time.Second)
+
+	// Re-validate the tokens of open connections, write the connection audit log and sweep
+	// expired facilitator transfers for the app's lifetime
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			h.tokens.Start()
+			h.audit.Start()
+			h.transfers.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			h.tokens.Stop()
+			h.audit.Stop()
+			h.transfers.Stop()
+			return nil
+		},
+	})
+	return h
 }
 
 // NewAdminHandlerFx creates the admin handler for fx
-func NewAdminHandlerFx(userRepo *postgres.UserRepository, teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository) *AdminHandler {
-	return NewAdminHandler(userRepo, teamRepo, teamMemberRepo)
+func NewAdminHandlerFx(userRepo *postgres.UserRepository, teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository, oidcDebugRepo *postgres.OIDCDebugRepository, auditRepo *postgres.ConnectionAuditRepository, cfg *config.Config) *AdminHandler {
+	return NewAdminHandler(userRepo, teamRepo, teamMemberRepo, oidcDebugRepo, auditRepo, cfg.OIDC.DebugClaims)
 }
 
 // NewWebhookHandlerFx creates the webhook handler for fx
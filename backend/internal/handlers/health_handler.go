@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// HealthHandler handles readiness checks
+type HealthHandler struct {
+	bus bus.MessageBus
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(messageBus bus.MessageBus) *HealthHandler {
+	return &HealthHandler{bus: messageBus}
+}
+
+// healthResponse is the body of /healthz
+type healthResponse struct {
+	Status    string               `json:"status"`
+	Bus       busHealthStats       `json:"bus"`
+	WebSocket websocketHealthStats `json:"websocket"`
+}
+
+type websocketHealthStats struct {
+	SendBuffer ws.SendBufferStats `json:"sendBuffer"`
+}
+
+type busHealthStats struct {
+	Healthy bool                      `json:"healthy"`
+	Error   string                    `json:"error,omitempty"`
+	Topics  map[string]bus.TopicStats `json:"topics"`
+}
+
+// Healthz reports whether the message bus relay is working, with its per-topic counters
+// and how full client send buffers get.
+// It returns 503 when the bus is unhealthy so readiness probes take the pod out of rotation.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{
+		Status: "ok",
+		Bus: busHealthStats{
+			Healthy: true,
+			Topics:  h.bus.Stats(),
+		},
+		WebSocket: websocketHealthStats{SendBuffer: h.bus.Hub().SendBufferStats()},
+	}
+	status := http.StatusOK
+	if err := h.bus.Healthy(); err != nil {
+		resp.Status = "unavailable"
+		resp.Bus.Healthy = false
+		resp.Bus.Error = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
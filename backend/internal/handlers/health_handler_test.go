@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// brokenBus is a bus whose relay has failed
+type brokenBus struct {
+	bus.MessageBus
+}
+
+func (brokenBus) Healthy() error { return errors.New("relay down") }
+
+func TestHealthzReflectsBusHealth(t *testing.T) {
+	local := bus.NewLocalBus(ws.NewHub())
+
+	for name, tc := range map[string]struct {
+		bus    bus.MessageBus
+		status int
+	}{
+		"healthy": {bus: local, status: http.StatusOK},
+		"broken":  {bus: brokenBus{local}, status: http.StatusServiceUnavailable},
+	} {
+		rec := httptest.NewRecorder()
+		NewHealthHandler(tc.bus).Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, tc.status)
+		}
+		var body healthResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decode body: %v", name, err)
+		}
+		if body.Bus.Healthy != (tc.status == http.StatusOK) {
+			t.Errorf("%s: body = %+v", name, body)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/services"
+)
+
+const (
+	// idempotencyKeyHeader is the request header a client sets to make a create safe to retry
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks a response replayed from an earlier request with the same key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 1 << 20
+)
+
+// IdempotencyHandler replays the stored response of a request retried with the same Idempotency-Key
+type IdempotencyHandler struct {
+	idempotencyService *services.IdempotencyService
+}
+
+// NewIdempotencyHandler creates a new idempotency handler
+func NewIdempotencyHandler(idempotencyService *services.IdempotencyService) *IdempotencyHandler {
+	return &IdempotencyHandler{idempotencyService: idempotencyService}
+}
+
+// Idempotent processes a request with an Idempotency-Key header once per user and key:
+// retries with the same method, path and body get the first response back, retries with
+// another request are rejected. Server errors free the key so the client may retry.
+// Requests without the header are passed through.
+func (h *IdempotencyHandler) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, `{"error": "idempotency key is too long"}`, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			http.Error(w, `{"error": "request body is too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		userID := middleware.GetUserID(ctx)
+		stored, err := h.idempotencyService.Begin(ctx, userID, key, idempotencyRequestHash(r, body))
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+			http.Error(w, `{"error": "a request with this idempotency key is still being processed"}`, http.StatusConflict)
+			return
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			http.Error(w, `{"error": "idempotency key was already used for another request"}`, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			slog.Error("idempotency: failed to claim key", "userId", userID, "error", err)
+			http.Error(w, `{"error": "failed to check idempotency key"}`, http.StatusInternalServerError)
+			return
+		}
+
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(*stored.StatusCode)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// The response must be stored even if the client went away meanwhile
+		ctx = context.WithoutCancel(ctx)
+		if rec.status >= http.StatusInternalServerError {
+			err = h.idempotencyService.Abandon(ctx, userID, key)
+		} else {
+			err = h.idempotencyService.Finish(ctx, userID, key, rec.status, w.Header().Get("Content-Type"), rec.body.Bytes())
+		}
+		if err != nil {
+			slog.Error("idempotency: failed to store response", "userId", userID, "error", err)
+		}
+	})
+}
+
+// idempotencyRequestHash fingerprints the request a key was used for
+func idempotencyRequestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder writes the response through while keeping a copy to store
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestIdempotentCreateActionIsReplayed(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := &RetrospectiveHandler{retroService: env.Services.Retro}
+	idem := NewIdempotencyHandler(env.Services.Idempotency)
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	router := chi.NewRouter()
+	router.With(idem.Idempotent).Post("/retrospectives/{retroId}/actions", h.CreateAction)
+
+	create := func(userID uuid.UUID, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/retrospectives/"+retro.ID.String()+"/actions", strings.NewReader(body))
+		req.Header.Set(idempotencyKeyHeader, key)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) models.ActionItem {
+		t.Helper()
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("got %d, want 201: %s", rec.Code, rec.Body.String())
+		}
+		var action models.ActionItem
+		if err := json.Unmarshal(rec.Body.Bytes(), &action); err != nil {
+			t.Fatalf("decode action: %v", err)
+		}
+		return action
+	}
+
+	first := decode(create(alice.ID, "retry-1", `{"title":"Fix the build"}`))
+	replay := create(alice.ID, "retry-1", `{"title":"Fix the build"}`)
+	if got := decode(replay); got.ID != first.ID {
+		t.Fatalf("replay returned action %s, want the first action %s", got.ID, first.ID)
+	}
+	if replay.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Fatalf("replay is missing the %s header", idempotentReplayedHeader)
+	}
+
+	actions, err := env.Services.Retro.ListActions(context.Background(), retro.ID)
+	if err != nil {
+		t.Fatalf("list actions: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("got %d actions after the replay, want 1", len(actions))
+	}
+
+	// Keys are scoped per user
+	if other := decode(create(bob.ID, "retry-1", `{"title":"Fix the build"}`)); other.ID == first.ID {
+		t.Fatal("another user's request with the same key was replayed")
+	}
+
+	if rec := create(alice.ID, "retry-1", `{"title":"Something else"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key with another body got %d, want 422", rec.Code)
+	}
+}
+
+func TestIdempotentKeyHeader(t *testing.T) {
+	idem := NewIdempotencyHandler(nil)
+	calls := 0
+	handler := idem.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("without a key got %d after %d calls, want the request passed through", rec.Code, calls)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+	req.Header.Set(idempotencyKeyHeader, strings.Repeat("k", maxIdempotencyKeyLength+1))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || calls != 1 {
+		t.Fatalf("with a too long key got %d after %d calls, want 400 without calling the handler", rec.Code, calls)
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Metrics exposes the pod's WebSocket and bus counters in the Prometheus text format.
+// Gauges are read from the hub on each scrape, so they follow register, unregister, join and leave.
+func (h *HealthHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	hub := h.bus.Hub().Metrics()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	writeMetric(out, "retrotro_ws_connections", "gauge", "Open WebSocket connections on this pod.")
+	fmt.Fprintf(out, "retrotro_ws_connections %d\n", hub.Connections)
+	writeMetric(out, "retrotro_ws_rooms", "gauge", "Retrospective rooms with at least one client on this pod.")
+	fmt.Fprintf(out, "retrotro_ws_rooms %d\n", hub.Rooms)
+	writeMetric(out, "retrotro_ws_pending_disconnects", "gauge", "Clients in their reconnection grace period.")
+	fmt.Fprintf(out, "retrotro_ws_pending_disconnects %d\n", hub.PendingDisconnects)
+	writeMetric(out, "retrotro_ws_limit", "gauge", "Most connections or rooms this pod accepts at once, 0 for no limit.")
+	fmt.Fprintf(out, "retrotro_ws_limit{resource=\"connections\"} %d\n", hub.Limits.MaxConnections)
+	fmt.Fprintf(out, "retrotro_ws_limit{resource=\"rooms\"} %d\n", hub.Limits.MaxRooms)
+	writeMetric(out, "retrotro_ws_limit_rejections_total", "counter", "Connections and room joins refused because the pod was at its limit.")
+	fmt.Fprintf(out, "retrotro_ws_limit_rejections_total{resource=\"connections\"} %d\n", hub.Limits.RejectedConnections)
+	fmt.Fprintf(out, "retrotro_ws_limit_rejections_total{resource=\"rooms\"} %d\n", hub.Limits.RejectedRooms)
+
+	writeMetric(out, "retrotro_ws_broadcasts_total", "counter", "Room broadcasts sent from this pod, by message type.")
+	for _, msgType := range sortedKeys(hub.Broadcasts) {
+		fmt.Fprintf(out, "retrotro_ws_broadcasts_total{type=%q} %d\n", msgType, hub.Broadcasts[msgType])
+	}
+
+	writeMetric(out, "retrotro_ws_send_buffer_high_water", "gauge", "Most messages queued for one client at once.")
+	fmt.Fprintf(out, "retrotro_ws_send_buffer_high_water %d\n", hub.SendBuffer.HighWater)
+	writeMetric(out, "retrotro_ws_send_buffer_overflows_total", "counter", "Messages that found a client's send buffer full.")
+	fmt.Fprintf(out, "retrotro_ws_send_buffer_overflows_total %d\n", hub.SendBuffer.Overflows)
+	writeMetric(out, "retrotro_ws_unknown_messages_total", "counter", "Incoming WebSocket messages of a type the server does not handle.")
+	fmt.Fprintf(out, "retrotro_ws_unknown_messages_total %d\n", hub.UnknownMessages)
+
+	topics := h.bus.Stats()
+	writeMetric(out, "retrotro_bus_messages_total", "counter", "Messages relayed through the message bus, by topic and direction.")
+	for _, topic := range sortedKeys(topics) {
+		s := topics[topic]
+		fmt.Fprintf(out, "retrotro_bus_messages_total{topic=%q,direction=\"published\"} %d\n", topic, s.Published)
+		fmt.Fprintf(out, "retrotro_bus_messages_total{topic=%q,direction=\"received\"} %d\n", topic, s.Received)
+		fmt.Fprintf(out, "retrotro_bus_messages_total{topic=%q,direction=\"dropped\"} %d\n", topic, s.Dropped)
+	}
+
+	healthy := 1
+	if h.bus.Healthy() != nil {
+		healthy = 0
+	}
+	writeMetric(out, "retrotro_bus_healthy", "gauge", "Whether the message bus relay is working.")
+	fmt.Fprintf(out, "retrotro_bus_healthy %d\n", healthy)
+}
+
+func writeMetric(out *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func scrape(t *testing.T, h *HealthHandler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Metrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func TestMetricsFollowConnectionsAndBroadcasts(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	messageBus := bus.NewLocalBus(hub)
+	h := NewHealthHandler(messageBus)
+
+	roomID := uuid.NewString()
+	client := &ws.Client{ID: "c1", UserID: uuid.New(), Hub: hub, Send: make(chan []byte, 8)}
+	hub.Register(client)
+	hub.JoinRoom(client, roomID)
+	messageBus.BroadcastToRoom(roomID, ws.Message{Type: "item_created"})
+	<-client.Send
+
+	want := []string{
+		"retrotro_ws_connections 1\n",
+		"retrotro_ws_rooms 1\n",
+		`retrotro_ws_broadcasts_total{type="item_created"} 1` + "\n",
+		"retrotro_bus_healthy 1\n",
+		"# TYPE retrotro_bus_messages_total counter\n",
+	}
+	body := scrape(t, h)
+	for _, line := range want {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics missing %q:\n%s", line, body)
+		}
+	}
+
+	hub.LeaveRoom(client)
+	hub.Unregister(client)
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(scrape(t, h), "retrotro_ws_connections 0\n") {
+		if time.Now().After(deadline) {
+			t.Fatalf("connection gauge not decremented:\n%s", scrape(t, h))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if body := scrape(t, h); !strings.Contains(body, "retrotro_ws_rooms 0\n") {
+		t.Errorf("room gauge not decremented:\n%s", body)
+	}
+}
+
+func TestUnknownMessageTypeIsReported(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	h := &WebSocketHandler{hub: hub}
+	metrics := NewHealthHandler(bus.NewLocalBus(hub))
+	client := &ws.Client{ID: "c1", UserID: uuid.New(), Hub: hub, Send: make(chan []byte, 8)}
+
+	h.handleMessage(client, []byte(`{"type":"item_pin","payload":{}}`))
+
+	select {
+	case data := <-client.Send:
+		var msg struct {
+			Type    string `json:"type"`
+			Payload struct {
+				Code        string `json:"code"`
+				MessageType string `json:"messageType"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		if msg.Type != "error" || msg.Payload.Code != "unknown_message_type" || msg.Payload.MessageType != "item_pin" {
+			t.Fatalf("got %s, want unknown_message_type for item_pin", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no error received")
+	}
+
+	if body := scrape(t, metrics); !strings.Contains(body, "retrotro_ws_unknown_messages_total 1\n") {
+		t.Errorf("unknown message not counted:\n%s", body)
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/services"
+)
+
+// RecurringRetroHandler handles recurring retrospective endpoints
+type RecurringRetroHandler struct {
+	recurringService *services.RecurringRetroService
+}
+
+// NewRecurringRetroHandler creates a new recurring retrospective handler
+func NewRecurringRetroHandler(recurringService *services.RecurringRetroService) *RecurringRetroHandler {
+	return &RecurringRetroHandler{recurringService: recurringService}
+}
+
+// PreviewRequest represents a recurring schedule preview request
+type PreviewRequest struct {
+	CronExpression string `json:"cronExpression"`
+	Timezone       string `json:"timezone"`
+	Count          int    `json:"count"`
+}
+
+// Preview returns the next occurrences of a cron expression
+func (h *RecurringRetroHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req PreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	occurrences, err := h.recurringService.PreviewOccurrences(ctx, userID, teamID, services.PreviewOccurrencesInput{
+		CronExpression: req.CronExpression,
+		Timezone:       req.Timezone,
+		Count:          req.Count,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCron):
+			http.Error(w, `{"error": "invalid cron expression"}`, http.StatusBadRequest)
+		case errors.Is(err, services.ErrInvalidTimezone):
+			http.Error(w, `{"error": "invalid timezone"}`, http.StatusBadRequest)
+		case errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"occurrences": occurrences,
+	})
+}
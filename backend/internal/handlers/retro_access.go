@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/services"
+)
+
+// RequireRetroAccess rejects requests on a retrospective, its actions, timer and phases
+// from users who are not members of the retrospective's team
+func (h *RetrospectiveHandler) RequireRetroAccess(next http.Handler) http.Handler {
+	return h.requireAccess(next, h.retroService.CheckAccess)
+}
+
+// RequireRetroParticipation rejects requests to read a retrospective or to add its items and votes
+// from users who are not members of the retrospective's team, unless the retro is open
+func (h *RetrospectiveHandler) RequireRetroParticipation(next http.Handler) http.Handler {
+	return h.requireAccess(next, h.retroService.CheckParticipantAccess)
+}
+
+func (h *RetrospectiveHandler) requireAccess(next http.Handler, check func(ctx context.Context, retroID, userID uuid.UUID) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+		if err != nil {
+			http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+			return
+		}
+
+		err = check(r.Context(), retroID, middleware.GetUserID(r.Context()))
+		switch {
+		case errors.Is(err, services.ErrRetroNotFound):
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+			return
+		case errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+			return
+		case err != nil:
+			http.Error(w, `{"error": "failed to check team membership"}`, http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
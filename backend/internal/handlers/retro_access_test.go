@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestRetroRoutesRequireAccess(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := &RetrospectiveHandler{retroService: env.Services.Retro}
+
+	admin := env.CreateUser(t, "Admin")
+	member := env.CreateUser(t, "Member")
+	outsider := env.CreateUser(t, "Outsider")
+	team := env.CreateTeam(t, admin, member)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	open := env.CreateRetro(t, team, admin, services.CreateRetroInput{OpenAccess: true})
+	deleted := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	if err := env.Services.Retro.Delete(context.Background(), deleted.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Route("/retrospectives/{retroId}", func(r chi.Router) {
+		r.Use(h.RequireRetroParticipation)
+		r.Get("/items", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	})
+
+	cases := []struct {
+		name    string
+		userID  uuid.UUID
+		retroID string
+		want    int
+	}{
+		{name: "member", userID: member.ID, retroID: retro.ID.String(), want: http.StatusOK},
+		{name: "non-member", userID: outsider.ID, retroID: retro.ID.String(), want: http.StatusForbidden},
+		{name: "non-member of an open retro", userID: outsider.ID, retroID: open.ID.String(), want: http.StatusOK},
+		{name: "member of a soft-deleted retro", userID: admin.ID, retroID: deleted.ID.String(), want: http.StatusOK},
+		{name: "non-member of a soft-deleted retro", userID: outsider.ID, retroID: deleted.ID.String(), want: http.StatusForbidden},
+		{name: "unknown retro", userID: member.ID, retroID: uuid.NewString(), want: http.StatusNotFound},
+		{name: "malformed retro ID", userID: member.ID, retroID: "not-a-uuid", want: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/retrospectives/"+tc.retroID+"/items", nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tc.userID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("got %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestOpenRetroManagementRoutesRequireMembership(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := &RetrospectiveHandler{retroService: env.Services.Retro}
+
+	admin := env.CreateUser(t, "Admin")
+	outsider := env.CreateUser(t, "Outsider")
+	team := env.CreateTeam(t, admin)
+	open := env.CreateRetro(t, team, admin, services.CreateRetroInput{OpenAccess: true})
+
+	router := chi.NewRouter()
+	router.Route("/retrospectives/{retroId}", retroRoutes(h, &IdempotencyHandler{}))
+
+	cases := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: http.MethodGet, path: "/", want: http.StatusOK},
+		{method: http.MethodPut, path: "/", want: http.StatusForbidden},
+		{method: http.MethodDelete, path: "/", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/start", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/end", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/archive", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/share", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/clone", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/timer/start", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/phase/next", want: http.StatusForbidden},
+		{method: http.MethodPost, path: "/phase/set", want: http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/retrospectives/"+open.ID.String()+tc.path, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, outsider.ID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("got %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+}
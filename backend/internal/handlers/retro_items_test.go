@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/middleware"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func TestItemRoutesRejectItemOfAnotherRetro(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := &RetrospectiveHandler{retroService: env.Services.Retro}
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	other := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	foreign, err := env.Services.Retro.CreateItem(ctx, other.ID, admin.ID, services.CreateItemInput{ColumnID: "start", Content: "Keep me"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Put("/retrospectives/{retroId}/items/{itemId}", h.UpdateItem)
+	router.Delete("/retrospectives/{retroId}/items/{itemId}", h.DeleteItem)
+	router.Get("/retrospectives/{retroId}/items/{itemId}/myvotes", h.MyVotes)
+
+	path := "/retrospectives/" + retro.ID.String() + "/items/" + foreign.ID.String()
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"content":"Changed"}`)),
+		httptest.NewRequest(http.MethodDelete, path, nil),
+		httptest.NewRequest(http.MethodGet, path+"/myvotes", nil),
+	} {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, admin.ID))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: got %d, want 404: %s", req.Method, rec.Code, rec.Body.String())
+		}
+	}
+
+	items, err := env.Services.Retro.ListItems(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(items) != 1 || items[0].Content != "Keep me" {
+		t.Fatalf("got %+v, want the item of the other retro unchanged", items)
+	}
+}
+
+func TestItemDeleteRejectsItemOfAnotherRoom(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	other := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	foreign, err := env.Services.Retro.CreateItem(ctx, other.ID, admin.ID, services.CreateItemInput{ColumnID: "start", Content: "Keep me"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	client := &ws.Client{ID: uuid.NewString(), UserID: admin.ID, RoomID: retro.ID.String(), Hub: h.hub, Send: make(chan []byte, 16)}
+	h.hub.Register(client)
+	h.handleItemDelete(client, json.RawMessage(`{"itemId":"`+foreign.ID.String()+`"}`))
+
+	select {
+	case data := <-client.Send:
+		var msg ws.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		payload, _ := msg.Payload.(map[string]interface{})
+		if msg.Type != "error" || payload["code"] != "item_not_found" {
+			t.Fatalf("got %s %v, want error item_not_found", msg.Type, msg.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no error received")
+	}
+
+	items, err := env.Services.Retro.ListItems(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want the item of the other retro kept", len(items))
+	}
+}
+
+func TestListItemsModeFollowsRecognizedParams(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := &RetrospectiveHandler{retroService: env.Services.Retro}
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	for _, column := range []string{"stop", "start", "stop"} {
+		if _, err := env.Services.Retro.CreateItem(ctx, retro.ID, admin.ID, services.CreateItemInput{ColumnID: column, Content: "Item"}); err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Get("/retrospectives/{retroId}/items", h.ListItems)
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/retrospectives/"+retro.ID.String()+"/items"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, admin.ID))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// An unrelated param keeps the plain listing and its order
+	plain, busted := list(""), list("?_=123")
+	if plain.Code != http.StatusOK || busted.Code != http.StatusOK || plain.Body.String() != busted.Body.String() {
+		t.Fatalf("?_=123 got %d %s, want the plain listing %d %s", busted.Code, busted.Body.String(), plain.Code, plain.Body.String())
+	}
+
+	if rec := list("?tree=true&q=Item"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("tree with filters got %d, want 400: %s", rec.Code, rec.Body.String())
+	}
+	if rec := list("?tree=true"); rec.Code != http.StatusOK {
+		t.Fatalf("tree got %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+}
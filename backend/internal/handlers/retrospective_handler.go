@@ -3,15 +3,20 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"github.com/jycamier/retrotro/backend/internal/bus"
 	"github.com/jycamier/retrotro/backend/internal/middleware"
 	"github.com/jycamier/retrotro/backend/internal/models"
 	"github.com/jycamier/retrotro/backend/internal/services"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
 )
 
 // RetrospectiveHandler handles retrospective endpoints
@@ -20,15 +25,25 @@ type RetrospectiveHandler struct {
 	timerService      *services.TimerService
 	leanCoffeeService *services.LeanCoffeeService
 	analysisService   *services.AnalysisService
+	surveyService     *services.SurveyService
+	snapshotService   *services.SnapshotService
+	authService       *services.AuthService
+	bridge            bus.MessageBus // tells the retro's room about changes made over REST
+	frontendURL       string         // base of the guest links
 }
 
 // NewRetrospectiveHandler creates a new retrospective handler
-func NewRetrospectiveHandler(retroService *services.RetrospectiveService, timerService *services.TimerService, leanCoffeeService *services.LeanCoffeeService, analysisService *services.AnalysisService) *RetrospectiveHandler {
+func NewRetrospectiveHandler(retroService *services.RetrospectiveService, timerService *services.TimerService, leanCoffeeService *services.LeanCoffeeService, analysisService *services.AnalysisService, surveyService *services.SurveyService, snapshotService *services.SnapshotService, authService *services.AuthService, bridge bus.MessageBus, frontendURL string) *RetrospectiveHandler {
 	return &RetrospectiveHandler{
 		retroService:      retroService,
 		timerService:      timerService,
 		leanCoffeeService: leanCoffeeService,
 		analysisService:   analysisService,
+		surveyService:     surveyService,
+		snapshotService:   snapshotService,
+		authService:       authService,
+		bridge:            bridge,
+		frontendURL:       frontendURL,
 	}
 }
 
@@ -47,6 +62,13 @@ type CreateRetroRequest struct {
 	PhaseTimerOverrides   map[models.RetroPhase]int `json:"phaseTimerOverrides"`
 	ScheduledAt           *time.Time                `json:"scheduledAt"`
 	LCTopicTimeboxSeconds *int                      `json:"lcTopicTimeboxSeconds"`
+	MaxActionsPerRetro    *int                      `json:"maxActionsPerRetro"`
+	AutoAdvanceOnTimerEnd bool                      `json:"autoAdvanceOnTimerEnd"`
+	VoteLimitPerGroup     bool                      `json:"voteLimitPerGroup"`
+	BatchVoteUpdates      bool                      `json:"batchVoteUpdates"`
+	OpenAccess            bool                      `json:"openAccess"`
+	AllowGuests           bool                      `json:"allowGuests"`
+	CarryOverActions      bool                      `json:"carryOverActions"`
 }
 
 // Create creates a new retrospective
@@ -84,9 +106,25 @@ func (h *RetrospectiveHandler) Create(w http.ResponseWriter, r *http.Request) {
 		PhaseTimerOverrides:   req.PhaseTimerOverrides,
 		ScheduledAt:           req.ScheduledAt,
 		LCTopicTimeboxSeconds: req.LCTopicTimeboxSeconds,
+		MaxActionsPerRetro:    req.MaxActionsPerRetro,
+		AutoAdvanceOnTimerEnd: req.AutoAdvanceOnTimerEnd,
+		VoteLimitPerGroup:     req.VoteLimitPerGroup,
+		BatchVoteUpdates:      req.BatchVoteUpdates,
+		OpenAccess:            req.OpenAccess,
+		AllowGuests:           req.AllowGuests,
+		CarryOverActions:      req.CarryOverActions,
 	})
 	if err != nil {
-		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+		case errors.Is(err, services.ErrTemplateNotFound):
+			http.Error(w, `{"error": "template not found"}`, http.StatusNotFound)
+		case errors.Is(err, services.ErrTemplateNotAccessible):
+			http.Error(w, `{"error": "template belongs to another team"}`, http.StatusForbidden)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -95,7 +133,49 @@ func (h *RetrospectiveHandler) Create(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(retro)
 }
 
-// List lists retrospectives for a team
+const (
+	defaultRetroListLimit = 50
+	maxRetroListLimit     = 200
+)
+
+// parseRetroListFilter extracts status, sorting and pagination parameters from the query string
+func parseRetroListFilter(r *http.Request) (*models.RetroListFilter, error) {
+	query := r.URL.Query()
+	filter := &models.RetroListFilter{
+		IncludeArchived: query.Get("includeArchived") == "true",
+		Sort:            models.RetroSortCreatedAt,
+		Limit:           defaultRetroListLimit,
+	}
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		status := models.RetroStatus(statusStr)
+		filter.Status = &status
+	}
+
+	switch sort := models.RetroSort(query.Get("sort")); sort {
+	case "", models.RetroSortCreatedAt:
+	case models.RetroSortEndedAt:
+		filter.Sort = sort
+	default:
+		return nil, errors.New("sort must be created_at or ended_at")
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = min(limit, maxRetroListLimit)
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	return filter, nil
+}
+
+// List returns a page of a team's retrospectives; the total is in the X-Total-Count header
 func (h *RetrospectiveHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -111,19 +191,26 @@ func (h *RetrospectiveHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var status *models.RetroStatus
-	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
-		s := models.RetroStatus(statusStr)
-		status = &s
+	filter, err := parseRetroListFilter(r)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
 	}
 
-	retros, err := h.retroService.ListByTeam(ctx, teamID, status)
+	var retros []*models.Retrospective
+	var total int
+	if r.URL.Query().Get("includeDeleted") == "true" {
+		retros, total, err = h.retroService.ListByTeamIncludingDeleted(ctx, teamID, middleware.GetUserID(ctx), filter)
+	} else {
+		retros, total, err = h.retroService.ListByTeam(ctx, teamID, filter)
+	}
 	if err != nil {
-		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		writeTeamAdminError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 	_ = json.NewEncoder(w).Encode(retros)
 }
 
@@ -137,7 +224,7 @@ func (h *RetrospectiveHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	retro, err := h.retroService.GetByID(ctx, retroID)
+	retro, err := h.retroService.GetDetail(ctx, retroID)
 	if err != nil {
 		if err == services.ErrRetroNotFound {
 			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
@@ -176,12 +263,24 @@ func (h *RetrospectiveHandler) Update(w http.ResponseWriter, r *http.Request) {
 		AllowItemEdit       *bool                     `json:"allowItemEdit"`
 		AllowVoteChange     *bool                     `json:"allowVoteChange"`
 		PhaseTimerOverrides map[models.RetroPhase]int `json:"phaseTimerOverrides"`
+		MaxActionsPerRetro  *int                      `json:"maxActionsPerRetro"`
+		AutoAdvance         *bool                     `json:"autoAdvanceOnTimerEnd"`
+		VoteLimitPerGroup   *bool                     `json:"voteLimitPerGroup"`
+		BatchVoteUpdates    *bool                     `json:"batchVoteUpdates"`
+		OpenAccess          *bool                     `json:"openAccess"`
+		AllowGuests         *bool                     `json:"allowGuests"`
+		UpdatedAt           *time.Time                `json:"updatedAt"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
 		return
 	}
 
+	// The version the client edited; the update is refused if the retro changed since
+	if req.UpdatedAt != nil {
+		retro.UpdatedAt = *req.UpdatedAt
+	}
+
 	if req.Name != nil {
 		retro.Name = *req.Name
 	}
@@ -206,8 +305,35 @@ func (h *RetrospectiveHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.PhaseTimerOverrides != nil {
 		retro.PhaseTimerOverrides = req.PhaseTimerOverrides
 	}
+	if req.AutoAdvance != nil {
+		retro.AutoAdvanceOnTimerEnd = *req.AutoAdvance
+	}
+	if req.VoteLimitPerGroup != nil {
+		retro.VoteLimitPerGroup = *req.VoteLimitPerGroup
+	}
+	if req.BatchVoteUpdates != nil {
+		retro.BatchVoteUpdates = *req.BatchVoteUpdates
+	}
+	if req.OpenAccess != nil {
+		retro.OpenAccess = *req.OpenAccess
+	}
+	if req.AllowGuests != nil {
+		retro.AllowGuests = *req.AllowGuests
+	}
+	if req.MaxActionsPerRetro != nil {
+		// 0 removes the cap
+		if *req.MaxActionsPerRetro > 0 {
+			retro.MaxActionsPerRetro = req.MaxActionsPerRetro
+		} else {
+			retro.MaxActionsPerRetro = nil
+		}
+	}
 
 	if err := h.retroService.Update(ctx, retro); err != nil {
+		if errors.Is(err, services.ErrConcurrentModification) {
+			http.Error(w, `{"error": "retrospective was modified by someone else, reload it"}`, http.StatusConflict)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -226,14 +352,200 @@ func (h *RetrospectiveHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.retroService.Delete(ctx, retroID); err != nil {
+	// Deleting keeps the retro's data so it can be restored; purge removes it for good
+	// and needs the token of a dry run, so nothing is destroyed by a single request
+	query := r.URL.Query()
+	switch {
+	case query.Get("purge") == "true" && query.Get("dryRun") == "true":
+		preview, err := h.retroService.PreviewPurge(ctx, retroID, middleware.GetUserID(ctx))
+		if err != nil {
+			writeTeamAdminError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(preview)
+		return
+	case query.Get("purge") == "true":
+		err = h.retroService.Purge(ctx, retroID, middleware.GetUserID(ctx), query.Get("token"))
+	default:
+		err = h.retroService.Delete(ctx, retroID)
+	}
+	if errors.Is(err, services.ErrPurgeNotConfirmed) {
+		http.Error(w, `{"error": "confirmation token is missing or out of date, repeat the dry run"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeTeamAdminError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Restore brings back a deleted retrospective
+func (h *RetrospectiveHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	retro, err := h.retroService.Restore(ctx, retroID, middleware.GetUserID(ctx))
+	if err != nil {
+		writeTeamAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(retro)
+}
+
+// writeTeamAdminError maps the errors of retro operations reserved to team admins to a response
+func writeTeamAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrRetroNotFound):
+		http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+	case errors.Is(err, services.ErrNotTeamMember):
+		http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+	case errors.Is(err, services.ErrNotAuthorized):
+		http.Error(w, `{"error": "only team admins can do this"}`, http.StatusForbidden)
+	default:
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+	}
+}
+
+// CloneRetroRequest represents a retrospective clone request
+type CloneRetroRequest struct {
+	Name             string `json:"name"` // empty uses the source name with " (copy)"
+	CarryOverActions bool   `json:"carryOverActions"`
+}
+
+// Clone creates a new draft retrospective with the settings of this one
+func (h *RetrospectiveHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var req CloneRetroRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	retro, err := h.retroService.Clone(ctx, retroID, middleware.GetUserID(ctx), services.CloneRetroInput{
+		Name:             req.Name,
+		CarryOverActions: req.CarryOverActions,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRetroNotFound):
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+		case errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+		case errors.Is(err, services.ErrTemplateNotFound):
+			http.Error(w, `{"error": "template not found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(retro)
+}
+
+// ShareRequest represents a share link request
+type ShareRequest struct {
+	ExpiresInMinutes int  `json:"expiresInMinutes"` // 0 uses GUEST_TOKEN_TTL
+	SpectatorOnly    bool `json:"spectatorOnly"`
+}
+
+// Share creates a guest link to the retrospective and lets guests in; only its facilitator may
+// share it. A new link revokes the previous one. The token is in the URL fragment so it never
+// reaches server logs.
+func (h *RetrospectiveHandler) Share(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	expiresIn := h.authService.GuestTokenTTL()
+	if req.ExpiresInMinutes != 0 {
+		expiresIn = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+
+	retro, err := h.retroService.CreateShare(ctx, retroID, middleware.GetUserID(ctx), expiresIn, req.SpectatorOnly)
+	if err != nil {
+		writeShareError(w, err)
+		return
+	}
+
+	token, err := h.authService.CreateGuestLinkToken(retro.ID, *retro.ShareID, *retro.ShareExpiresAt)
+	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"url":           h.frontendURL + "/join/" + retro.ID.String() + "#" + token,
+		"token":         token,
+		"expiresAt":     retro.ShareExpiresAt,
+		"spectatorOnly": retro.ShareSpectatorOnly,
+	})
+}
+
+// Unshare revokes the retrospective's guest link
+func (h *RetrospectiveHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.retroService.RevokeShare(ctx, retroID, middleware.GetUserID(ctx)); err != nil {
+		writeShareError(w, err)
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func writeShareError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrRetroNotFound):
+		http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+	case errors.Is(err, services.ErrShareNotFound):
+		http.Error(w, `{"error": "retrospective has no share link"}`, http.StatusNotFound)
+	case errors.Is(err, services.ErrNotFacilitator):
+		http.Error(w, `{"error": "only the facilitator can share the retrospective"}`, http.StatusForbidden)
+	case errors.Is(err, services.ErrInvalidShareExpiry):
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+	default:
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+	}
+}
+
 // Start starts a retrospective
 func (h *RetrospectiveHandler) Start(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -274,6 +586,38 @@ func (h *RetrospectiveHandler) End(w http.ResponseWriter, r *http.Request) {
 
 	retro, err := h.retroService.End(ctx, retroID)
 	if err != nil {
+		if errors.Is(err, services.ErrConcurrentModification) {
+			http.Error(w, `{"error": "retrospective was modified by someone else, reload it"}`, http.StatusConflict)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(retro)
+}
+
+// Archive archives a completed retrospective
+func (h *RetrospectiveHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	retro, err := h.retroService.Archive(ctx, retroID)
+	if err != nil {
+		if errors.Is(err, services.ErrRetroNotCompleted) {
+			http.Error(w, `{"error": "only completed retrospectives can be archived"}`, http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrRetroNotFound) {
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -282,6 +626,121 @@ func (h *RetrospectiveHandler) End(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(retro)
 }
 
+// Unarchive moves an archived retrospective back to completed
+func (h *RetrospectiveHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	retro, err := h.retroService.Unarchive(ctx, retroID)
+	if err != nil {
+		if errors.Is(err, services.ErrRetroNotArchived) {
+			http.Error(w, `{"error": "retrospective is not archived"}`, http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrRetroNotFound) {
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(retro)
+}
+
+// BulkArchiveRequest represents a bulk archive request
+type BulkArchiveRequest struct {
+	OlderThan *time.Time  `json:"olderThan"`
+	RetroIDs  []uuid.UUID `json:"retroIds"`
+}
+
+// BulkArchive archives many completed retrospectives of a team at once
+func (h *RetrospectiveHandler) BulkArchive(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req BulkArchiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	h.bulkArchive(w, r, teamID, services.BulkArchiveInput{
+		OlderThan: req.OlderThan,
+		RetroIDs:  req.RetroIDs,
+	})
+}
+
+// ArchiveOlderThan archives a team's completed retrospectives that ended before
+// the duration given in the olderThan query param (e.g. 90d, 720h)
+func (h *RetrospectiveHandler) ArchiveOlderThan(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	age, err := parseAge(r.URL.Query().Get("olderThan"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid olderThan duration"}`, http.StatusBadRequest)
+		return
+	}
+
+	cutoff := time.Now().Add(-age)
+	h.bulkArchive(w, r, teamID, services.BulkArchiveInput{OlderThan: &cutoff})
+}
+
+func (h *RetrospectiveHandler) bulkArchive(w http.ResponseWriter, r *http.Request, teamID uuid.UUID, input services.BulkArchiveInput) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	ids, err := h.retroService.ArchiveByTeam(ctx, teamID, userID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArchiveFilter) {
+			http.Error(w, `{"error": "olderThan or retroIds is required"}`, http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrNotTeamMember) || errors.Is(err, services.ErrNotAuthorized) {
+			http.Error(w, `{"error": "not authorized"}`, http.StatusForbidden)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"archived": len(ids),
+		"retroIds": ids,
+	})
+}
+
+// parseAge parses a positive duration, accepting a day suffix (e.g. 90d) on top of Go durations
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.New("invalid duration")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.New("invalid duration")
+	}
+	return d, nil
+}
+
 // ListItems lists items for a retrospective
 func (h *RetrospectiveHandler) ListItems(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -292,7 +751,34 @@ func (h *RetrospectiveHandler) ListItems(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	items, err := h.retroService.ListItems(ctx, retroID)
+	// Only the recognized params pick the mode, so unrelated ones such as cache busters are ignored
+	query := r.URL.Query()
+	tree := query.Get("tree") == "true"
+	filtered := false
+	for _, param := range itemFilterParams {
+		if query.Has(param) {
+			filtered = true
+			break
+		}
+	}
+
+	var items []*models.Item
+	switch {
+	case tree && filtered:
+		http.Error(w, `{"error": "tree cannot be combined with filters"}`, http.StatusBadRequest)
+		return
+	case tree:
+		items, err = h.retroService.ListItemsTree(ctx, retroID)
+	case filtered:
+		filter, ferr := parseItemFilter(r)
+		if ferr != nil {
+			http.Error(w, `{"error": "`+ferr.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		items, err = h.retroService.SearchItems(ctx, retroID, filter)
+	default:
+		items, err = h.retroService.ListItems(ctx, retroID)
+	}
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -302,6 +788,35 @@ func (h *RetrospectiveHandler) ListItems(w http.ResponseWriter, r *http.Request)
 	_ = json.NewEncoder(w).Encode(items)
 }
 
+// itemFilterParams are the query params that turn ListItems into a search
+var itemFilterParams = []string{"q", "columnId", "minVotes", "sort"}
+
+// parseItemFilter extracts search, column, vote threshold and sort parameters from query string
+func parseItemFilter(r *http.Request) (*models.ItemFilter, error) {
+	q := r.URL.Query()
+	filter := &models.ItemFilter{
+		Query:    strings.TrimSpace(q.Get("q")),
+		ColumnID: q.Get("columnId"),
+		Sort:     models.ItemSort(q.Get("sort")),
+	}
+
+	if minStr := q.Get("minVotes"); minStr != "" {
+		minVotes, err := strconv.Atoi(minStr)
+		if err != nil || minVotes < 0 {
+			return nil, errors.New("invalid minVotes")
+		}
+		filter.MinVotes = minVotes
+	}
+
+	switch filter.Sort {
+	case models.ItemSortPosition, models.ItemSortVotes, models.ItemSortCreated:
+	default:
+		return nil, errors.New("invalid sort, expected votes or created")
+	}
+
+	return filter, nil
+}
+
 // CreateItemRequest represents a create item request
 type CreateItemRequest struct {
 	ColumnID string `json:"columnId"`
@@ -339,6 +854,68 @@ func (h *RetrospectiveHandler) CreateItem(w http.ResponseWriter, r *http.Request
 	_ = json.NewEncoder(w).Encode(item)
 }
 
+// ImportItemsRequest represents a bulk item import request
+type ImportItemsRequest struct {
+	Items []CreateItemRequest `json:"items"`
+}
+
+// ImportItems creates many items at once and tells the room with a single items_imported
+func (h *RetrospectiveHandler) ImportItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req ImportItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	inputs := make([]services.CreateItemInput, len(req.Items))
+	for i, item := range req.Items {
+		inputs[i] = services.CreateItemInput{ColumnID: item.ColumnID, Content: item.Content}
+	}
+
+	items, err := h.retroService.ImportItems(ctx, retroID, userID, inputs)
+	if err != nil {
+		var importErr *services.ItemImportError
+		switch {
+		case errors.As(err, &importErr):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":  "invalid items",
+				"fields": importErr.Fields,
+			})
+		case errors.Is(err, services.ErrInvalidItemImport):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, services.ErrNotFacilitator):
+			http.Error(w, `{"error": "only the facilitator can import items"}`, http.StatusForbidden)
+		case errors.Is(err, services.ErrRetroNotFound):
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if h.bridge != nil {
+		h.bridge.BroadcastToRoom(retroID.String(), ws.Message{
+			Type:    "items_imported",
+			Payload: map[string]interface{}{"items": items},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(items)
+}
+
 // UpdateItemRequest represents an update item request
 type UpdateItemRequest struct {
 	Content string `json:"content"`
@@ -348,6 +925,11 @@ type UpdateItemRequest struct {
 func (h *RetrospectiveHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
 	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
 	if err != nil {
 		http.Error(w, `{"error": "invalid item ID"}`, http.StatusBadRequest)
@@ -360,8 +942,12 @@ func (h *RetrospectiveHandler) UpdateItem(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	item, err := h.retroService.UpdateItem(ctx, itemID, req.Content)
+	item, err := h.retroService.UpdateItem(ctx, retroID, itemID, middleware.GetUserID(ctx), req.Content)
 	if err != nil {
+		if errors.Is(err, services.ErrItemNotFound) {
+			http.Error(w, `{"error": "item not found"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -370,17 +956,60 @@ func (h *RetrospectiveHandler) UpdateItem(w http.ResponseWriter, r *http.Request
 	_ = json.NewEncoder(w).Encode(item)
 }
 
+// GetItemHistory lists the content changes of an item
+func (h *RetrospectiveHandler) GetItemHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid item ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.retroService.GetItemHistory(ctx, retroID, itemID, middleware.GetUserID(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRetroNotFound):
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+		case errors.Is(err, services.ErrItemNotFound):
+			http.Error(w, `{"error": "item not found"}`, http.StatusNotFound)
+		case errors.Is(err, services.ErrNotFacilitator):
+			http.Error(w, `{"error": "only the facilitator can see the history of anonymous items"}`, http.StatusForbidden)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(history)
+}
+
 // DeleteItem deletes an item
 func (h *RetrospectiveHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
 	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
 	if err != nil {
 		http.Error(w, `{"error": "invalid item ID"}`, http.StatusBadRequest)
 		return
 	}
 
-	if err := h.retroService.DeleteItem(ctx, itemID); err != nil {
+	if err := h.retroService.DeleteItem(ctx, retroID, itemID); err != nil {
+		if errors.Is(err, services.ErrItemNotFound) {
+			http.Error(w, `{"error": "item not found"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -397,6 +1026,11 @@ type GroupItemsRequest struct {
 func (h *RetrospectiveHandler) GroupItems(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
 	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
 	if err != nil {
 		http.Error(w, `{"error": "invalid item ID"}`, http.StatusBadRequest)
@@ -409,7 +1043,11 @@ func (h *RetrospectiveHandler) GroupItems(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if _, err := h.retroService.GroupItems(ctx, itemID, req.ChildIDs); err != nil {
+	if _, err := h.retroService.GroupItems(ctx, retroID, itemID, req.ChildIDs); err != nil {
+		if errors.Is(err, services.ErrItemNotFound) {
+			http.Error(w, `{"error": "item not found"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -443,6 +1081,10 @@ func (h *RetrospectiveHandler) Vote(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, `{"error": "item vote limit reached"}`, http.StatusBadRequest)
 			return
 		}
+		if errors.Is(err, services.ErrItemNotFound) {
+			http.Error(w, `{"error": "item not found"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -466,7 +1108,40 @@ func (h *RetrospectiveHandler) Unvote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MyVotes returns how many of the current user's votes are on an item
+func (h *RetrospectiveHandler) MyVotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid item ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.retroService.GetUserVoteCountOnItem(ctx, retroID, itemID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrItemNotFound) {
+			http.Error(w, `{"error": "item not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"itemId": itemID,
+		"count":  count,
+	})
 }
 
 // ListActions lists action items for a retrospective
@@ -513,14 +1188,111 @@ func (h *RetrospectiveHandler) ListTeamActions(w http.ResponseWriter, r *http.Re
 	_ = json.NewEncoder(w).Encode(actions)
 }
 
+// actionStatuses are the statuses an action item can be in
+var actionStatuses = map[string]bool{"todo": true, "in_progress": true, "done": true}
+
+// ListMyActions lists the action items assigned to the current user across their teams
+func (h *RetrospectiveHandler) ListMyActions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	filter := &models.ActionListFilter{Status: query.Get("status"), Sort: models.ActionSortCreatedAt}
+	if filter.Status != "" && !actionStatuses[filter.Status] {
+		http.Error(w, `{"error": "status must be todo, in_progress or done"}`, http.StatusBadRequest)
+		return
+	}
+	switch sort := models.ActionSort(query.Get("sort")); sort {
+	case "", models.ActionSortCreatedAt:
+	case models.ActionSortDueDate:
+		filter.Sort = sort
+	default:
+		http.Error(w, `{"error": "sort must be created_at or due_date"}`, http.StatusBadRequest)
+		return
+	}
+
+	actions, err := h.retroService.ListMyActions(ctx, middleware.GetUserID(ctx), filter)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(actions)
+}
+
+// ListActionComments lists the comments on an action item with their authors' names
+func (h *RetrospectiveHandler) ListActionComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actionID, err := uuid.Parse(chi.URLParam(r, "actionId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid action ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	comments, err := h.retroService.ListActionComments(ctx, actionID, middleware.GetUserID(ctx))
+	if err != nil {
+		writeActionCommentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(comments)
+}
+
+// AddActionCommentRequest represents an add action comment request
+type AddActionCommentRequest struct {
+	Content string `json:"content"`
+}
+
+// AddActionComment adds a comment to an action item
+func (h *RetrospectiveHandler) AddActionComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	actionID, err := uuid.Parse(chi.URLParam(r, "actionId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid action ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req AddActionCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.retroService.AddActionComment(ctx, actionID, middleware.GetUserID(ctx), req.Content)
+	if err != nil {
+		writeActionCommentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(comment)
+}
+
+func writeActionCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrActionNotFound):
+		http.Error(w, `{"error": "action item not found"}`, http.StatusNotFound)
+	case errors.Is(err, services.ErrNotTeamMember):
+		http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+	case errors.Is(err, services.ErrInvalidActionComment):
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+	default:
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+	}
+}
+
 // CreateActionRequest represents a create action request
 type CreateActionRequest struct {
-	Title       string     `json:"title"`
-	Description *string    `json:"description"`
-	AssigneeID  *uuid.UUID `json:"assigneeId"`
-	DueDate     *time.Time `json:"dueDate"`
-	ItemID      *uuid.UUID `json:"itemId"`
-	Priority    int        `json:"priority"`
+	Title       string                `json:"title"`
+	Description *string               `json:"description"`
+	AssigneeID  *uuid.UUID            `json:"assigneeId"`
+	DueDate     *time.Time            `json:"dueDate"`
+	ItemID      *uuid.UUID            `json:"itemId"`
+	Priority    models.ActionPriority `json:"priority"` // 0 low, 1 medium, 2 high, 3 urgent
 }
 
 // CreateAction creates a new action item
@@ -549,7 +1321,14 @@ func (h *RetrospectiveHandler) CreateAction(w http.ResponseWriter, r *http.Reque
 		Priority:    req.Priority,
 	})
 	if err != nil {
-		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, services.ErrActionLimitReached):
+			http.Error(w, `{"error": "action limit reached"}`, http.StatusConflict)
+		case errors.Is(err, services.ErrInvalidPriority):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -558,10 +1337,39 @@ func (h *RetrospectiveHandler) CreateAction(w http.ResponseWriter, r *http.Reque
 	_ = json.NewEncoder(w).Encode(action)
 }
 
+// GetActionAllowance returns how many more action items can be created in a retrospective
+func (h *RetrospectiveHandler) GetActionAllowance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	remaining, err := h.retroService.GetActionAllowance(ctx, retroID)
+	if err != nil {
+		if errors.Is(err, services.ErrRetroNotFound) {
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]*int{"remaining": remaining})
+}
+
 // UpdateAction updates an action item
 func (h *RetrospectiveHandler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
 	actionID, err := uuid.Parse(chi.URLParam(r, "actionId"))
 	if err != nil {
 		http.Error(w, `{"error": "invalid action ID"}`, http.StatusBadRequest)
@@ -574,7 +1382,7 @@ func (h *RetrospectiveHandler) UpdateAction(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	action, err := h.retroService.UpdateAction(ctx, actionID, services.CreateActionInput{
+	action, err := h.retroService.UpdateAction(ctx, retroID, actionID, services.CreateActionInput{
 		Title:       req.Title,
 		Description: req.Description,
 		AssigneeID:  req.AssigneeID,
@@ -583,7 +1391,14 @@ func (h *RetrospectiveHandler) UpdateAction(w http.ResponseWriter, r *http.Reque
 		Priority:    req.Priority,
 	})
 	if err != nil {
-		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, services.ErrInvalidPriority):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, services.ErrActionNotFound):
+			http.Error(w, `{"error": "action item not found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -595,13 +1410,22 @@ func (h *RetrospectiveHandler) UpdateAction(w http.ResponseWriter, r *http.Reque
 func (h *RetrospectiveHandler) DeleteAction(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
 	actionID, err := uuid.Parse(chi.URLParam(r, "actionId"))
 	if err != nil {
 		http.Error(w, `{"error": "invalid action ID"}`, http.StatusBadRequest)
 		return
 	}
 
-	if err := h.retroService.DeleteAction(ctx, actionID); err != nil {
+	if err := h.retroService.DeleteAction(ctx, retroID, actionID); err != nil {
+		if errors.Is(err, services.ErrActionNotFound) {
+			http.Error(w, `{"error": "action item not found"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -769,7 +1593,7 @@ func (h *RetrospectiveHandler) SetPhase(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
-// ListTemplates lists templates
+// ListTemplates lists templates. With ?builtIn=false, only the team's own templates are listed.
 func (h *RetrospectiveHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -781,7 +1605,17 @@ func (h *RetrospectiveHandler) ListTemplates(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	templates, err := h.retroService.ListTemplates(ctx, teamID)
+	var templates []*models.Template
+	var err error
+	if r.URL.Query().Get("builtIn") == "false" {
+		if teamID == nil {
+			http.Error(w, `{"error": "builtIn=false requires a teamId"}`, http.StatusBadRequest)
+			return
+		}
+		templates, err = h.retroService.ListTeamTemplates(ctx, *teamID)
+	} else {
+		templates, err = h.retroService.ListTemplates(ctx, teamID)
+	}
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -831,6 +1665,13 @@ func (h *RetrospectiveHandler) CreateTemplate(w http.ResponseWriter, r *http.Req
 
 	created, err := h.retroService.CreateTemplate(ctx, &template)
 	if err != nil {
+		if writeTemplateValidationError(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidMoodScale) {
+			http.Error(w, `{"error": "mood scale values must be unique and not empty"}`, http.StatusBadRequest)
+			return
+		}
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -840,6 +1681,154 @@ func (h *RetrospectiveHandler) CreateTemplate(w http.ResponseWriter, r *http.Req
 	_ = json.NewEncoder(w).Encode(created)
 }
 
+// UpdateTemplateRequest represents an update template request
+type UpdateTemplateRequest struct {
+	Name        string                    `json:"name"`
+	Description *string                   `json:"description"`
+	Columns     []models.TemplateColumn   `json:"columns"`
+	PhaseTimes  map[models.RetroPhase]int `json:"phaseTimes"`
+	MoodScale   []models.MoodOption       `json:"moodScale"`
+}
+
+// UpdateTemplate replaces a team template
+func (h *RetrospectiveHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid template ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	template, err := h.retroService.UpdateTemplate(ctx, templateID, userID, services.UpdateTemplateInput{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Columns:     req.Columns,
+		PhaseTimes:  req.PhaseTimes,
+		MoodScale:   req.MoodScale,
+	})
+	if err != nil {
+		if writeTemplateValidationError(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidMoodScale) {
+			http.Error(w, `{"error": "mood scale values must be unique and not empty"}`, http.StatusBadRequest)
+			return
+		}
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(template)
+}
+
+// DeleteTemplate deletes a team template that no retrospective uses
+func (h *RetrospectiveHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid template ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.retroService.DeleteTemplate(ctx, templateID, userID); err != nil {
+		if errors.Is(err, services.ErrTemplateInUse) {
+			http.Error(w, `{"error": "template is used by retrospectives"}`, http.StatusConflict)
+			return
+		}
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTemplateValidationError responds with the invalid fields when err is a template validation error
+func writeTemplateValidationError(w http.ResponseWriter, err error) bool {
+	var validationErr *services.TemplateValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "invalid template",
+		"fields": validationErr.Fields,
+	})
+	return true
+}
+
+// writeTemplateError maps the errors shared by template mutations to a response
+func writeTemplateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		http.Error(w, `{"error": "template not found"}`, http.StatusNotFound)
+	case errors.Is(err, services.ErrTemplateBuiltIn):
+		http.Error(w, `{"error": "built-in templates cannot be modified"}`, http.StatusForbidden)
+	case errors.Is(err, services.ErrNotTeamMember):
+		http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+	case errors.Is(err, services.ErrNotAuthorized):
+		http.Error(w, `{"error": "only team admins can change team templates"}`, http.StatusForbidden)
+	default:
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+	}
+}
+
+// DuplicateTemplateRequest represents a duplicate template request
+type DuplicateTemplateRequest struct {
+	Name string `json:"name"`
+}
+
+// DuplicateTemplate copies a template into a new template owned by the team in the teamId query parameter
+func (h *RetrospectiveHandler) DuplicateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid template ID"}`, http.StatusBadRequest)
+		return
+	}
+	teamID, err := uuid.Parse(r.URL.Query().Get("teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "teamId is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var req DuplicateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.retroService.DuplicateTemplate(ctx, templateID, teamID, userID, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTemplateNotFound):
+			http.Error(w, `{"error": "template not found"}`, http.StatusNotFound)
+		case errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(template)
+}
+
 // GetRotiResults returns ROTI results for a retrospective
 func (h *RetrospectiveHandler) GetRotiResults(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -860,9 +1849,34 @@ func (h *RetrospectiveHandler) GetRotiResults(w http.ResponseWriter, r *http.Req
 	_ = json.NewEncoder(w).Encode(results)
 }
 
+// GetSurveyResults returns end-of-session survey results for a retrospective
+func (h *RetrospectiveHandler) GetSurveyResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.surveyService.GetResults(ctx, retroID)
+	if err != nil {
+		if errors.Is(err, services.ErrRetroNotFound) {
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
 // PatchTeamAction partially updates a team action item (status, assignee)
 func (h *RetrospectiveHandler) PatchTeamAction(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
 
 	actionID, err := uuid.Parse(chi.URLParam(r, "actionId"))
 	if err != nil {
@@ -876,7 +1890,7 @@ func (h *RetrospectiveHandler) PatchTeamAction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	action, err := h.retroService.PatchAction(ctx, actionID, req)
+	action, err := h.retroService.PatchAction(ctx, actionID, userID, req)
 	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
@@ -949,3 +1963,58 @@ func (h *RetrospectiveHandler) GetIcebreakerMoods(w http.ResponseWriter, r *http
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(moods)
 }
+
+// ListAttendees returns the attendance records of a retrospective
+func (h *RetrospectiveHandler) ListAttendees(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	attendees, err := h.retroService.ListAttendees(ctx, retroID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrRetroNotFound) {
+			http.Error(w, `{"error": "retrospective not found"}`, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrNotTeamMember) {
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(attendees)
+}
+
+// GetStateSnapshot returns a one-time retro_state snapshot announced over the WebSocket by retro_state_ref
+func (h *RetrospectiveHandler) GetStateSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	retroID, err := uuid.Parse(chi.URLParam(r, "retroId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid retrospective ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	payload, err := h.snapshotService.Consume(ctx, r.URL.Query().Get("token"), retroID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrSnapshotNotFound) {
+			http.Error(w, `{"error": "snapshot not found or expired"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(payload)
+}
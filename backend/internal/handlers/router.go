@@ -34,6 +34,10 @@ func NewRouter(
 	statsHandler *StatsHandler,
 	adminHandler *AdminHandler,
 	webhookHandler *WebhookHandler,
+	activityHandler *ActivityHandler,
+	recurringHandler *RecurringRetroHandler,
+	healthHandler *HealthHandler,
+	idempotencyHandler *IdempotencyHandler,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
@@ -48,8 +52,8 @@ func NewRouter(
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.CORSOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "X-Total-Count", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -59,6 +63,10 @@ func NewRouter(
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
+	// Readiness check, fails when the message bus relay is broken
+	r.Get("/healthz", healthHandler.Healthz)
+	// Prometheus scrape endpoint
+	r.Get("/metrics", healthHandler.Metrics)
 
 	// Auth routes (public)
 	r.Route("/auth", func(r chi.Router) {
@@ -71,18 +79,29 @@ func NewRouter(
 		r.Get("/dev-users", authHandler.GetDevUsers)
 	})
 
+	// Dev-mode reset between e2e runs (public, like dev-login)
+	r.Post("/dev/reset", authHandler.DevReset)
+
 	// API routes (protected)
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Use(middleware.JWTAuth(cfg.JWT.Secret))
 
 		r.Get("/me", authHandler.GetCurrentUser)
+		r.Get("/me/actions", retroHandler.ListMyActions)
+
+		// Action item comments, for the members of the action's team
+		r.Get("/actions/{actionId}/comments", retroHandler.ListActionComments)
+		r.Post("/actions/{actionId}/comments", retroHandler.AddActionComment)
+		r.Get("/users/search", teamHandler.SearchUsers)
 
 		// Admin routes
 		r.Route("/admin", func(r chi.Router) {
 			r.Use(middleware.RequireAdmin)
 			r.Get("/users", adminHandler.ListUsers)
+			r.Get("/users/{userId}/oidc-debug", adminHandler.GetUserOIDCDebug)
 			r.Get("/teams", adminHandler.ListTeams)
 			r.Get("/teams/{teamId}/members", adminHandler.GetTeamMembers)
+			r.Get("/retrospectives/{retroId}/connections", adminHandler.ListRetroConnections)
 		})
 
 		// Teams
@@ -97,6 +116,9 @@ func NewRouter(
 				r.Post("/members", teamHandler.AddMember)
 				r.Delete("/members/{userId}", teamHandler.RemoveMember)
 				r.Put("/members/{userId}/role", teamHandler.UpdateMemberRole)
+				r.Get("/invites", teamHandler.ListInvites)
+				r.Post("/invites", teamHandler.CreateInvite)
+				r.Delete("/invites/{inviteId}", teamHandler.RevokeInvite)
 
 				r.Route("/stats", func(r chi.Router) {
 					r.Get("/roti", statsHandler.GetTeamRotiStats)
@@ -106,10 +128,23 @@ func NewRouter(
 					r.Get("/users/{userId}/mood", statsHandler.GetUserMoodStats)
 				})
 
+				// Planned vs actual phase durations
+				r.Get("/phase-analytics", statsHandler.GetTeamPhaseAnalytics)
+
 				// Team actions from completed retrospectives
 				r.Get("/actions", retroHandler.ListTeamActions)
 				r.Patch("/actions/{actionId}", retroHandler.PatchTeamAction)
 
+				// Bulk archive of completed retrospectives (team admin)
+				r.Post("/retros/archive", retroHandler.BulkArchive)
+				r.Post("/retrospectives/archive", retroHandler.ArchiveOlderThan)
+
+				// Team activity timeline (retros and actions)
+				r.Get("/activity", activityHandler.GetTeamActivity)
+
+				// Recurring retrospectives
+				r.Post("/recurring-retros/preview", recurringHandler.Preview)
+
 				// Team topics from completed Lean Coffee sessions
 				r.Get("/topics", retroHandler.ListTeamTopics)
 				r.Post("/topics/analyze", retroHandler.AnalyzeTeamTopics)
@@ -133,58 +168,89 @@ func NewRouter(
 			r.Get("/", retroHandler.ListTemplates)
 			r.Post("/", retroHandler.CreateTemplate)
 			r.Get("/{templateId}", retroHandler.GetTemplate)
+			r.Put("/{templateId}", retroHandler.UpdateTemplate)
+			r.Delete("/{templateId}", retroHandler.DeleteTemplate)
+			r.Post("/{templateId}/duplicate", retroHandler.DuplicateTemplate)
 		})
 
 		// Retrospectives
 		r.Route("/retrospectives", func(r chi.Router) {
 			r.Get("/", retroHandler.List)
 			r.Post("/", retroHandler.Create)
-			r.Route("/{retroId}", func(r chi.Router) {
-				r.Get("/", retroHandler.Get)
-				r.Put("/", retroHandler.Update)
-				r.Delete("/", retroHandler.Delete)
-				r.Post("/start", retroHandler.Start)
-				r.Post("/end", retroHandler.End)
-
-				r.Route("/items", func(r chi.Router) {
-					r.Get("/", retroHandler.ListItems)
-					r.Post("/", retroHandler.CreateItem)
-					r.Put("/{itemId}", retroHandler.UpdateItem)
-					r.Delete("/{itemId}", retroHandler.DeleteItem)
-					r.Post("/{itemId}/group", retroHandler.GroupItems)
-				})
+			r.Route("/{retroId}", retroRoutes(retroHandler, idempotencyHandler))
+		})
+	})
 
-				r.Post("/items/{itemId}/vote", retroHandler.Vote)
-				r.Delete("/items/{itemId}/vote", retroHandler.Unvote)
+	// WebSocket endpoint
+	r.Get("/ws", wsHandler.HandleConnection)
 
-				r.Route("/actions", func(r chi.Router) {
-					r.Get("/", retroHandler.ListActions)
-					r.Post("/", retroHandler.CreateAction)
-					r.Put("/{actionId}", retroHandler.UpdateAction)
-					r.Delete("/{actionId}", retroHandler.DeleteAction)
-				})
+	return r
+}
 
-				r.Route("/timer", func(r chi.Router) {
-					r.Post("/start", retroHandler.StartTimer)
-					r.Post("/pause", retroHandler.PauseTimer)
-					r.Post("/resume", retroHandler.ResumeTimer)
-					r.Post("/reset", retroHandler.ResetTimer)
-					r.Post("/add-time", retroHandler.AddTime)
-				})
+// retroRoutes registers the routes of one retrospective
+func retroRoutes(retroHandler *RetrospectiveHandler, idempotencyHandler *IdempotencyHandler) func(r chi.Router) {
+	return func(r chi.Router) {
+		// Open retros may be read, and given items and votes, by any signed-in user
+		r.Group(func(r chi.Router) {
+			r.Use(retroHandler.RequireRetroParticipation)
 
-				r.Post("/phase/next", retroHandler.NextPhase)
-				r.Post("/phase/set", retroHandler.SetPhase)
+			r.Get("/", retroHandler.Get)
 
-				r.Get("/roti", retroHandler.GetRotiResults)
-				r.Get("/icebreaker", retroHandler.GetIcebreakerMoods)
-			})
+			r.Get("/items", retroHandler.ListItems)
+			r.With(idempotencyHandler.Idempotent).Post("/items", retroHandler.CreateItem)
+			r.With(idempotencyHandler.Idempotent).Post("/items/bulk", retroHandler.ImportItems)
+			r.Put("/items/{itemId}", retroHandler.UpdateItem)
+			r.Get("/items/{itemId}/history", retroHandler.GetItemHistory)
+			r.Delete("/items/{itemId}", retroHandler.DeleteItem)
+
+			r.With(idempotencyHandler.Idempotent).Post("/items/{itemId}/vote", retroHandler.Vote)
+			r.Delete("/items/{itemId}/vote", retroHandler.Unvote)
+			r.Get("/items/{itemId}/myvotes", retroHandler.MyVotes)
+
+			r.Get("/roti", retroHandler.GetRotiResults)
+			r.Get("/survey", retroHandler.GetSurveyResults)
+			r.Get("/icebreaker", retroHandler.GetIcebreakerMoods)
+			r.Get("/attendees", retroHandler.ListAttendees)
+			r.Get("/snapshot", retroHandler.GetStateSnapshot)
 		})
-	})
 
-	// WebSocket endpoint
-	r.Get("/ws", wsHandler.HandleConnection)
+		// Managing the retro stays with the members of its team, open or not
+		r.Group(func(r chi.Router) {
+			r.Use(retroHandler.RequireRetroAccess)
 
-	return r
+			r.Put("/", retroHandler.Update)
+			r.Delete("/", retroHandler.Delete)
+			r.Post("/start", retroHandler.Start)
+			r.Post("/end", retroHandler.End)
+			r.Post("/archive", retroHandler.Archive)
+			r.Post("/unarchive", retroHandler.Unarchive)
+			r.Post("/restore", retroHandler.Restore)
+			r.Post("/clone", retroHandler.Clone)
+			r.Post("/share", retroHandler.Share)
+			r.Delete("/share", retroHandler.Unshare)
+
+			r.Post("/items/{itemId}/group", retroHandler.GroupItems)
+
+			r.Route("/actions", func(r chi.Router) {
+				r.Get("/", retroHandler.ListActions)
+				r.With(idempotencyHandler.Idempotent).Post("/", retroHandler.CreateAction)
+				r.Get("/allowance", retroHandler.GetActionAllowance)
+				r.Put("/{actionId}", retroHandler.UpdateAction)
+				r.Delete("/{actionId}", retroHandler.DeleteAction)
+			})
+
+			r.Route("/timer", func(r chi.Router) {
+				r.Post("/start", retroHandler.StartTimer)
+				r.Post("/pause", retroHandler.PauseTimer)
+				r.Post("/resume", retroHandler.ResumeTimer)
+				r.Post("/reset", retroHandler.ResetTimer)
+				r.Post("/add-time", retroHandler.AddTime)
+			})
+
+			r.Post("/phase/next", retroHandler.NextPhase)
+			r.Post("/phase/set", retroHandler.SetPhase)
+		})
+	}
 }
 
 // StartServer starts the HTTP server with lifecycle management
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -33,6 +34,18 @@ func parseStatsFilter(r *http.Request) *models.StatsFilter {
 		}
 	}
 
+	if startStr := r.URL.Query().Get("startDate"); startStr != "" {
+		if start, err := time.Parse(time.RFC3339, startStr); err == nil {
+			filter.StartDate = &start
+		}
+	}
+
+	if endStr := r.URL.Query().Get("endDate"); endStr != "" {
+		if end, err := time.Parse(time.RFC3339, endStr); err == nil {
+			filter.EndDate = &end
+		}
+	}
+
 	return filter
 }
 
@@ -182,3 +195,30 @@ func (h *StatsHandler) GetUserMoodStats(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(stats)
 }
+
+// GetTeamPhaseAnalytics returns planned vs actual phase durations for a team
+func (h *StatsHandler) GetTeamPhaseAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	filter := parseStatsFilter(r)
+
+	analytics, err := h.statsService.GetTeamPhaseAnalytics(ctx, userID, teamID, filter)
+	if err != nil {
+		if err == services.ErrNotTeamMember {
+			http.Error(w, `{"error": "not a team member"}`, http.StatusForbidden)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(analytics)
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -109,8 +112,9 @@ func (h *TeamHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 // UpdateTeamRequest represents an update team request
 type UpdateTeamRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
+	Name           *string `json:"name"`
+	Description    *string `json:"description"`
+	MaxOpenActions *int    `json:"maxOpenActions"`
 }
 
 // Update updates a team
@@ -131,8 +135,9 @@ func (h *TeamHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	team, err := h.teamService.Update(ctx, userID, teamID, services.UpdateTeamInput{
-		Name:        req.Name,
-		Description: req.Description,
+		Name:           req.Name,
+		Description:    req.Description,
+		MaxOpenActions: req.MaxOpenActions,
 	})
 	if err != nil {
 		if err == services.ErrNotAuthorized {
@@ -234,6 +239,71 @@ func (h *TeamHandler) AddMember(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 }
 
+const (
+	defaultUserSearchLimit = 10
+	maxUserSearchLimit     = 50
+	minUserSearchQuery     = 2
+)
+
+// UserSearchResult is a user as shown in the member autocomplete
+type UserSearchResult struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"displayName"`
+	AvatarURL   *string   `json:"avatarUrl,omitempty"`
+}
+
+// SearchUsers searches users by email or display name for adding them to a team
+func (h *TeamHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	var teamID uuid.UUID
+	if teamIDStr := r.URL.Query().Get("teamId"); teamIDStr != "" {
+		var err error
+		if teamID, err = uuid.Parse(teamIDStr); err != nil {
+			http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len([]rune(query)) < minUserSearchQuery {
+		http.Error(w, `{"error": "q must be at least 2 characters"}`, http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultUserSearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = min(n, maxUserSearchLimit)
+		}
+	}
+
+	users, err := h.teamService.SearchUsers(ctx, userID, teamID, query, limit)
+	if err != nil {
+		if err == services.ErrNotAuthorized || err == services.ErrNotTeamMember {
+			http.Error(w, `{"error": "not authorized"}`, http.StatusForbidden)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	results := make([]UserSearchResult, len(users))
+	for i, u := range users {
+		results[i] = UserSearchResult{
+			ID:          u.ID,
+			Email:       u.Email,
+			DisplayName: u.DisplayName,
+			AvatarURL:   u.AvatarURL,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
 // RemoveMember removes a member from a team
 func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -306,3 +376,111 @@ func (h *TeamHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// CreateInviteRequest represents an invite request
+type CreateInviteRequest struct {
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"`
+}
+
+// CreateInvite invites someone who has not logged in yet to a team
+func (h *TeamHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.RoleMember
+	}
+	if req.Role != models.RoleMember && req.Role != models.RoleAdmin {
+		http.Error(w, `{"error": "invalid role"}`, http.StatusBadRequest)
+		return
+	}
+
+	invite, err := h.teamService.CreateInvite(ctx, userID, teamID, req.Email, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotAuthorized), errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not authorized"}`, http.StatusForbidden)
+		case errors.Is(err, services.ErrInvalidEmail):
+			http.Error(w, `{"error": "invalid email"}`, http.StatusBadRequest)
+		case errors.Is(err, services.ErrUserExists):
+			http.Error(w, `{"error": "user already exists, add them as a member"}`, http.StatusConflict)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(invite)
+}
+
+// ListInvites lists the pending invitations of a team
+func (h *TeamHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	invites, err := h.teamService.ListInvites(ctx, userID, teamID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotAuthorized) || errors.Is(err, services.ErrNotTeamMember) {
+			http.Error(w, `{"error": "not authorized"}`, http.StatusForbidden)
+			return
+		}
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(invites)
+}
+
+// RevokeInvite deletes a pending invitation
+func (h *TeamHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	teamID, err := uuid.Parse(chi.URLParam(r, "teamId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid team ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	inviteID, err := uuid.Parse(chi.URLParam(r, "inviteId"))
+	if err != nil {
+		http.Error(w, `{"error": "invalid invite ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.teamService.RevokeInvite(ctx, userID, teamID, inviteID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotAuthorized), errors.Is(err, services.ErrNotTeamMember):
+			http.Error(w, `{"error": "not authorized"}`, http.StatusForbidden)
+		case errors.Is(err, services.ErrInviteNotFound):
+			http.Error(w, `{"error": "invite not found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
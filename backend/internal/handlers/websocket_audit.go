@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+const (
+	// auditQueueSize is how many audit writes may wait for the writer; further ones are dropped
+	auditQueueSize = 1024
+	// maxAuditUserAgent is the length user agents are cut to
+	maxAuditUserAgent = 512
+)
+
+// auditTokenPattern matches JWTs, so a token pasted into a header is never persisted
+var auditTokenPattern = regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]*`)
+
+// ConnectionAuditRepository interface for the WebSocket connection audit log
+type ConnectionAuditRepository interface {
+	Open(ctx context.Context, entry *models.WSConnectionAudit) error
+	AddRetro(ctx context.Context, connectionID string, retroID uuid.UUID) error
+	Close(ctx context.Context, connectionID string, at time.Time) error
+}
+
+// connectionAuditor writes the audit log of WebSocket connections in the background, so the
+// database is never waited on while connecting or joining. Writes are dropped when the queue is full.
+type connectionAuditor struct {
+	repo  ConnectionAuditRepository
+	queue chan func(ctx context.Context) error
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func newConnectionAuditor(repo ConnectionAuditRepository) *connectionAuditor {
+	return &connectionAuditor{
+		repo:  repo,
+		queue: make(chan func(ctx context.Context) error, auditQueueSize),
+		stop:  make(chan struct{}),
+	}
+}
+
+// connected records the client's connection with the address and user agent of its upgrade request
+func (a *connectionAuditor) connected(client *ws.Client, r *http.Request) {
+	entry := &models.WSConnectionAudit{
+		ConnectionID: client.ID,
+		UserID:       client.UserID,
+		Guest:        client.Guest,
+		IPAddress:    remoteIP(r),
+		UserAgent:    auditUserAgent(r.UserAgent()),
+		ConnectedAt:  client.ConnectedAt,
+	}
+	a.enqueue(func(ctx context.Context) error {
+		return a.repo.Open(ctx, entry)
+	})
+}
+
+// joined records that the client joined the retro
+func (a *connectionAuditor) joined(client *ws.Client, retroID uuid.UUID) {
+	connectionID := client.ID
+	a.enqueue(func(ctx context.Context) error {
+		return a.repo.AddRetro(ctx, connectionID, retroID)
+	})
+}
+
+// disconnected closes the client's entry with the connection's duration
+func (a *connectionAuditor) disconnected(client *ws.Client, at time.Time) {
+	connectionID := client.ID
+	a.enqueue(func(ctx context.Context) error {
+		return a.repo.Close(ctx, connectionID, at)
+	})
+}
+
+func (a *connectionAuditor) enqueue(write func(ctx context.Context) error) {
+	select {
+	case a.queue <- write:
+	default:
+		slog.Warn("connection audit queue is full, dropping an entry")
+	}
+}
+
+// Start writes the queued entries in the background until Stop
+func (a *connectionAuditor) Start() {
+	a.done.Add(1)
+	go func() {
+		defer a.done.Done()
+		for {
+			select {
+			case write := <-a.queue:
+				a.write(write)
+			case <-a.stop:
+				// Flush what was queued before shutdown
+				for {
+					select {
+					case write := <-a.queue:
+						a.write(write)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop writes the entries still queued and stops the writer
+func (a *connectionAuditor) Stop() {
+	close(a.stop)
+	a.done.Wait()
+}
+
+func (a *connectionAuditor) write(write func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := write(ctx); err != nil {
+		slog.Error("failed to write connection audit entry", "error", err)
+	}
+}
+
+// remoteIP returns the client address of the request, as set by the RealIP middleware
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// auditUserAgent cuts the user agent to a bounded length with any token in it redacted
+func auditUserAgent(userAgent string) string {
+	userAgent = auditTokenPattern.ReplaceAllString(userAgent, "[redacted]")
+	if len(userAgent) > maxAuditUserAgent {
+		userAgent = userAgent[:maxAuditUserAgent]
+	}
+	return userAgent
+}
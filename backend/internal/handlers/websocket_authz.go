@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// wsRule declares who may send a WebSocket message type and when.
+// Rules are evaluated centrally in handleMessage before the message is dispatched.
+type wsRule struct {
+	room        bool                // the client must have joined a retro
+	facilitator bool                // only the retro's facilitator
+	phases      []models.RetroPhase // allowed phases, any phase when empty
+	session     models.SessionType  // only this session type, any when empty
+	message     string              // shown when the facilitator check fails
+	closed      bool                // also allowed once the retro is completed or archived
+	spectator   bool                // also allowed for read-only spectators
+}
+
+// needsRetro reports whether evaluating the rule requires loading the retro
+func (r wsRule) needsRetro() bool {
+	return r.facilitator || len(r.phases) > 0 || r.session != ""
+}
+
+// wsRules is the authorization table for incoming WebSocket messages.
+// Message types that are not listed are not authorized and fall through to the unknown-type handling.
+// Room messages mutate the retro and are rejected once it has ended unless the rule sets closed.
+var wsRules = map[string]wsRule{
+	"join_retro":  {},
+	"leave_retro": {},
+	"time_sync":   {},
+	"heartbeat":   {},
+	// The new token is checked against the connection's user by the handler
+	"token_refresh": {},
+
+	"item_create": {room: true, phases: []models.RetroPhase{models.PhaseBrainstorm, models.PhasePropose}},
+	"item_update": {room: true, phases: []models.RetroPhase{models.PhaseBrainstorm, models.PhasePropose}},
+	"item_delete": {room: true, phases: []models.RetroPhase{models.PhaseBrainstorm, models.PhasePropose}},
+	"item_move":   {room: true},
+	"item_group": {room: true, facilitator: true, session: models.SessionTypeRetro,
+		phases:  []models.RetroPhase{models.PhaseGroup},
+		message: "Only the facilitator can group items"},
+	"item_mark_discussed": {room: true, facilitator: true, session: models.SessionTypeRetro,
+		phases:  []models.RetroPhase{models.PhaseDiscuss},
+		message: "Only the facilitator can mark items as discussed"},
+
+	"vote_add":     {room: true, phases: []models.RetroPhase{models.PhaseVote}},
+	"vote_remove":  {room: true, phases: []models.RetroPhase{models.PhaseVote}},
+	"vote_summary": {room: true, closed: true, spectator: true},
+
+	"timer_start":    {room: true, facilitator: true, message: "Only the facilitator can control the timer"},
+	"timer_pause":    {room: true, facilitator: true, message: "Only the facilitator can control the timer"},
+	"timer_resume":   {room: true, facilitator: true, message: "Only the facilitator can control the timer"},
+	"timer_add_time": {room: true, facilitator: true, message: "Only the facilitator can control the timer"},
+
+	"phase_next": {room: true, facilitator: true, message: "Only the facilitator can change the phase"},
+	"phase_set":  {room: true, facilitator: true, message: "Only the facilitator can change the phase"},
+
+	"action_create":     {room: true, phases: []models.RetroPhase{models.PhaseDiscuss, models.PhaseAction}},
+	"action_complete":   {room: true},
+	"action_uncomplete": {room: true},
+	"action_delete":     {room: true},
+
+	"retro_end": {room: true, facilitator: true, message: "Only the facilitator can end the retrospective"},
+	// The facilitator or a team admin may reopen; the service checks the role
+	"retro_reopen": {room: true, closed: true},
+
+	"mood_set":    {room: true, phases: []models.RetroPhase{models.PhaseWaiting, models.PhaseIcebreaker}},
+	"mood_clear":  {room: true, phases: []models.RetroPhase{models.PhaseWaiting, models.PhaseIcebreaker}},
+	"roti_vote":   {room: true, phases: []models.RetroPhase{models.PhaseRoti}},
+	"roti_reveal": {room: true, facilitator: true, message: "Only the facilitator can reveal the ROTI results"},
+
+	"survey_start":  {room: true, facilitator: true, message: "Only the facilitator can start the survey"},
+	"survey_answer": {room: true},
+	"survey_reveal": {room: true, facilitator: true, message: "Only the facilitator can reveal survey results"},
+
+	"draft_typing": {room: true, phases: []models.RetroPhase{models.PhaseBrainstorm, models.PhasePropose}},
+	"draft_clear":  {room: true, closed: true},
+
+	// Takeovers are only allowed during waiting; mid-session the facilitator hands off with facilitator_transfer.
+	// The admin role is checked by the handler.
+	"facilitator_claim":            {room: true, phases: []models.RetroPhase{models.PhaseWaiting}},
+	"facilitator_transfer":         {room: true, facilitator: true, message: "Only the current facilitator can transfer the role"},
+	"facilitator_transfer_accept":  {room: true},
+	"facilitator_transfer_decline": {room: true},
+
+	// discuss_set_item is shared by the retro carousel and the LC queue, so it has no session restriction
+	"discuss_set_item": {room: true, facilitator: true, message: "Only the facilitator can navigate discussion items"},
+	"lc_queue_reorder": {room: true, facilitator: true, session: models.SessionTypeLeanCoffee,
+		message: "Only the facilitator can reorder the queue"},
+	"discuss_focus_item": {room: true, facilitator: true, session: models.SessionTypeRetro,
+		phases:  []models.RetroPhase{models.PhaseDiscuss},
+		message: "Only the facilitator can focus an item"},
+}
+
+// wsDenial is why a rule rejected a message
+type wsDenial struct {
+	Code    string
+	Reason  string
+	Message string
+}
+
+// evaluateStatus rejects mutations of a retro that has ended
+func (r wsRule) evaluateStatus(status models.RetroStatus) *wsDenial {
+	if !r.closed && (status == models.StatusCompleted || status == models.StatusArchived) {
+		return &wsDenial{Code: "retro_closed", Reason: "retro_closed", Message: "This retrospective has ended"}
+	}
+	return nil
+}
+
+// evaluate checks the rule against the retro the client is in
+func (r wsRule) evaluate(msgType string, retro *models.Retrospective, userID uuid.UUID) *wsDenial {
+	if r.session != "" && retro.SessionType != r.session {
+		if r.session == models.SessionTypeLeanCoffee {
+			return &wsDenial{Code: "not_lean_coffee", Reason: "wrong_session", Message: "This action is only available in Lean Coffee sessions"}
+		}
+		return &wsDenial{Code: "not_retro", Reason: "wrong_session", Message: "This action is not available in Lean Coffee sessions"}
+	}
+
+	if r.facilitator && retro.FacilitatorID != userID {
+		message := r.message
+		if message == "" {
+			message = "Only the facilitator can do this"
+		}
+		return &wsDenial{Code: "forbidden", Reason: "not_facilitator", Message: message}
+	}
+
+	if len(r.phases) > 0 && !phaseIn(retro.CurrentPhase, r.phases) {
+		return &wsDenial{
+			Code:    "forbidden",
+			Reason:  "wrong_phase",
+			Message: fmt.Sprintf("%s is not allowed during the %s phase", msgType, retro.CurrentPhase),
+		}
+	}
+
+	return nil
+}
+
+func phaseIn(phase models.RetroPhase, phases []models.RetroPhase) bool {
+	for _, p := range phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize evaluates the message's rule and tells the client why it was rejected
+func (h *WebSocketHandler) authorize(client *ws.Client, msgType string) bool {
+	rule, ok := wsRules[msgType]
+	if !ok || !rule.room {
+		return true
+	}
+
+	if client.RoomID == "" {
+		h.sendDenial(client, msgType, &wsDenial{Code: "forbidden", Reason: "not_in_room", Message: "Join a retrospective first"})
+		return false
+	}
+	if client.Spectator && !rule.spectator {
+		h.sendDenial(client, msgType, &wsDenial{Code: "spectator_readonly", Reason: "spectator", Message: "Spectators cannot take part in the retrospective"})
+		return false
+	}
+	if !rule.needsRetro() && rule.closed {
+		return true
+	}
+
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return false
+	}
+
+	// Only the status is needed for most messages, so it is cached briefly
+	var retro *models.Retrospective
+	status, cached := h.retroStatus.get(retroID)
+	if rule.needsRetro() || !cached {
+		retro, err = h.retroService.GetByID(context.Background(), retroID)
+		if err != nil {
+			log.Printf("authorize: failed to get retro: %v", err)
+			return false
+		}
+		status = retro.Status
+		h.retroStatus.set(retroID, status)
+	}
+
+	if denial := rule.evaluateStatus(status); denial != nil {
+		h.sendDenial(client, msgType, denial)
+		return false
+	}
+	if retro == nil {
+		return true
+	}
+	if denial := rule.evaluate(msgType, retro, client.UserID); denial != nil {
+		h.sendDenial(client, msgType, denial)
+		return false
+	}
+	return true
+}
+
+// retroStatusTTL bounds how long another pod may keep accepting mutations after a retro ends
+const retroStatusTTL = 3 * time.Second
+
+// retroStatusCache remembers retro statuses for a few seconds so authorizing a message
+// does not cost a database query. The local pod invalidates on end and reopen.
+type retroStatusCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]retroStatusEntry
+}
+
+type retroStatusEntry struct {
+	status    models.RetroStatus
+	expiresAt time.Time
+}
+
+func newRetroStatusCache() *retroStatusCache {
+	return &retroStatusCache{entries: make(map[uuid.UUID]retroStatusEntry)}
+}
+
+func (c *retroStatusCache) get(retroID uuid.UUID) (models.RetroStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[retroID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, retroID)
+		return "", false
+	}
+	return entry.status, true
+}
+
+func (c *retroStatusCache) set(retroID uuid.UUID, status models.RetroStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[retroID] = retroStatusEntry{status: status, expiresAt: time.Now().Add(retroStatusTTL)}
+}
+
+func (c *retroStatusCache) invalidate(retroID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, retroID)
+}
+
+func (h *WebSocketHandler) sendDenial(client *ws.Client, msgType string, denial *wsDenial) {
+	h.sendWSError(client, WSError{
+		Code:        denial.Code,
+		Message:     denial.Message,
+		Reason:      denial.Reason,
+		MessageType: msgType,
+	})
+}
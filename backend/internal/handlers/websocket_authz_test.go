@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+var allPhases = []models.RetroPhase{
+	models.PhaseWaiting, models.PhaseIcebreaker, models.PhaseBrainstorm, models.PhaseGroup,
+	models.PhaseVote, models.PhaseDiscuss, models.PhaseAction, models.PhaseRoti, models.PhasePropose,
+}
+
+// allowedRetro returns a retro in which the rule passes for the facilitator
+func allowedRetro(rule wsRule, facilitator uuid.UUID) *models.Retrospective {
+	retro := &models.Retrospective{
+		FacilitatorID: facilitator,
+		CurrentPhase:  models.PhaseBrainstorm,
+		SessionType:   models.SessionTypeRetro,
+	}
+	if len(rule.phases) > 0 {
+		retro.CurrentPhase = rule.phases[0]
+	}
+	if rule.session != "" {
+		retro.SessionType = rule.session
+	}
+	return retro
+}
+
+func TestWSRulesAllowFacilitatorInAllowedState(t *testing.T) {
+	facilitator := uuid.New()
+	for msgType, rule := range wsRules {
+		if denial := rule.evaluate(msgType, allowedRetro(rule, facilitator), facilitator); denial != nil {
+			t.Errorf("%s: facilitator denied with %+v", msgType, denial)
+		}
+	}
+}
+
+func TestWSRulesFacilitatorOnly(t *testing.T) {
+	facilitator, participant := uuid.New(), uuid.New()
+	for msgType, rule := range wsRules {
+		denial := rule.evaluate(msgType, allowedRetro(rule, facilitator), participant)
+		if !rule.facilitator {
+			if denial != nil {
+				t.Errorf("%s: participant denied with %+v", msgType, denial)
+			}
+			continue
+		}
+		if denial == nil || denial.Code != "forbidden" || denial.Reason != "not_facilitator" {
+			t.Errorf("%s: participant got %+v, want forbidden/not_facilitator", msgType, denial)
+		}
+	}
+}
+
+func TestWSRulesPhases(t *testing.T) {
+	facilitator := uuid.New()
+	for msgType, rule := range wsRules {
+		for _, phase := range allPhases {
+			retro := allowedRetro(rule, facilitator)
+			retro.CurrentPhase = phase
+			denial := rule.evaluate(msgType, retro, facilitator)
+
+			allowed := len(rule.phases) == 0 || phaseIn(phase, rule.phases)
+			if allowed && denial != nil {
+				t.Errorf("%s in %s: denied with %+v", msgType, phase, denial)
+			}
+			if !allowed && (denial == nil || denial.Reason != "wrong_phase") {
+				t.Errorf("%s in %s: got %+v, want wrong_phase", msgType, phase, denial)
+			}
+		}
+	}
+}
+
+func TestWSRulesSessionType(t *testing.T) {
+	facilitator := uuid.New()
+	want := map[models.SessionType]string{
+		models.SessionTypeRetro:      "not_retro",
+		models.SessionTypeLeanCoffee: "not_lean_coffee",
+	}
+	for msgType, rule := range wsRules {
+		if rule.session == "" {
+			continue
+		}
+		retro := allowedRetro(rule, facilitator)
+		retro.SessionType = models.SessionTypeRetro
+		if rule.session == models.SessionTypeRetro {
+			retro.SessionType = models.SessionTypeLeanCoffee
+		}
+		denial := rule.evaluate(msgType, retro, facilitator)
+		if denial == nil || denial.Code != want[rule.session] {
+			t.Errorf("%s in a %s session: got %+v, want %s", msgType, retro.SessionType, denial, want[rule.session])
+		}
+	}
+}
+
+func TestWSRulesRejectMutationsOnceClosed(t *testing.T) {
+	readOnly := map[string]bool{"vote_summary": true, "retro_reopen": true, "draft_clear": true}
+	for msgType, rule := range wsRules {
+		if !rule.room {
+			continue
+		}
+		if denial := rule.evaluateStatus(models.StatusActive); denial != nil {
+			t.Errorf("%s in an active retro: denied with %+v", msgType, denial)
+		}
+		for _, status := range []models.RetroStatus{models.StatusCompleted, models.StatusArchived} {
+			denial := rule.evaluateStatus(status)
+			if readOnly[msgType] && denial != nil {
+				t.Errorf("%s in a %s retro: denied with %+v", msgType, status, denial)
+			}
+			if !readOnly[msgType] && (denial == nil || denial.Code != "retro_closed") {
+				t.Errorf("%s in a %s retro: got %+v, want retro_closed", msgType, status, denial)
+			}
+		}
+	}
+}
+
+func TestRetroStatusCacheExpires(t *testing.T) {
+	cache := newRetroStatusCache()
+	retroID := uuid.New()
+
+	cache.set(retroID, models.StatusCompleted)
+	if status, ok := cache.get(retroID); !ok || status != models.StatusCompleted {
+		t.Fatalf("get = %q, %v; want completed", status, ok)
+	}
+	cache.invalidate(retroID)
+	if _, ok := cache.get(retroID); ok {
+		t.Fatal("invalidated status still cached")
+	}
+
+	cache.entries[retroID] = retroStatusEntry{status: models.StatusActive, expiresAt: time.Now().Add(-time.Second)}
+	if _, ok := cache.get(retroID); ok {
+		t.Fatal("expired status still cached")
+	}
+}
+
+func TestWSRulesCoverEveryMessageType(t *testing.T) {
+	// Message types dispatched by handleMessage
+	dispatched := []string{
+		"join_retro", "leave_retro", "time_sync", "heartbeat",
+		"item_create", "item_update", "item_delete", "item_move", "item_group", "item_mark_discussed",
+		"vote_add", "vote_summary", "vote_remove",
+		"timer_start", "timer_pause", "timer_resume", "timer_add_time",
+		"phase_next", "phase_set",
+		"action_create", "action_complete", "action_uncomplete", "action_delete",
+		"retro_end", "retro_reopen",
+		"mood_set", "mood_clear", "roti_vote", "roti_reveal",
+		"survey_start", "survey_answer", "survey_reveal",
+		"draft_typing", "draft_clear",
+		"facilitator_claim", "facilitator_transfer", "facilitator_transfer_accept", "facilitator_transfer_decline",
+		"discuss_set_item", "lc_queue_reorder", "discuss_focus_item",
+	}
+	for _, msgType := range dispatched {
+		if _, ok := wsRules[msgType]; !ok {
+			t.Errorf("%s has no authorization rule", msgType)
+		}
+	}
+}
+
+func TestAuthorizeRequiresRoom(t *testing.T) {
+	h := &WebSocketHandler{hub: ws.NewHub()}
+	client := &ws.Client{UserID: uuid.New(), Send: make(chan []byte, 1)}
+
+	if !h.authorize(client, "heartbeat") {
+		t.Fatal("heartbeat should not require a room")
+	}
+	if h.authorize(client, "vote_add") {
+		t.Fatal("vote_add outside a room should be rejected")
+	}
+
+	var msg struct {
+		Type    string            `json:"type"`
+		Payload map[string]string `json:"payload"`
+	}
+	if err := json.Unmarshal(<-client.Send, &msg); err != nil {
+		t.Fatalf("decode error message: %v", err)
+	}
+	if msg.Type != "error" || msg.Payload["code"] != "forbidden" || msg.Payload["reason"] != "not_in_room" || msg.Payload["messageType"] != "vote_add" {
+		t.Errorf("unexpected error message: %+v", msg)
+	}
+}
+
+func TestAuthorizeRejectsSpectators(t *testing.T) {
+	h := &WebSocketHandler{hub: ws.NewHub()}
+	client := &ws.Client{UserID: uuid.New(), RoomID: uuid.NewString(), Spectator: true, Send: make(chan []byte, 1)}
+
+	if !h.authorize(client, "vote_summary") {
+		t.Fatal("spectators should be able to read the vote summary")
+	}
+	if h.authorize(client, "item_create") {
+		t.Fatal("item_create from a spectator should be rejected")
+	}
+
+	var msg struct {
+		Type    string            `json:"type"`
+		Payload map[string]string `json:"payload"`
+	}
+	if err := json.Unmarshal(<-client.Send, &msg); err != nil {
+		t.Fatalf("decode error message: %v", err)
+	}
+	if msg.Payload["code"] != "spectator_readonly" || msg.Payload["messageType"] != "item_create" {
+		t.Errorf("unexpected error message: %+v", msg)
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// draftTracker remembers the columns each client is typing in, so their typing
+// indicators can be cleared when the client leaves without sending draft_clear.
+// It also throttles draft_typing per client and column: the first keystroke is relayed
+// at once, later ones at most once per interval with the latest content length.
+type draftTracker struct {
+	interval time.Duration // 0 relays every keystroke
+
+	mu      sync.Mutex
+	columns map[*ws.Client]map[string]*draftState
+}
+
+// draftState is one client's draft in one column
+type draftState struct {
+	lastSent time.Time
+	pending  int         // latest content length not relayed yet
+	timer    *time.Timer // relays pending at the end of the interval, nil when nothing is pending
+}
+
+func newDraftTracker(interval time.Duration) *draftTracker {
+	return &draftTracker{interval: interval, columns: make(map[*ws.Client]map[string]*draftState)}
+}
+
+// typing records a keystroke and calls send now or once the interval has passed
+func (d *draftTracker) typing(client *ws.Client, columnID string, contentLength int, send func(contentLength int)) {
+	d.mu.Lock()
+	if d.columns[client] == nil {
+		d.columns[client] = make(map[string]*draftState)
+	}
+	state := d.columns[client][columnID]
+	if state == nil {
+		state = &draftState{}
+		d.columns[client][columnID] = state
+	}
+
+	now := time.Now()
+	if state.timer == nil && now.Sub(state.lastSent) >= d.interval {
+		state.lastSent = now
+		d.mu.Unlock()
+		send(contentLength)
+		return
+	}
+
+	// Coalesce into the relay already scheduled, so the final length is never lost
+	state.pending = contentLength
+	if state.timer == nil {
+		state.timer = time.AfterFunc(state.lastSent.Add(d.interval).Sub(now), func() {
+			d.flush(client, columnID, state, send)
+		})
+	}
+	d.mu.Unlock()
+}
+
+func (d *draftTracker) flush(client *ws.Client, columnID string, state *draftState, send func(contentLength int)) {
+	d.mu.Lock()
+	// The draft was cleared in the meantime
+	if d.columns[client][columnID] != state || state.timer == nil {
+		d.mu.Unlock()
+		return
+	}
+	contentLength := state.pending
+	state.timer = nil
+	state.lastSent = time.Now()
+	d.mu.Unlock()
+	send(contentLength)
+}
+
+// clear forgets the client's draft in the column, dropping a pending relay
+func (d *draftTracker) clear(client *ws.Client, columnID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if state := d.columns[client][columnID]; state != nil && state.timer != nil {
+		state.timer.Stop()
+	}
+	delete(d.columns[client], columnID)
+	if len(d.columns[client]) == 0 {
+		delete(d.columns, client)
+	}
+}
+
+// take forgets the client's drafts and returns their columns
+func (d *draftTracker) take(client *ws.Client) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	columns := make([]string, 0, len(d.columns[client]))
+	for columnID, state := range d.columns[client] {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		columns = append(columns, columnID)
+	}
+	delete(d.columns, client)
+	sort.Strings(columns)
+	return columns
+}
+
+// handleDraftTyping handles broadcasting draft typing status to other participants
+func (h *WebSocketHandler) handleDraftTyping(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		ColumnID      string `json:"columnId"`
+		ContentLength int    `json:"contentLength"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		log.Printf("handleDraftTyping: failed to unmarshal payload: %v", err)
+		return
+	}
+
+	// Broadcast to other users (not the author) that someone is typing
+	roomID := client.RoomID
+	h.drafts.typing(client, data.ColumnID, data.ContentLength, func(contentLength int) {
+		h.bridge.BroadcastToRoomExcept(roomID, ws.Message{
+			Type: "draft_typing",
+			Payload: map[string]interface{}{
+				"userId":        client.UserID,
+				"userName":      client.UserName,
+				"columnId":      data.ColumnID,
+				"contentLength": contentLength,
+			},
+		}, client)
+	})
+}
+
+// handleDraftClear handles clearing a draft when user submits or clears the input
+func (h *WebSocketHandler) handleDraftClear(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		ColumnID string `json:"columnId"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		log.Printf("handleDraftClear: failed to unmarshal payload: %v", err)
+		return
+	}
+
+	h.drafts.clear(client, data.ColumnID)
+	h.broadcastDraftCleared(client, client.RoomID, data.ColumnID)
+}
+
+// clearDrafts clears the typing indicators the client left in the room
+func (h *WebSocketHandler) clearDrafts(client *ws.Client, roomID string) {
+	for _, columnID := range h.drafts.take(client) {
+		h.broadcastDraftCleared(client, roomID, columnID)
+	}
+}
+
+// handleDisconnect cleans up after a connection closed, whatever the reason
+func (h *WebSocketHandler) handleDisconnect(client *ws.Client) {
+	h.tokens.forget(client)
+	if h.audit != nil {
+		h.audit.disconnected(client, time.Now())
+	}
+	if client.RoomID != "" {
+		h.clearDrafts(client, client.RoomID)
+	}
+}
+
+// broadcastDraftCleared tells the other users that the client's draft in the column is gone
+func (h *WebSocketHandler) broadcastDraftCleared(client *ws.Client, roomID, columnID string) {
+	h.bridge.BroadcastToRoomExcept(roomID, ws.Message{
+		Type: "draft_cleared",
+		Payload: map[string]interface{}{
+			"userId":   client.UserID,
+			"columnId": columnID,
+		},
+	}, client)
+}
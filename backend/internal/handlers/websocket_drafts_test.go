@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+type draftMessage struct {
+	Type    string `json:"type"`
+	Payload struct {
+		UserID        uuid.UUID `json:"userId"`
+		ColumnID      string    `json:"columnId"`
+		ContentLength int       `json:"contentLength"`
+	} `json:"payload"`
+}
+
+func receiveDraft(t *testing.T, client *ws.Client) draftMessage {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		var msg draftMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+		return draftMessage{}
+	}
+}
+
+func expectNoDraft(t *testing.T, client *ws.Client, wait time.Duration) {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		t.Fatalf("unexpected message: %s", data)
+	case <-time.After(wait):
+	}
+}
+
+func newDraftRoom(t *testing.T, interval time.Duration) (*WebSocketHandler, *ws.Client, *ws.Client) {
+	t.Helper()
+	hub := ws.NewHub()
+	go hub.Run()
+	h := &WebSocketHandler{hub: hub, bridge: bus.NewLocalBus(hub), drafts: newDraftTracker(interval), tokens: newTokenWatcher(nil, nil, 0, 0)}
+
+	roomID := uuid.NewString()
+	alice := &ws.Client{ID: "alice", UserID: uuid.New(), RoomID: roomID, Hub: hub, Send: make(chan []byte, 8)}
+	bob := &ws.Client{ID: "bob", UserID: uuid.New(), RoomID: roomID, Hub: hub, Send: make(chan []byte, 8)}
+	hub.Register(alice)
+	hub.Register(bob)
+	return h, alice, bob
+}
+
+func TestDisconnectClearsDrafts(t *testing.T) {
+	h, alice, bob := newDraftRoom(t, 0)
+
+	h.handleDraftTyping(alice, json.RawMessage(`{"columnId":"start","contentLength":3}`))
+	h.handleDraftTyping(alice, json.RawMessage(`{"columnId":"stop","contentLength":5}`))
+	h.handleDraftClear(alice, json.RawMessage(`{"columnId":"stop"}`))
+	for _, want := range []string{"draft_typing", "draft_typing", "draft_cleared"} {
+		if msg := receiveDraft(t, bob); msg.Type != want {
+			t.Fatalf("got %s, want %s", msg.Type, want)
+		}
+	}
+
+	h.handleDisconnect(alice)
+	msg := receiveDraft(t, bob)
+	if msg.Type != "draft_cleared" || msg.Payload.ColumnID != "start" || msg.Payload.UserID != alice.UserID {
+		t.Fatalf("after disconnect got %+v, want draft_cleared for alice in start", msg)
+	}
+
+	// Nothing is left to clear
+	h.handleDisconnect(alice)
+	expectNoDraft(t, bob, 50*time.Millisecond)
+}
+
+func TestDraftTypingIsThrottled(t *testing.T) {
+	h, alice, bob := newDraftRoom(t, 100*time.Millisecond)
+
+	for length := 1; length <= 5; length++ {
+		h.handleDraftTyping(alice, json.RawMessage(fmt.Sprintf(`{"columnId":"start","contentLength":%d}`, length)))
+	}
+
+	if msg := receiveDraft(t, bob); msg.Type != "draft_typing" || msg.Payload.ContentLength != 1 {
+		t.Fatalf("first keystroke got %+v, want draft_typing with length 1", msg)
+	}
+	if msg := receiveDraft(t, bob); msg.Type != "draft_typing" || msg.Payload.ContentLength != 5 {
+		t.Fatalf("coalesced keystrokes got %+v, want draft_typing with the latest length 5", msg)
+	}
+	expectNoDraft(t, bob, 150*time.Millisecond)
+}
+
+func TestDraftClearDropsPendingTyping(t *testing.T) {
+	h, alice, bob := newDraftRoom(t, 100*time.Millisecond)
+
+	h.handleDraftTyping(alice, json.RawMessage(`{"columnId":"start","contentLength":1}`))
+	h.handleDraftTyping(alice, json.RawMessage(`{"columnId":"start","contentLength":2}`))
+	h.handleDraftClear(alice, json.RawMessage(`{"columnId":"start"}`))
+
+	for _, want := range []string{"draft_typing", "draft_cleared"} {
+		if msg := receiveDraft(t, bob); msg.Type != want {
+			t.Fatalf("got %s, want %s", msg.Type, want)
+		}
+	}
+	// The pending keystroke must not bring the indicator back
+	expectNoDraft(t, bob, 150*time.Millisecond)
+}
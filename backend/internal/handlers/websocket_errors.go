@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/jycamier/retrotro/backend/internal/services"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// WSError is the payload of every WebSocket "error" message. Clients switch on Code;
+// Message is a human readable fallback.
+type WSError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Field names the payload field that was rejected
+	Field string `json:"field,omitempty"`
+	// Reason tells which authorization rule refused the message
+	Reason string `json:"reason,omitempty"`
+	// MessageType is the type of the message that was refused
+	MessageType string `json:"messageType,omitempty"`
+}
+
+// sendError sends an error with the given code to the client
+func (h *WebSocketHandler) sendError(client *ws.Client, code, message string) {
+	h.sendWSError(client, WSError{Code: code, Message: message})
+}
+
+// sendWSError sends an error carrying more than a code and message to the client
+func (h *WebSocketHandler) sendWSError(client *ws.Client, wsErr WSError) {
+	h.hub.SendToClient(client, ws.Message{Type: "error", Payload: wsErr})
+}
+
+// sendNotFoundError tells the client that the item or action it named is not part of its retro.
+// Other errors are not sent.
+func (h *WebSocketHandler) sendNotFoundError(client *ws.Client, err error) {
+	switch {
+	case errors.Is(err, services.ErrItemNotFound):
+		h.sendError(client, "item_not_found", "This item is not part of the retrospective")
+	case errors.Is(err, services.ErrActionNotFound):
+		h.sendError(client, "action_not_found", "This action is not part of the retrospective")
+	}
+}
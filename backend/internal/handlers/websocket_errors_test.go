@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// noTransfers is a FacilitatorTransferRepository without any pending transfer
+type noTransfers struct{}
+
+func (noTransfers) Put(ctx context.Context, transfer *models.FacilitatorTransfer) error { return nil }
+func (noTransfers) Take(ctx context.Context, retroID, toUserID uuid.UUID) (*models.FacilitatorTransfer, error) {
+	return nil, postgres.ErrNotFound
+}
+func (noTransfers) Cancel(ctx context.Context, retroID, userID uuid.UUID) (*models.FacilitatorTransfer, error) {
+	return nil, postgres.ErrNotFound
+}
+func (noTransfers) Delete(ctx context.Context, retroID uuid.UUID) error { return nil }
+func (noTransfers) DeleteExpired(ctx context.Context) ([]*models.FacilitatorTransfer, error) {
+	return nil, nil
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	h := &WebSocketHandler{hub: hub, transferRepo: noTransfers{}}
+	client := &ws.Client{ID: "alice", UserID: uuid.New(), RoomID: uuid.NewString(), Hub: hub, Send: make(chan []byte, 8)}
+
+	h.handleFacilitatorTransferAccept(client)
+
+	select {
+	case data := <-client.Send:
+		var msg struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		// Optional fields are left out rather than sent empty
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(msg.Payload, &fields); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if msg.Type != "error" || len(fields) != 2 {
+			t.Fatalf("got %s, want an error with only a code and a message", data)
+		}
+		var wsErr WSError
+		if err := json.Unmarshal(msg.Payload, &wsErr); err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if wsErr.Code != "no_pending_transfer" || wsErr.Message == "" {
+			t.Fatalf("got %+v, want the no_pending_transfer code", wsErr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no error received")
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/jycamier/retrotro/backend/internal/auth"
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// wsGuestProtocolPrefix marks the subprotocol carrying a guest link or session token
+const wsGuestProtocolPrefix = "guest."
+
+// guestTokenFromRequest extracts a guest token from the ["retrotro", "guest.<jwt>"] subprotocols
+func guestTokenFromRequest(r *http.Request) string {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if token, ok := strings.CutPrefix(protocol, wsGuestProtocolPrefix); ok && token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// handleGuestConnection upgrades a guest's connection. The token must belong to the retro's
+// active share link. A guest link token creates a new guest named after ?name= and the guest
+// is sent a guest_session with the token to reconnect with; a guest session token reconnects
+// the same guest.
+func (h *WebSocketHandler) handleGuestConnection(w http.ResponseWriter, r *http.Request, token string) {
+	claims, err := h.authService.ValidateGuestToken(token)
+	if err != nil {
+		http.Error(w, "invalid guest token", http.StatusUnauthorized)
+		return
+	}
+	retroID, _ := uuid.Parse(claims.RetroID)
+	shareID, _ := uuid.Parse(claims.ShareID)
+
+	// Refuse before creating a guest nobody could use
+	retro, err := h.retroService.CheckShare(r.Context(), retroID, shareID)
+	switch {
+	case errors.Is(err, services.ErrRetroNotFound):
+		http.Error(w, "retrospective not found", http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrGuestsNotAllowed):
+		http.Error(w, "guests are not allowed in this retrospective", http.StatusForbidden)
+		return
+	case errors.Is(err, services.ErrShareRevoked), errors.Is(err, services.ErrShareExpired):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		slog.Error("failed to check guest share link", "retroId", retroID.String(), "error", err)
+		http.Error(w, "failed to join as guest", http.StatusInternalServerError)
+		return
+	}
+
+	guest, session, err := h.authService.JoinAsGuest(r.Context(), claims, r.URL.Query().Get("name"))
+	switch {
+	case errors.Is(err, services.ErrInvalidGuestName):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, auth.ErrInvalidToken):
+		http.Error(w, "invalid guest token", http.StatusUnauthorized)
+		return
+	case err != nil:
+		slog.Error("failed to join as guest", "retroId", retroID.String(), "error", err)
+		http.Error(w, "failed to join as guest", http.StatusInternalServerError)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	client := &ws.Client{
+		ID:           uuid.New().String(),
+		UserID:       guest.ID,
+		UserName:     guest.DisplayName,
+		Hub:          h.hub,
+		Conn:         conn,
+		Send:         make(chan []byte, h.sendBufferSize),
+		ConnectedAt:  time.Now(),
+		Spectator:    retro.ShareSpectatorOnly || r.URL.Query().Get("spectator") == "true",
+		Guest:        true,
+		GuestRetroID: retroID,
+		GuestShareID: shareID,
+	}
+	if err := h.hub.Register(client); err != nil {
+		closeOverCapacity(conn, err)
+		return
+	}
+	h.tokens.trackGuest(client, token, claims)
+	h.audit.connected(client, r)
+
+	if session != "" {
+		h.hub.SendToClient(client, ws.Message{
+			Type: "guest_session",
+			Payload: map[string]interface{}{
+				"userId":      guest.ID,
+				"displayName": guest.DisplayName,
+				"retroId":     retroID,
+				"token":       session,
+				"expiresAt":   claims.ExpiresAt.Time,
+			},
+		})
+	}
+
+	go client.WritePump()
+	go func() {
+		client.ReadPump(h.handleMessage)
+		h.handleDisconnect(client)
+	}()
+}
+
+// checkGuestJoin lets a guest join the retro they were invited to, as long as it still allows
+// guests and their share link was not revoked. Otherwise the guest gets a guests_not_allowed
+// error and their connection is closed.
+func (h *WebSocketHandler) checkGuestJoin(client *ws.Client, retro *models.Retrospective) bool {
+	if client.GuestRetroID == retro.ID && services.ValidateShare(retro, client.GuestShareID, time.Now()) == nil {
+		return true
+	}
+
+	slog.Info("rejected join from a guest",
+		"retroId", retro.ID.String(),
+		"userId", client.UserID.String(),
+	)
+	h.sendError(client, "guests_not_allowed", "Guests can only join the retrospective they were invited to")
+	h.hub.Unregister(client)
+	return false
+}
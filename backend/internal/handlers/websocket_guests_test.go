@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/auth"
+	"github.com/jycamier/retrotro/backend/internal/config"
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// connectAsGuest sends a WebSocket upgrade with the guest token and returns the response
+func connectAsGuest(h *WebSocketHandler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ws?name=Gina", nil)
+	req.Header.Set("Sec-WebSocket-Protocol", "retrotro, "+wsGuestProtocolPrefix+token)
+	rec := httptest.NewRecorder()
+	h.HandleConnection(rec, req)
+	return rec
+}
+
+func TestGuestTokensAreNotAccessTokens(t *testing.T) {
+	authService := newTestAuthService(fakeRevocations{})
+	retroID := uuid.New()
+
+	link, err := authService.CreateGuestLinkToken(retroID, uuid.New(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("create guest link: %v", err)
+	}
+	if _, err := authService.ValidateToken(link); err == nil {
+		t.Error("guest link was accepted as an access token")
+	}
+	if _, err := auth.NewJWTManager(testJWTSecret, 15, 1).ValidateRefreshToken(link); err == nil {
+		t.Error("guest link was accepted as a refresh token")
+	}
+	claims, err := authService.ValidateGuestToken(link)
+	if err != nil || claims.RetroID != retroID.String() {
+		t.Fatalf("guest link claims = %+v, %v, want the retro it was made for", claims, err)
+	}
+
+	pair, err := auth.NewJWTManager(testJWTSecret, 15, 1).GenerateTokenPair(uuid.New(), "user@example.com", "User", false)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if _, err := authService.ValidateGuestToken(pair.AccessToken); err == nil {
+		t.Error("access token was accepted as a guest token")
+	}
+}
+
+func TestExpiredGuestLinkIsRefused(t *testing.T) {
+	authService := newTestAuthService(fakeRevocations{})
+	link, err := authService.CreateGuestLinkToken(uuid.New(), uuid.New(), time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("create guest link: %v", err)
+	}
+	if _, err := authService.ValidateGuestToken(link); !errors.Is(err, auth.ErrExpiredToken) {
+		t.Errorf("expired link = %v, want ErrExpiredToken", err)
+	}
+
+	h := &WebSocketHandler{authService: authService}
+	if rec := connectAsGuest(h, link); rec.Code != http.StatusUnauthorized {
+		t.Errorf("connecting with an expired link got %d, want 401", rec.Code)
+	}
+}
+
+// guestShare shares the retro and returns its guest link token with the guest it admits
+func guestShare(t *testing.T, env *testenv.Env, authService *services.AuthService, retro *models.Retrospective) (string, *models.User, *auth.GuestClaims) {
+	t.Helper()
+	shared, err := env.Services.Retro.CreateShare(context.Background(), retro.ID, retro.FacilitatorID, time.Hour, false)
+	if err != nil {
+		t.Fatalf("share retro: %v", err)
+	}
+	link, err := authService.CreateGuestLinkToken(retro.ID, *shared.ShareID, *shared.ShareExpiresAt)
+	if err != nil {
+		t.Fatalf("create guest link: %v", err)
+	}
+	claims, _ := authService.ValidateGuestToken(link)
+	guest, _, err := authService.JoinAsGuest(context.Background(), claims, "Gina")
+	if err != nil {
+		t.Fatalf("join as guest: %v", err)
+	}
+	return link, guest, claims
+}
+
+func newGuestClient(h *WebSocketHandler, guest *models.User, claims *auth.GuestClaims) *ws.Client {
+	return &ws.Client{
+		ID: uuid.NewString(), UserID: guest.ID, UserName: guest.DisplayName, Hub: h.hub, Send: make(chan []byte, 16),
+		Guest: true, GuestRetroID: uuid.MustParse(claims.RetroID), GuestShareID: uuid.MustParse(claims.ShareID),
+	}
+}
+
+func TestGuestJoinsOnlyTheInvitedRetro(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := newJoinHandler(env)
+	authService := newTestAuthServiceWithUsers(env)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	other := env.CreateRetro(t, team, admin, services.CreateRetroInput{AllowGuests: true})
+	_, guest, claims := guestShare(t, env, authService, retro)
+
+	if msg := joinWithClient(t, h, newGuestClient(h, guest, claims), retro); msg.Type != "retro_state" {
+		t.Fatalf("guest got %s %v, want retro_state", msg.Type, msg.Payload)
+	}
+
+	msg := joinWithClient(t, h, newGuestClient(h, guest, claims), other)
+	payload, _ := msg.Payload.(map[string]interface{})
+	if msg.Type != "error" || payload["code"] != "guests_not_allowed" {
+		t.Fatalf("guest joining another retro got %s %v, want error guests_not_allowed", msg.Type, msg.Payload)
+	}
+
+	// Revoking the link keeps its guests out
+	if err := env.Services.Retro.RevokeShare(context.Background(), retro.ID, admin.ID); err != nil {
+		t.Fatalf("revoke share: %v", err)
+	}
+	retro, _ = env.Services.Retro.GetByID(context.Background(), retro.ID)
+	msg = joinWithClient(t, h, newGuestClient(h, guest, claims), retro)
+	payload, _ = msg.Payload.(map[string]interface{})
+	if msg.Type != "error" || payload["code"] != "guests_not_allowed" {
+		t.Fatalf("guest of a revoked link got %s %v, want error guests_not_allowed", msg.Type, msg.Payload)
+	}
+}
+
+func TestGuestGetsLargeRetroStateInline(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := newJoinHandler(env)
+	h.stateSnapshotThreshold = 1
+	authService := newTestAuthServiceWithUsers(env)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	_, guest, claims := guestShare(t, env, authService, retro)
+
+	// A guest token cannot fetch the REST snapshot, so the ref would be useless
+	if msg := joinWithClient(t, h, newGuestClient(h, guest, claims), retro); msg.Type != "retro_state" {
+		t.Fatalf("guest got %s %v, want retro_state inline", msg.Type, msg.Payload)
+	}
+}
+
+func TestRevokedGuestLinkIsRefused(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	authService := newTestAuthServiceWithUsers(env)
+	h := newJoinHandler(env)
+	h.authService = authService
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	link, _, _ := guestShare(t, env, authService, retro)
+
+	if err := env.Services.Retro.RevokeShare(context.Background(), retro.ID, admin.ID); err != nil {
+		t.Fatalf("revoke share: %v", err)
+	}
+	if rec := connectAsGuest(h, link); rec.Code != http.StatusUnauthorized {
+		t.Errorf("connecting with a revoked link got %d, want 401", rec.Code)
+	}
+}
+
+func TestTokenWatcherClosesGuestsOfRevokedLink(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	authService := newTestAuthServiceWithUsers(env)
+	watcher := newTokenWatcher(authService, env.Services.Retro, time.Minute, 30*time.Second)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	link, guest, claims := guestShare(t, env, authService, retro)
+	client := newGuestClient(&WebSocketHandler{}, guest, claims)
+	watcher.trackGuest(client, link, claims)
+
+	ctx := context.Background()
+	if got := watcher.sweep(ctx, time.Now()); len(got) != 0 {
+		t.Fatalf("active link: got %d clients to close, want none", len(got))
+	}
+	if got := watcher.sweep(ctx, claims.ExpiresAt.Add(31*time.Second)); len(got) != 1 || got[0] != client {
+		t.Fatalf("expired link: got %v, want the guest", got)
+	}
+
+	if err := env.Services.Retro.RevokeShare(ctx, retro.ID, admin.ID); err != nil {
+		t.Fatalf("revoke share: %v", err)
+	}
+	if got := watcher.sweep(ctx, time.Now()); len(got) != 1 || got[0] != client {
+		t.Fatalf("revoked link: got %v, want the guest", got)
+	}
+}
+
+// newTestAuthServiceWithUsers is an auth service that can create guests in the test database
+func newTestAuthServiceWithUsers(env *testenv.Env) *services.AuthService {
+	return services.NewAuthService(nil, env.Repos.Users, nil, config.JWTConfig{Secret: testJWTSecret, AccessTokenTTL: 15, RefreshTokenTTL: 1, GuestTokenTTL: 60}, nil, 0, nil, nil)
+}
@@ -7,25 +7,91 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
 	"github.com/jycamier/retrotro/backend/internal/bus"
 	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
 	"github.com/jycamier/retrotro/backend/internal/services"
 	ws "github.com/jycamier/retrotro/backend/internal/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// TODO: Implement proper origin check in production
-		return true
-	},
+// newUpgrader creates the WebSocket upgrader; only browsers on an allowed origin may upgrade
+func newUpgrader(allowedOrigins []string, devMode bool) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		// Negotiate permessage-deflate; the write pump only compresses messages above a threshold
+		EnableCompression: true,
+		CheckOrigin:       originChecker(allowedOrigins, devMode),
+		// Echoed back to clients that pass their token as a subprotocol
+		Subprotocols: []string{wsSubprotocol},
+	}
+}
+
+const (
+	// wsSubprotocol is the subprotocol the server selects during the handshake
+	wsSubprotocol = "retrotro"
+	// wsTokenProtocolPrefix marks the offered subprotocol that carries the access token
+	wsTokenProtocolPrefix = "bearer."
+)
+
+// tokenFromRequest extracts the access token from the handshake. The Sec-WebSocket-Protocol
+// header (["retrotro", "bearer.<jwt>"]) is preferred, then an Authorization header for
+// non-browser clients; the deprecated ?token= query parameter is only read when allowed.
+func tokenFromRequest(r *http.Request, allowQueryToken bool) string {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if token, ok := strings.CutPrefix(protocol, wsTokenProtocolPrefix); ok && token != "" {
+			return token
+		}
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token
+	}
+
+	if allowQueryToken {
+		if token := r.URL.Query().Get("token"); token != "" {
+			slog.Warn("WebSocket token passed in the query string is deprecated; use the Sec-WebSocket-Protocol header")
+			return token
+		}
+	}
+	return ""
+}
+
+// originChecker allows the configured CORS origins to upgrade, guarding against cross-site
+// WebSocket hijacking. Requests without an Origin header come from non-browser clients and
+// are allowed; dev mode allows every origin. Rejected upgrades get a 403 from the upgrader.
+func originChecker(allowedOrigins []string, devMode bool) func(r *http.Request) bool {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+		if origin != "" {
+			allowed[origin] = true
+		}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || devMode || allowed["*"] {
+			return true
+		}
+		if allowed[strings.ToLower(strings.TrimRight(origin, "/"))] {
+			return true
+		}
+		slog.Warn("WebSocket upgrade refused for origin", "origin", origin)
+		return false
+	}
 }
 
+// facilitatorTransferTimeout is how long a target has to accept a mid-session facilitator transfer
+const facilitatorTransferTimeout = 60 * time.Second
+
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
 	hub               *ws.Hub
@@ -34,8 +100,28 @@ type WebSocketHandler struct {
 	timerService      *services.TimerService
 	authService       *services.AuthService
 	leanCoffeeService *services.LeanCoffeeService
+	surveyService     *services.SurveyService
 	teamMemberRepo    TeamMemberRepository
 	attendeeRepo      AttendeeRepository
+	snapshotService   *services.SnapshotService
+	upgrader          websocket.Upgrader
+	retroStatus       *retroStatusCache
+
+	allowQueryToken         bool
+	autoReassignFacilitator bool
+	// stateSnapshotThreshold is the retro_state size above which it is served over REST instead (0 disables)
+	stateSnapshotThreshold int
+	// sendBufferSize is the capacity of each client's outgoing message queue
+	sendBufferSize int
+
+	// transferRepo holds the facilitator transfers awaiting their target's confirmation
+	transferRepo FacilitatorTransferRepository
+
+	drafts    *draftTracker
+	votes     *voteBatcher
+	tokens    *tokenWatcher
+	audit     *connectionAuditor
+	transfers *transferSweeper
 }
 
 // TeamMemberRepository interface for team member operations
@@ -57,8 +143,22 @@ func NewWebSocketHandler(
 	timerService *services.TimerService,
 	authService *services.AuthService,
 	leanCoffeeService *services.LeanCoffeeService,
+	surveyService *services.SurveyService,
 	teamMemberRepo TeamMemberRepository,
 	attendeeRepo AttendeeRepository,
+	auditRepo ConnectionAuditRepository,
+	transferRepo FacilitatorTransferRepository,
+	snapshotService *services.SnapshotService,
+	autoReassignFacilitator bool,
+	stateSnapshotThreshold int,
+	allowedOrigins []string,
+	devMode bool,
+	allowQueryToken bool,
+	sendBufferSize int,
+	draftTypingInterval time.Duration,
+	voteBatchWindow time.Duration,
+	tokenRecheckInterval time.Duration,
+	tokenClockSkew time.Duration,
 ) *WebSocketHandler {
 	h := &WebSocketHandler{
 		hub:               hub,
@@ -67,9 +167,32 @@ func NewWebSocketHandler(
 		timerService:      timerService,
 		authService:       authService,
 		leanCoffeeService: leanCoffeeService,
+		surveyService:     surveyService,
 		teamMemberRepo:    teamMemberRepo,
 		attendeeRepo:      attendeeRepo,
+		snapshotService:   snapshotService,
+		upgrader:          newUpgrader(allowedOrigins, devMode),
+		retroStatus:       newRetroStatusCache(),
+		transferRepo:      transferRepo,
+		drafts:            newDraftTracker(draftTypingInterval),
+		tokens:            newTokenWatcher(authService, retroService, tokenRecheckInterval, tokenClockSkew),
+		audit:             newConnectionAuditor(auditRepo),
+
+		allowQueryToken:         allowQueryToken,
+		sendBufferSize:          sendBufferSize,
+		autoReassignFacilitator: autoReassignFacilitator,
+		stateSnapshotThreshold:  stateSnapshotThreshold,
+	}
+	if voteBatchWindow > 0 {
+		h.votes = newVoteBatcher(voteBatchWindow, h.flushVotes)
 	}
+	h.transfers = newTransferSweeper(transferRepo, transferSweepInterval, h.broadcastTransferExpired)
+
+	// Auto-advance phases whose timer ran out when the retro opted in
+	timerService.OnTimerEnded = h.handleTimerEnded
+
+	// Tell the room when REST updates change what participants are allowed to do
+	retroService.OnSettingsChanged = h.handleSettingsChanged
 
 	// Set callback for when user leaves room (handles abrupt browser close via grace period)
 	hub.OnUserLeftRoom = func(roomID string, userID uuid.UUID) {
@@ -105,6 +228,10 @@ func NewWebSocketHandler(
 			slog.Debug("OnUserLeftRoom: broadcasting team members status")
 			h.broadcastTeamMembersStatus(retroID, retro.TeamID)
 		}
+		// The grace period has already expired here, so the facilitator is really gone
+		if h.autoReassignFacilitator && retro.Status == models.StatusActive && retro.FacilitatorID == userID {
+			h.reassignFacilitator(context.Background(), retro, userID)
+		}
 	}
 
 	return h
@@ -118,9 +245,13 @@ type WSMessage struct {
 
 // HandleConnection handles a new WebSocket connection
 func (h *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
-	// Get token from query parameter
-	token := r.URL.Query().Get("token")
+	token := tokenFromRequest(r, h.allowQueryToken)
 	if token == "" {
+		// Guests have no access token, only a guest link or session token
+		if guestToken := guestTokenFromRequest(r); guestToken != "" {
+			h.handleGuestConnection(w, r, guestToken)
+			return
+		}
 		http.Error(w, "missing token", http.StatusUnauthorized)
 		return
 	}
@@ -139,7 +270,7 @@ func (h *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Upgrade connection
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
 		return
@@ -147,20 +278,31 @@ func (h *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Reque
 
 	// Create client
 	client := &ws.Client{
-		ID:       uuid.New().String(),
-		UserID:   userID,
-		UserName: claims.Name,
-		Hub:      h.hub,
-		Conn:     conn,
-		Send:     make(chan []byte, 256),
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		UserName:    claims.Name,
+		Hub:         h.hub,
+		Conn:        conn,
+		Send:        make(chan []byte, h.sendBufferSize),
+		ConnectedAt: time.Now(),
+		// ?spectator=true watches retros read-only
+		Spectator: r.URL.Query().Get("spectator") == "true",
 	}
 
 	// Register client
-	h.hub.Register(client)
+	if err := h.hub.Register(client); err != nil {
+		closeOverCapacity(conn, err)
+		return
+	}
+	h.tokens.track(client, token, claims)
+	h.audit.connected(client, r)
 
 	// Start goroutines
 	go client.WritePump()
-	go client.ReadPump(h.handleMessage)
+	go func() {
+		client.ReadPump(h.handleMessage)
+		h.handleDisconnect(client)
+	}()
 }
 
 // handleMessage handles incoming WebSocket messages
@@ -173,25 +315,42 @@ func (h *WebSocketHandler) handleMessage(client *ws.Client, data []byte) {
 
 	log.Printf("Received WebSocket message type: %s", msg.Type)
 
+	if !h.authorize(client, msg.Type) {
+		return
+	}
+	if !h.validate(client, msg.Type, msg.Payload) {
+		return
+	}
+
 	switch msg.Type {
 	case "join_retro":
 		h.handleJoinRetro(client, msg.Payload)
 	case "leave_retro":
 		h.handleLeaveRetro(client)
+	case "time_sync":
+		h.handleTimeSync(client, msg.Payload)
 	case "heartbeat":
 		// No-op: client sending heartbeat to keep connection alive
 		// Useful for detecting stale connections and keeping connection active on high-latency networks
 		slog.Debug("received heartbeat", "userId", client.UserID.String())
+	case "token_refresh":
+		h.handleTokenRefresh(client, msg.Payload)
 	case "item_create":
 		h.handleItemCreate(client, msg.Payload)
 	case "item_update":
 		h.handleItemUpdate(client, msg.Payload)
 	case "item_delete":
 		h.handleItemDelete(client, msg.Payload)
+	case "item_move":
+		h.handleItemMove(client, msg.Payload)
 	case "item_group":
 		h.handleItemGroup(client, msg.Payload)
+	case "item_mark_discussed":
+		h.handleItemMarkDiscussed(client, msg.Payload)
 	case "vote_add":
 		h.handleVoteAdd(client, msg.Payload)
+	case "vote_summary":
+		h.handleVoteSummary(client)
 	case "vote_remove":
 		h.handleVoteRemove(client, msg.Payload)
 	case "timer_start":
@@ -216,12 +375,22 @@ func (h *WebSocketHandler) handleMessage(client *ws.Client, data []byte) {
 		h.handleActionDelete(client, msg.Payload)
 	case "retro_end":
 		h.handleRetroEnd(client)
+	case "retro_reopen":
+		h.handleRetroReopen(client)
 	case "mood_set":
 		h.handleMoodSet(client, msg.Payload)
+	case "mood_clear":
+		h.handleMoodClear(client)
 	case "roti_vote":
 		h.handleRotiVote(client, msg.Payload)
 	case "roti_reveal":
 		h.handleRotiReveal(client)
+	case "survey_start":
+		h.handleSurveyStart(client, msg.Payload)
+	case "survey_answer":
+		h.handleSurveyAnswer(client, msg.Payload)
+	case "survey_reveal":
+		h.handleSurveyReveal(client)
 	case "draft_typing":
 		h.handleDraftTyping(client, msg.Payload)
 	case "draft_clear":
@@ -230,38 +399,46 @@ func (h *WebSocketHandler) handleMessage(client *ws.Client, data []byte) {
 		h.handleFacilitatorClaim(client)
 	case "facilitator_transfer":
 		h.handleFacilitatorTransfer(client, msg.Payload)
+	case "facilitator_transfer_accept":
+		h.handleFacilitatorTransferAccept(client)
+	case "facilitator_transfer_decline":
+		h.handleFacilitatorTransferDecline(client)
 	case "discuss_set_item":
 		h.handleDiscussSetItem(client, msg.Payload)
+	case "lc_queue_reorder":
+		h.handleLCQueueReorder(client, msg.Payload)
+	case "discuss_focus_item":
+		h.handleDiscussFocusItem(client, msg.Payload)
 	default:
-		log.Printf("Unknown message type: %s", msg.Type)
+		h.handleUnknownMessage(client, msg.Type)
 	}
 }
 
+// handleUnknownMessage tells the client its message type is not handled, which usually
+// means the frontend is newer than the backend
+func (h *WebSocketHandler) handleUnknownMessage(client *ws.Client, msgType string) {
+	slog.Warn("websocket: unknown message type", "type", msgType, "userId", client.UserID)
+	h.hub.CountUnknownMessage()
+	h.sendWSError(client, WSError{
+		Code:        "unknown_message_type",
+		Message:     "Unknown message type: " + msgType,
+		MessageType: msgType,
+	})
+}
+
 // handleJoinRetro handles joining a retrospective room
 func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMessage) {
 	var data struct {
 		RetroID string `json:"retroId"`
 	}
 	if err := json.Unmarshal(payload, &data); err != nil {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"code":    "invalid_payload",
-				"message": "Invalid join request payload",
-			},
-		})
+		h.sendError(client, "invalid_payload", "Invalid join request payload")
 		return
 	}
 
 	retroID, err := uuid.Parse(data.RetroID)
 	if err != nil {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"code":    "invalid_retro_id",
-				"message": "Invalid retrospective ID",
-			},
-		})
+		h.sendError(client, "invalid_retro_id", "Invalid retrospective ID")
 		return
 	}
 
@@ -274,10 +451,6 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 		"alreadyInRoom", userAlreadyInRoom,
 	)
 
-	// Join room
-	h.hub.JoinRoom(client, retroID.String())
-
-	// Send current retro state
 	retro, err := h.retroService.GetByID(context.Background(), retroID)
 	if err != nil {
 		slog.Error("failed to get retro for join",
@@ -285,21 +458,46 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 			"userId", client.UserID.String(),
 			"error", err,
 		)
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"code":    "join_failed",
-				"message": "Failed to join retrospective. Please try again.",
-			},
-		})
+		h.sendError(client, "join_failed", "Failed to join retrospective. Please try again.")
+		return
+	}
+
+	if !h.checkJoinAccess(client, retro) {
+		return
+	}
+
+	// Drafts in the previous room are abandoned when switching retros
+	if client.RoomID != "" && client.RoomID != retroID.String() {
+		h.clearDrafts(client, client.RoomID)
+	}
+
+	// Join room; the connection is closed when this pod cannot open another room
+	if err := h.hub.JoinRoom(client, retroID.String()); err != nil {
+		closeOverCapacity(client.Conn, err)
 		return
 	}
+	if h.audit != nil {
+		h.audit.joined(client, retroID)
+	}
+
+	// Late joiners of a running retro still count as attendees; spectators never do
+	if !userAlreadyInRoom && !client.Spectator {
+		if err := h.retroService.RecordPresence(context.Background(), retro, client.UserID); err != nil {
+			slog.Warn("failed to record attendance",
+				"retroId", retroID.String(),
+				"userId", client.UserID.String(),
+				"error", err,
+			)
+		}
+	}
 
 	items, _ := h.retroService.ListItems(context.Background(), retroID)
 	actions, _ := h.retroService.ListActions(context.Background(), retroID)
 	moods, _ := h.retroService.GetIcebreakerMoods(context.Background(), retroID)
+	moodScale, _ := h.retroService.GetMoodScale(context.Background(), retro)
 	rotiResults, _ := h.retroService.GetRotiResults(context.Background(), retroID)
-	voteSummary, _ := h.retroService.GetVoteSummary(context.Background(), retroID)
+	surveyResults, _ := h.surveyService.GetResults(context.Background(), retroID)
+	actionsRemaining, _ := h.retroService.GetActionAllowance(context.Background(), retroID)
 
 	// Get participants (currently connected, local + remote)
 	participants := h.bridge.GetRoomClients(retroID.String())
@@ -307,8 +505,10 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 	connectedUserIds := make(map[uuid.UUID]bool)
 	for i, p := range participants {
 		participantList[i] = map[string]interface{}{
-			"userId": p.UserID,
-			"name":   p.UserName,
+			"userId":    p.UserID,
+			"name":      p.UserName,
+			"spectator": p.Spectator,
+			"guest":     p.Guest,
 		}
 		connectedUserIds[p.UserID] = true
 	}
@@ -337,14 +537,12 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 		}
 	}
 
-	// Convert voteSummary to JSON-friendly format with string keys
+	// Vote summary shaped for this client (per-user details are hidden in anonymous retros)
 	voteSummaryJSON := make(map[string]map[string]int)
-	for userID, itemVotes := range voteSummary {
-		userKey := userID.String()
-		voteSummaryJSON[userKey] = make(map[string]int)
-		for itemID, count := range itemVotes {
-			voteSummaryJSON[userKey][itemID.String()] = count
-		}
+	voteTotals := make(map[string]int)
+	if view, err := h.retroService.GetVoteSummaryFor(context.Background(), retro, client.UserID); err == nil {
+		voteSummaryJSON = view.ByUser
+		voteTotals = view.ItemTotals
 	}
 
 	// Build retro_state payload
@@ -355,10 +553,19 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 		"participants":   participantList,
 		"timerRunning":   h.timerService.IsTimerRunning(retroID),
 		"timerRemaining": h.timerService.GetRemainingSeconds(retroID),
+		"serverNow":      services.ServerNow(),
 		"moods":          moods,
+		"moodScale":      moodScale,
 		"rotiResults":    rotiResults,
+		"surveyResults":  surveyResults,
 		"teamMembers":    teamMembersWithStatus,
 		"voteSummary":    voteSummaryJSON,
+		"voteTotals":     voteTotals,
+		"permissions":    services.PermissionsFor(retro),
+		// nil when no action cap applies
+		"actionsRemaining": actionsRemaining,
+		// The actions continued from a previous retro, also listed in actions
+		"carriedOverActions": carriedOverActions(actions),
 	}
 
 	// Add LC discussion state if this is a Lean Coffee session
@@ -369,23 +576,22 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 		}
 	}
 
-	h.hub.SendToClient(client, ws.Message{
-		Type:    "retro_state",
-		Payload: retroStatePayload,
-	})
+	h.sendRetroState(client, retroID, retroStatePayload)
 
 	// Broadcast participant joined only if user wasn't already in room (local check only)
 	if !userAlreadyInRoom {
 		h.bridge.BroadcastToRoomExcept(retroID.String(), ws.Message{
 			Type: "participant_joined",
 			Payload: map[string]interface{}{
-				"userId": client.UserID,
-				"name":   client.UserName,
+				"userId":    client.UserID,
+				"name":      client.UserName,
+				"spectator": client.Spectator,
+				"guest":     client.Guest,
 			},
 		}, client)
 
 		// Publish presence join to other pods
-		h.bridge.PublishPresenceJoin(retroID.String(), client.UserID, client.UserName)
+		h.bridge.PublishPresenceJoin(retroID.String(), client.UserID, client.UserName, client.Spectator, client.Guest)
 
 		// Broadcast team member status update if in waiting phase
 		slog.Debug("checking if should broadcast team status",
@@ -399,6 +605,106 @@ func (h *WebSocketHandler) handleJoinRetro(client *ws.Client, payload json.RawMe
 	}
 }
 
+// checkJoinAccess lets members of the retro's team join, and anyone when the retro is open.
+// Other users get a not_team_member error and their connection is closed. Guests are
+// checked against the retro they were invited to instead.
+func (h *WebSocketHandler) checkJoinAccess(client *ws.Client, retro *models.Retrospective) bool {
+	if client.Guest {
+		return h.checkGuestJoin(client, retro)
+	}
+	if retro.OpenAccess {
+		return true
+	}
+
+	_, err := h.teamMemberRepo.GetByTeamAndUser(context.Background(), retro.TeamID, client.UserID)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, postgres.ErrNotFound) {
+		slog.Error("failed to check team membership for join",
+			"retroId", retro.ID.String(),
+			"userId", client.UserID.String(),
+			"error", err,
+		)
+		h.sendError(client, "join_failed", "Failed to join retrospective. Please try again.")
+		return false
+	}
+
+	slog.Info("rejected join from a non-member",
+		"retroId", retro.ID.String(),
+		"userId", client.UserID.String(),
+	)
+	h.sendError(client, "not_team_member", "Only members of the team can join this retrospective")
+	// Unregistering closes the send queue once the error is flushed, then the connection
+	h.hub.Unregister(client)
+	return false
+}
+
+// carriedOverActions returns the actions carried over from a previous retro
+func carriedOverActions(actions []*models.ActionItem) []*models.ActionItem {
+	carried := []*models.ActionItem{}
+	for _, action := range actions {
+		if action.CarriedFromID != nil {
+			carried = append(carried, action)
+		}
+	}
+	return carried
+}
+
+// sendRetroState sends retro_state to a joining client. A state above the snapshot threshold is
+// stored as a one-time REST snapshot and announced with a small retro_state_ref instead, except
+// for guests: their token is not accepted by the REST API, so they always get it inline.
+func (h *WebSocketHandler) sendRetroState(client *ws.Client, retroID uuid.UUID, payload map[string]interface{}) {
+	data, err := json.Marshal(ws.Message{Type: "retro_state", Payload: payload})
+	if err != nil {
+		log.Printf("Error marshaling retro_state: %v", err)
+		return
+	}
+
+	if h.stateSnapshotThreshold <= 0 || len(data) <= h.stateSnapshotThreshold || client.Guest {
+		h.hub.SendRawToClient(client, data)
+		return
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err == nil {
+		var token string
+		var expiresAt time.Time
+		token, expiresAt, err = h.snapshotService.Create(context.Background(), retroID, client.UserID, payloadJSON)
+		if err == nil {
+			h.hub.SendToClient(client, ws.Message{
+				Type: "retro_state_ref",
+				Payload: map[string]interface{}{
+					"url":       "/api/v1/retrospectives/" + retroID.String() + "/snapshot?token=" + token,
+					"token":     token,
+					"size":      len(payloadJSON),
+					"expiresAt": expiresAt,
+				},
+			})
+			return
+		}
+	}
+
+	// Fall back to the full state rather than leaving the client without one
+	slog.Warn("failed to create retro_state snapshot, sending inline",
+		"retroId", retroID.String(),
+		"size", len(data),
+		"error", err,
+	)
+	h.hub.SendRawToClient(client, data)
+}
+
+// participants returns the clients in a room that take part, leaving out spectators
+func (h *WebSocketHandler) participants(roomID string) []*ws.Client {
+	var participants []*ws.Client
+	for _, c := range h.bridge.GetRoomClients(roomID) {
+		if !c.Spectator {
+			participants = append(participants, c)
+		}
+	}
+	return participants
+}
+
 // broadcastTeamMembersStatus broadcasts the updated team members status to all clients in the room
 func (h *WebSocketHandler) broadcastTeamMembersStatus(retroID, teamID uuid.UUID) {
 	// Get current participants (local + remote)
@@ -443,6 +749,23 @@ func (h *WebSocketHandler) broadcastTeamMembersStatus(retroID, teamID uuid.UUID)
 	})
 }
 
+// handleTimeSync replies with the server time so the client can recalibrate its clock offset.
+// The client's own send time is echoed back to let it account for round-trip latency.
+func (h *WebSocketHandler) handleTimeSync(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		ClientSentAt json.RawMessage `json:"client_sent_at"`
+	}
+	_ = json.Unmarshal(payload, &data)
+
+	h.hub.SendToClient(client, ws.Message{
+		Type: "time_sync",
+		Payload: map[string]interface{}{
+			"server_now":     services.ServerNow(),
+			"client_sent_at": data.ClientSentAt,
+		},
+	})
+}
+
 // handleLeaveRetro handles leaving a retrospective room
 func (h *WebSocketHandler) handleLeaveRetro(client *ws.Client) {
 	if client.RoomID == "" {
@@ -465,6 +788,7 @@ func (h *WebSocketHandler) handleLeaveRetro(client *ws.Client) {
 		retro, _ = h.retroService.GetByID(context.Background(), retroID)
 	}
 
+	h.clearDrafts(client, roomID)
 	h.hub.LeaveRoom(client)
 
 	// Only broadcast participant_left if user has no more local connections in room
@@ -488,11 +812,6 @@ func (h *WebSocketHandler) handleLeaveRetro(client *ws.Client) {
 
 // handleItemCreate handles creating an item
 func (h *WebSocketHandler) handleItemCreate(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		slog.Debug("handleItemCreate: client not in a room")
-		return
-	}
-
 	var data struct {
 		ColumnID string `json:"columnId"`
 		Content  string `json:"content"`
@@ -537,10 +856,6 @@ func (h *WebSocketHandler) handleItemCreate(client *ws.Client, payload json.RawM
 
 // handleItemUpdate handles updating an item
 func (h *WebSocketHandler) handleItemUpdate(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ItemID  string `json:"itemId"`
 		Content string `json:"content"`
@@ -549,13 +864,18 @@ func (h *WebSocketHandler) handleItemUpdate(client *ws.Client, payload json.RawM
 		return
 	}
 
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
 	itemID, err := uuid.Parse(data.ItemID)
 	if err != nil {
 		return
 	}
 
-	item, err := h.retroService.UpdateItem(context.Background(), itemID, data.Content)
+	item, err := h.retroService.UpdateItem(context.Background(), retroID, itemID, client.UserID, data.Content)
 	if err != nil {
+		h.sendNotFoundError(client, err)
 		return
 	}
 
@@ -567,10 +887,6 @@ func (h *WebSocketHandler) handleItemUpdate(client *ws.Client, payload json.RawM
 
 // handleItemDelete handles deleting an item
 func (h *WebSocketHandler) handleItemDelete(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ItemID string `json:"itemId"`
 	}
@@ -578,12 +894,17 @@ func (h *WebSocketHandler) handleItemDelete(client *ws.Client, payload json.RawM
 		return
 	}
 
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
 	itemID, err := uuid.Parse(data.ItemID)
 	if err != nil {
 		return
 	}
 
-	if err := h.retroService.DeleteItem(context.Background(), itemID); err != nil {
+	if err := h.retroService.DeleteItem(context.Background(), retroID, itemID); err != nil {
+		h.sendNotFoundError(client, err)
 		return
 	}
 
@@ -595,15 +916,46 @@ func (h *WebSocketHandler) handleItemDelete(client *ws.Client, payload json.RawM
 	})
 }
 
-// handleItemGroup handles grouping items together
-func (h *WebSocketHandler) handleItemGroup(client *ws.Client, payload json.RawMessage) {
-	log.Printf("handleItemGroup called, roomID: %s, payload: %s", client.RoomID, string(payload))
+// handleItemMove handles moving an item, along with its grouped children, to a column position
+func (h *WebSocketHandler) handleItemMove(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		ItemID   string `json:"itemId"`
+		ColumnID string `json:"columnId"`
+		Position int    `json:"position"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil || data.ColumnID == "" {
+		return
+	}
 
-	if client.RoomID == "" {
-		log.Printf("handleItemGroup: client not in a room")
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+	itemID, err := uuid.Parse(data.ItemID)
+	if err != nil {
+		return
+	}
+
+	moved, err := h.retroService.MoveItem(context.Background(), retroID, itemID, data.ColumnID, data.Position)
+	if err != nil {
+		log.Printf("handleItemMove: MoveItem failed: %v", err)
+		h.sendNotFoundError(client, err)
 		return
 	}
 
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "items_moved",
+		Payload: map[string]interface{}{
+			"itemId": data.ItemID,
+			"items":  moved,
+		},
+	})
+}
+
+// handleItemGroup handles grouping items together
+func (h *WebSocketHandler) handleItemGroup(client *ws.Client, payload json.RawMessage) {
+	log.Printf("handleItemGroup called, roomID: %s, payload: %s", client.RoomID, string(payload))
+
 	var data struct {
 		ParentID string   `json:"parentId"`
 		ChildIDs []string `json:"childIds"`
@@ -614,6 +966,10 @@ func (h *WebSocketHandler) handleItemGroup(client *ws.Client, payload json.RawMe
 	}
 	log.Printf("handleItemGroup: parentID=%s, childIDs=%v", data.ParentID, data.ChildIDs)
 
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
 	parentID, err := uuid.Parse(data.ParentID)
 	if err != nil {
 		return
@@ -628,9 +984,10 @@ func (h *WebSocketHandler) handleItemGroup(client *ws.Client, payload json.RawMe
 		childIDs = append(childIDs, id)
 	}
 
-	allAffected, err := h.retroService.GroupItems(context.Background(), parentID, childIDs)
+	allAffected, err := h.retroService.GroupItems(context.Background(), retroID, parentID, childIDs)
 	if err != nil {
 		log.Printf("handleItemGroup: GroupItems failed: %v", err)
+		h.sendNotFoundError(client, err)
 		return
 	}
 
@@ -648,12 +1005,45 @@ func (h *WebSocketHandler) handleItemGroup(client *ws.Client, payload json.RawMe
 	})
 }
 
-// handleVoteAdd handles adding a vote
-func (h *WebSocketHandler) handleVoteAdd(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
+// handleItemMarkDiscussed toggles whether an item was discussed
+func (h *WebSocketHandler) handleItemMarkDiscussed(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		ItemID string `json:"itemId"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return
+	}
+
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+	itemID, err := uuid.Parse(data.ItemID)
+	if err != nil {
+		return
+	}
+
+	discussed, err := h.retroService.ToggleItemDiscussed(context.Background(), retroID, itemID, client.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrItemNotFound) {
+			h.sendNotFoundError(client, err)
+			return
+		}
+		log.Printf("handleItemMarkDiscussed: failed to toggle item: %v", err)
 		return
 	}
 
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "item_discussed_updated",
+		Payload: map[string]interface{}{
+			"itemId":    itemID,
+			"discussed": discussed,
+		},
+	})
+}
+
+// handleVoteAdd handles adding a vote
+func (h *WebSocketHandler) handleVoteAdd(client *ws.Client, payload json.RawMessage) {
 	var data struct {
 		ItemID string `json:"itemId"`
 	}
@@ -673,45 +1063,43 @@ func (h *WebSocketHandler) handleVoteAdd(client *ws.Client, payload json.RawMess
 
 	if err := h.retroService.Vote(context.Background(), retroID, itemID, client.UserID); err != nil {
 		if errors.Is(err, services.ErrVoteLimitReached) {
-			h.hub.SendToClient(client, ws.Message{
-				Type: "error",
-				Payload: map[string]interface{}{
-					"code":    "vote_limit_reached",
-					"message": "Vous avez atteint la limite de votes",
-				},
-			})
+			h.sendError(client, "vote_limit_reached", "You have used all your votes")
 		} else if errors.Is(err, services.ErrItemVoteLimitReached) {
-			h.hub.SendToClient(client, ws.Message{
-				Type: "error",
-				Payload: map[string]interface{}{
-					"code":    "item_vote_limit_reached",
-					"message": "Limite de votes atteinte pour cet item",
-				},
-			})
+			h.sendError(client, "item_vote_limit_reached", "You cannot vote for this item again")
 		}
 		return
 	}
 
-	// Get updated vote count for this user
-	userVoteCount, _ := h.retroService.GetUserVoteCount(context.Background(), retroID, client.UserID)
+	h.broadcastVoteUpdated(client, retroID, itemID, "add")
+}
 
-	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
-		Type: "vote_updated",
-		Payload: map[string]interface{}{
-			"itemId":        data.ItemID,
-			"action":        "add",
-			"userId":        client.UserID,
-			"userVoteCount": userVoteCount,
-		},
+// handleVoteSummary replies with the vote summary the client is allowed to see
+func (h *WebSocketHandler) handleVoteSummary(client *ws.Client) {
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	retro, err := h.retroService.GetByID(ctx, retroID)
+	if err != nil {
+		return
+	}
+
+	view, err := h.retroService.GetVoteSummaryFor(ctx, retro, client.UserID)
+	if err != nil {
+		log.Printf("handleVoteSummary: failed to get vote summary: %v", err)
+		return
+	}
+
+	h.hub.SendToClient(client, ws.Message{
+		Type:    "vote_summary",
+		Payload: view,
 	})
 }
 
 // handleVoteRemove handles removing a vote
 func (h *WebSocketHandler) handleVoteRemove(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ItemID string `json:"itemId"`
 	}
@@ -733,26 +1121,11 @@ func (h *WebSocketHandler) handleVoteRemove(client *ws.Client, payload json.RawM
 		return
 	}
 
-	// Get updated vote count for this user
-	userVoteCount, _ := h.retroService.GetUserVoteCount(context.Background(), retroID, client.UserID)
-
-	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
-		Type: "vote_updated",
-		Payload: map[string]interface{}{
-			"itemId":        data.ItemID,
-			"action":        "remove",
-			"userId":        client.UserID,
-			"userVoteCount": userVoteCount,
-		},
-	})
+	h.broadcastVoteUpdated(client, retroID, itemID, "remove")
 }
 
 // handleTimerStart handles starting the timer
 func (h *WebSocketHandler) handleTimerStart(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		DurationSeconds int `json:"duration_seconds"`
 	}
@@ -770,10 +1143,6 @@ func (h *WebSocketHandler) handleTimerStart(client *ws.Client, payload json.RawM
 
 // handleTimerPause handles pausing the timer
 func (h *WebSocketHandler) handleTimerPause(client *ws.Client) {
-	if client.RoomID == "" {
-		return
-	}
-
 	retroID, err := uuid.Parse(client.RoomID)
 	if err != nil {
 		return
@@ -784,10 +1153,6 @@ func (h *WebSocketHandler) handleTimerPause(client *ws.Client) {
 
 // handleTimerResume handles resuming the timer
 func (h *WebSocketHandler) handleTimerResume(client *ws.Client) {
-	if client.RoomID == "" {
-		return
-	}
-
 	retroID, err := uuid.Parse(client.RoomID)
 	if err != nil {
 		return
@@ -798,10 +1163,6 @@ func (h *WebSocketHandler) handleTimerResume(client *ws.Client) {
 
 // handleTimerAddTime handles adding time to the timer
 func (h *WebSocketHandler) handleTimerAddTime(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		Seconds int `json:"seconds"`
 	}
@@ -819,10 +1180,6 @@ func (h *WebSocketHandler) handleTimerAddTime(client *ws.Client, payload json.Ra
 
 // handlePhaseNext handles advancing to the next phase
 func (h *WebSocketHandler) handlePhaseNext(client *ws.Client) {
-	if client.RoomID == "" {
-		return
-	}
-
 	retroID, err := uuid.Parse(client.RoomID)
 	if err != nil {
 		return
@@ -834,17 +1191,6 @@ func (h *WebSocketHandler) handlePhaseNext(client *ws.Client) {
 		return
 	}
 
-	// Check if the client is the facilitator
-	if retro.FacilitatorID != client.UserID {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Only the facilitator can change the phase",
-			},
-		})
-		return
-	}
-
 	previousPhase := retro.CurrentPhase
 
 	// If transitioning from waiting to icebreaker, record attendance
@@ -852,7 +1198,7 @@ func (h *WebSocketHandler) handlePhaseNext(client *ws.Client) {
 		teamMembers, err := h.teamMemberRepo.ListByTeam(ctx, retro.TeamID)
 		if err == nil {
 			// Get connected users (local + remote)
-			participants := h.bridge.GetRoomClients(retroID.String())
+			participants := h.participants(retroID.String())
 			connectedUserIds := make(map[uuid.UUID]bool)
 			for _, p := range participants {
 				connectedUserIds[p.UserID] = true
@@ -880,25 +1226,79 @@ func (h *WebSocketHandler) handlePhaseNext(client *ws.Client) {
 
 	// For LC sessions entering discuss phase, broadcast the initial discussion state
 	if retro.SessionType == models.SessionTypeLeanCoffee && nextPhase == models.PhaseDiscuss {
-		lcState, err := h.leanCoffeeService.GetDiscussionState(ctx, retroID)
-		if err == nil {
-			h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
-				Type:    "lc_discussion_state",
-				Payload: lcState,
-			})
-		}
+		h.broadcastLCDiscussionState(ctx, retroID)
 	}
 
 	// Auto-start timer for the new phase if configured
 	h.autoStartPhaseTimer(ctx, retroID, retro.TemplateID, nextPhase)
 }
 
-// handlePhaseSet handles setting a specific phase
-func (h *WebSocketHandler) handlePhaseSet(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
+// handleTimerEnded advances to the next phase when a phase timer runs out and the retro
+// has auto-advance enabled. Paused timers never end, so pausing cancels the auto-advance.
+// Manual timers and Lean Coffee topic timeboxes never advance the phase.
+func (h *WebSocketHandler) handleTimerEnded(retroID uuid.UUID, phase models.RetroPhase, kind services.TimerKind) {
+	if kind != services.TimerKindPhase {
+		return
+	}
+
+	ctx := context.Background()
+	retro, err := h.retroService.GetByID(ctx, retroID)
+	if err != nil {
+		return
+	}
+
+	if !retro.AutoAdvanceOnTimerEnd || retro.Status != models.StatusActive {
+		return
+	}
+
+	// The facilitator already moved on while the timer was running
+	if retro.CurrentPhase != phase {
+		return
+	}
+
+	// Never auto-advance past the final phase
+	phases := services.GetPhaseSequence(retro.SessionType)
+	if len(phases) == 0 || phases[len(phases)-1] == phase {
 		return
 	}
 
+	nextPhase, err := h.retroService.NextPhase(ctx, retroID)
+	if err != nil || nextPhase == phase {
+		return
+	}
+
+	slog.Info("auto-advanced phase on timer end", "retroId", retroID, "from", phase, "to", nextPhase)
+
+	h.bridge.BroadcastToRoom(retroID.String(), ws.Message{
+		Type: "phase_changed",
+		Payload: map[string]interface{}{
+			"previous_phase": phase,
+			"current_phase":  nextPhase,
+			"triggered_by":   "timer",
+		},
+	})
+
+	if retro.SessionType == models.SessionTypeLeanCoffee && nextPhase == models.PhaseDiscuss {
+		h.broadcastLCDiscussionState(ctx, retroID)
+	}
+
+	h.autoStartPhaseTimer(ctx, retroID, retro.TemplateID, nextPhase)
+}
+
+// handleSettingsChanged broadcasts the new effective permissions after a settings update so
+// clients stop offering actions that are no longer allowed
+func (h *WebSocketHandler) handleSettingsChanged(retro *models.Retrospective, permissions services.RetroPermissions) {
+	h.bridge.BroadcastToRoom(retro.ID.String(), ws.Message{
+		Type: "retro_settings_changed",
+		Payload: map[string]interface{}{
+			"retroId":     retro.ID,
+			"permissions": permissions,
+		},
+	})
+}
+
+// handlePhaseSet handles setting a specific phase
+func (h *WebSocketHandler) handlePhaseSet(client *ws.Client, payload json.RawMessage) {
 	var data struct {
 		Phase string `json:"phase"`
 	}
@@ -917,17 +1317,6 @@ func (h *WebSocketHandler) handlePhaseSet(client *ws.Client, payload json.RawMes
 		return
 	}
 
-	// Check if the client is the facilitator
-	if retro.FacilitatorID != client.UserID {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Only the facilitator can change the phase",
-			},
-		})
-		return
-	}
-
 	previousPhase := retro.CurrentPhase
 
 	newPhase := models.RetroPhase(data.Phase)
@@ -958,7 +1347,7 @@ func (h *WebSocketHandler) autoStartPhaseTimer(ctx context.Context, retroID, tem
 
 	// Only start timer if duration is configured (> 0)
 	if duration > 0 {
-		if err := h.timerService.StartTimer(ctx, retroID, duration); err != nil {
+		if err := h.timerService.StartPhaseTimer(ctx, retroID, duration); err != nil {
 			slog.Error("failed to auto-start timer", "error", err, "phase", phase)
 		} else {
 			slog.Info("auto-started timer", "retroId", retroID, "phase", phase, "duration", duration)
@@ -968,10 +1357,6 @@ func (h *WebSocketHandler) autoStartPhaseTimer(ctx context.Context, retroID, tem
 
 // handleActionCreate handles creating an action item
 func (h *WebSocketHandler) handleActionCreate(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		Title      string  `json:"title"`
 		AssigneeID *string `json:"assigneeId"`
@@ -1007,6 +1392,10 @@ func (h *WebSocketHandler) handleActionCreate(client *ws.Client, payload json.Ra
 
 	action, err := h.retroService.CreateAction(context.Background(), retroID, client.UserID, input)
 	if err != nil {
+		if errors.Is(err, services.ErrActionLimitReached) {
+			h.sendError(client, "action_limit_reached", "The maximum number of action items has been reached")
+			return
+		}
 		log.Printf("handleActionCreate: failed to create action: %v", err)
 		return
 	}
@@ -1015,14 +1404,20 @@ func (h *WebSocketHandler) handleActionCreate(client *ws.Client, payload json.Ra
 		Type:    "action_created",
 		Payload: action,
 	})
+
+	// Let clients know how many actions can still be created when a cap applies
+	if remaining, err := h.retroService.GetActionAllowance(context.Background(), retroID); err == nil && remaining != nil {
+		h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+			Type: "action_allowance_updated",
+			Payload: map[string]interface{}{
+				"actionsRemaining": *remaining,
+			},
+		})
+	}
 }
 
 // handleActionComplete handles marking an action as completed
 func (h *WebSocketHandler) handleActionComplete(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ActionID string `json:"actionId"`
 	}
@@ -1030,13 +1425,18 @@ func (h *WebSocketHandler) handleActionComplete(client *ws.Client, payload json.
 		return
 	}
 
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
 	actionID, err := uuid.Parse(data.ActionID)
 	if err != nil {
 		return
 	}
 
-	action, err := h.retroService.CompleteAction(context.Background(), actionID)
+	action, err := h.retroService.CompleteAction(context.Background(), retroID, actionID, client.UserID)
 	if err != nil {
+		h.sendNotFoundError(client, err)
 		return
 	}
 
@@ -1048,10 +1448,6 @@ func (h *WebSocketHandler) handleActionComplete(client *ws.Client, payload json.
 
 // handleActionUncomplete handles marking an action as not completed
 func (h *WebSocketHandler) handleActionUncomplete(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ActionID string `json:"actionId"`
 	}
@@ -1059,13 +1455,18 @@ func (h *WebSocketHandler) handleActionUncomplete(client *ws.Client, payload jso
 		return
 	}
 
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
 	actionID, err := uuid.Parse(data.ActionID)
 	if err != nil {
 		return
 	}
 
-	action, err := h.retroService.UncompleteAction(context.Background(), actionID)
+	action, err := h.retroService.UncompleteAction(context.Background(), retroID, actionID, client.UserID)
 	if err != nil {
+		h.sendNotFoundError(client, err)
 		return
 	}
 
@@ -1077,10 +1478,6 @@ func (h *WebSocketHandler) handleActionUncomplete(client *ws.Client, payload jso
 
 // handleActionDelete handles deleting an action item
 func (h *WebSocketHandler) handleActionDelete(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ActionID string `json:"actionId"`
 	}
@@ -1088,12 +1485,17 @@ func (h *WebSocketHandler) handleActionDelete(client *ws.Client, payload json.Ra
 		return
 	}
 
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
 	actionID, err := uuid.Parse(data.ActionID)
 	if err != nil {
 		return
 	}
 
-	if err := h.retroService.DeleteAction(context.Background(), actionID); err != nil {
+	if err := h.retroService.DeleteAction(context.Background(), retroID, actionID); err != nil {
+		h.sendNotFoundError(client, err)
 		return
 	}
 
@@ -1107,10 +1509,6 @@ func (h *WebSocketHandler) handleActionDelete(client *ws.Client, payload json.Ra
 
 // handleRetroEnd handles ending a retrospective
 func (h *WebSocketHandler) handleRetroEnd(client *ws.Client) {
-	if client.RoomID == "" {
-		return
-	}
-
 	retroID, err := uuid.Parse(client.RoomID)
 	if err != nil {
 		return
@@ -1119,31 +1517,68 @@ func (h *WebSocketHandler) handleRetroEnd(client *ws.Client) {
 	retro, err := h.retroService.End(context.Background(), retroID)
 	if err != nil {
 		log.Printf("handleRetroEnd: failed to end retro: %v", err)
+		h.sendError(client, "end_failed", "Failed to end the retrospective")
 		return
 	}
+	h.retroStatus.invalidate(retroID)
 
 	// Get final items and actions for the summary
 	items, _ := h.retroService.ListItems(context.Background(), retroID)
 	actions, _ := h.retroService.ListActions(context.Background(), retroID)
 	rotiResults, _ := h.retroService.GetRotiResults(context.Background(), retroID)
+	surveyResults, _ := h.surveyService.GetResults(context.Background(), retroID)
 
 	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
 		Type: "retro_ended",
 		Payload: map[string]interface{}{
-			"retro":       retro,
-			"items":       items,
-			"actions":     actions,
-			"rotiResults": rotiResults,
+			"retro":         retro,
+			"items":         items,
+			"actions":       actions,
+			"rotiResults":   rotiResults,
+			"surveyResults": surveyResults,
 		},
 	})
 }
 
-// handleMoodSet handles setting a user's mood in the icebreaker phase
-func (h *WebSocketHandler) handleMoodSet(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
+// handleRetroReopen handles reopening a retrospective that was ended by mistake
+func (h *WebSocketHandler) handleRetroReopen(client *ws.Client) {
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	retro, err := h.retroService.Reopen(context.Background(), retroID, client.UserID)
+	if err != nil {
+		code, message := "reopen_failed", "Failed to reopen the retrospective"
+		switch {
+		case errors.Is(err, services.ErrNotAuthorized), errors.Is(err, services.ErrNotTeamMember):
+			code, message = "not_authorized", "Only the facilitator or a team admin can reopen the retrospective"
+		case errors.Is(err, services.ErrRetroArchived):
+			code, message = "retro_archived", "Archived retrospectives must be unarchived before reopening"
+		case errors.Is(err, services.ErrRetroNotReopenable):
+			code, message = "not_completed", "Only completed retrospectives can be reopened"
+		default:
+			log.Printf("handleRetroReopen: failed to reopen retro: %v", err)
+		}
+		h.sendError(client, code, message)
 		return
 	}
 
+	h.retroStatus.invalidate(retroID)
+
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "retro_reopened",
+		Payload: map[string]interface{}{
+			"retro":          retro,
+			"phase":          retro.CurrentPhase,
+			"reopenedBy":     client.UserID,
+			"reopenedByName": client.UserName,
+		},
+	})
+}
+
+// handleMoodSet handles setting a user's mood in the icebreaker phase
+func (h *WebSocketHandler) handleMoodSet(client *ws.Client, payload json.RawMessage) {
 	var data struct {
 		Mood string `json:"mood"`
 	}
@@ -1157,14 +1592,14 @@ func (h *WebSocketHandler) handleMoodSet(client *ws.Client, payload json.RawMess
 		return
 	}
 
-	mood, err := h.retroService.SetIcebreakerMood(context.Background(), retroID, client.UserID, models.MoodWeather(data.Mood))
+	mood, err := h.retroService.SetIcebreakerMood(context.Background(), retroID, client.UserID, data.Mood)
 	if err != nil {
-		log.Printf("handleMoodSet: failed to set mood: %v", err)
+		h.sendMoodError(client, "handleMoodSet", err)
 		return
 	}
 
 	// Get participant count and mood count
-	participants := h.bridge.GetRoomClients(retroID.String())
+	participants := h.participants(retroID.String())
 	moodCount, _ := h.retroService.CountIcebreakerMoods(context.Background(), retroID)
 
 	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
@@ -1179,14 +1614,48 @@ func (h *WebSocketHandler) handleMoodSet(client *ws.Client, payload json.RawMess
 	})
 }
 
-// handleRotiVote handles a user's ROTI vote
-func (h *WebSocketHandler) handleRotiVote(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
+// handleMoodClear removes the user's mood and tells the room the new count
+func (h *WebSocketHandler) handleMoodClear(client *ws.Client) {
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	if err := h.retroService.ClearIcebreakerMood(context.Background(), retroID, client.UserID); err != nil {
+		h.sendMoodError(client, "handleMoodClear", err)
 		return
 	}
 
+	participants := h.participants(retroID.String())
+	moodCount, _ := h.retroService.CountIcebreakerMoods(context.Background(), retroID)
+
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "mood_cleared",
+		Payload: map[string]interface{}{
+			"userId":           client.UserID,
+			"moodCount":        moodCount,
+			"participantCount": len(participants),
+		},
+	})
+}
+
+func (h *WebSocketHandler) sendMoodError(client *ws.Client, handler string, err error) {
+	code, message := "mood_failed", "Failed to update your mood"
+	if errors.Is(err, services.ErrInvalidPhase) {
+		code, message = "invalid_phase", "Moods can only be shared before the retro starts or during the icebreaker"
+	} else if errors.Is(err, services.ErrInvalidMood) {
+		code, message = "invalid_mood", "This mood is not part of the retrospective's mood scale"
+	} else {
+		log.Printf("%s: %v", handler, err)
+	}
+	h.sendError(client, code, message)
+}
+
+// handleRotiVote handles a user's ROTI vote
+func (h *WebSocketHandler) handleRotiVote(client *ws.Client, payload json.RawMessage) {
 	var data struct {
-		Rating int `json:"rating"`
+		Rating  int    `json:"rating"`
+		Comment string `json:"comment"`
 	}
 	if err := json.Unmarshal(payload, &data); err != nil {
 		log.Printf("handleRotiVote: failed to unmarshal payload: %v", err)
@@ -1198,14 +1667,25 @@ func (h *WebSocketHandler) handleRotiVote(client *ws.Client, payload json.RawMes
 		return
 	}
 
-	_, err = h.retroService.SetRotiVote(context.Background(), retroID, client.UserID, data.Rating)
+	_, err = h.retroService.SetRotiVote(context.Background(), retroID, client.UserID, data.Rating, data.Comment)
 	if err != nil {
-		log.Printf("handleRotiVote: failed to set vote: %v", err)
+		code, message := "roti_vote_failed", "Failed to save your ROTI vote"
+		switch {
+		case errors.Is(err, services.ErrRotiRevealed):
+			code, message = "roti_revealed", "ROTI results are revealed, votes can no longer change"
+		case errors.Is(err, services.ErrInvalidRotiRating):
+			code, message = "invalid_rating", "Rating must be between 1 and 5"
+		case errors.Is(err, services.ErrRotiCommentTooLong):
+			code, message = "comment_too_long", "Your ROTI comment is too long"
+		default:
+			log.Printf("handleRotiVote: failed to set vote: %v", err)
+		}
+		h.sendError(client, code, message)
 		return
 	}
 
-	// Get participant count and vote count
-	participants := h.bridge.GetRoomClients(retroID.String())
+	// Get participant count and vote count (a changed vote replaces the previous one)
+	participants := h.participants(retroID.String())
 	voteCount, _ := h.retroService.CountRotiVotes(context.Background(), retroID)
 
 	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
@@ -1220,17 +1700,17 @@ func (h *WebSocketHandler) handleRotiVote(client *ws.Client, payload json.RawMes
 
 // handleRotiReveal handles revealing ROTI results (facilitator only)
 func (h *WebSocketHandler) handleRotiReveal(client *ws.Client) {
-	if client.RoomID == "" {
-		return
-	}
-
 	retroID, err := uuid.Parse(client.RoomID)
 	if err != nil {
 		return
 	}
 
-	results, err := h.retroService.RevealRotiResults(context.Background(), retroID)
+	results, err := h.retroService.RevealRotiResults(context.Background(), retroID, client.UserID)
 	if err != nil {
+		if errors.Is(err, services.ErrNotFacilitator) {
+			h.sendError(client, "not_facilitator", "Only the facilitator can reveal the ROTI results")
+			return
+		}
 		log.Printf("handleRotiReveal: failed to reveal results: %v", err)
 		return
 	}
@@ -1241,63 +1721,119 @@ func (h *WebSocketHandler) handleRotiReveal(client *ws.Client) {
 	})
 }
 
-// handleDraftTyping handles broadcasting draft typing status to other participants
-func (h *WebSocketHandler) handleDraftTyping(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
+// handleSurveyStart handles starting the end-of-session survey (facilitator only)
+func (h *WebSocketHandler) handleSurveyStart(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		Questions []services.SurveyQuestionInput `json:"questions"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		log.Printf("handleSurveyStart: failed to unmarshal payload: %v", err)
 		return
 	}
 
-	var data struct {
-		ColumnID      string `json:"columnId"`
-		ContentLength int    `json:"contentLength"`
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
 	}
-	if err := json.Unmarshal(payload, &data); err != nil {
-		log.Printf("handleDraftTyping: failed to unmarshal payload: %v", err)
+
+	ctx := context.Background()
+	questions, err := h.surveyService.StartSurvey(ctx, retroID, data.Questions)
+	if err != nil {
+		if errors.Is(err, services.ErrSurveyInvalidQuestions) {
+			h.sendError(client, "invalid_survey", err.Error())
+			return
+		}
+		log.Printf("handleSurveyStart: failed to start survey: %v", err)
 		return
 	}
 
-	// Broadcast to other users (not the author) that someone is typing
-	h.bridge.BroadcastToRoomExcept(client.RoomID, ws.Message{
-		Type: "draft_typing",
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "survey_started",
 		Payload: map[string]interface{}{
-			"userId":        client.UserID,
-			"userName":      client.UserName,
-			"columnId":      data.ColumnID,
-			"contentLength": data.ContentLength,
+			"questions": questions,
 		},
-	}, client)
+	})
 }
 
-// handleDraftClear handles clearing a draft when user submits or clears the input
-func (h *WebSocketHandler) handleDraftClear(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
+// handleSurveyAnswer handles a participant's answer to a survey question
+func (h *WebSocketHandler) handleSurveyAnswer(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		QuestionID string  `json:"questionId"`
+		ScaleValue *int    `json:"scaleValue"`
+		TextValue  *string `json:"textValue"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		log.Printf("handleSurveyAnswer: failed to unmarshal payload: %v", err)
 		return
 	}
 
-	var data struct {
-		ColumnID string `json:"columnId"`
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
 	}
-	if err := json.Unmarshal(payload, &data); err != nil {
-		log.Printf("handleDraftClear: failed to unmarshal payload: %v", err)
+
+	questionID, err := uuid.Parse(data.QuestionID)
+	if err != nil {
 		return
 	}
 
-	// Broadcast to other users that the draft is cleared
-	h.bridge.BroadcastToRoomExcept(client.RoomID, ws.Message{
-		Type: "draft_cleared",
+	ctx := context.Background()
+	_, err = h.surveyService.SubmitAnswer(ctx, retroID, client.UserID, questionID, data.ScaleValue, data.TextValue)
+	if err != nil {
+		if errors.Is(err, services.ErrSurveyInvalidAnswer) || errors.Is(err, services.ErrSurveyQuestionNotFound) {
+			h.sendError(client, "invalid_survey_answer", err.Error())
+			return
+		}
+		log.Printf("handleSurveyAnswer: failed to set answer: %v", err)
+		return
+	}
+
+	// Only counts are broadcast so answers stay hidden until reveal
+	results, err := h.surveyService.GetResults(ctx, retroID)
+	if err != nil {
+		return
+	}
+	answerCount := 0
+	for _, q := range results.Questions {
+		if q.Question.ID == questionID {
+			answerCount = q.AnswerCount
+			break
+		}
+	}
+	participants := h.participants(retroID.String())
+
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "survey_answer_submitted",
 		Payload: map[string]interface{}{
-			"userId":   client.UserID,
-			"columnId": data.ColumnID,
+			"questionId":       questionID,
+			"answerCount":      answerCount,
+			"participantCount": len(participants),
 		},
-	}, client)
+	})
 }
 
-// handleFacilitatorClaim handles a user claiming the facilitator role
-func (h *WebSocketHandler) handleFacilitatorClaim(client *ws.Client) {
-	if client.RoomID == "" {
+// handleSurveyReveal handles revealing survey results (facilitator only)
+func (h *WebSocketHandler) handleSurveyReveal(client *ws.Client) {
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	results, err := h.surveyService.RevealResults(ctx, retroID)
+	if err != nil {
+		log.Printf("handleSurveyReveal: failed to reveal results: %v", err)
 		return
 	}
 
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type:    "survey_results_revealed",
+		Payload: results,
+	})
+}
+
+// handleFacilitatorClaim handles a user claiming the facilitator role
+func (h *WebSocketHandler) handleFacilitatorClaim(client *ws.Client) {
 	retroID, err := uuid.Parse(client.RoomID)
 	if err != nil {
 		return
@@ -1310,17 +1846,6 @@ func (h *WebSocketHandler) handleFacilitatorClaim(client *ws.Client) {
 		return
 	}
 
-	// Only allow claiming during waiting phase
-	if retro.CurrentPhase != models.PhaseWaiting {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Facilitator can only be changed during the waiting phase",
-			},
-		})
-		return
-	}
-
 	// Check if user has the right role (admin or facilitator of the team)
 	member, err := h.teamMemberRepo.GetByTeamAndUser(ctx, retro.TeamID, client.UserID)
 	if err != nil {
@@ -1329,38 +1854,15 @@ func (h *WebSocketHandler) handleFacilitatorClaim(client *ws.Client) {
 	}
 
 	if member.Role != models.RoleAdmin {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Only admins can claim the facilitator role",
-			},
-		})
+		h.sendError(client, "not_admin", "Only admins can claim the facilitator role")
 		return
 	}
 
-	// Update the facilitator
-	retro.FacilitatorID = client.UserID
-	if err := h.retroService.Update(ctx, retro); err != nil {
-		log.Printf("handleFacilitatorClaim: failed to update retro: %v", err)
-		return
-	}
-
-	// Broadcast the change to all participants
-	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
-		Type: "facilitator_changed",
-		Payload: map[string]interface{}{
-			"facilitatorId":   client.UserID,
-			"facilitatorName": client.UserName,
-		},
-	})
+	h.changeFacilitator(ctx, retro, client.UserID, client.UserName)
 }
 
 // handleFacilitatorTransfer handles transferring the facilitator role to another participant
 func (h *WebSocketHandler) handleFacilitatorTransfer(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		UserID string `json:"userId"`
 	}
@@ -1387,62 +1889,200 @@ func (h *WebSocketHandler) handleFacilitatorTransfer(client *ws.Client, payload
 		return
 	}
 
-	// Only allow transfer during waiting phase
-	if retro.CurrentPhase != models.PhaseWaiting {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Facilitator can only be changed during the waiting phase",
-			},
-		})
-		return
-	}
-
-	// Check if client is the current facilitator
-	if retro.FacilitatorID != client.UserID {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Only the current facilitator can transfer the role",
-			},
-		})
-		return
-	}
-
 	// Check if target user is in the room (local + remote)
 	if !h.bridge.IsUserInRoom(client.RoomID, targetUserID) {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Target user is not in the room",
-			},
-		})
+		h.sendError(client, "user_not_in_room", "Target user is not in the room")
 		return
 	}
 
 	// Get target user name
 	participants := h.bridge.GetRoomClients(client.RoomID)
 	var targetUserName string
+	var targetIsGuest, targetIsSpectator bool
 	for _, p := range participants {
 		if p.UserID == targetUserID {
 			targetUserName = p.UserName
+			targetIsGuest = p.Guest
+			targetIsSpectator = p.Spectator
 			break
 		}
 	}
 
-	// Update the facilitator
-	retro.FacilitatorID = targetUserID
+	// Guests are not on the team and cannot run its retro
+	if targetIsGuest {
+		h.sendError(client, "guest_not_allowed", "Guests cannot be facilitator")
+		return
+	}
+	// Spectators are read-only, so they could not run it either
+	if targetIsSpectator {
+		h.sendError(client, "spectator_not_allowed", "Spectators cannot be facilitator")
+		return
+	}
+
+	// In the waiting room the role moves immediately
+	if retro.CurrentPhase == models.PhaseWaiting {
+		h.changeFacilitator(ctx, retro, targetUserID, targetUserName)
+		return
+	}
+
+	// Mid-session the target has to accept before the role moves
+	expiresAt := time.Now().Add(facilitatorTransferTimeout)
+	if err := h.transferRepo.Put(ctx, &models.FacilitatorTransfer{
+		RetroID:    retroID,
+		FromUserID: client.UserID,
+		ToUserID:   targetUserID,
+		ToUserName: targetUserName,
+		ExpiresAt:  expiresAt,
+	}); err != nil {
+		log.Printf("handleFacilitatorTransfer: failed to store transfer: %v", err)
+		h.sendError(client, "transfer_failed", "Failed to request the facilitator transfer")
+		return
+	}
+
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "facilitator_transfer_requested",
+		Payload: map[string]interface{}{
+			"fromUserId":   client.UserID,
+			"fromUserName": client.UserName,
+			"toUserId":     targetUserID,
+			"toUserName":   targetUserName,
+			"expiresAt":    expiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+// handleFacilitatorTransferAccept handles the target accepting a pending facilitator transfer
+func (h *WebSocketHandler) handleFacilitatorTransferAccept(client *ws.Client) {
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	pending, err := h.transferRepo.Take(ctx, retroID, client.UserID)
+	if err != nil {
+		if !errors.Is(err, postgres.ErrNotFound) {
+			log.Printf("handleFacilitatorTransferAccept: failed to take transfer: %v", err)
+		}
+		h.sendError(client, "no_pending_transfer", "No pending facilitator transfer for you")
+		return
+	}
+
+	retro, err := h.retroService.GetByID(ctx, retroID)
+	if err != nil {
+		log.Printf("handleFacilitatorTransferAccept: failed to get retro: %v", err)
+		return
+	}
+
+	// The role may have changed hands since the request was made
+	if retro.FacilitatorID != pending.FromUserID {
+		h.sendError(client, "transfer_outdated", "The facilitator has changed since the transfer was requested")
+		return
+	}
+
+	h.changeFacilitator(ctx, retro, client.UserID, client.UserName)
+}
+
+// handleFacilitatorTransferDecline handles the target declining, or the facilitator cancelling, a pending transfer
+func (h *WebSocketHandler) handleFacilitatorTransferDecline(client *ws.Client) {
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	pending, err := h.transferRepo.Cancel(context.Background(), retroID, client.UserID)
+	if err != nil {
+		if !errors.Is(err, postgres.ErrNotFound) {
+			log.Printf("handleFacilitatorTransferDecline: failed to cancel transfer: %v", err)
+		}
+		return
+	}
+
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "facilitator_transfer_declined",
+		Payload: map[string]interface{}{
+			"fromUserId": pending.FromUserID,
+			"toUserId":   pending.ToUserID,
+			"declinedBy": client.UserID,
+		},
+	})
+}
+
+// broadcastTransferExpired tells the room that a facilitator transfer expired without an answer
+func (h *WebSocketHandler) broadcastTransferExpired(transfer *models.FacilitatorTransfer) {
+	h.bridge.BroadcastToRoom(transfer.RetroID.String(), ws.Message{
+		Type: "facilitator_transfer_expired",
+		Payload: map[string]interface{}{
+			"fromUserId": transfer.FromUserID,
+			"toUserId":   transfer.ToUserID,
+		},
+	})
+}
+
+// reassignFacilitator promotes a remaining participant after the facilitator left an active retro.
+// Team admins are preferred, then whoever has been connected the longest; guests and spectators are
+// never promoted.
+func (h *WebSocketHandler) reassignFacilitator(ctx context.Context, retro *models.Retrospective, leftUserID uuid.UUID) {
+	roles := make(map[uuid.UUID]models.Role)
+	if members, err := h.teamMemberRepo.ListByTeam(ctx, retro.TeamID); err == nil {
+		for _, m := range members {
+			roles[m.UserID] = m.Role
+		}
+	}
+
+	var candidates []*ws.Client
+	for _, c := range h.participants(retro.ID.String()) {
+		if c.UserID != leftUserID && !c.Guest && !c.Spectator {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		slog.Debug("reassignFacilitator: no participant left to promote", "retroId", retro.ID.String())
+		return
+	}
+
+	// Remote clients have no ConnectedAt and are ranked after local ones
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iAdmin := roles[candidates[i].UserID] == models.RoleAdmin
+		jAdmin := roles[candidates[j].UserID] == models.RoleAdmin
+		if iAdmin != jAdmin {
+			return iAdmin
+		}
+		ci, cj := candidates[i].ConnectedAt, candidates[j].ConnectedAt
+		if ci.IsZero() != cj.IsZero() {
+			return !ci.IsZero()
+		}
+		return ci.Before(cj)
+	})
+
+	next := candidates[0]
+	slog.Info("reassigning facilitator after disconnect",
+		"retroId", retro.ID.String(),
+		"previousFacilitator", leftUserID.String(),
+		"newFacilitator", next.UserID.String(),
+	)
+	h.changeFacilitator(ctx, retro, next.UserID, next.UserName)
+}
+
+// changeFacilitator persists the new facilitator and broadcasts facilitator_changed
+func (h *WebSocketHandler) changeFacilitator(ctx context.Context, retro *models.Retrospective, userID uuid.UUID, userName string) {
+	retro.FacilitatorID = userID
 	if err := h.retroService.Update(ctx, retro); err != nil {
-		log.Printf("handleFacilitatorTransfer: failed to update retro: %v", err)
+		log.Printf("changeFacilitator: failed to update retro: %v", err)
 		return
 	}
 
+	roomID := retro.ID.String()
+	if err := h.transferRepo.Delete(ctx, retro.ID); err != nil {
+		log.Printf("changeFacilitator: failed to clear pending transfer: %v", err)
+	}
+
 	// Broadcast the change to all participants
-	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+	h.bridge.BroadcastToRoom(roomID, ws.Message{
 		Type: "facilitator_changed",
 		Payload: map[string]interface{}{
-			"facilitatorId":   targetUserID,
-			"facilitatorName": targetUserName,
+			"facilitatorId":   userID,
+			"facilitatorName": userName,
 		},
 	})
 }
@@ -1451,10 +2091,6 @@ func (h *WebSocketHandler) handleFacilitatorTransfer(client *ws.Client, payload
 // For retros: broadcasts discuss_item_changed to sync the carousel.
 // For LC: also updates lc_current_topic_id, records history, and starts timer.
 func (h *WebSocketHandler) handleDiscussSetItem(client *ws.Client, payload json.RawMessage) {
-	if client.RoomID == "" {
-		return
-	}
-
 	var data struct {
 		ItemID string `json:"itemId"`
 	}
@@ -1480,40 +2116,29 @@ func (h *WebSocketHandler) handleDiscussSetItem(client *ws.Client, payload json.
 		return
 	}
 
-	// Only facilitator can navigate
-	if retro.FacilitatorID != client.UserID {
-		h.hub.SendToClient(client, ws.Message{
-			Type: "error",
-			Payload: map[string]interface{}{
-				"message": "Only the facilitator can navigate discussion items",
-			},
-		})
-		return
-	}
-
 	if retro.SessionType == models.SessionTypeLeanCoffee {
 		// LC mode: update topic, record history, start timer
 		history, _, err := h.leanCoffeeService.SetTopic(ctx, retroID, itemID)
 		if err != nil {
+			if errors.Is(err, services.ErrNoTopicsToDiscuss) {
+				// Nothing left to discuss: let everyone see the final state
+				h.broadcastLCDiscussionState(ctx, retroID)
+				return
+			}
 			log.Printf("handleDiscussSetItem: failed to set LC topic: %v", err)
+			h.sendError(client, "discuss_failed", "Failed to set discussion topic")
 			return
 		}
 
 		// Broadcast LC-specific state update
-		lcState, err := h.leanCoffeeService.GetDiscussionState(ctx, retroID)
-		if err == nil {
-			h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
-				Type:    "lc_discussion_state",
-				Payload: lcState,
-			})
-		}
+		h.broadcastLCDiscussionState(ctx, retroID)
 
 		// Start topic timer if configured
 		timeboxSeconds := 300 // default 5 min
 		if retro.LCTopicTimeboxSeconds != nil {
 			timeboxSeconds = *retro.LCTopicTimeboxSeconds
 		}
-		_ = h.timerService.StartTimer(ctx, retroID, timeboxSeconds)
+		_ = h.timerService.StartTopicTimer(ctx, retroID, timeboxSeconds)
 
 		_ = history // used for creating history entry
 	}
@@ -1538,3 +2163,113 @@ func (h *WebSocketHandler) handleDiscussSetItem(client *ws.Client, payload json.
 		},
 	})
 }
+
+// handleDiscussFocusItem highlights the same item on every screen during a retro's discuss phase.
+// The focus is stored on the retro so late joiners get it in retro_state.
+func (h *WebSocketHandler) handleDiscussFocusItem(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		ItemID string `json:"itemId"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return
+	}
+
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+	itemID, err := uuid.Parse(data.ItemID)
+	if err != nil {
+		return
+	}
+
+	if err := h.retroService.FocusItem(context.Background(), retroID, itemID, client.UserID); err != nil {
+		if errors.Is(err, services.ErrItemNotFound) {
+			h.sendNotFoundError(client, err)
+			return
+		}
+		log.Printf("handleDiscussFocusItem: failed to focus item: %v", err)
+		return
+	}
+
+	h.bridge.BroadcastToRoom(client.RoomID, ws.Message{
+		Type: "discuss_focused",
+		Payload: map[string]interface{}{
+			"itemId": itemID,
+		},
+	})
+}
+
+// handleLCQueueReorder handles the facilitator manually reordering the Lean Coffee queue
+func (h *WebSocketHandler) handleLCQueueReorder(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		TopicIDs []string `json:"topicIds"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		log.Printf("handleLCQueueReorder: failed to unmarshal payload: %v", err)
+		return
+	}
+
+	retroID, err := uuid.Parse(client.RoomID)
+	if err != nil {
+		return
+	}
+
+	// One bad ID rejects the whole reorder, so the queue is never saved with topics missing
+	topicIDs := make([]uuid.UUID, 0, len(data.TopicIDs))
+	for _, idStr := range data.TopicIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			h.sendQueueReorderInvalid(client, "topicIds must be UUIDs")
+			return
+		}
+		topicIDs = append(topicIDs, id)
+	}
+
+	ctx := context.Background()
+	if err := h.leanCoffeeService.ReorderQueue(ctx, retroID, topicIDs); err != nil {
+		if errors.Is(err, services.ErrTopicNotInSession) {
+			h.sendQueueReorderInvalid(client, "topicIds must be topics of this session")
+			return
+		}
+		log.Printf("handleLCQueueReorder: failed to reorder queue: %v", err)
+		h.sendError(client, "reorder_failed", "Failed to reorder the queue")
+		return
+	}
+
+	h.broadcastLCDiscussionState(ctx, retroID)
+}
+
+// sendQueueReorderInvalid rejects an lc_queue_reorder whose topicIds are not all topics of the session
+func (h *WebSocketHandler) sendQueueReorderInvalid(client *ws.Client, message string) {
+	h.sendWSError(client, WSError{
+		Code:        "validation",
+		Message:     message,
+		Field:       "topicIds",
+		MessageType: "lc_queue_reorder",
+	})
+}
+
+// broadcastLCDiscussionState broadcasts the current Lean Coffee discussion state to the room.
+// When no topic is being discussed and the queue is empty, it also broadcasts lc_all_topics_done.
+func (h *WebSocketHandler) broadcastLCDiscussionState(ctx context.Context, retroID uuid.UUID) {
+	lcState, err := h.leanCoffeeService.GetDiscussionState(ctx, retroID)
+	if err != nil {
+		log.Printf("broadcastLCDiscussionState: failed to get discussion state: %v", err)
+		return
+	}
+
+	h.bridge.BroadcastToRoom(retroID.String(), ws.Message{
+		Type:    "lc_discussion_updated",
+		Payload: lcState,
+	})
+
+	if lcState.CurrentTopicID == nil && len(lcState.Queue) == 0 {
+		h.bridge.BroadcastToRoom(retroID.String(), ws.Message{
+			Type: "lc_all_topics_done",
+			Payload: map[string]interface{}{
+				"doneCount": len(lcState.Done),
+			},
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func newJoinHandler(env *testenv.Env) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub:               env.Pod.Hub,
+		bridge:            env.Pod.Bus,
+		retroService:      env.Services.Retro,
+		timerService:      env.Services.Timer,
+		leanCoffeeService: env.Services.LeanCoffee,
+		surveyService:     env.Services.Survey,
+		teamMemberRepo:    env.Repos.TeamMembers,
+		attendeeRepo:      env.Repos.Attendees,
+		retroStatus:       newRetroStatusCache(),
+		transferRepo:      env.Repos.Transfers,
+		drafts:            newDraftTracker(0),
+		tokens:            newTokenWatcher(nil, nil, 0, 0),
+	}
+}
+
+// joinRetro connects the user and sends join_retro, returning the first message received
+func joinRetro(t *testing.T, h *WebSocketHandler, user *models.User, retro *models.Retrospective) (*ws.Client, ws.Message) {
+	t.Helper()
+	client := &ws.Client{ID: uuid.NewString(), UserID: user.ID, UserName: user.DisplayName, Hub: h.hub, Send: make(chan []byte, 16)}
+	return client, joinWithClient(t, h, client, retro)
+}
+
+// joinWithClient registers the client and sends join_retro, returning the first message received
+func joinWithClient(t *testing.T, h *WebSocketHandler, client *ws.Client, retro *models.Retrospective) ws.Message {
+	t.Helper()
+	h.hub.Register(client)
+	h.handleJoinRetro(client, json.RawMessage(`{"retroId":"`+retro.ID.String()+`"}`))
+
+	select {
+	case data, ok := <-client.Send:
+		if !ok {
+			t.Fatal("connection closed without a message")
+		}
+		var msg ws.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+		return ws.Message{}
+	}
+}
+
+func TestJoinRetroRejectsNonMember(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	outsider := env.CreateUser(t, "Outsider")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+
+	client, msg := joinRetro(t, h, outsider, retro)
+	payload, _ := msg.Payload.(map[string]interface{})
+	if msg.Type != "error" || payload["code"] != "not_team_member" {
+		t.Fatalf("got %s %v, want error not_team_member", msg.Type, msg.Payload)
+	}
+	if h.hub.IsUserInRoom(retro.ID.String(), outsider.ID) {
+		t.Fatal("non-member was added to the room")
+	}
+	select {
+	case _, ok := <-client.Send:
+		if ok {
+			t.Fatal("got another message, want the connection closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not closed")
+	}
+
+	// The team admin joins as usual
+	if _, msg := joinRetro(t, h, admin, retro); msg.Type != "retro_state" {
+		t.Fatalf("member got %s, want retro_state", msg.Type)
+	}
+}
+
+func TestJoinOpenRetroAcceptsNonMember(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	guest := env.CreateUser(t, "Guest")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{OpenAccess: true})
+
+	if _, msg := joinRetro(t, h, guest, retro); msg.Type != "retro_state" {
+		t.Fatalf("non-member of an open retro got %s %v, want retro_state", msg.Type, msg.Payload)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func TestLCQueueReorderRejectsInvalidTopics(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	session := env.CreateRetro(t, team, admin, services.CreateRetroInput{SessionType: models.SessionTypeLeanCoffee})
+	other := env.CreateRetro(t, team, admin, services.CreateRetroInput{SessionType: models.SessionTypeLeanCoffee})
+	var topics []*models.Item
+	for _, retro := range []*models.Retrospective{session, session, other} {
+		topic, err := env.Services.Retro.CreateItem(ctx, retro.ID, admin.ID, services.CreateItemInput{ColumnID: "topics", Content: "Topic"})
+		if err != nil {
+			t.Fatalf("create topic: %v", err)
+		}
+		topics = append(topics, topic)
+	}
+	first, second, foreign := topics[0], topics[1], topics[2]
+
+	client := &ws.Client{ID: uuid.NewString(), UserID: admin.ID, RoomID: session.ID.String(), Hub: h.hub, Send: make(chan []byte, 16)}
+	h.hub.Register(client)
+
+	for name, topicIDs := range map[string][]string{
+		"unparsable ID":        {second.ID.String(), "not-a-uuid", first.ID.String()},
+		"topic of another one": {second.ID.String(), foreign.ID.String(), first.ID.String()},
+	} {
+		payload, _ := json.Marshal(map[string][]string{"topicIds": topicIDs})
+		h.handleLCQueueReorder(client, payload)
+
+		select {
+		case data := <-client.Send:
+			var msg struct {
+				Type    string  `json:"type"`
+				Payload WSError `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("decode message: %v", err)
+			}
+			if msg.Type != "error" || msg.Payload.Code != "validation" || msg.Payload.Field != "topicIds" {
+				t.Fatalf("%s: got %s %+v, want a validation error on topicIds", name, msg.Type, msg.Payload)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: no error received", name)
+		}
+
+		got, err := env.Services.Retro.GetByID(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("get session: %v", err)
+		}
+		if len(got.LCQueueOrder) != 0 {
+			t.Fatalf("%s: queue saved as %v, want the reorder rejected", name, got.LCQueueOrder)
+		}
+	}
+
+	h.handleLCQueueReorder(client, json.RawMessage(`{"topicIds":["`+second.ID.String()+`","`+first.ID.String()+`"]}`))
+	got, err := env.Services.Retro.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if len(got.LCQueueOrder) != 2 || got.LCQueueOrder[0] != second.ID || got.LCQueueOrder[1] != first.ID {
+		t.Fatalf("queue = %v, want the valid reorder saved", got.LCQueueOrder)
+	}
+}
+
+func TestLCDiscussSetItemEndsWhenNothingIsLeft(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	session := env.CreateRetro(t, team, admin, services.CreateRetroInput{SessionType: models.SessionTypeLeanCoffee})
+	var topics []*models.Item
+	for range 2 {
+		topic, err := env.Services.Retro.CreateItem(ctx, session.ID, admin.ID, services.CreateItemInput{ColumnID: "topics", Content: "Topic"})
+		if err != nil {
+			t.Fatalf("create topic: %v", err)
+		}
+		topics = append(topics, topic)
+	}
+	first := topics[0]
+
+	// Both topics discussed, the second one still current
+	for _, topic := range topics {
+		if _, _, err := env.Services.LeanCoffee.SetTopic(ctx, session.ID, topic.ID); err != nil {
+			t.Fatalf("set topic: %v", err)
+		}
+	}
+
+	client := &ws.Client{ID: uuid.NewString(), UserID: admin.ID, RoomID: session.ID.String(), Hub: h.hub, Send: make(chan []byte, 16)}
+	h.hub.Register(client)
+	h.handleDiscussSetItem(client, json.RawMessage(`{"itemId":"`+first.ID.String()+`"}`))
+
+	deadline := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case data := <-client.Send:
+			var msg ws.Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("decode message: %v", err)
+			}
+			switch msg.Type {
+			case "lc_all_topics_done":
+				done = true
+			case "error", "discuss_item_changed":
+				t.Fatalf("got %s %v, want lc_all_topics_done", msg.Type, msg.Payload)
+			}
+		case <-deadline:
+			t.Fatal("lc_all_topics_done not received")
+		}
+	}
+
+	state, err := env.Services.LeanCoffee.GetDiscussionState(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("discussion state: %v", err)
+	}
+	if state.CurrentTopicID != nil || len(state.Queue) != 0 || len(state.Done) != 2 {
+		t.Fatalf("state = current %v, %d queued, %d done; want both topics done", state.CurrentTopicID, len(state.Queue), len(state.Done))
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// closeOverCapacity closes a connection the hub has no room for with a try_again_later close
+// frame naming the limit, so the client backs off before reconnecting.
+func closeOverCapacity(conn *websocket.Conn, err error) {
+	reason := "too_many_connections"
+	if errors.Is(err, ws.ErrTooManyRooms) {
+		reason = "too_many_rooms"
+	}
+	slog.Warn("websocket: closing connection over capacity", "reason", reason)
+	if conn == nil {
+		return
+	}
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, reason)
+	_ = conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+	_ = conn.Close()
+}
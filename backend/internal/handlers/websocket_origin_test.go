@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialWithOrigin upgrades against a server using the handler's upgrader and returns the handshake status
+func dialWithOrigin(t *testing.T, upgrader websocket.Upgrader, origin string) int {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), header)
+	if err == nil {
+		_ = conn.Close()
+	}
+	if resp == nil {
+		t.Fatalf("dial with origin %q: %v", origin, err)
+	}
+	return resp.StatusCode
+}
+
+func TestUpgraderChecksOrigin(t *testing.T) {
+	upgrader := newUpgrader([]string{"https://retro.example.com", " http://localhost:3000/"}, false)
+
+	cases := map[string]int{
+		"https://retro.example.com": http.StatusSwitchingProtocols,
+		"https://RETRO.example.com": http.StatusSwitchingProtocols,
+		"http://localhost:3000":     http.StatusSwitchingProtocols,
+		"":                          http.StatusSwitchingProtocols, // non-browser client
+		"https://evil.example.com":  http.StatusForbidden,
+		"http://retro.example.com":  http.StatusForbidden,
+		"null":                      http.StatusForbidden,
+	}
+	for origin, want := range cases {
+		if got := dialWithOrigin(t, upgrader, origin); got != want {
+			t.Errorf("origin %q: status %d, want %d", origin, got, want)
+		}
+	}
+}
+
+func TestUpgraderDevModeAllowsAnyOrigin(t *testing.T) {
+	upgrader := newUpgrader([]string{"http://localhost:3000"}, true)
+	if got := dialWithOrigin(t, upgrader, "http://192.168.1.20:5173"); got != http.StatusSwitchingProtocols {
+		t.Errorf("dev mode: status %d, want %d", got, http.StatusSwitchingProtocols)
+	}
+}
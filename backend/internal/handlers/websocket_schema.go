@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// wsFieldKind is the JSON value a payload field must hold
+type wsFieldKind int
+
+const (
+	wsString wsFieldKind = iota // a non-empty string
+	wsUUID                      // a string holding a UUID
+	wsNumber                    // a number
+	wsArray                     // an array of any values
+	wsUUIDs                     // an array of UUID strings
+)
+
+func (k wsFieldKind) String() string {
+	switch k {
+	case wsUUID:
+		return "a UUID"
+	case wsNumber:
+		return "a number"
+	case wsArray:
+		return "an array"
+	case wsUUIDs:
+		return "an array of UUIDs"
+	default:
+		return "a non-empty string"
+	}
+}
+
+// wsField is a payload field a message type requires
+type wsField struct {
+	name string
+	kind wsFieldKind
+}
+
+// wsSchemas lists the required payload fields of incoming WebSocket messages.
+// They are checked centrally in handleMessage before the message is dispatched, so a client
+// forgetting a field gets an invalid_payload error instead of a silent no-op.
+// Message types that are not listed take no payload or only optional fields.
+var wsSchemas = map[string][]wsField{
+	"join_retro":    {{"retroId", wsUUID}},
+	"token_refresh": {{"token", wsString}},
+
+	"item_create": {{"columnId", wsString}, {"content", wsString}},
+	"item_update": {{"itemId", wsUUID}, {"content", wsString}},
+	"item_delete": {{"itemId", wsUUID}},
+	"item_move":   {{"itemId", wsUUID}, {"columnId", wsString}, {"position", wsNumber}},
+	"item_group":  {{"parentId", wsUUID}, {"childIds", wsUUIDs}},
+
+	"item_mark_discussed": {{"itemId", wsUUID}},
+
+	"vote_add":    {{"itemId", wsUUID}},
+	"vote_remove": {{"itemId", wsUUID}},
+
+	"timer_add_time": {{"seconds", wsNumber}},
+	"phase_set":      {{"phase", wsString}},
+
+	"action_create":     {{"title", wsString}},
+	"action_complete":   {{"actionId", wsUUID}},
+	"action_uncomplete": {{"actionId", wsUUID}},
+	"action_delete":     {{"actionId", wsUUID}},
+
+	"mood_set":      {{"mood", wsString}},
+	"roti_vote":     {{"rating", wsNumber}},
+	"survey_start":  {{"questions", wsArray}},
+	"survey_answer": {{"questionId", wsUUID}},
+
+	"draft_typing": {{"columnId", wsString}, {"contentLength", wsNumber}},
+	"draft_clear":  {{"columnId", wsString}},
+
+	"facilitator_transfer": {{"userId", wsUUID}},
+	"discuss_set_item":     {{"itemId", wsUUID}},
+	"lc_queue_reorder":     {{"topicIds", wsUUIDs}},
+	"discuss_focus_item":   {{"itemId", wsUUID}},
+}
+
+// wsPayloadError names the payload field that failed validation
+type wsPayloadError struct {
+	Field   string
+	Message string
+}
+
+// validatePayload checks the payload against the message type's schema; nil when it is valid
+// or the type has no schema
+func validatePayload(msgType string, payload json.RawMessage) *wsPayloadError {
+	fields, ok := wsSchemas[msgType]
+	if !ok {
+		return nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &values); err != nil || values == nil {
+		return &wsPayloadError{Message: "payload must be a JSON object"}
+	}
+
+	for _, field := range fields {
+		raw, ok := values[field.name]
+		if !ok || bytes.Equal(raw, []byte("null")) {
+			return &wsPayloadError{Field: field.name, Message: field.name + " is required"}
+		}
+		if !field.kind.matches(raw) {
+			return &wsPayloadError{Field: field.name, Message: fmt.Sprintf("%s must be %s", field.name, field.kind)}
+		}
+	}
+	return nil
+}
+
+func (k wsFieldKind) matches(raw json.RawMessage) bool {
+	switch k {
+	case wsNumber:
+		var n float64
+		return json.Unmarshal(raw, &n) == nil
+	case wsArray:
+		var values []json.RawMessage
+		return json.Unmarshal(raw, &values) == nil
+	case wsUUIDs:
+		var ids []string
+		if json.Unmarshal(raw, &ids) != nil {
+			return false
+		}
+		for _, id := range ids {
+			if _, err := uuid.Parse(id); err != nil {
+				return false
+			}
+		}
+		return true
+	case wsUUID:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return false
+		}
+		_, err := uuid.Parse(s)
+		return err == nil
+	default:
+		var s string
+		return json.Unmarshal(raw, &s) == nil && s != ""
+	}
+}
+
+// validate checks the message's payload and tells the client which field is wrong
+func (h *WebSocketHandler) validate(client *ws.Client, msgType string, payload json.RawMessage) bool {
+	payloadErr := validatePayload(msgType, payload)
+	if payloadErr == nil {
+		return true
+	}
+
+	h.sendWSError(client, WSError{
+		Code:        "invalid_payload",
+		Message:     payloadErr.Message,
+		Field:       payloadErr.Field,
+		MessageType: msgType,
+	})
+	return false
+}
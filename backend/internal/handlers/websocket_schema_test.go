@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func TestWSSchemasHaveRules(t *testing.T) {
+	for msgType := range wsSchemas {
+		if _, ok := wsRules[msgType]; !ok {
+			t.Errorf("%s has a schema but no rule, so it is never dispatched", msgType)
+		}
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	id := uuid.NewString()
+	cases := []struct {
+		name      string
+		msgType   string
+		payload   string
+		wantField string
+		wantOK    bool
+	}{
+		{name: "join", msgType: "join_retro", payload: `{"retroId":"` + id + `"}`, wantOK: true},
+		{name: "join without retroId", msgType: "join_retro", payload: `{}`, wantField: "retroId"},
+		{name: "join with a malformed retroId", msgType: "join_retro", payload: `{"retroId":"42"}`, wantField: "retroId"},
+		{name: "item without content", msgType: "item_create", payload: `{"columnId":"start","content":""}`, wantField: "content"},
+		{name: "move with a null position", msgType: "item_move", payload: `{"itemId":"` + id + `","columnId":"start","position":null}`, wantField: "position"},
+		{name: "move to position 0", msgType: "item_move", payload: `{"itemId":"` + id + `","columnId":"start","position":0}`, wantOK: true},
+		{name: "group with a bad child", msgType: "item_group", payload: `{"parentId":"` + id + `","childIds":["` + id + `","nope"]}`, wantField: "childIds"},
+		{name: "roti with a string rating", msgType: "roti_vote", payload: `{"rating":"5"}`, wantField: "rating"},
+		{name: "payload is not an object", msgType: "vote_add", payload: `"` + id + `"`},
+		{name: "missing payload", msgType: "vote_add", payload: ``},
+		{name: "no schema", msgType: "phase_next", payload: ``, wantOK: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePayload(tc.msgType, json.RawMessage(tc.payload))
+			if tc.wantOK {
+				if err != nil {
+					t.Fatalf("got %+v, want the payload accepted", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("got no error, want the payload rejected")
+			}
+			if err.Field != tc.wantField {
+				t.Fatalf("got field %q (%s), want %q", err.Field, err.Message, tc.wantField)
+			}
+		})
+	}
+}
+
+func TestHandleMessageRejectsInvalidPayload(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	h := &WebSocketHandler{hub: hub}
+	client := &ws.Client{ID: "alice", UserID: uuid.New(), Hub: hub, Send: make(chan []byte, 8)}
+
+	h.handleMessage(client, []byte(`{"type":"join_retro","payload":{}}`))
+
+	select {
+	case data := <-client.Send:
+		var msg struct {
+			Type    string `json:"type"`
+			Payload struct {
+				Code        string `json:"code"`
+				Field       string `json:"field"`
+				MessageType string `json:"messageType"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		if msg.Type != "error" || msg.Payload.Code != "invalid_payload" || msg.Payload.Field != "retroId" || msg.Payload.MessageType != "join_retro" {
+			t.Fatalf("got %s, want invalid_payload on retroId", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no error received")
+	}
+}
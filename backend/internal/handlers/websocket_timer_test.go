@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestTimerEndedAdvancesOnlyOnPhaseTimers(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{AutoAdvanceOnTimerEnd: true})
+	started, err := env.Services.Retro.Start(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { _ = env.Services.Timer.StopTimer(context.Background(), retro.ID) })
+	phase := started.CurrentPhase
+
+	// A topic timebox or a manual timer running out leaves the phase alone
+	for _, kind := range []services.TimerKind{services.TimerKindTopic, services.TimerKindManual} {
+		h.handleTimerEnded(retro.ID, phase, kind)
+		got, err := env.Services.Retro.GetByID(ctx, retro.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.CurrentPhase != phase {
+			t.Fatalf("%s timer moved the retro from %s to %s", kind, phase, got.CurrentPhase)
+		}
+	}
+
+	// A phase timer of a phase the retro already left does not advance it either
+	h.handleTimerEnded(retro.ID, models.PhaseRoti, services.TimerKindPhase)
+	if got, _ := env.Services.Retro.GetByID(ctx, retro.ID); got.CurrentPhase != phase {
+		t.Fatalf("stale phase timer moved the retro from %s to %s", phase, got.CurrentPhase)
+	}
+
+	h.handleTimerEnded(retro.ID, phase, services.TimerKindPhase)
+	got, err := env.Services.Retro.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.CurrentPhase == phase {
+		t.Fatalf("phase timer left the retro in %s, want the next phase", phase)
+	}
+}
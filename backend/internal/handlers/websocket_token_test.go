@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenFromRequest(t *testing.T) {
+	cases := []struct {
+		name       string
+		protocol   string
+		authz      string
+		query      string
+		allowQuery bool
+		want       string
+	}{
+		{name: "subprotocol", protocol: "retrotro, bearer.jwt-a", want: "jwt-a"},
+		{name: "subprotocol wins over header", protocol: "retrotro, bearer.jwt-a", authz: "Bearer jwt-b", want: "jwt-a"},
+		{name: "authorization header", authz: "Bearer jwt-b", query: "jwt-c", allowQuery: true, want: "jwt-b"},
+		{name: "query when allowed", query: "jwt-c", allowQuery: true, want: "jwt-c"},
+		{name: "query when disallowed", query: "jwt-c"},
+		{name: "empty bearer protocol", protocol: "retrotro, bearer."},
+		{name: "non-bearer authorization", authz: "Basic abc"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := "/ws"
+			if tc.query != "" {
+				target += "?token=" + tc.query
+			}
+			r := httptest.NewRequest("GET", target, nil)
+			if tc.protocol != "" {
+				r.Header.Set("Sec-WebSocket-Protocol", tc.protocol)
+			}
+			if tc.authz != "" {
+				r.Header.Set("Authorization", tc.authz)
+			}
+			if got := tokenFromRequest(r, tc.allowQuery); got != tc.want {
+				t.Errorf("token = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/jycamier/retrotro/backend/internal/auth"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// closeTokenExpired is the close code sent when a connection's token expired or was revoked
+const closeTokenExpired = 4001
+
+var (
+	errTokenRevoked      = errors.New("token has been revoked")
+	errTokenUserMismatch = errors.New("token belongs to another user")
+)
+
+// tokenWatcher re-validates the access tokens of open connections, and the guest tokens of
+// guests' connections. The token is only checked at upgrade otherwise, so a connection would
+// outlive its expiry, a logout or the revocation of the guest's share link.
+type tokenWatcher struct {
+	authService  *services.AuthService
+	retroService *services.RetrospectiveService // looks up the share links of guests
+	interval     time.Duration                  // 0 disables the re-checks
+	skew         time.Duration                  // tokens are accepted this long past their expiry, for clock drift between pods
+
+	mu     sync.Mutex
+	tokens map[*ws.Client]connToken
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// connToken is the access or guest token a connection authenticated with
+type connToken struct {
+	raw       string
+	id        string
+	expiresAt time.Time
+	guest     bool
+}
+
+func newTokenWatcher(authService *services.AuthService, retroService *services.RetrospectiveService, interval, skew time.Duration) *tokenWatcher {
+	return &tokenWatcher{
+		authService:  authService,
+		retroService: retroService,
+		interval:     interval,
+		skew:         skew,
+		tokens:       make(map[*ws.Client]connToken),
+		stop:         make(chan struct{}),
+	}
+}
+
+// track remembers the token the client authenticated with
+func (t *tokenWatcher) track(client *ws.Client, token string, claims *auth.JWTClaims) {
+	ct := connToken{raw: token, id: claims.ID}
+	if claims.ExpiresAt != nil {
+		ct.expiresAt = claims.ExpiresAt.Time
+	}
+	t.mu.Lock()
+	t.tokens[client] = ct
+	t.mu.Unlock()
+}
+
+// trackGuest remembers the guest token the guest authenticated with
+func (t *tokenWatcher) trackGuest(client *ws.Client, token string, claims *auth.GuestClaims) {
+	ct := connToken{raw: token, guest: true}
+	if claims.ExpiresAt != nil {
+		ct.expiresAt = claims.ExpiresAt.Time
+	}
+	t.mu.Lock()
+	t.tokens[client] = ct
+	t.mu.Unlock()
+}
+
+// refresh validates a new token for the client and tracks it in place of the current one.
+// The token must belong to the client's user and must not be revoked.
+func (t *tokenWatcher) refresh(ctx context.Context, client *ws.Client, token string) (*auth.JWTClaims, error) {
+	claims, err := t.authService.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+	if userID != client.UserID {
+		return nil, errTokenUserMismatch
+	}
+	revoked, err := t.authService.RevokedTokens(ctx, []string{claims.ID})
+	if err != nil {
+		return nil, err
+	}
+	if revoked[claims.ID] {
+		return nil, errTokenRevoked
+	}
+
+	t.track(client, token, claims)
+	return claims, nil
+}
+
+// forget drops the client's token once it disconnected
+func (t *tokenWatcher) forget(client *ws.Client) {
+	t.mu.Lock()
+	delete(t.tokens, client)
+	t.mu.Unlock()
+}
+
+// Start re-checks the tokens every interval in the background until Stop
+func (t *tokenWatcher) Start() {
+	if t.interval <= 0 {
+		return
+	}
+	t.done.Add(1)
+	go func() {
+		defer t.done.Done()
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				for _, client := range t.sweep(context.Background(), time.Now()) {
+					t.forget(client)
+					closeWithTokenExpired(client)
+				}
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the re-checks and waits for the current one to finish
+func (t *tokenWatcher) Stop() {
+	close(t.stop)
+	t.done.Wait()
+}
+
+// sweep returns the clients whose token is no longer valid at now: expired beyond the
+// clock skew, rejected by ValidateToken, or revoked. Guests' tokens are no longer valid
+// once their share link was revoked.
+func (t *tokenWatcher) sweep(ctx context.Context, now time.Time) []*ws.Client {
+	t.mu.Lock()
+	tokens := make(map[*ws.Client]connToken, len(t.tokens))
+	for client, ct := range t.tokens {
+		tokens[client] = ct
+	}
+	t.mu.Unlock()
+
+	var invalid []*ws.Client
+	byID := make(map[string][]*ws.Client)
+	guests := make(map[uuid.UUID][]*ws.Client)
+	for client, ct := range tokens {
+		if !ct.expiresAt.IsZero() && now.After(ct.expiresAt.Add(t.skew)) {
+			invalid = append(invalid, client)
+			continue
+		}
+		if ct.guest {
+			if _, err := t.authService.ValidateGuestToken(ct.raw); err != nil && !errors.Is(err, auth.ErrExpiredToken) {
+				invalid = append(invalid, client)
+				continue
+			}
+			guests[client.GuestRetroID] = append(guests[client.GuestRetroID], client)
+			continue
+		}
+		// An expired token is still within the skew here
+		if _, err := t.authService.ValidateToken(ct.raw); err != nil && !errors.Is(err, auth.ErrExpiredToken) {
+			invalid = append(invalid, client)
+			continue
+		}
+		if ct.id != "" {
+			byID[ct.id] = append(byID[ct.id], client)
+		}
+	}
+
+	invalid = append(invalid, t.revokedGuests(ctx, guests, now)...)
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	revoked, err := t.authService.RevokedTokens(ctx, ids)
+	if err != nil {
+		// Keep the connections rather than dropping everyone on a database hiccup
+		slog.Error("tokenWatcher: failed to check revoked tokens", "error", err)
+		return invalid
+	}
+	for id := range revoked {
+		invalid = append(invalid, byID[id]...)
+	}
+	return invalid
+}
+
+// revokedGuests returns the guests whose retro is gone or whose share link was revoked
+func (t *tokenWatcher) revokedGuests(ctx context.Context, guests map[uuid.UUID][]*ws.Client, now time.Time) []*ws.Client {
+	var invalid []*ws.Client
+	for retroID, clients := range guests {
+		retro, err := t.retroService.GetByID(ctx, retroID)
+		if errors.Is(err, services.ErrRetroNotFound) {
+			invalid = append(invalid, clients...)
+			continue
+		}
+		if err != nil {
+			slog.Error("tokenWatcher: failed to check guest share link", "retroId", retroID, "error", err)
+			continue
+		}
+		for _, client := range clients {
+			// The share link expires with the token, within the same skew
+			if services.ValidateShare(retro, client.GuestShareID, now.Add(-t.skew)) != nil {
+				invalid = append(invalid, client)
+			}
+		}
+	}
+	return invalid
+}
+
+// handleTokenRefresh swaps the connection's access token for a fresh one, so a retro can
+// outlive the token the connection was opened with without reconnecting
+func (h *WebSocketHandler) handleTokenRefresh(client *ws.Client, payload json.RawMessage) {
+	var data struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil || data.Token == "" {
+		h.sendTokenRefreshError(client, "invalid_payload", "A new access token is required")
+		return
+	}
+
+	claims, err := h.tokens.refresh(context.Background(), client, data.Token)
+	switch {
+	case errors.Is(err, errTokenUserMismatch):
+		h.sendTokenRefreshError(client, "token_user_mismatch", "The token belongs to another user")
+		return
+	case errors.Is(err, auth.ErrExpiredToken):
+		h.sendTokenRefreshError(client, "token_expired", "The token has expired")
+		return
+	case err != nil:
+		slog.Info("handleTokenRefresh: token rejected", "userId", client.UserID, "error", err)
+		h.sendTokenRefreshError(client, "invalid_token", "The token is not valid")
+		return
+	}
+
+	payloadOut := map[string]interface{}{}
+	if claims.ExpiresAt != nil {
+		payloadOut["expiresAt"] = claims.ExpiresAt.Time
+	}
+	h.hub.SendToClient(client, ws.Message{Type: "token_refreshed", Payload: payloadOut})
+}
+
+// sendTokenRefreshError tells the client its new token was refused; the connection keeps the current one
+func (h *WebSocketHandler) sendTokenRefreshError(client *ws.Client, code, message string) {
+	h.sendWSError(client, WSError{Code: code, Message: message, MessageType: "token_refresh"})
+}
+
+// closeWithTokenExpired closes the connection with a token_expired close frame. The read
+// pump then fails and runs the usual disconnect handling.
+func closeWithTokenExpired(client *ws.Client) {
+	slog.Info("websocket: closing connection with an invalid token", "userId", client.UserID, "clientId", client.ID)
+	if client.Conn == nil {
+		return
+	}
+	closeMsg := websocket.FormatCloseMessage(closeTokenExpired, "token_expired")
+	_ = client.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+	_ = client.Conn.Close()
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/auth"
+	"github.com/jycamier/retrotro/backend/internal/config"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// fakeRevocations is an in-memory TokenRevocationStore
+type fakeRevocations map[string]bool
+
+func (f fakeRevocations) Revoke(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	f[tokenID] = true
+	return nil
+}
+
+func (f fakeRevocations) FindRevoked(ctx context.Context, tokenIDs []string) (map[string]bool, error) {
+	revoked := make(map[string]bool)
+	for _, id := range tokenIDs {
+		if f[id] {
+			revoked[id] = true
+		}
+	}
+	return revoked, nil
+}
+
+const testJWTSecret = "test-secret"
+
+func newTestAuthService(revocations fakeRevocations) *services.AuthService {
+	return services.NewAuthService(nil, nil, nil, config.JWTConfig{Secret: testJWTSecret, AccessTokenTTL: 15, RefreshTokenTTL: 1, GuestTokenTTL: 60}, nil, 0, nil, revocations)
+}
+
+// connectWithToken tracks a client authenticated with a fresh token signed with secret
+func connectWithToken(t *testing.T, watcher *tokenWatcher, secret string) (*ws.Client, *auth.JWTClaims, string) {
+	t.Helper()
+	userID := uuid.New()
+	pair, err := auth.NewJWTManager(secret, 15, 1).GenerateTokenPair(userID, "user@example.com", "User", false)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	claims, err := auth.NewJWTManager(secret, 15, 1).ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("validate token: %v", err)
+	}
+	client := &ws.Client{ID: uuid.NewString(), UserID: userID}
+	watcher.track(client, pair.AccessToken, claims)
+	return client, claims, pair.AccessToken
+}
+
+func TestTokenWatcherHonorsClockSkew(t *testing.T) {
+	watcher := newTokenWatcher(newTestAuthService(fakeRevocations{}), nil, time.Minute, 30*time.Second)
+	client, claims, _ := connectWithToken(t, watcher, testJWTSecret)
+	expiry := claims.ExpiresAt.Time
+
+	if got := watcher.sweep(context.Background(), time.Now()); len(got) != 0 {
+		t.Fatalf("valid token: got %d clients to close, want none", len(got))
+	}
+	if got := watcher.sweep(context.Background(), expiry.Add(20*time.Second)); len(got) != 0 {
+		t.Fatalf("token expired within the skew: got %d clients to close, want none", len(got))
+	}
+	if got := watcher.sweep(context.Background(), expiry.Add(31*time.Second)); len(got) != 1 || got[0] != client {
+		t.Fatalf("token expired beyond the skew: got %v, want the client", got)
+	}
+
+	// Disconnected clients are not checked any more
+	watcher.forget(client)
+	if got := watcher.sweep(context.Background(), expiry.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("after forget: got %d clients to close, want none", len(got))
+	}
+}
+
+func TestTokenWatcherClosesRevokedAndInvalidTokens(t *testing.T) {
+	revocations := fakeRevocations{}
+	authService := newTestAuthService(revocations)
+	watcher := newTokenWatcher(authService, nil, time.Minute, 30*time.Second)
+
+	loggedOut, _, token := connectWithToken(t, watcher, testJWTSecret)
+	staying, _, _ := connectWithToken(t, watcher, testJWTSecret)
+	forged, _, _ := connectWithToken(t, watcher, "another-secret")
+
+	if err := authService.RevokeToken(context.Background(), token); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	closed := make(map[*ws.Client]bool)
+	for _, client := range watcher.sweep(context.Background(), time.Now()) {
+		closed[client] = true
+	}
+	if !closed[loggedOut] || !closed[forged] || closed[staying] || len(closed) != 2 {
+		t.Fatalf("closed %v, want the revoked and the forged token's clients only", closed)
+	}
+}
+
+func TestTokenRefreshKeepsConnectionAlive(t *testing.T) {
+	revocations := fakeRevocations{}
+	authService := newTestAuthService(revocations)
+	watcher := newTokenWatcher(authService, nil, time.Minute, 0)
+	client, oldClaims, _ := connectWithToken(t, watcher, testJWTSecret)
+
+	jwt := auth.NewJWTManager(testJWTSecret, 30, 1)
+	fresh, err := jwt.GenerateTokenPair(client.UserID, "user@example.com", "User", false)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	claims, err := watcher.refresh(context.Background(), client, fresh.AccessToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// The connection now lives until the new token expires
+	afterOld := oldClaims.ExpiresAt.Time.Add(time.Minute)
+	if got := watcher.sweep(context.Background(), afterOld); len(got) != 0 {
+		t.Fatalf("after the old token expired: got %d clients to close, want none", len(got))
+	}
+	if got := watcher.sweep(context.Background(), claims.ExpiresAt.Time.Add(time.Second)); len(got) != 1 {
+		t.Fatalf("after the new token expired: got %d clients to close, want the client", len(got))
+	}
+}
+
+func TestTokenRefreshRejectsAnotherUserOrRevokedToken(t *testing.T) {
+	revocations := fakeRevocations{}
+	authService := newTestAuthService(revocations)
+	watcher := newTokenWatcher(authService, nil, time.Minute, 0)
+	client, oldClaims, _ := connectWithToken(t, watcher, testJWTSecret)
+	jwt := auth.NewJWTManager(testJWTSecret, 15, 1)
+
+	mallory, err := jwt.GenerateTokenPair(uuid.New(), "mallory@example.com", "Mallory", true)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if _, err := watcher.refresh(context.Background(), client, mallory.AccessToken); !errors.Is(err, errTokenUserMismatch) {
+		t.Fatalf("another user's token: got %v, want errTokenUserMismatch", err)
+	}
+
+	revoked, err := jwt.GenerateTokenPair(client.UserID, "user@example.com", "User", false)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if err := authService.RevokeToken(context.Background(), revoked.AccessToken); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := watcher.refresh(context.Background(), client, revoked.AccessToken); !errors.Is(err, errTokenRevoked) {
+		t.Fatalf("revoked token: got %v, want errTokenRevoked", err)
+	}
+
+	if _, err := watcher.refresh(context.Background(), client, "not-a-jwt"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("garbage token: got %v, want ErrInvalidToken", err)
+	}
+
+	// A refused refresh leaves the current token in place
+	if got := watcher.sweep(context.Background(), oldClaims.ExpiresAt.Time.Add(time.Second)); len(got) != 1 {
+		t.Fatalf("got %d clients to close once the original token expired, want the client", len(got))
+	}
+}
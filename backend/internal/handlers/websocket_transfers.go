@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// transferSweepInterval is how often expired facilitator transfers are removed
+const transferSweepInterval = 15 * time.Second
+
+// FacilitatorTransferRepository interface for the pending facilitator transfers. They are stored
+// rather than kept in memory, so the target can accept on another pod than the facilitator's.
+type FacilitatorTransferRepository interface {
+	Put(ctx context.Context, transfer *models.FacilitatorTransfer) error
+	Take(ctx context.Context, retroID, toUserID uuid.UUID) (*models.FacilitatorTransfer, error)
+	Cancel(ctx context.Context, retroID, userID uuid.UUID) (*models.FacilitatorTransfer, error)
+	Delete(ctx context.Context, retroID uuid.UUID) error
+	DeleteExpired(ctx context.Context) ([]*models.FacilitatorTransfer, error)
+}
+
+// transferSweeper removes the facilitator transfers that expired without an answer. Each expired
+// transfer is removed by a single pod, which reports it through onExpired.
+type transferSweeper struct {
+	repo      FacilitatorTransferRepository
+	interval  time.Duration
+	onExpired func(transfer *models.FacilitatorTransfer)
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func newTransferSweeper(repo FacilitatorTransferRepository, interval time.Duration, onExpired func(transfer *models.FacilitatorTransfer)) *transferSweeper {
+	return &transferSweeper{
+		repo:      repo,
+		interval:  interval,
+		onExpired: onExpired,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start sweeps expired transfers in the background until Stop
+func (s *transferSweeper) Start() {
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.sweep(context.Background())
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sweeps and waits for the current one to finish
+func (s *transferSweeper) Stop() {
+	close(s.stop)
+	s.done.Wait()
+}
+
+func (s *transferSweeper) sweep(ctx context.Context) {
+	expired, err := s.repo.DeleteExpired(ctx)
+	if err != nil {
+		slog.Error("failed to sweep expired facilitator transfers", "error", err)
+		return
+	}
+	for _, transfer := range expired {
+		s.onExpired(transfer)
+	}
+}
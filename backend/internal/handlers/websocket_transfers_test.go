@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func TestFacilitatorTransferAcceptedOnAnotherPod(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	first := newJoinHandler(env)
+	second := newJoinHandler(env)
+	pod := env.Cluster.NewPod(t)
+	second.hub, second.bridge = pod.Hub, pod.Bus
+
+	admin := env.CreateUser(t, "Admin")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, admin, bob)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	if _, err := env.Services.Retro.Start(ctx, retro.ID); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseBrainstorm); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+
+	facilitator, _ := joinRetro(t, first, admin, retro)
+	target, _ := joinRetro(t, second, bob, retro)
+	deadline := time.Now().Add(2 * time.Second)
+	for !first.bridge.IsUserInRoom(retro.ID.String(), bob.ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("the other pod's participant never showed up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	first.handleFacilitatorTransfer(facilitator, json.RawMessage(`{"userId":"`+bob.ID.String()+`"}`))
+	second.handleFacilitatorTransferAccept(target)
+
+	got, err := env.Services.Retro.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.FacilitatorID != bob.ID {
+		t.Fatalf("facilitator is %s, want %s after accepting on the other pod", got.FacilitatorID, bob.ID)
+	}
+	if _, err := env.Repos.Transfers.Take(ctx, retro.ID, bob.ID); err == nil {
+		t.Fatal("the accepted transfer is still pending")
+	}
+}
+
+func TestTransferSweeperRemovesExpiredTransfers(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	admin := env.CreateUser(t, "Admin")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, admin, bob)
+	expired := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	pending := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	for retro, expiresAt := range map[*models.Retrospective]time.Time{
+		expired: time.Now().Add(-time.Second),
+		pending: time.Now().Add(time.Minute),
+	} {
+		if err := env.Repos.Transfers.Put(ctx, &models.FacilitatorTransfer{RetroID: retro.ID, FromUserID: admin.ID, ToUserID: bob.ID, ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("put transfer: %v", err)
+		}
+	}
+
+	var swept []*models.FacilitatorTransfer
+	sweeper := newTransferSweeper(env.Repos.Transfers, time.Hour, func(transfer *models.FacilitatorTransfer) {
+		swept = append(swept, transfer)
+	})
+	sweeper.sweep(ctx)
+
+	if len(swept) != 1 || swept[0].RetroID != expired.ID {
+		t.Fatalf("swept %v, want only the expired transfer", swept)
+	}
+	if _, err := env.Repos.Transfers.Take(ctx, pending.ID, bob.ID); err != nil {
+		t.Fatalf("the pending transfer was swept: %v", err)
+	}
+}
+
+func TestSpectatorsCannotBecomeFacilitator(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	h := newJoinHandler(env)
+
+	admin := env.CreateUser(t, "Admin")
+	bob := env.CreateUser(t, "Bob")
+	carol := env.CreateUser(t, "Carol")
+	team := env.CreateTeam(t, admin, bob, carol)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	if _, err := env.Services.Retro.Start(ctx, retro.ID); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	facilitator, _ := joinRetro(t, h, admin, retro)
+	spectator := &ws.Client{ID: uuid.NewString(), UserID: bob.ID, UserName: bob.DisplayName, Hub: h.hub, Send: make(chan []byte, 16), Spectator: true}
+	joinWithClient(t, h, spectator, retro)
+
+	h.handleFacilitatorTransfer(facilitator, json.RawMessage(`{"userId":"`+bob.ID.String()+`"}`))
+	deadline := time.After(2 * time.Second)
+	for refused := false; !refused; {
+		select {
+		case data := <-facilitator.Send:
+			var msg struct {
+				Type    string  `json:"type"`
+				Payload WSError `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("decode message: %v", err)
+			}
+			if msg.Type == "error" {
+				if msg.Payload.Code != "spectator_not_allowed" {
+					t.Fatalf("transfer to a spectator got %+v, want spectator_not_allowed", msg.Payload)
+				}
+				refused = true
+			}
+		case <-deadline:
+			t.Fatal("transfer to a spectator was not refused")
+		}
+	}
+
+	// The facilitator left: the spectator is never promoted, the other participant is
+	h.reassignFacilitator(ctx, retro, admin.ID)
+	got, err := env.Services.Retro.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.FacilitatorID != admin.ID {
+		t.Fatalf("facilitator is %s, want no spectator promoted", got.FacilitatorID)
+	}
+
+	joinRetro(t, h, carol, retro)
+	h.reassignFacilitator(ctx, got, admin.ID)
+	got, err = env.Services.Retro.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.FacilitatorID != carol.ID {
+		t.Fatalf("facilitator is %s, want %s promoted", got.FacilitatorID, carol.ID)
+	}
+}
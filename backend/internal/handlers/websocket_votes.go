@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// voteBatcher coalesces the vote changes of a room over a window, so that a busy vote
+// phase sends one votes_updated per window instead of one vote_updated per vote.
+type voteBatcher struct {
+	window time.Duration
+	flush  func(roomID string, batch *voteBatch)
+
+	mu      sync.Mutex
+	pending map[string]*voteBatch // roomID -> changes not broadcast yet
+}
+
+// voteBatch is the set of items and voters whose counts changed in a room during a window
+type voteBatch struct {
+	retroID uuid.UUID
+	items   map[uuid.UUID]struct{}
+	users   map[uuid.UUID]struct{}
+}
+
+func newVoteBatcher(window time.Duration, flush func(roomID string, batch *voteBatch)) *voteBatcher {
+	return &voteBatcher{window: window, flush: flush, pending: make(map[string]*voteBatch)}
+}
+
+// add records a vote change; the first change of a window schedules the flush
+func (b *voteBatcher) add(roomID string, retroID, itemID, userID uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch := b.pending[roomID]
+	if batch == nil {
+		batch = &voteBatch{retroID: retroID, items: make(map[uuid.UUID]struct{}), users: make(map[uuid.UUID]struct{})}
+		b.pending[roomID] = batch
+		time.AfterFunc(b.window, func() {
+			b.mu.Lock()
+			delete(b.pending, roomID)
+			b.mu.Unlock()
+			b.flush(roomID, batch)
+		})
+	}
+	batch.items[itemID] = struct{}{}
+	batch.users[userID] = struct{}{}
+}
+
+func (batch *voteBatch) itemIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(batch.items))
+	for id := range batch.items {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// broadcastVoteUpdated tells the room that a vote was added or removed.
+// In anonymous retros the room does not learn who voted; the voter gets their counts alone.
+// In retros with batched vote updates the voter is answered at once and the room gets the
+// new totals with the next votes_updated.
+func (h *WebSocketHandler) broadcastVoteUpdated(client *ws.Client, retroID, itemID uuid.UUID, action string) {
+	ctx := context.Background()
+	retro, err := h.retroService.GetByID(ctx, retroID)
+	if err != nil {
+		log.Printf("broadcastVoteUpdated: failed to get retro: %v", err)
+		return
+	}
+
+	counts := voteCounts{}
+	counts.item, _ = h.retroService.GetItemVoteCount(ctx, itemID)
+	counts.user, _ = h.retroService.GetUserVoteCount(ctx, retroID, client.UserID)
+	counts.userOnItem, _ = h.retroService.GetUserVoteCountOnItem(ctx, retroID, itemID, client.UserID)
+
+	if retro.BatchVoteUpdates && h.votes != nil {
+		// The voter's budget must not lag behind their clicks
+		_, voter := voteUpdatedPayloads(true, itemID, action, client.UserID, counts)
+		h.hub.SendToClient(client, ws.Message{Type: "vote_updated", Payload: voter})
+		h.votes.add(client.RoomID, retroID, itemID, client.UserID)
+		return
+	}
+
+	room, voter := voteUpdatedPayloads(retro.AnonymousVoting, itemID, action, client.UserID, counts)
+	if voter == nil {
+		h.bridge.BroadcastToRoom(client.RoomID, ws.Message{Type: "vote_updated", Payload: room})
+		return
+	}
+	h.bridge.BroadcastToRoomExcept(client.RoomID, ws.Message{Type: "vote_updated", Payload: room}, client)
+	h.hub.SendToClient(client, ws.Message{Type: "vote_updated", Payload: voter})
+}
+
+// flushVotes broadcasts the current totals of the items in the batch, and the vote counts
+// of the voters unless votes are anonymous
+func (h *WebSocketHandler) flushVotes(roomID string, batch *voteBatch) {
+	ctx := context.Background()
+	retro, err := h.retroService.GetByID(ctx, batch.retroID)
+	if err != nil {
+		log.Printf("flushVotes: failed to get retro: %v", err)
+		return
+	}
+
+	totals, err := h.retroService.GetItemVoteCounts(ctx, batch.itemIDs())
+	if err != nil {
+		log.Printf("flushVotes: failed to count votes: %v", err)
+		return
+	}
+	payload := map[string]interface{}{"votes": totals}
+
+	if !retro.AnonymousVoting {
+		userVoteCounts := make(map[uuid.UUID]int, len(batch.users))
+		for userID := range batch.users {
+			userVoteCounts[userID], _ = h.retroService.GetUserVoteCount(ctx, batch.retroID, userID)
+		}
+		payload["userVoteCounts"] = userVoteCounts
+	}
+
+	h.bridge.BroadcastToRoom(roomID, ws.Message{Type: "votes_updated", Payload: payload})
+}
+
+// voteCounts are the vote totals after a vote changed
+type voteCounts struct {
+	item       int // all votes on the item
+	user       int // the voter's votes in the retro
+	userOnItem int // the voter's votes on the item
+}
+
+// voteUpdatedPayloads builds the vote_updated payload for the room and, when votes are anonymous,
+// the separate payload for the voter. voter is nil when the room payload names the voter.
+func voteUpdatedPayloads(anonymous bool, itemID uuid.UUID, action string, userID uuid.UUID, counts voteCounts) (room, voter map[string]interface{}) {
+	room = map[string]interface{}{
+		"itemId":    itemID,
+		"action":    action,
+		"voteCount": counts.item,
+	}
+	personal := map[string]interface{}{
+		"itemId":            itemID,
+		"action":            action,
+		"voteCount":         counts.item,
+		"userId":            userID,
+		"userVoteCount":     counts.user,
+		"userItemVoteCount": counts.userOnItem,
+	}
+	if anonymous {
+		return room, personal
+	}
+	return personal, nil
+}
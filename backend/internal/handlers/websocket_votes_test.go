@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAnonymousVoteUpdatedHidesVoter(t *testing.T) {
+	itemID, userID := uuid.New(), uuid.New()
+	counts := voteCounts{item: 4, user: 2, userOnItem: 1}
+
+	for _, action := range []string{"add", "remove"} {
+		room, voter := voteUpdatedPayloads(true, itemID, action, userID, counts)
+
+		data, err := json.Marshal(room)
+		if err != nil {
+			t.Fatalf("%s: encode room payload: %v", action, err)
+		}
+		for _, key := range []string{"userId", "userVoteCount", "userItemVoteCount"} {
+			if _, ok := room[key]; ok {
+				t.Errorf("%s: room payload has %s: %s", action, key, data)
+			}
+		}
+		if strings.Contains(string(data), userID.String()) {
+			t.Errorf("%s: room payload leaks the voter: %s", action, data)
+		}
+		if room["voteCount"] != 4 || room["action"] != action {
+			t.Errorf("%s: room payload %s, want the action and the item total", action, data)
+		}
+
+		if voter == nil {
+			t.Fatalf("%s: voter payload is missing", action)
+		}
+		if voter["userId"] != userID || voter["userVoteCount"] != 2 || voter["userItemVoteCount"] != 1 {
+			t.Errorf("%s: voter payload %v, want the voter's own counts", action, voter)
+		}
+	}
+}
+
+func TestVoteUpdatedNamesVoterWhenNotAnonymous(t *testing.T) {
+	itemID, userID := uuid.New(), uuid.New()
+
+	room, voter := voteUpdatedPayloads(false, itemID, "add", userID, voteCounts{item: 1, user: 1, userOnItem: 1})
+	if voter != nil {
+		t.Fatalf("got a separate voter payload %v, want the room payload only", voter)
+	}
+	if room["userId"] != userID || room["userVoteCount"] != 1 || room["voteCount"] != 1 {
+		t.Fatalf("room payload %v, want the voter and the counts", room)
+	}
+}
+
+// recordedFlushes collects the batches a voteBatcher flushes
+type recordedFlushes struct {
+	mu      sync.Mutex
+	batches []*voteBatch
+	flushed chan struct{}
+}
+
+func newRecordedFlushes() *recordedFlushes {
+	return &recordedFlushes{flushed: make(chan struct{}, 64)}
+}
+
+func (r *recordedFlushes) flush(roomID string, batch *voteBatch) {
+	r.mu.Lock()
+	r.batches = append(r.batches, batch)
+	r.mu.Unlock()
+	r.flushed <- struct{}{}
+}
+
+func (r *recordedFlushes) wait(t testing.TB) {
+	t.Helper()
+	select {
+	case <-r.flushed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("votes were not flushed")
+	}
+}
+
+func TestVoteBatcherCoalescesWindow(t *testing.T) {
+	flushes := newRecordedFlushes()
+	batcher := newVoteBatcher(50*time.Millisecond, flushes.flush)
+
+	retroID, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	start, stop := uuid.New(), uuid.New()
+	batcher.add("room", retroID, start, alice)
+	batcher.add("room", retroID, start, bob)
+	batcher.add("room", retroID, stop, alice)
+	flushes.wait(t)
+
+	select {
+	case <-flushes.flushed:
+		t.Fatal("one window was flushed twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+	batch := flushes.batches[0]
+	if batch.retroID != retroID || len(batch.items) != 2 || len(batch.users) != 2 {
+		t.Fatalf("got batch %+v, want both items and both voters of the retro", batch)
+	}
+
+	// A vote after the flush opens a new window
+	batcher.add("room", retroID, stop, bob)
+	flushes.wait(t)
+	if got := flushes.batches[1]; len(got.items) != 1 || len(got.users) != 1 {
+		t.Fatalf("got second batch %+v, want only the late vote", got)
+	}
+}
+
+// BenchmarkVoteBroadcasts measures how many room broadcasts a burst of votes produces
+// once batched, against one vote_updated per vote without batching.
+func BenchmarkVoteBroadcasts(b *testing.B) {
+	const votes, items, users = 200, 10, 20
+	itemIDs := make([]uuid.UUID, items)
+	for i := range itemIDs {
+		itemIDs[i] = uuid.New()
+	}
+	userIDs := make([]uuid.UUID, users)
+	for i := range userIDs {
+		userIDs[i] = uuid.New()
+	}
+
+	flushes := newRecordedFlushes()
+	batcher := newVoteBatcher(time.Millisecond, flushes.flush)
+	retroID := uuid.New()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for v := 0; v < votes; v++ {
+			batcher.add("room", retroID, itemIDs[v%items], userIDs[v%users])
+		}
+		flushes.wait(b)
+	}
+	b.StopTimer()
+
+	// Let a window still running finish before counting
+	time.Sleep(10 * time.Millisecond)
+	flushes.mu.Lock()
+	broadcasts := len(flushes.batches)
+	flushes.mu.Unlock()
+	b.ReportMetric(float64(votes), "unbatched-broadcasts/op")
+	b.ReportMetric(float64(broadcasts)/float64(b.N), "broadcasts/op")
+}
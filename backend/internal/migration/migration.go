@@ -31,6 +31,11 @@ func RunMigrations(lc fx.Lifecycle, cfg *config.Config) error {
 	return nil
 }
 
+// Run applies all pending migrations to the given database
+func Run(databaseURL string) error {
+	return runMigrations(databaseURL)
+}
+
 func runMigrations(databaseURL string) error {
 	slog.Info("running database migrations")
 
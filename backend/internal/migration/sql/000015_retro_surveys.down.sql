@@ -0,0 +1,4 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS survey_revealed;
+
+DROP TABLE IF EXISTS retro_survey_answers;
+DROP TABLE IF EXISTS retro_survey_questions;
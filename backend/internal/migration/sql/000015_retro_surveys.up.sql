@@ -0,0 +1,27 @@
+-- Migration: Add end-of-session survey questions and answers
+
+CREATE TABLE retro_survey_questions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    retro_id UUID NOT NULL REFERENCES retrospectives(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    prompt TEXT NOT NULL,
+    answer_type VARCHAR(10) NOT NULL CHECK (answer_type IN ('scale', 'text')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_retro_survey_questions_retro ON retro_survey_questions(retro_id);
+
+CREATE TABLE retro_survey_answers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    question_id UUID NOT NULL REFERENCES retro_survey_questions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scale_value INTEGER CHECK (scale_value >= 1 AND scale_value <= 5),
+    text_value TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT retro_survey_answers_unique UNIQUE (question_id, user_id)
+);
+
+CREATE INDEX idx_retro_survey_answers_question ON retro_survey_answers(question_id);
+
+-- Flag to reveal survey results, same as roti_revealed
+ALTER TABLE retrospectives ADD COLUMN survey_revealed BOOLEAN NOT NULL DEFAULT false;
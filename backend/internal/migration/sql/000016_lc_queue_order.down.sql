@@ -0,0 +1 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS lc_queue_order;
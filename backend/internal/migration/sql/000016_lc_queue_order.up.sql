@@ -0,0 +1,3 @@
+-- Manual Lean Coffee queue order set by the facilitator.
+-- Topics listed here come first, in this order; the others keep the vote-based order.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS lc_queue_order UUID[];
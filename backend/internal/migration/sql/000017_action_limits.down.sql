@@ -0,0 +1,2 @@
+ALTER TABLE teams DROP COLUMN IF EXISTS max_open_actions;
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS max_actions_per_retro;
@@ -0,0 +1,5 @@
+-- Optional caps on action items. NULL means unlimited.
+-- max_actions_per_retro limits the actions created in a single retrospective,
+-- max_open_actions limits the team's open (not completed) actions across all retros.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS max_actions_per_retro INT;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS max_open_actions INT;
@@ -0,0 +1 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS auto_advance_on_timer_end;
@@ -0,0 +1,2 @@
+-- When enabled, a phase automatically advances to the next one when its timer ends.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS auto_advance_on_timer_end BOOLEAN NOT NULL DEFAULT false;
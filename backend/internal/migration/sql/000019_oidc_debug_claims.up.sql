@@ -0,0 +1,9 @@
+-- Last raw ID token claims per user, only written when DEBUG_OIDC_CLAIMS is enabled.
+CREATE TABLE IF NOT EXISTS oidc_debug_claims (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    claims JSONB NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_debug_claims_expires_at ON oidc_debug_claims(expires_at);
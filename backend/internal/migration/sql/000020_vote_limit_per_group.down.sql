@@ -0,0 +1 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS vote_limit_per_group;
@@ -0,0 +1,2 @@
+-- When enabled, max_votes_per_item caps a user's votes on a whole item group instead of on each item.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS vote_limit_per_group BOOLEAN NOT NULL DEFAULT false;
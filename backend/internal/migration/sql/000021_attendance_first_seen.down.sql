@@ -0,0 +1,4 @@
+ALTER TABLE retro_attendees DROP COLUMN IF EXISTS first_seen_at;
+
+COMMENT ON TABLE retro_attendees IS 'Records attendance for each retrospective - who was present when the retro started';
+COMMENT ON COLUMN retro_attendees.attended IS 'Whether the user was connected when transitioning from waiting to icebreaker phase';
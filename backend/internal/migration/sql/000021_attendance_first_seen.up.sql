@@ -0,0 +1,8 @@
+-- Attendance is now also recorded when someone joins an active retrospective after it started.
+ALTER TABLE retro_attendees ADD COLUMN IF NOT EXISTS first_seen_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE retro_attendees SET first_seen_at = recorded_at WHERE attended AND first_seen_at IS NULL;
+
+COMMENT ON TABLE retro_attendees IS 'Records attendance for each retrospective - who was present when the retro started or joined it later';
+COMMENT ON COLUMN retro_attendees.attended IS 'Whether the user was connected when the retro started or joined it while active';
+COMMENT ON COLUMN retro_attendees.first_seen_at IS 'When the user was first seen connected to the active retro';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS retro_state_snapshots;
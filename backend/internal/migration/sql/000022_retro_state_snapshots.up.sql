@@ -0,0 +1,11 @@
+-- One-time retro_state snapshots served over REST when the state is too large for a WebSocket frame.
+CREATE TABLE IF NOT EXISTS retro_state_snapshots (
+    token_hash TEXT PRIMARY KEY,
+    retrospective_id UUID NOT NULL REFERENCES retrospectives(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_retro_state_snapshots_expires_at ON retro_state_snapshots(expires_at);
@@ -0,0 +1,13 @@
+-- Pending team invitations for people who have not logged in yet.
+-- Emails are stored lowercased; the invite is claimed when a user with that email logs in.
+CREATE TABLE IF NOT EXISTS team_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role user_role NOT NULL DEFAULT 'member',
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT team_invites_unique UNIQUE (team_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_invites_email ON team_invites(email);
@@ -0,0 +1,5 @@
+-- Moods outside the weather scale cannot be represented by the enum
+DELETE FROM icebreaker_moods WHERE mood NOT IN ('sunny', 'partly_cloudy', 'cloudy', 'rainy', 'stormy');
+ALTER TABLE icebreaker_moods ALTER COLUMN mood TYPE mood_weather USING mood::mood_weather;
+
+ALTER TABLE templates DROP COLUMN IF EXISTS mood_scale;
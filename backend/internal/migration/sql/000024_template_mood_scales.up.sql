@@ -0,0 +1,5 @@
+-- Templates can define their own icebreaker mood scale; NULL keeps the weather scale
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS mood_scale JSONB;
+
+-- Moods are validated against the template's scale instead of a fixed enum
+ALTER TABLE icebreaker_moods ALTER COLUMN mood TYPE VARCHAR(50) USING mood::text;
@@ -0,0 +1 @@
+ALTER TABLE roti_votes DROP COLUMN IF EXISTS comment;
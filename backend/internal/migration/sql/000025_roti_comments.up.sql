@@ -0,0 +1,2 @@
+-- Optional free-text feedback explaining a ROTI rating
+ALTER TABLE roti_votes ADD COLUMN IF NOT EXISTS comment TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_retrospectives_scheduled_drafts;
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS reminded_at;
@@ -0,0 +1,5 @@
+-- When the retro.reminder webhook was sent, so each scheduled retro is reminded once
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_retrospectives_scheduled_drafts
+    ON retrospectives(scheduled_at) WHERE status = 'draft' AND scheduled_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_retrospectives_team_not_deleted;
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS deleted_at;
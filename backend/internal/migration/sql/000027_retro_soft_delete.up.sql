@@ -0,0 +1,5 @@
+-- Deleted retros are kept so an accidental delete does not wipe the team's history
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_retrospectives_team_not_deleted
+    ON retrospectives(team_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+-- Content changes of items, so facilitators can see who edited a card and how.
+-- Rows go away with their item.
+CREATE TABLE IF NOT EXISTS item_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    old_content TEXT NOT NULL,
+    new_content TEXT NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_history_item ON item_history(item_id, edited_at);
@@ -0,0 +1 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS batch_vote_updates;
@@ -0,0 +1,2 @@
+-- When enabled, vote changes are coalesced into periodic votes_updated broadcasts instead of one vote_updated per vote.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS batch_vote_updates BOOLEAN NOT NULL DEFAULT false;
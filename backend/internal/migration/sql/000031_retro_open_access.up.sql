@@ -0,0 +1,2 @@
+-- When enabled, any signed-in user with the retro's link may join it, not only members of its team.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS open_access BOOLEAN NOT NULL DEFAULT false;
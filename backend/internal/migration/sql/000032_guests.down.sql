@@ -0,0 +1,4 @@
+ALTER TABLE votes DROP COLUMN IF EXISTS is_guest;
+ALTER TABLE items DROP COLUMN IF EXISTS is_guest;
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS allow_guests;
+ALTER TABLE users DROP COLUMN IF EXISTS is_guest;
@@ -0,0 +1,10 @@
+-- Guests join a retro with a share link and a display name, without an account.
+-- Each guest is backed by a users row so their items and votes keep their foreign keys.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_guest BOOLEAN NOT NULL DEFAULT false;
+
+-- When enabled, the facilitator may share a guest link to the retro.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS allow_guests BOOLEAN NOT NULL DEFAULT false;
+
+-- Items and votes remember whether a guest cast them.
+ALTER TABLE items ADD COLUMN IF NOT EXISTS is_guest BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS is_guest BOOLEAN NOT NULL DEFAULT false;
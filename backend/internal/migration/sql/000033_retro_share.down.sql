@@ -0,0 +1,3 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS share_spectator_only;
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS share_expires_at;
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS share_id;
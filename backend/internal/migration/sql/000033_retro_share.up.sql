@@ -0,0 +1,5 @@
+-- The retro's active guest share link. Guest tokens name the share they were issued for,
+-- so clearing or replacing share_id revokes the link and the guest sessions opened with it.
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS share_id UUID;
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS share_expires_at TIMESTAMPTZ;
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS share_spectator_only BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,10 @@
+-- Follow-up notes on action items, between retros. Rows go away with their action.
+CREATE TABLE IF NOT EXISTS action_comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    action_id UUID NOT NULL REFERENCES action_items(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_action_comments_action ON action_comments(action_id, created_at);
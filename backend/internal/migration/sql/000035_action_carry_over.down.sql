@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_action_items_carried_from;
+ALTER TABLE action_items DROP COLUMN IF EXISTS origin_retro_id;
+ALTER TABLE action_items DROP COLUMN IF EXISTS carried_from_id;
@@ -0,0 +1,6 @@
+-- Unfinished actions carried over into a later retro are copies of the action they continue.
+-- An action is carried over at most once: the copy takes over from it.
+ALTER TABLE action_items ADD COLUMN IF NOT EXISTS carried_from_id UUID REFERENCES action_items(id) ON DELETE SET NULL;
+ALTER TABLE action_items ADD COLUMN IF NOT EXISTS origin_retro_id UUID REFERENCES retrospectives(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_action_items_carried_from ON action_items(carried_from_id) WHERE carried_from_id IS NOT NULL;
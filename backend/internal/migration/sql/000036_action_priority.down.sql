@@ -0,0 +1,2 @@
+ALTER TABLE action_items DROP CONSTRAINT IF EXISTS action_items_priority_check;
+ALTER TABLE action_items ALTER COLUMN priority DROP NOT NULL;
@@ -0,0 +1,6 @@
+-- Action priorities follow the 0-3 scale (low, medium, high, urgent); clamp older free-form values into it.
+UPDATE action_items SET priority = LEAST(GREATEST(COALESCE(priority, 0), 0), 3)
+WHERE priority IS NULL OR priority < 0 OR priority > 3;
+
+ALTER TABLE action_items ALTER COLUMN priority SET NOT NULL;
+ALTER TABLE action_items ADD CONSTRAINT action_items_priority_check CHECK (priority BETWEEN 0 AND 3);
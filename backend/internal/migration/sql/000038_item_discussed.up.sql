@@ -0,0 +1,2 @@
+-- Facilitators tick off the cards they covered in the discuss phase of a retro
+ALTER TABLE items ADD COLUMN IF NOT EXISTS discussed BOOLEAN NOT NULL DEFAULT false;
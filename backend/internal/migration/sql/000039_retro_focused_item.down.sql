@@ -0,0 +1 @@
+ALTER TABLE retrospectives DROP COLUMN IF EXISTS focused_item_id;
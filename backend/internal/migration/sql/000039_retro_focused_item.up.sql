@@ -0,0 +1,2 @@
+-- Card the facilitator highlights on every screen in the discuss phase of a retro
+ALTER TABLE retrospectives ADD COLUMN IF NOT EXISTS focused_item_id UUID REFERENCES items(id) ON DELETE SET NULL;
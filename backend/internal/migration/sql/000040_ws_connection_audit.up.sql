@@ -0,0 +1,15 @@
+-- Authenticated WebSocket connections, for security reviews. Each row lists the retros the
+-- connection joined and is closed with the connection's duration. Tokens are never stored.
+CREATE TABLE IF NOT EXISTS ws_connection_audit (
+    connection_id TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    guest BOOLEAN NOT NULL DEFAULT false,
+    retro_ids UUID[] NOT NULL DEFAULT '{}',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    connected_at TIMESTAMPTZ NOT NULL,
+    disconnected_at TIMESTAMPTZ,
+    duration_seconds INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_ws_connection_audit_retro_ids ON ws_connection_audit USING GIN (retro_ids);
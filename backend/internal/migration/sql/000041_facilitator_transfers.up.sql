@@ -0,0 +1,12 @@
+-- Mid-session facilitator transfers awaiting the target's confirmation, at most one per retro.
+-- They are stored so the target can accept from any pod; expired rows are swept.
+CREATE TABLE IF NOT EXISTS facilitator_transfers (
+    retro_id UUID PRIMARY KEY REFERENCES retrospectives(id) ON DELETE CASCADE,
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_name TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_facilitator_transfers_expires_at ON facilitator_transfers(expires_at);
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityType represents the kind of event in a team activity timeline
+type ActivityType string
+
+const (
+	ActivityRetroStarted    ActivityType = "retro.started"
+	ActivityRetroEnded      ActivityType = "retro.ended"
+	ActivityActionCreated   ActivityType = "action.created"
+	ActivityActionCompleted ActivityType = "action.completed"
+)
+
+// ActivityEvent represents a single entry in a team activity timeline
+type ActivityEvent struct {
+	Type        ActivityType `json:"type"`
+	OccurredAt  time.Time    `json:"occurredAt"`
+	RetroID     uuid.UUID    `json:"retroId"`
+	RetroName   string       `json:"retroName"`
+	ActionID    *uuid.UUID   `json:"actionId,omitempty"`
+	ActionTitle *string      `json:"actionTitle,omitempty"`
+	UserID      *uuid.UUID   `json:"userId,omitempty"`
+}
+
+// ActivityFilter represents filter and pagination options for activity queries
+type ActivityFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
@@ -37,17 +37,41 @@ const (
 	PhasePropose    RetroPhase = "propose"
 )
 
-// MoodWeather represents weather-based mood for icebreaker
-type MoodWeather string
+// IsValid reports whether the phase is one of the known phases
+func (p RetroPhase) IsValid() bool {
+	switch p {
+	case PhaseWaiting, PhaseIcebreaker, PhaseBrainstorm, PhaseGroup, PhaseVote,
+		PhaseDiscuss, PhaseAction, PhaseRoti, PhasePropose:
+		return true
+	}
+	return false
+}
 
+// Weather moods make up the default icebreaker mood scale
 const (
-	MoodSunny        MoodWeather = "sunny"
-	MoodPartlyCloudy MoodWeather = "partly_cloudy"
-	MoodCloudy       MoodWeather = "cloudy"
-	MoodRainy        MoodWeather = "rainy"
-	MoodStormy       MoodWeather = "stormy"
+	MoodSunny        = "sunny"
+	MoodPartlyCloudy = "partly_cloudy"
+	MoodCloudy       = "cloudy"
+	MoodRainy        = "rainy"
+	MoodStormy       = "stormy"
 )
 
+// MoodOption is one value of an icebreaker mood scale
+type MoodOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// DefaultMoodScale is used by templates that do not define their own
+var DefaultMoodScale = []MoodOption{
+	{Value: MoodSunny, Label: "Sunny", Icon: "☀️"},
+	{Value: MoodPartlyCloudy, Label: "Partly cloudy", Icon: "⛅"},
+	{Value: MoodCloudy, Label: "Cloudy", Icon: "☁️"},
+	{Value: MoodRainy, Label: "Rainy", Icon: "🌧️"},
+	{Value: MoodStormy, Label: "Stormy", Icon: "⛈️"},
+}
+
 // RetroStatus represents status of a retrospective
 type RetroStatus string
 
@@ -67,22 +91,66 @@ type User struct {
 	OIDCSubject string     `json:"-" db:"oidc_subject"`
 	OIDCIssuer  string     `json:"-" db:"oidc_issuer"`
 	IsAdmin     bool       `json:"isAdmin" db:"is_admin"`
+	IsGuest     bool       `json:"isGuest,omitempty" db:"is_guest"` // joined a retro with a share link, has no account
 	LastLoginAt *time.Time `json:"lastLoginAt,omitempty" db:"last_login_at"`
 	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
 }
 
+// TeamInvite is a pending invitation for someone who has not logged in yet
+type TeamInvite struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TeamID    uuid.UUID  `json:"teamId" db:"team_id"`
+	Email     string     `json:"email" db:"email"`
+	Role      Role       `json:"role" db:"role"`
+	InvitedBy *uuid.UUID `json:"invitedBy,omitempty" db:"invited_by"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// OIDCDebugClaims is a redacted, time-limited capture of a user's last ID token claims
+type OIDCDebugClaims struct {
+	UserID     uuid.UUID              `json:"userId" db:"user_id"`
+	Claims     map[string]interface{} `json:"claims" db:"claims"`
+	CapturedAt time.Time              `json:"capturedAt" db:"captured_at"`
+	ExpiresAt  time.Time              `json:"expiresAt" db:"expires_at"`
+}
+
+// WSConnectionAudit records who opened a WebSocket connection, when and from where.
+// The duration is set once the connection closed.
+type WSConnectionAudit struct {
+	ConnectionID    string      `json:"connectionId" db:"connection_id"`
+	UserID          uuid.UUID   `json:"userId" db:"user_id"`
+	UserName        string      `json:"userName,omitempty"`
+	Guest           bool        `json:"guest" db:"guest"`
+	RetroIDs        []uuid.UUID `json:"retroIds" db:"retro_ids"` // retros the connection joined
+	IPAddress       string      `json:"ipAddress" db:"ip_address"`
+	UserAgent       string      `json:"userAgent" db:"user_agent"`
+	ConnectedAt     time.Time   `json:"connectedAt" db:"connected_at"`
+	DisconnectedAt  *time.Time  `json:"disconnectedAt,omitempty" db:"disconnected_at"`
+	DurationSeconds *int        `json:"durationSeconds,omitempty" db:"duration_seconds"`
+}
+
+// FacilitatorTransfer is a mid-session facilitator transfer awaiting the target's confirmation
+type FacilitatorTransfer struct {
+	RetroID    uuid.UUID `json:"retroId" db:"retro_id"`
+	FromUserID uuid.UUID `json:"fromUserId" db:"from_user_id"`
+	ToUserID   uuid.UUID `json:"toUserId" db:"to_user_id"`
+	ToUserName string    `json:"toUserName" db:"to_user_name"`
+	ExpiresAt  time.Time `json:"expiresAt" db:"expires_at"`
+}
+
 // Team represents a team/group in the system
 type Team struct {
-	ID            uuid.UUID  `json:"id" db:"id"`
-	Name          string     `json:"name" db:"name"`
-	Slug          string     `json:"slug" db:"slug"`
-	Description   *string    `json:"description,omitempty" db:"description"`
-	OIDCGroupID   *string    `json:"-" db:"oidc_group_id"`
-	IsOIDCManaged bool       `json:"isOidcManaged" db:"is_oidc_managed"`
-	CreatedBy     *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
-	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
-	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Name           string     `json:"name" db:"name"`
+	Slug           string     `json:"slug" db:"slug"`
+	Description    *string    `json:"description,omitempty" db:"description"`
+	OIDCGroupID    *string    `json:"-" db:"oidc_group_id"`
+	IsOIDCManaged  bool       `json:"isOidcManaged" db:"is_oidc_managed"`
+	MaxOpenActions *int       `json:"maxOpenActions,omitempty" db:"max_open_actions"`
+	CreatedBy      *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" db:"updated_at"`
 }
 
 // TeamMember represents membership in a team
@@ -111,6 +179,25 @@ type Template struct {
 	CreatedBy   *uuid.UUID         `json:"createdBy,omitempty" db:"created_by"`
 	CreatedAt   time.Time          `json:"createdAt" db:"created_at"`
 	PhaseTimes  map[RetroPhase]int `json:"phaseTimes,omitempty"`
+	MoodScale   []MoodOption       `json:"moodScale,omitempty"` // empty uses DefaultMoodScale
+}
+
+// Moods returns the template's mood scale
+func (t *Template) Moods() []MoodOption {
+	if len(t.MoodScale) == 0 {
+		return DefaultMoodScale
+	}
+	return t.MoodScale
+}
+
+// MoodScaleContains reports whether a mood is one of the scale's values
+func MoodScaleContains(scale []MoodOption, mood string) bool {
+	for _, option := range scale {
+		if option.Value == mood {
+			return true
+		}
+	}
+	return false
 }
 
 // TemplateColumn represents a column in a template
@@ -134,10 +221,19 @@ type Retrospective struct {
 	CurrentPhase          RetroPhase         `json:"currentPhase" db:"current_phase"`
 	MaxVotesPerUser       int                `json:"maxVotesPerUser" db:"max_votes_per_user"`
 	MaxVotesPerItem       int                `json:"maxVotesPerItem" db:"max_votes_per_item"`
+	MaxActionsPerRetro    *int               `json:"maxActionsPerRetro,omitempty" db:"max_actions_per_retro"`
 	AnonymousVoting       bool               `json:"anonymousVoting" db:"anonymous_voting"`
 	AnonymousItems        bool               `json:"anonymousItems" db:"anonymous_items"`
 	AllowItemEdit         bool               `json:"allowItemEdit" db:"allow_item_edit"`
 	AllowVoteChange       bool               `json:"allowVoteChange" db:"allow_vote_change"`
+	AutoAdvanceOnTimerEnd bool               `json:"autoAdvanceOnTimerEnd" db:"auto_advance_on_timer_end"`
+	VoteLimitPerGroup     bool               `json:"voteLimitPerGroup" db:"vote_limit_per_group"`
+	BatchVoteUpdates      bool               `json:"batchVoteUpdates" db:"batch_vote_updates"`
+	OpenAccess            bool               `json:"openAccess" db:"open_access"`   // any signed-in user may join, not only team members
+	AllowGuests           bool               `json:"allowGuests" db:"allow_guests"` // guests without an account may join with a share link
+	ShareID               *uuid.UUID         `json:"-" db:"share_id"`               // active guest share link, nil when none or revoked
+	ShareExpiresAt        *time.Time         `json:"shareExpiresAt,omitempty" db:"share_expires_at"`
+	ShareSpectatorOnly    bool               `json:"shareSpectatorOnly" db:"share_spectator_only"` // guests of the share link can only watch
 	PhaseTimerOverrides   map[RetroPhase]int `json:"phaseTimerOverrides,omitempty" db:"phase_timer_overrides"`
 	TimerStartedAt        *time.Time         `json:"timerStartedAt,omitempty" db:"timer_started_at"`
 	TimerDurationSeconds  *int               `json:"timerDurationSeconds,omitempty" db:"timer_duration_seconds"`
@@ -147,13 +243,16 @@ type Retrospective struct {
 	StartedAt             *time.Time         `json:"startedAt,omitempty" db:"started_at"`
 	EndedAt               *time.Time         `json:"endedAt,omitempty" db:"ended_at"`
 	RotiRevealed          bool               `json:"rotiRevealed" db:"roti_revealed"`
+	FocusedItemID         *uuid.UUID         `json:"focusedItemId,omitempty" db:"focused_item_id"` // card highlighted for everyone in the discuss phase
 	CreatedAt             time.Time          `json:"createdAt" db:"created_at"`
 	UpdatedAt             time.Time          `json:"updatedAt" db:"updated_at"`
+	DeletedAt             *time.Time         `json:"deletedAt,omitempty" db:"deleted_at"` // soft-deleted, hidden unless requested
 
 	// Lean Coffee specific fields
-	SessionType          SessionType `json:"sessionType" db:"session_type"`
-	LCCurrentTopicID     *uuid.UUID  `json:"lcCurrentTopicId,omitempty" db:"lc_current_topic_id"`
-	LCTopicTimeboxSeconds *int       `json:"lcTopicTimeboxSeconds,omitempty" db:"lc_topic_timebox_seconds"`
+	SessionType           SessionType `json:"sessionType" db:"session_type"`
+	LCCurrentTopicID      *uuid.UUID  `json:"lcCurrentTopicId,omitempty" db:"lc_current_topic_id"`
+	LCTopicTimeboxSeconds *int        `json:"lcTopicTimeboxSeconds,omitempty" db:"lc_topic_timebox_seconds"`
+	LCQueueOrder          []uuid.UUID `json:"lcQueueOrder,omitempty" db:"lc_queue_order"`
 
 	// Joined fields
 	Team        *Team     `json:"team,omitempty"`
@@ -183,13 +282,46 @@ type Item struct {
 	AuthorID  uuid.UUID  `json:"authorId" db:"author_id"`
 	GroupID   *uuid.UUID `json:"groupId,omitempty" db:"group_id"`
 	Position  int        `json:"position" db:"position"`
+	IsGuest   bool       `json:"isGuest" db:"is_guest"`    // written by a guest
+	Discussed bool       `json:"discussed" db:"discussed"` // ticked off by the facilitator in the discuss phase
 	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
 
 	// Computed fields
-	VoteCount int     `json:"voteCount"`
-	Author    *User   `json:"author,omitempty"`
-	Children  []*Item `json:"children,omitempty"`
+	VoteCount      int     `json:"voteCount"`
+	GroupVoteCount int     `json:"groupVoteCount"` // VoteCount plus the votes of all nested children
+	Author         *User   `json:"author,omitempty"`
+	Children       []*Item `json:"children,omitempty"`
+}
+
+// ItemEdit is one recorded change of an item's content
+type ItemEdit struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ItemID     uuid.UUID  `json:"itemId" db:"item_id"`
+	EditorID   *uuid.UUID `json:"editorId,omitempty" db:"editor_id"` // nil once the editor's account is deleted
+	OldContent string     `json:"oldContent" db:"old_content"`
+	NewContent string     `json:"newContent" db:"new_content"`
+	EditedAt   time.Time  `json:"editedAt" db:"edited_at"`
+
+	// Joined fields
+	EditorName string `json:"editorName,omitempty" db:"editor_name"`
+}
+
+// ItemSort defines the ordering of an item search
+type ItemSort string
+
+const (
+	ItemSortPosition ItemSort = ""        // column, then position (board order)
+	ItemSortVotes    ItemSort = "votes"   // most voted first
+	ItemSortCreated  ItemSort = "created" // oldest first
+)
+
+// ItemFilter represents filter options for item searches
+type ItemFilter struct {
+	Query    string // case-insensitive content match
+	ColumnID string
+	MinVotes int
+	Sort     ItemSort
 }
 
 // Vote represents a vote on an item
@@ -197,33 +329,105 @@ type Vote struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	ItemID    uuid.UUID `json:"itemId" db:"item_id"`
 	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	IsGuest   bool      `json:"isGuest" db:"is_guest"` // cast by a guest
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
 // ActionItem represents an action item from a retrospective
 type ActionItem struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	RetroID     uuid.UUID  `json:"retroId" db:"retro_id"`
-	ItemID      *uuid.UUID `json:"itemId,omitempty" db:"item_id"`
-	Title       string     `json:"title" db:"title"`
-	Description *string    `json:"description,omitempty" db:"description"`
-	AssigneeID  *uuid.UUID `json:"assigneeId,omitempty" db:"assignee_id"`
-	DueDate     *time.Time `json:"dueDate,omitempty" db:"due_date"`
-	IsCompleted bool       `json:"isCompleted" db:"is_completed"`
-	Status      string     `json:"status" db:"status"`
-	CompletedAt *time.Time `json:"completedAt,omitempty" db:"completed_at"`
-	Priority    int        `json:"priority" db:"priority"`
-	ExternalID  *string    `json:"externalId,omitempty" db:"external_id"`
-	ExternalURL *string    `json:"externalUrl,omitempty" db:"external_url"`
-	CreatedBy   uuid.UUID  `json:"createdBy" db:"created_by"`
-	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+	ID          uuid.UUID      `json:"id" db:"id"`
+	RetroID     uuid.UUID      `json:"retroId" db:"retro_id"`
+	ItemID      *uuid.UUID     `json:"itemId,omitempty" db:"item_id"`
+	Title       string         `json:"title" db:"title"`
+	Description *string        `json:"description,omitempty" db:"description"`
+	AssigneeID  *uuid.UUID     `json:"assigneeId,omitempty" db:"assignee_id"`
+	DueDate     *time.Time     `json:"dueDate,omitempty" db:"due_date"`
+	IsCompleted bool           `json:"isCompleted" db:"is_completed"`
+	Status      string         `json:"status" db:"status"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty" db:"completed_at"`
+	Priority    ActionPriority `json:"priority" db:"priority"`
+	ExternalID  *string        `json:"externalId,omitempty" db:"external_id"`
+	ExternalURL *string        `json:"externalUrl,omitempty" db:"external_url"`
+	CreatedBy   uuid.UUID      `json:"createdBy" db:"created_by"`
+	CreatedAt   time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updatedAt" db:"updated_at"`
+
+	// Set on actions carried over from a previous retro
+	CarriedFromID *uuid.UUID `json:"carriedFromId,omitempty" db:"carried_from_id"` // the unfinished action this one continues
+	OriginRetroID *uuid.UUID `json:"originRetroId,omitempty" db:"origin_retro_id"` // the retro the action was first raised in
 
 	// Joined fields
 	Assignee    *User  `json:"assignee,omitempty"`
 	Item        *Item  `json:"item,omitempty"`
 	ItemContent string `json:"itemContent,omitempty" db:"item_content"`
 	RetroName   string `json:"retroName,omitempty" db:"retro_name"`
+	TeamName    string `json:"teamName,omitempty" db:"team_name"`
+}
+
+// ActionPriority ranks action items, from low to urgent. Lists show the most urgent first.
+type ActionPriority int
+
+const (
+	PriorityLow    ActionPriority = 0 // default
+	PriorityMedium ActionPriority = 1
+	PriorityHigh   ActionPriority = 2
+	PriorityUrgent ActionPriority = 3
+)
+
+// IsValid reports whether the priority is on the scale
+func (p ActionPriority) IsValid() bool {
+	return p >= PriorityLow && p <= PriorityUrgent
+}
+
+// ActionComment is a follow-up note on an action item
+type ActionComment struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ActionID  uuid.UUID  `json:"actionId" db:"action_id"`
+	AuthorID  *uuid.UUID `json:"authorId,omitempty" db:"author_id"` // nil once the author's account is deleted
+	Content   string     `json:"content" db:"content"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+
+	// Joined fields
+	AuthorName string `json:"authorName,omitempty" db:"author_name"`
+}
+
+// ActionSort is the order action items are listed in
+type ActionSort string
+
+const (
+	ActionSortCreatedAt ActionSort = "created_at" // newest first
+	ActionSortDueDate   ActionSort = "due_date"   // soonest due first, undated last
+)
+
+// ActionListFilter represents filter and sorting options for listing a user's action items
+type ActionListFilter struct {
+	Status string // empty lists every status
+	Sort   ActionSort
+}
+
+// RetroSort is the order a team's retrospectives are listed in, most recent first
+type RetroSort string
+
+const (
+	RetroSortCreatedAt RetroSort = "created_at"
+	RetroSortEndedAt   RetroSort = "ended_at" // retros that have not ended come last
+)
+
+// RetroListFilter represents filter, sorting and pagination options for listing a team's retrospectives
+type RetroListFilter struct {
+	Status          *RetroStatus
+	IncludeArchived bool // list archived retros with the others when no status is given
+	IncludeDeleted  bool
+	Sort            RetroSort
+	Limit           int // 0 lists them all
+	Offset          int
+}
+
+// RetroContentCounts is what permanently deleting a retrospective removes
+type RetroContentCounts struct {
+	Items   int `json:"items"`
+	Votes   int `json:"votes"`
+	Actions int `json:"actions"`
 }
 
 // Integration represents an external integration
@@ -278,12 +482,12 @@ type RetroPhaseHistory struct {
 
 // IcebreakerMood represents a participant's mood in the icebreaker phase
 type IcebreakerMood struct {
-	ID        uuid.UUID   `json:"id" db:"id"`
-	RetroID   uuid.UUID   `json:"retroId" db:"retro_id"`
-	UserID    uuid.UUID   `json:"userId" db:"user_id"`
-	Mood      MoodWeather `json:"mood" db:"mood"`
-	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
-	User      *User       `json:"user,omitempty"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	RetroID   uuid.UUID `json:"retroId" db:"retro_id"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	Mood      string    `json:"mood" db:"mood"` // a value of the template's mood scale
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	User      *User     `json:"user,omitempty"`
 }
 
 // RotiVote represents a ROTI (Return On Time Invested) vote
@@ -292,17 +496,27 @@ type RotiVote struct {
 	RetroID   uuid.UUID `json:"retroId" db:"retro_id"`
 	UserID    uuid.UUID `json:"userId" db:"user_id"`
 	Rating    int       `json:"rating" db:"rating"` // 1-5
+	Comment   *string   `json:"comment,omitempty" db:"comment"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	User      *User     `json:"user,omitempty"`
 }
 
+// RotiComment is a ROTI comment in the revealed results; UserID and User are
+// omitted when the retro uses anonymous voting
+type RotiComment struct {
+	Comment string     `json:"comment"`
+	UserID  *uuid.UUID `json:"userId,omitempty"`
+	User    *User      `json:"user,omitempty"`
+}
+
 // RotiResults represents aggregated ROTI results
 type RotiResults struct {
-	Average      float64     `json:"average"`
-	TotalVotes   int         `json:"totalVotes"`
-	Distribution map[int]int `json:"distribution"` // rating -> count
-	Revealed     bool        `json:"revealed"`
-	Votes        []*RotiVote `json:"votes,omitempty"`
+	Average      float64        `json:"average"`
+	TotalVotes   int            `json:"totalVotes"`
+	Distribution map[int]int    `json:"distribution"` // rating -> count
+	Revealed     bool           `json:"revealed"`
+	Votes        []*RotiVote    `json:"votes,omitempty"`
+	Comments     []*RotiComment `json:"comments,omitempty"`
 }
 
 // StatsFilter represents filter options for statistics queries
@@ -323,11 +537,11 @@ type RotiEvolutionPoint struct {
 
 // MoodEvolutionPoint represents a mood data point in time
 type MoodEvolutionPoint struct {
-	RetroID      uuid.UUID           `json:"retroId"`
-	RetroName    string              `json:"retroName"`
-	Date         time.Time           `json:"date"`
-	Distribution map[MoodWeather]int `json:"distribution"`
-	MoodCount    int                 `json:"moodCount"`
+	RetroID      uuid.UUID      `json:"retroId"`
+	RetroName    string         `json:"retroName"`
+	Date         time.Time      `json:"date"`
+	Distribution map[string]int `json:"distribution"`
+	MoodCount    int            `json:"moodCount"`
 }
 
 // TeamRotiStats represents aggregated ROTI statistics for a team
@@ -342,7 +556,7 @@ type TeamRotiStats struct {
 
 // TeamMoodStats represents aggregated mood statistics for a team
 type TeamMoodStats struct {
-	Distribution      map[MoodWeather]int   `json:"distribution"` // mood -> count
+	Distribution      map[string]int        `json:"distribution"` // mood -> count
 	TotalMoods        int                   `json:"totalMoods"`
 	TotalRetros       int                   `json:"totalRetros"`
 	ParticipationRate float64               `json:"participationRate"`
@@ -364,8 +578,8 @@ type UserRotiStats struct {
 // UserMoodStats represents mood statistics for a specific user
 type UserMoodStats struct {
 	UserID            uuid.UUID             `json:"userId"`
-	Distribution      map[MoodWeather]int   `json:"distribution"` // mood -> count
-	MostCommonMood    MoodWeather           `json:"mostCommonMood"`
+	Distribution      map[string]int        `json:"distribution"` // mood -> count
+	MostCommonMood    string                `json:"mostCommonMood"`
 	TotalMoods        int                   `json:"totalMoods"`
 	RetrosAttended    int                   `json:"retrosAttended"`
 	ParticipationRate float64               `json:"participationRate"`
@@ -378,13 +592,30 @@ type CombinedUserStats struct {
 	MoodStats *UserMoodStats `json:"moodStats"`
 }
 
+// PhaseTimingStats represents planned vs actual duration of a phase across retrospectives
+type PhaseTimingStats struct {
+	Phase             RetroPhase `json:"phase"`
+	Count             int        `json:"count"`
+	AvgPlannedSeconds float64    `json:"avgPlannedSeconds"`
+	AvgActualSeconds  float64    `json:"avgActualSeconds"`
+	OverrunPercent    float64    `json:"overrunPercent"`
+	OverrunCount      int        `json:"overrunCount"`
+}
+
+// TeamPhaseAnalytics represents aggregated phase timing statistics for a team
+type TeamPhaseAnalytics struct {
+	RetroCount int                 `json:"retroCount"`
+	Phases     []*PhaseTimingStats `json:"phases"`
+}
+
 // RetroAttendee represents attendance record for a retrospective
 type RetroAttendee struct {
-	ID              uuid.UUID `json:"id" db:"id"`
-	RetrospectiveID uuid.UUID `json:"retrospectiveId" db:"retrospective_id"`
-	UserID          uuid.UUID `json:"userId" db:"user_id"`
-	Attended        bool      `json:"attended" db:"attended"`
-	RecordedAt      time.Time `json:"recordedAt" db:"recorded_at"`
+	ID              uuid.UUID  `json:"id" db:"id"`
+	RetrospectiveID uuid.UUID  `json:"retrospectiveId" db:"retrospective_id"`
+	UserID          uuid.UUID  `json:"userId" db:"user_id"`
+	Attended        bool       `json:"attended" db:"attended"`
+	RecordedAt      time.Time  `json:"recordedAt" db:"recorded_at"`
+	FirstSeenAt     *time.Time `json:"firstSeenAt,omitempty" db:"first_seen_at"`
 
 	// Joined fields
 	User *User `json:"user,omitempty"`
@@ -424,3 +655,26 @@ type DiscussedTopic struct {
 	TotalDiscussionSeconds int       `json:"totalDiscussionSeconds"`
 	ExtensionCount         int       `json:"extensionCount"`
 }
+
+// IdempotencyKey is the response stored for a request sent with an Idempotency-Key header
+type IdempotencyKey struct {
+	UserID      uuid.UUID
+	Key         string
+	RequestHash string // method, path and body of the request that claimed the key
+	StatusCode  *int   // nil while that request is still being processed
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// DevResetSummary counts what a dev-mode reset deleted
+type DevResetSummary struct {
+	TeamID         *uuid.UUID `json:"teamId,omitempty"` // nil when every team was reset
+	Retrospectives int        `json:"retrospectives"`
+	Items          int        `json:"items"`
+	Votes          int        `json:"votes"`
+	Actions        int        `json:"actions"`
+	Moods          int        `json:"moods"`
+	RotiVotes      int        `json:"rotiVotes"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SurveyAnswerType represents the kind of answer a survey question expects
+type SurveyAnswerType string
+
+const (
+	SurveyAnswerScale SurveyAnswerType = "scale" // 1-5
+	SurveyAnswerText  SurveyAnswerType = "text"
+)
+
+// SurveyQuestion represents an end-of-session survey question
+type SurveyQuestion struct {
+	ID         uuid.UUID        `json:"id" db:"id"`
+	RetroID    uuid.UUID        `json:"retroId" db:"retro_id"`
+	Position   int              `json:"position" db:"position"`
+	Prompt     string           `json:"prompt" db:"prompt"`
+	AnswerType SurveyAnswerType `json:"answerType" db:"answer_type"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// SurveyAnswer represents a participant's answer to a survey question
+type SurveyAnswer struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	QuestionID uuid.UUID  `json:"questionId" db:"question_id"`
+	UserID     *uuid.UUID `json:"userId,omitempty" db:"user_id"`
+	ScaleValue *int       `json:"scaleValue,omitempty" db:"scale_value"`
+	TextValue  *string    `json:"textValue,omitempty" db:"text_value"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	User       *User      `json:"user,omitempty"`
+}
+
+// SurveyQuestionResult represents aggregated answers for a single question
+type SurveyQuestionResult struct {
+	Question     *SurveyQuestion `json:"question"`
+	AnswerCount  int             `json:"answerCount"`
+	Average      *float64        `json:"average,omitempty"`      // scale questions only
+	Distribution map[int]int     `json:"distribution,omitempty"` // scale questions only
+	Answers      []*SurveyAnswer `json:"answers,omitempty"`      // only once revealed
+}
+
+// SurveyResults represents aggregated survey results for a retrospective
+type SurveyResults struct {
+	Questions []*SurveyQuestionResult `json:"questions"`
+	Revealed  bool                    `json:"revealed"`
+}
@@ -10,10 +10,25 @@ import (
 type WebhookEvent string
 
 const (
-	WebhookEventRetroCompleted WebhookEvent = "retro.completed"
-	WebhookEventActionCreated  WebhookEvent = "action.created"
+	WebhookEventRetroReminder   WebhookEvent = "retro.reminder"
+	WebhookEventRetroStarted    WebhookEvent = "retro.started"
+	WebhookEventRetroCompleted  WebhookEvent = "retro.completed"
+	WebhookEventActionCreated   WebhookEvent = "action.created"
+	WebhookEventActionCommented WebhookEvent = "action.commented"
+	WebhookEventActionCompleted WebhookEvent = "action.completed"
+	WebhookEventActionReopened  WebhookEvent = "action.reopened"
 )
 
+// IsValid reports whether the event is one webhooks can subscribe to
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventRetroReminder, WebhookEventRetroStarted, WebhookEventRetroCompleted,
+		WebhookEventActionCreated, WebhookEventActionCommented, WebhookEventActionCompleted, WebhookEventActionReopened:
+		return true
+	}
+	return false
+}
+
 // Webhook represents a webhook configuration
 type Webhook struct {
 	ID        uuid.UUID      `json:"id" db:"id"`
@@ -51,22 +66,49 @@ type WebhookPayload struct {
 	Data      interface{}  `json:"data"`
 }
 
+// RetroReminderData represents the data payload for retro.reminder events
+type RetroReminderData struct {
+	Name          string    `json:"name"`
+	TeamName      string    `json:"teamName"`
+	FacilitatorID uuid.UUID `json:"facilitatorId"`
+	ScheduledAt   time.Time `json:"scheduledAt"`
+	StartsIn      int       `json:"startsInMinutes"`
+	JoinURL       string    `json:"joinUrl"`
+}
+
+// RetroStartedData represents the data payload for retro.started events
+type RetroStartedData struct {
+	Name          string     `json:"name"`
+	FacilitatorID uuid.UUID  `json:"facilitatorId"`
+	ScheduledAt   *time.Time `json:"scheduledAt,omitempty"`
+	StartedAt     time.Time  `json:"startedAt"`
+	AutoStarted   bool       `json:"autoStarted"` // started by the scheduler at scheduledAt
+}
+
 // RetroCompletedData represents the data payload for retro.completed events
 type RetroCompletedData struct {
-	Name             string          `json:"name"`
-	FacilitatorID    uuid.UUID       `json:"facilitatorId"`
-	ParticipantCount int             `json:"participantCount"`
-	ItemCount        int             `json:"itemCount"`
-	ActionCount      int             `json:"actionCount"`
-	AverageRoti      *float64        `json:"averageRoti,omitempty"`
-	Moods            []MoodData      `json:"moods,omitempty"`
-	RotiVotes        []RotiVoteData  `json:"rotiVotes,omitempty"`
+	Name             string            `json:"name"`
+	FacilitatorID    uuid.UUID         `json:"facilitatorId"`
+	ParticipantCount int               `json:"participantCount"`
+	ItemCount        int               `json:"itemCount"`
+	ActionCount      int               `json:"actionCount"`
+	AverageRoti      *float64          `json:"averageRoti,omitempty"`
+	Moods            []MoodData        `json:"moods,omitempty"`
+	RotiVotes        []RotiVoteData    `json:"rotiVotes,omitempty"`
+	RotiComments     []RotiCommentData `json:"rotiComments,omitempty"`
+	Participants     []ParticipantData `json:"participants,omitempty"`
+}
+
+// ParticipantData represents an attendee in webhook payloads
+type ParticipantData struct {
+	UserID      uuid.UUID `json:"userId"`
+	DisplayName string    `json:"displayName"`
 }
 
 // MoodData represents mood information in webhook payloads
 type MoodData struct {
-	UserID uuid.UUID   `json:"userId"`
-	Mood   MoodWeather `json:"mood"`
+	UserID uuid.UUID `json:"userId"`
+	Mood   string    `json:"mood"`
 }
 
 // RotiVoteData represents ROTI vote information in webhook payloads
@@ -75,15 +117,43 @@ type RotiVoteData struct {
 	Rating int       `json:"rating"`
 }
 
+// RotiCommentData represents a ROTI comment in webhook payloads; UserID is omitted for anonymous retros
+type RotiCommentData struct {
+	UserID  *uuid.UUID `json:"userId,omitempty"`
+	Comment string     `json:"comment"`
+}
+
 // ActionCreatedData represents the data payload for action.created events
 type ActionCreatedData struct {
-	ActionID     uuid.UUID  `json:"actionId"`
-	Title        string     `json:"title"`
-	Description  *string    `json:"description,omitempty"`
-	AssigneeID   *uuid.UUID `json:"assigneeId,omitempty"`
-	AssigneeName *string    `json:"assigneeName,omitempty"`
-	DueDate      *time.Time `json:"dueDate,omitempty"`
-	Priority     int        `json:"priority"`
-	CreatedBy    uuid.UUID  `json:"createdBy"`
-	SourceItemID *uuid.UUID `json:"sourceItemId,omitempty"`
+	ActionID     uuid.UUID      `json:"actionId"`
+	Title        string         `json:"title"`
+	Description  *string        `json:"description,omitempty"`
+	AssigneeID   *uuid.UUID     `json:"assigneeId,omitempty"`
+	AssigneeName *string        `json:"assigneeName,omitempty"`
+	DueDate      *time.Time     `json:"dueDate,omitempty"`
+	Priority     ActionPriority `json:"priority"`
+	CreatedBy    uuid.UUID      `json:"createdBy"`
+	SourceItemID *uuid.UUID     `json:"sourceItemId,omitempty"`
+}
+
+// ActionCompletedData represents the data payload for action.completed and action.reopened events
+type ActionCompletedData struct {
+	ActionID    uuid.UUID  `json:"actionId"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	AssigneeID  *uuid.UUID `json:"assigneeId,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"` // unset once reopened
+	ChangedBy   uuid.UUID  `json:"changedBy"`
+	ExternalID  *string    `json:"externalId,omitempty"`
+	ExternalURL *string    `json:"externalUrl,omitempty"`
+}
+
+// ActionCommentedData represents the data payload for action.commented events
+type ActionCommentedData struct {
+	ActionID   uuid.UUID  `json:"actionId"`
+	Title      string     `json:"title"`
+	CommentID  uuid.UUID  `json:"commentId"`
+	Content    string     `json:"content"`
+	AuthorID   *uuid.UUID `json:"authorId,omitempty"`
+	AuthorName string     `json:"authorName"`
 }
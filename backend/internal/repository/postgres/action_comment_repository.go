@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// ActionCommentRepository handles action item comment database operations
+type ActionCommentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewActionCommentRepository creates a new action comment repository
+func NewActionCommentRepository(pool *pgxpool.Pool) *ActionCommentRepository {
+	return &ActionCommentRepository{pool: pool}
+}
+
+// Create stores a comment on an action item and returns it with its author's name
+func (r *ActionCommentRepository) Create(ctx context.Context, comment *models.ActionComment) (*models.ActionComment, error) {
+	query := `
+		INSERT INTO action_comments (id, action_id, author_id, content)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, action_id, author_id, content, created_at,
+		          COALESCE((SELECT display_name FROM users WHERE id = $3), '')
+	`
+
+	var created models.ActionComment
+	err := r.pool.QueryRow(ctx, query, uuid.New(), comment.ActionID, comment.AuthorID, comment.Content).Scan(
+		&created.ID, &created.ActionID, &created.AuthorID, &created.Content, &created.CreatedAt, &created.AuthorName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ListByAction lists the comments on an action item, oldest first
+func (r *ActionCommentRepository) ListByAction(ctx context.Context, actionID uuid.UUID) ([]*models.ActionComment, error) {
+	query := `
+		SELECT c.id, c.action_id, c.author_id, c.content, c.created_at,
+		       COALESCE(u.display_name, '')
+		FROM action_comments c
+		LEFT JOIN users u ON u.id = c.author_id
+		WHERE c.action_id = $1
+		ORDER BY c.created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, actionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*models.ActionComment{}
+	for rows.Next() {
+		var comment models.ActionComment
+		if err := rows.Scan(&comment.ID, &comment.ActionID, &comment.AuthorID, &comment.Content,
+			&comment.CreatedAt, &comment.AuthorName); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &comment)
+	}
+
+	return comments, rows.Err()
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// activityEventsCTE merges retro and action events of a team into a single timeline.
+// $1 = team_id, $2 = from (nullable), $3 = to (nullable)
+const activityEventsCTE = `
+	WITH events AS (
+		SELECT 'retro.started' AS type, r.started_at AS occurred_at, r.id AS retro_id, r.name AS retro_name,
+			NULL::uuid AS action_id, NULL::text AS action_title, r.facilitator_id AS user_id
+		FROM retrospectives r
+		WHERE r.team_id = $1 AND r.deleted_at IS NULL AND r.started_at IS NOT NULL
+		UNION ALL
+		SELECT 'retro.ended', r.ended_at, r.id, r.name, NULL::uuid, NULL::text, r.facilitator_id
+		FROM retrospectives r
+		WHERE r.team_id = $1 AND r.deleted_at IS NULL AND r.ended_at IS NOT NULL
+		UNION ALL
+		SELECT 'action.created', a.created_at, r.id, r.name, a.id, a.title, a.created_by
+		FROM action_items a
+		JOIN retrospectives r ON r.id = a.retro_id
+		WHERE r.team_id = $1 AND r.deleted_at IS NULL AND a.carried_from_id IS NULL
+		UNION ALL
+		SELECT 'action.completed', a.completed_at, r.id, r.name, a.id, a.title, a.assignee_id
+		FROM action_items a
+		JOIN retrospectives r ON r.id = a.retro_id
+		WHERE r.team_id = $1 AND r.deleted_at IS NULL AND a.completed_at IS NOT NULL
+	)
+`
+
+const activityWhereClause = `
+	WHERE ($2::timestamptz IS NULL OR occurred_at >= $2)
+	AND ($3::timestamptz IS NULL OR occurred_at <= $3)
+`
+
+// ActivityRepository handles team activity timeline queries
+type ActivityRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewActivityRepository creates a new activity repository
+func NewActivityRepository(pool *pgxpool.Pool) *ActivityRepository {
+	return &ActivityRepository{pool: pool}
+}
+
+// ListByTeam returns a page of the team activity timeline, most recent first, and the total count
+func (r *ActivityRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, filter *models.ActivityFilter) ([]*models.ActivityEvent, int, error) {
+	var total int
+	countQuery := activityEventsCTE + `SELECT COUNT(*) FROM events` + activityWhereClause
+	if err := r.pool.QueryRow(ctx, countQuery, teamID, filter.From, filter.To).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := activityEventsCTE + `
+		SELECT type, occurred_at, retro_id, retro_name, action_id, action_title, user_id
+		FROM events` + activityWhereClause + `
+		ORDER BY occurred_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := r.pool.Query(ctx, query, teamID, filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []*models.ActivityEvent{}
+	for rows.Next() {
+		event := &models.ActivityEvent{}
+		if err := rows.Scan(
+			&event.Type, &event.OccurredAt, &event.RetroID, &event.RetroName,
+			&event.ActionID, &event.ActionTitle, &event.UserID,
+		); err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
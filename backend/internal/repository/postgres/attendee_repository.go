@@ -22,16 +22,32 @@ func NewAttendeeRepository(pool *pgxpool.Pool) *AttendeeRepository {
 // Record records a user's attendance for a retrospective
 func (r *AttendeeRepository) Record(ctx context.Context, retroID, userID uuid.UUID, attended bool) error {
 	query := `
-		INSERT INTO retro_attendees (retrospective_id, user_id, attended)
-		VALUES ($1, $2, $3)
+		INSERT INTO retro_attendees (retrospective_id, user_id, attended, first_seen_at)
+		VALUES ($1, $2, $3, CASE WHEN $3 THEN NOW() END)
 		ON CONFLICT (retrospective_id, user_id)
-		DO UPDATE SET attended = $3, recorded_at = NOW()
+		DO UPDATE SET attended = $3, recorded_at = NOW(),
+		    first_seen_at = CASE WHEN $3 THEN COALESCE(retro_attendees.first_seen_at, NOW()) ELSE retro_attendees.first_seen_at END
 	`
 
 	_, err := r.pool.Exec(ctx, query, retroID, userID, attended)
 	return err
 }
 
+// MarkPresent records that a user joined the retrospective, keeping the time they were first seen
+func (r *AttendeeRepository) MarkPresent(ctx context.Context, retroID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO retro_attendees (retrospective_id, user_id, attended, first_seen_at)
+		VALUES ($1, $2, true, NOW())
+		ON CONFLICT (retrospective_id, user_id)
+		DO UPDATE SET attended = true,
+		    recorded_at = CASE WHEN retro_attendees.attended THEN retro_attendees.recorded_at ELSE NOW() END,
+		    first_seen_at = COALESCE(retro_attendees.first_seen_at, NOW())
+	`
+
+	_, err := r.pool.Exec(ctx, query, retroID, userID)
+	return err
+}
+
 // RecordBatch records attendance for multiple users at once
 func (r *AttendeeRepository) RecordBatch(ctx context.Context, retroID uuid.UUID, attendees map[uuid.UUID]bool) error {
 	for userID, attended := range attendees {
@@ -45,7 +61,7 @@ func (r *AttendeeRepository) RecordBatch(ctx context.Context, retroID uuid.UUID,
 // GetByRetro gets all attendance records for a retrospective
 func (r *AttendeeRepository) GetByRetro(ctx context.Context, retroID uuid.UUID) ([]*models.RetroAttendee, error) {
 	query := `
-		SELECT ra.id, ra.retrospective_id, ra.user_id, ra.attended, ra.recorded_at,
+		SELECT ra.id, ra.retrospective_id, ra.user_id, ra.attended, ra.recorded_at, ra.first_seen_at,
 		       u.id, u.display_name, u.avatar_url
 		FROM retro_attendees ra
 		JOIN users u ON u.id = ra.user_id
@@ -64,7 +80,7 @@ func (r *AttendeeRepository) GetByRetro(ctx context.Context, retroID uuid.UUID)
 		var a models.RetroAttendee
 		var user models.User
 		err := rows.Scan(
-			&a.ID, &a.RetrospectiveID, &a.UserID, &a.Attended, &a.RecordedAt,
+			&a.ID, &a.RetrospectiveID, &a.UserID, &a.Attended, &a.RecordedAt, &a.FirstSeenAt,
 			&user.ID, &user.DisplayName, &user.AvatarURL,
 		)
 		if err != nil {
@@ -109,7 +125,7 @@ func (r *AttendeeRepository) GetUserAttendanceStats(ctx context.Context, userID,
 			COUNT(*) as total
 		FROM retro_attendees ra
 		JOIN retrospectives r ON r.id = ra.retrospective_id
-		WHERE ra.user_id = $1 AND r.team_id = $2
+		WHERE ra.user_id = $1 AND r.team_id = $2 AND r.deleted_at IS NULL
 	`
 
 	err = r.pool.QueryRow(ctx, query, userID, teamID).Scan(&attended, &total)
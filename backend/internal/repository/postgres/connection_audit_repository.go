@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// ConnectionAuditRepository handles the audit log of WebSocket connections
+type ConnectionAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewConnectionAuditRepository creates a new connection audit repository
+func NewConnectionAuditRepository(pool *pgxpool.Pool) *ConnectionAuditRepository {
+	return &ConnectionAuditRepository{pool: pool}
+}
+
+// Open records a new connection
+func (r *ConnectionAuditRepository) Open(ctx context.Context, entry *models.WSConnectionAudit) error {
+	query := `
+		INSERT INTO ws_connection_audit (connection_id, user_id, guest, ip_address, user_agent, connected_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (connection_id) DO NOTHING
+	`
+	_, err := r.pool.Exec(ctx, query, entry.ConnectionID, entry.UserID, entry.Guest, entry.IPAddress, entry.UserAgent, entry.ConnectedAt)
+	return err
+}
+
+// AddRetro adds a retro the connection joined, once
+func (r *ConnectionAuditRepository) AddRetro(ctx context.Context, connectionID string, retroID uuid.UUID) error {
+	query := `
+		UPDATE ws_connection_audit SET retro_ids = array_append(retro_ids, $2)
+		WHERE connection_id = $1 AND NOT ($2 = ANY(retro_ids))
+	`
+	_, err := r.pool.Exec(ctx, query, connectionID, retroID)
+	return err
+}
+
+// Close records when the connection closed and how long it lasted
+func (r *ConnectionAuditRepository) Close(ctx context.Context, connectionID string, at time.Time) error {
+	query := `
+		UPDATE ws_connection_audit
+		SET disconnected_at = $2, duration_seconds = GREATEST(EXTRACT(EPOCH FROM $2 - connected_at)::INTEGER, 0)
+		WHERE connection_id = $1 AND disconnected_at IS NULL
+	`
+	_, err := r.pool.Exec(ctx, query, connectionID, at)
+	return err
+}
+
+// ListByRetro returns the most recent connections that joined the retro, newest first
+func (r *ConnectionAuditRepository) ListByRetro(ctx context.Context, retroID uuid.UUID, limit int) ([]*models.WSConnectionAudit, error) {
+	query := `
+		SELECT a.connection_id, a.user_id, u.display_name, a.guest, a.retro_ids, a.ip_address, a.user_agent,
+		       a.connected_at, a.disconnected_at, a.duration_seconds
+		FROM ws_connection_audit a
+		JOIN users u ON u.id = a.user_id
+		WHERE $1 = ANY(a.retro_ids)
+		ORDER BY a.connected_at DESC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, retroID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.WSConnectionAudit{}
+	for rows.Next() {
+		var e models.WSConnectionAudit
+		if err := rows.Scan(&e.ConnectionID, &e.UserID, &e.UserName, &e.Guest, &e.RetroIDs, &e.IPAddress, &e.UserAgent,
+			&e.ConnectedAt, &e.DisconnectedAt, &e.DurationSeconds); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// devBaselineSeed restores the dev team and users of 000004_dev_users_seed, with the
+// roles of 000011_simplify_roles, and drops the other members of the dev team
+const devBaselineSeed = `
+	INSERT INTO teams (id, name, slug, description, is_oidc_managed)
+	VALUES ('00000000-0000-0000-0000-000000000001', 'Dev Team', 'dev-team', 'Development team for testing', false)
+	ON CONFLICT (slug) DO UPDATE SET
+		name = EXCLUDED.name,
+		description = EXCLUDED.description;
+
+	INSERT INTO users (id, email, display_name, oidc_subject, oidc_issuer, is_admin)
+	VALUES
+		('00000000-0000-0000-0001-000000000001', 'admin@retrotro.dev', 'Dev Admin', 'dev-admin@retrotro.dev', 'dev-mode', true),
+		('00000000-0000-0000-0001-000000000002', 'manager@retrotro.dev', 'Team Manager', 'dev-manager@retrotro.dev', 'dev-mode', false),
+		('00000000-0000-0000-0001-000000000003', 'facilitator@retrotro.dev', 'Facilitateur', 'dev-facilitator@retrotro.dev', 'dev-mode', false),
+		('00000000-0000-0000-0001-000000000004', 'user1@retrotro.dev', 'User One', 'dev-user1@retrotro.dev', 'dev-mode', false),
+		('00000000-0000-0000-0001-000000000005', 'user2@retrotro.dev', 'User Two', 'dev-user2@retrotro.dev', 'dev-mode', false),
+		('00000000-0000-0000-0001-000000000006', 'user3@retrotro.dev', 'User Three', 'dev-user3@retrotro.dev', 'dev-mode', false)
+	ON CONFLICT (email) DO UPDATE SET
+		display_name = EXCLUDED.display_name,
+		oidc_subject = EXCLUDED.oidc_subject,
+		oidc_issuer = EXCLUDED.oidc_issuer,
+		is_admin = EXCLUDED.is_admin;
+
+	DELETE FROM team_members
+	WHERE team_id = (SELECT id FROM teams WHERE slug = 'dev-team')
+		AND user_id NOT IN (SELECT id FROM users WHERE email LIKE '%@retrotro.dev');
+
+	INSERT INTO team_members (team_id, user_id, role)
+	SELECT t.id, u.id, m.role::user_role
+	FROM (VALUES
+		('admin@retrotro.dev', 'admin'),
+		('manager@retrotro.dev', 'admin'),
+		('facilitator@retrotro.dev', 'member'),
+		('user1@retrotro.dev', 'member'),
+		('user2@retrotro.dev', 'member'),
+		('user3@retrotro.dev', 'member')
+	) AS m(email, role)
+	JOIN users u ON u.email = m.email
+	JOIN teams t ON t.slug = 'dev-team'
+	ON CONFLICT (team_id, user_id) DO UPDATE SET
+		role = EXCLUDED.role;
+`
+
+// DevRepository handles the dev-mode reset between e2e runs
+type DevRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDevRepository creates a new dev repository
+func NewDevRepository(pool *pgxpool.Pool) *DevRepository {
+	return &DevRepository{pool: pool}
+}
+
+// Reset deletes the retrospectives of the team, or of every team when teamID is nil, with
+// their items, votes, actions, moods and ROTI votes, then restores the dev team and users.
+// Both happen in one transaction.
+func (r *DevRepository) Reset(ctx context.Context, teamID *uuid.UUID) (*models.DevResetSummary, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	countQuery := `
+		WITH retros AS (
+			SELECT id FROM retrospectives WHERE $1::uuid IS NULL OR team_id = $1
+		)
+		SELECT
+			(SELECT COUNT(*) FROM retros),
+			(SELECT COUNT(*) FROM items WHERE retro_id IN (SELECT id FROM retros)),
+			(SELECT COUNT(*) FROM votes v JOIN items i ON i.id = v.item_id WHERE i.retro_id IN (SELECT id FROM retros)),
+			(SELECT COUNT(*) FROM action_items WHERE retro_id IN (SELECT id FROM retros)),
+			(SELECT COUNT(*) FROM icebreaker_moods WHERE retro_id IN (SELECT id FROM retros)),
+			(SELECT COUNT(*) FROM roti_votes WHERE retro_id IN (SELECT id FROM retros))
+	`
+	summary := &models.DevResetSummary{TeamID: teamID}
+	err = tx.QueryRow(ctx, countQuery, teamID).Scan(
+		&summary.Retrospectives, &summary.Items, &summary.Votes,
+		&summary.Actions, &summary.Moods, &summary.RotiVotes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items, votes, actions, moods and ROTI votes go with their retrospective
+	if _, err := tx.Exec(ctx, `DELETE FROM retrospectives WHERE $1::uuid IS NULL OR team_id = $1`, teamID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, devBaselineSeed); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
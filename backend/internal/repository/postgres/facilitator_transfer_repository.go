@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// FacilitatorTransferRepository handles the pending facilitator transfers of retrospectives
+type FacilitatorTransferRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewFacilitatorTransferRepository creates a new facilitator transfer repository
+func NewFacilitatorTransferRepository(pool *pgxpool.Pool) *FacilitatorTransferRepository {
+	return &FacilitatorTransferRepository{pool: pool}
+}
+
+const facilitatorTransferColumns = `retro_id, from_user_id, to_user_id, to_user_name, expires_at`
+
+// Put stores the pending transfer of a retro, replacing any previous one
+func (r *FacilitatorTransferRepository) Put(ctx context.Context, transfer *models.FacilitatorTransfer) error {
+	query := `
+		INSERT INTO facilitator_transfers (retro_id, from_user_id, to_user_id, to_user_name, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (retro_id) DO UPDATE
+		SET from_user_id = $2, to_user_id = $3, to_user_name = $4, expires_at = $5, created_at = NOW()
+	`
+	_, err := r.pool.Exec(ctx, query, transfer.RetroID, transfer.FromUserID, transfer.ToUserID, transfer.ToUserName, transfer.ExpiresAt)
+	return err
+}
+
+// Take removes and returns the retro's pending transfer to the user, if it has not expired.
+// When several pods take it at once, only one of them gets it.
+func (r *FacilitatorTransferRepository) Take(ctx context.Context, retroID, toUserID uuid.UUID) (*models.FacilitatorTransfer, error) {
+	query := `
+		DELETE FROM facilitator_transfers
+		WHERE retro_id = $1 AND to_user_id = $2 AND expires_at > NOW()
+		RETURNING ` + facilitatorTransferColumns
+	return r.deleteOne(ctx, query, retroID, toUserID)
+}
+
+// Cancel removes and returns the retro's pending transfer from or to the user
+func (r *FacilitatorTransferRepository) Cancel(ctx context.Context, retroID, userID uuid.UUID) (*models.FacilitatorTransfer, error) {
+	query := `
+		DELETE FROM facilitator_transfers
+		WHERE retro_id = $1 AND (to_user_id = $2 OR from_user_id = $2)
+		RETURNING ` + facilitatorTransferColumns
+	return r.deleteOne(ctx, query, retroID, userID)
+}
+
+// Delete removes the retro's pending transfer, if any
+func (r *FacilitatorTransferRepository) Delete(ctx context.Context, retroID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM facilitator_transfers WHERE retro_id = $1`, retroID)
+	return err
+}
+
+// DeleteExpired removes and returns the transfers that expired
+func (r *FacilitatorTransferRepository) DeleteExpired(ctx context.Context) ([]*models.FacilitatorTransfer, error) {
+	rows, err := r.pool.Query(ctx, `DELETE FROM facilitator_transfers WHERE expires_at <= NOW() RETURNING `+facilitatorTransferColumns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []*models.FacilitatorTransfer{}
+	for rows.Next() {
+		var t models.FacilitatorTransfer
+		if err := rows.Scan(&t.RetroID, &t.FromUserID, &t.ToUserID, &t.ToUserName, &t.ExpiresAt); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, &t)
+	}
+	return transfers, rows.Err()
+}
+
+func (r *FacilitatorTransferRepository) deleteOne(ctx context.Context, query string, retroID, userID uuid.UUID) (*models.FacilitatorTransfer, error) {
+	var t models.FacilitatorTransfer
+	err := r.pool.QueryRow(ctx, query, retroID, userID).Scan(&t.RetroID, &t.FromUserID, &t.ToUserID, &t.ToUserName, &t.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
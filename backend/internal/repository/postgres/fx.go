@@ -20,8 +20,10 @@ var Module = fx.Module("repository",
 		NewTemplateRepository,
 		NewRetrospectiveRepository,
 		NewItemRepository,
+		NewItemHistoryRepository,
 		NewVoteRepository,
 		NewActionItemRepository,
+		NewActionCommentRepository,
 		NewIcebreakerRepository,
 		NewRotiRepository,
 		NewStatsRepository,
@@ -29,6 +31,17 @@ var Module = fx.Module("repository",
 		NewWebhookRepository,
 		NewWebhookDeliveryRepository,
 		NewLCTopicHistoryRepository,
+		NewActivityRepository,
+		NewSurveyRepository,
+		NewPhaseHistoryRepository,
+		NewOIDCDebugRepository,
+		NewSnapshotRepository,
+		NewTeamInviteRepository,
+		NewRevokedTokenRepository,
+		NewIdempotencyRepository,
+		NewConnectionAuditRepository,
+		NewFacilitatorTransferRepository,
+		NewDevRepository,
 	),
 )
 
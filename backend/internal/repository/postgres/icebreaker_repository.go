@@ -22,7 +22,7 @@ func NewIcebreakerRepository(pool *pgxpool.Pool) *IcebreakerRepository {
 }
 
 // SetMood sets or updates a user's mood for a retrospective
-func (r *IcebreakerRepository) SetMood(ctx context.Context, retroID, userID uuid.UUID, mood models.MoodWeather) (*models.IcebreakerMood, error) {
+func (r *IcebreakerRepository) SetMood(ctx context.Context, retroID, userID uuid.UUID, mood string) (*models.IcebreakerMood, error) {
 	query := `
 		INSERT INTO icebreaker_moods (id, retro_id, user_id, mood)
 		VALUES ($1, $2, $3, $4)
@@ -105,6 +105,18 @@ func (r *IcebreakerRepository) GetMood(ctx context.Context, retroID, userID uuid
 	return &m, nil
 }
 
+// DeleteMood removes a user's mood for a retrospective
+func (r *IcebreakerRepository) DeleteMood(ctx context.Context, retroID, userID uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM icebreaker_moods WHERE retro_id = $1 AND user_id = $2`, retroID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // CountMoods counts the number of moods submitted for a retrospective
 func (r *IcebreakerRepository) CountMoods(ctx context.Context, retroID uuid.UUID) (int, error) {
 	query := `SELECT COUNT(*) FROM icebreaker_moods WHERE retro_id = $1`
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// IdempotencyRepository handles the responses stored under Idempotency-Key headers
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+// Reserve claims the user's key for a request with the given hash, after purging expired keys
+// and keys whose request started before staleBefore without completing.
+// It returns nil when the key was claimed, or the key already stored under it otherwise.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, userID uuid.UUID, key, requestHash string, expiresAt, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	purge := `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= NOW() OR (status_code IS NULL AND created_at <= $1)
+	`
+	if _, err := r.pool.Exec(ctx, purge, staleBefore); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO NOTHING
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, key, requestHash, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	query = `
+		SELECT user_id, key, request_hash, status_code, content_type, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`
+	stored := &models.IdempotencyKey{}
+	err = r.pool.QueryRow(ctx, query, userID, key).Scan(
+		&stored.UserID, &stored.Key, &stored.RequestHash, &stored.StatusCode,
+		&stored.ContentType, &stored.Body, &stored.CreatedAt, &stored.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Released in the meantime, the client may retry
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return stored, nil
+}
+
+// Complete stores the response of the request that claimed the key
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, body = $5
+		WHERE user_id = $1 AND key = $2
+	`
+	_, err := r.pool.Exec(ctx, query, userID, key, statusCode, contentType, body)
+	return err
+}
+
+// Release deletes the key, so a request that failed may be retried with it
+func (r *IdempotencyRepository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key)
+	return err
+}
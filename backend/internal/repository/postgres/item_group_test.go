@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+func newItem(column string, position int, parent *models.Item) *models.Item {
+	item := &models.Item{ID: uuid.New(), ColumnID: column, Position: position}
+	if parent != nil {
+		item.GroupID = &parent.ID
+	}
+	return item
+}
+
+func assertContiguous(t *testing.T, items []*models.Item, column string, want []*models.Item) {
+	t.Helper()
+
+	var got []*models.Item
+	for pos := 0; ; pos++ {
+		var found *models.Item
+		for _, item := range items {
+			if item.ColumnID == column && item.Position == pos {
+				if found != nil {
+					t.Fatalf("column %s: two items at position %d", column, pos)
+				}
+				found = item
+			}
+		}
+		if found == nil {
+			break
+		}
+		got = append(got, found)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("column %s: got %d contiguous items, want %d", column, len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("column %s: unexpected item at position %d", column, i)
+		}
+	}
+}
+
+func TestPlanGroupMoveMovesChildrenWithParent(t *testing.T) {
+	// "start": a, parent, b, child1 (of parent), grandchild (of child1)
+	// "stop":  x, y
+	a := newItem("start", 0, nil)
+	parent := newItem("start", 1, nil)
+	b := newItem("start", 2, nil)
+	child1 := newItem("start", 3, parent)
+	grandchild := newItem("start", 4, child1)
+	x := newItem("stop", 0, nil)
+	y := newItem("stop", 1, nil)
+	items := []*models.Item{a, parent, b, child1, grandchild, x, y}
+
+	moved := planGroupMove(items, parent.ID, "stop", 1)
+
+	if len(moved) != 5 { // b, parent, child1, grandchild and y
+		t.Fatalf("expected 5 changed items, got %d", len(moved))
+	}
+	assertContiguous(t, items, "start", []*models.Item{a, b})
+	assertContiguous(t, items, "stop", []*models.Item{x, parent, child1, grandchild, y})
+}
+
+func TestPlanGroupMoveWithinColumn(t *testing.T) {
+	parent := newItem("start", 0, nil)
+	child := newItem("start", 1, parent)
+	other := newItem("start", 2, nil)
+	items := []*models.Item{parent, child, other}
+
+	planGroupMove(items, parent.ID, "start", 1)
+
+	assertContiguous(t, items, "start", []*models.Item{other, parent, child})
+}
+
+func TestPlanGroupMoveClampsPosition(t *testing.T) {
+	a := newItem("start", 0, nil)
+	x := newItem("stop", 0, nil)
+	items := []*models.Item{a, x}
+
+	planGroupMove(items, a.ID, "stop", 42)
+	assertContiguous(t, items, "stop", []*models.Item{x, a})
+
+	planGroupMove(items, a.ID, "new", -3)
+	assertContiguous(t, items, "new", []*models.Item{a})
+	assertContiguous(t, items, "stop", []*models.Item{x})
+}
+
+func TestBuildItemTreeNestsAndSumsVotes(t *testing.T) {
+	parent := newItem("start", 0, nil)
+	child := newItem("start", 1, parent)
+	grandchild := newItem("start", 2, child)
+	parent.VoteCount, child.VoteCount, grandchild.VoteCount = 1, 2, 3
+
+	roots := buildItemTree([]*models.Item{parent, child, grandchild})
+
+	if len(roots) != 1 || roots[0] != parent {
+		t.Fatalf("expected parent as the only root, got %d roots", len(roots))
+	}
+	if len(parent.Children) != 1 || parent.Children[0] != child || len(child.Children) != 1 {
+		t.Fatal("expected parent > child > grandchild nesting")
+	}
+	if parent.GroupVoteCount != 6 || child.GroupVoteCount != 5 || grandchild.GroupVoteCount != 3 {
+		t.Fatalf("unexpected group vote counts %d/%d/%d", parent.GroupVoteCount, child.GroupVoteCount, grandchild.GroupVoteCount)
+	}
+}
+
+func TestBuildItemTreeBreaksCycles(t *testing.T) {
+	a := newItem("start", 0, nil)
+	b := newItem("start", 1, a)
+	a.GroupID = &b.ID
+	self := newItem("start", 2, nil)
+	self.GroupID = &self.ID
+
+	roots := buildItemTree([]*models.Item{a, b, self})
+
+	if len(roots) != 3 {
+		t.Fatalf("expected every item of a cycle at the top level, got %d roots", len(roots))
+	}
+}
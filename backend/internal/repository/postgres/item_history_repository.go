@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// ItemHistoryRepository handles item edit history database operations
+type ItemHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewItemHistoryRepository creates a new item history repository
+func NewItemHistoryRepository(pool *pgxpool.Pool) *ItemHistoryRepository {
+	return &ItemHistoryRepository{pool: pool}
+}
+
+// Record stores a change of an item's content
+func (r *ItemHistoryRepository) Record(ctx context.Context, edit *models.ItemEdit) error {
+	query := `
+		INSERT INTO item_history (id, item_id, editor_id, old_content, new_content, edited_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.pool.Exec(ctx, query, uuid.New(), edit.ItemID, edit.EditorID, edit.OldContent, edit.NewContent, edit.EditedAt)
+	return err
+}
+
+// ListByItem lists the content changes of an item, oldest first
+func (r *ItemHistoryRepository) ListByItem(ctx context.Context, itemID uuid.UUID) ([]*models.ItemEdit, error) {
+	query := `
+		SELECT h.id, h.item_id, h.editor_id, h.old_content, h.new_content, h.edited_at,
+		       COALESCE(u.display_name, '')
+		FROM item_history h
+		LEFT JOIN users u ON u.id = h.editor_id
+		WHERE h.item_id = $1
+		ORDER BY h.edited_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*models.ItemEdit{}
+	for rows.Next() {
+		var edit models.ItemEdit
+		if err := rows.Scan(&edit.ID, &edit.ItemID, &edit.EditorID, &edit.OldContent, &edit.NewContent,
+			&edit.EditedAt, &edit.EditorName); err != nil {
+			return nil, err
+		}
+		history = append(history, &edit)
+	}
+
+	return history, rows.Err()
+}
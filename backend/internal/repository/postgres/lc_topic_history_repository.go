@@ -139,7 +139,7 @@ func (r *LCTopicHistoryRepository) ListByTeam(ctx context.Context, teamID uuid.U
 		JOIN items i ON i.id = lth.topic_id
 		JOIN retrospectives r ON r.id = lth.retro_id
 		LEFT JOIN users u ON u.id = i.author_id
-		WHERE r.team_id = $1 AND r.status = 'completed' AND r.session_type = 'lean_coffee'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL AND r.session_type = 'lean_coffee'
 		ORDER BY lth.started_at DESC
 	`
 
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// OIDCDebugRepository handles storage of raw OIDC claims captured for debugging
+type OIDCDebugRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOIDCDebugRepository creates a new OIDC debug repository
+func NewOIDCDebugRepository(pool *pgxpool.Pool) *OIDCDebugRepository {
+	return &OIDCDebugRepository{pool: pool}
+}
+
+// Save stores the user's latest claims, replacing any previous capture, and purges expired captures
+func (r *OIDCDebugRepository) Save(ctx context.Context, userID uuid.UUID, claims map[string]interface{}, expiresAt time.Time) error {
+	query := `
+		INSERT INTO oidc_debug_claims (user_id, claims, captured_at, expires_at)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET claims = EXCLUDED.claims, captured_at = EXCLUDED.captured_at, expires_at = EXCLUDED.expires_at
+	`
+	if _, err := r.pool.Exec(ctx, query, userID, claims, expiresAt); err != nil {
+		return err
+	}
+
+	_, err := r.pool.Exec(ctx, `DELETE FROM oidc_debug_claims WHERE expires_at <= NOW()`)
+	return err
+}
+
+// FindByUser returns the user's captured claims if they have not expired yet
+func (r *OIDCDebugRepository) FindByUser(ctx context.Context, userID uuid.UUID) (*models.OIDCDebugClaims, error) {
+	query := `
+		SELECT user_id, claims, captured_at, expires_at
+		FROM oidc_debug_claims
+		WHERE user_id = $1 AND expires_at > NOW()
+	`
+
+	var c models.OIDCDebugClaims
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&c.UserID, &c.Claims, &c.CapturedAt, &c.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// PhaseHistoryRepository handles retrospective phase timing history database operations
+type PhaseHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPhaseHistoryRepository creates a new phase history repository
+func NewPhaseHistoryRepository(pool *pgxpool.Pool) *PhaseHistoryRepository {
+	return &PhaseHistoryRepository{pool: pool}
+}
+
+// Open records the start of a phase
+func (r *PhaseHistoryRepository) Open(ctx context.Context, retroID uuid.UUID, phase models.RetroPhase, plannedSeconds int, startedAt time.Time) error {
+	query := `
+		INSERT INTO retro_phase_history (id, retro_id, phase, started_at, planned_duration_seconds)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.pool.Exec(ctx, query, uuid.New(), retroID, phase, startedAt, plannedSeconds)
+	return err
+}
+
+// CloseOpen closes the retrospective's open phase rows, recording their actual duration
+func (r *PhaseHistoryRepository) CloseOpen(ctx context.Context, retroID uuid.UUID, endedAt time.Time) error {
+	query := `
+		UPDATE retro_phase_history
+		SET ended_at = $2,
+		    actual_duration_seconds = GREATEST(0, EXTRACT(EPOCH FROM ($2 - started_at)))::int
+		WHERE retro_id = $1 AND ended_at IS NULL
+	`
+	_, err := r.pool.Exec(ctx, query, retroID, endedAt)
+	return err
+}
+
+// ListByRetro lists the phase history of a retrospective in chronological order
+func (r *PhaseHistoryRepository) ListByRetro(ctx context.Context, retroID uuid.UUID) ([]*models.RetroPhaseHistory, error) {
+	query := `
+		SELECT id, retro_id, phase, started_at, ended_at, actual_duration_seconds, planned_duration_seconds
+		FROM retro_phase_history
+		WHERE retro_id = $1
+		ORDER BY started_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, retroID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*models.RetroPhaseHistory
+	for rows.Next() {
+		var h models.RetroPhaseHistory
+		if err := rows.Scan(
+			&h.ID, &h.RetroID, &h.Phase, &h.StartedAt, &h.EndedAt,
+			&h.ActualDurationSeconds, &h.PlannedDurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, &h)
+	}
+
+	return history, rows.Err()
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,15 +30,15 @@ func NewTemplateRepository(pool *pgxpool.Pool) *TemplateRepository {
 // FindByID finds a template by ID
 func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Template, error) {
 	query := `
-		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at
+		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at, mood_scale
 		FROM templates WHERE id = $1
 	`
 
 	var template models.Template
-	var columnsJSON []byte
+	var columnsJSON, moodScaleJSON []byte
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&template.ID, &template.Name, &template.Description, &columnsJSON,
-		&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt,
+		&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt, &moodScaleJSON,
 	)
 
 	if err != nil {
@@ -48,6 +51,9 @@ func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model
 	if err := json.Unmarshal(columnsJSON, &template.Columns); err != nil {
 		return nil, err
 	}
+	if err := unmarshalMoodScale(moodScaleJSON, &template); err != nil {
+		return nil, err
+	}
 
 	// Load phase timers
 	template.PhaseTimes, _ = r.GetPhaseTimers(ctx, id)
@@ -58,16 +64,16 @@ func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model
 // FindBuiltInByName finds a built-in template by name
 func (r *TemplateRepository) FindBuiltInByName(ctx context.Context, name string) (*models.Template, error) {
 	query := `
-		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at
+		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at, mood_scale
 		FROM templates WHERE name = $1 AND is_built_in = true
 		LIMIT 1
 	`
 
 	var template models.Template
-	var columnsJSON []byte
+	var columnsJSON, moodScaleJSON []byte
 	err := r.pool.QueryRow(ctx, query, name).Scan(
 		&template.ID, &template.Name, &template.Description, &columnsJSON,
-		&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt,
+		&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt, &moodScaleJSON,
 	)
 
 	if err != nil {
@@ -80,6 +86,9 @@ func (r *TemplateRepository) FindBuiltInByName(ctx context.Context, name string)
 	if err := json.Unmarshal(columnsJSON, &template.Columns); err != nil {
 		return nil, err
 	}
+	if err := unmarshalMoodScale(moodScaleJSON, &template); err != nil {
+		return nil, err
+	}
 
 	template.PhaseTimes, _ = r.GetPhaseTimers(ctx, template.ID)
 
@@ -89,47 +98,36 @@ func (r *TemplateRepository) FindBuiltInByName(ctx context.Context, name string)
 // ListBuiltIn lists all built-in templates
 func (r *TemplateRepository) ListBuiltIn(ctx context.Context) ([]*models.Template, error) {
 	query := `
-		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at
+		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at, mood_scale
 		FROM templates WHERE is_built_in = true
 		ORDER BY name
 	`
-
-	rows, err := r.pool.Query(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var templates []*models.Template
-	for rows.Next() {
-		var template models.Template
-		var columnsJSON []byte
-		err := rows.Scan(
-			&template.ID, &template.Name, &template.Description, &columnsJSON,
-			&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal(columnsJSON, &template.Columns); err != nil {
-			return nil, err
-		}
-		template.PhaseTimes, _ = r.GetPhaseTimers(ctx, template.ID)
-		templates = append(templates, &template)
-	}
-
-	return templates, nil
+	return r.list(ctx, query)
 }
 
 // ListByTeam lists templates for a team (including built-in)
 func (r *TemplateRepository) ListByTeam(ctx context.Context, teamID uuid.UUID) ([]*models.Template, error) {
 	query := `
-		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at
+		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at, mood_scale
 		FROM templates WHERE is_built_in = true OR team_id = $1
 		ORDER BY is_built_in DESC, name
 	`
+	return r.list(ctx, query, teamID)
+}
 
-	rows, err := r.pool.Query(ctx, query, teamID)
+// ListTeamOwned lists the templates a team created, without the built-in ones
+func (r *TemplateRepository) ListTeamOwned(ctx context.Context, teamID uuid.UUID) ([]*models.Template, error) {
+	query := `
+		SELECT id, name, description, columns, is_built_in, team_id, created_by, created_at, mood_scale
+		FROM templates WHERE team_id = $1 AND is_built_in = false
+		ORDER BY name
+	`
+	return r.list(ctx, query, teamID)
+}
+
+// list runs a query selecting template columns and loads the phase timers of each template
+func (r *TemplateRepository) list(ctx context.Context, query string, args ...any) ([]*models.Template, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -138,10 +136,10 @@ func (r *TemplateRepository) ListByTeam(ctx context.Context, teamID uuid.UUID) (
 	var templates []*models.Template
 	for rows.Next() {
 		var template models.Template
-		var columnsJSON []byte
+		var columnsJSON, moodScaleJSON []byte
 		err := rows.Scan(
 			&template.ID, &template.Name, &template.Description, &columnsJSON,
-			&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt,
+			&template.IsBuiltIn, &template.TeamID, &template.CreatedBy, &template.CreatedAt, &moodScaleJSON,
 		)
 		if err != nil {
 			return nil, err
@@ -149,6 +147,9 @@ func (r *TemplateRepository) ListByTeam(ctx context.Context, teamID uuid.UUID) (
 		if err := json.Unmarshal(columnsJSON, &template.Columns); err != nil {
 			return nil, err
 		}
+		if err := unmarshalMoodScale(moodScaleJSON, &template); err != nil {
+			return nil, err
+		}
 		template.PhaseTimes, _ = r.GetPhaseTimers(ctx, template.ID)
 		templates = append(templates, &template)
 	}
@@ -156,16 +157,16 @@ func (r *TemplateRepository) ListByTeam(ctx context.Context, teamID uuid.UUID) (
 	return templates, nil
 }
 
-// Create creates a new template
+// Create creates a new template with its phase timers
 func (r *TemplateRepository) Create(ctx context.Context, template *models.Template) (*models.Template, error) {
-	columnsJSON, err := json.Marshal(template.Columns)
+	columnsJSON, moodScaleJSON, err := marshalTemplate(template)
 	if err != nil {
 		return nil, err
 	}
 
 	query := `
-		INSERT INTO templates (id, name, description, columns, is_built_in, team_id, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO templates (id, name, description, columns, is_built_in, team_id, created_by, mood_scale)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at
 	`
 
@@ -173,18 +174,167 @@ func (r *TemplateRepository) Create(ctx context.Context, template *models.Templa
 		template.ID = uuid.New()
 	}
 
-	err = r.pool.QueryRow(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, query,
 		template.ID, template.Name, template.Description, columnsJSON,
-		template.IsBuiltIn, template.TeamID, template.CreatedBy,
+		template.IsBuiltIn, template.TeamID, template.CreatedBy, moodScaleJSON,
 	).Scan(&template.ID, &template.CreatedAt)
-
 	if err != nil {
 		return nil, err
 	}
 
+	if err := savePhaseTimers(ctx, tx, template.ID, template.PhaseTimes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return template, nil
 }
 
+// Update updates a template's name, description, columns and mood scale and replaces its phase timers
+func (r *TemplateRepository) Update(ctx context.Context, template *models.Template) error {
+	columnsJSON, moodScaleJSON, err := marshalTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		UPDATE templates SET name = $2, description = $3, columns = $4, mood_scale = $5
+		WHERE id = $1
+	`
+	tag, err := tx.Exec(ctx, query, template.ID, template.Name, template.Description, columnsJSON, moodScaleJSON)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := savePhaseTimers(ctx, tx, template.ID, template.PhaseTimes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete deletes a template; its phase timers are removed by cascade
+func (r *TemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM templates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CountRetrospectives counts the retrospectives that use a template
+func (r *TemplateRepository) CountRetrospectives(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM retrospectives WHERE template_id = $1`, id).Scan(&count)
+	return count, err
+}
+
+// marshalTemplate encodes the JSON columns of a template; an empty mood scale is stored as NULL
+func marshalTemplate(template *models.Template) (columnsJSON, moodScaleJSON []byte, err error) {
+	if columnsJSON, err = json.Marshal(template.Columns); err != nil {
+		return nil, nil, err
+	}
+	if len(template.MoodScale) > 0 {
+		if moodScaleJSON, err = json.Marshal(template.MoodScale); err != nil {
+			return nil, nil, err
+		}
+	}
+	return columnsJSON, moodScaleJSON, nil
+}
+
+// savePhaseTimers upserts the template's phase timers and removes those of phases no longer listed
+func savePhaseTimers(ctx context.Context, tx pgx.Tx, templateID uuid.UUID, phaseTimes map[models.RetroPhase]int) error {
+	phases := make([]string, 0, len(phaseTimes))
+	for phase := range phaseTimes {
+		phases = append(phases, string(phase))
+	}
+
+	_, err := tx.Exec(ctx, `
+		DELETE FROM template_phase_timers
+		WHERE template_id = $1 AND NOT (phase::text = ANY($2))
+	`, templateID, phases)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO template_phase_timers (template_id, phase, duration_seconds)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (template_id, phase) DO UPDATE SET duration_seconds = EXCLUDED.duration_seconds
+	`
+	for phase, duration := range phaseTimes {
+		if _, err := tx.Exec(ctx, query, templateID, phase, duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Duplicate copies a template, its columns, mood scale and phase timers into a new
+// team-owned template that is not built-in
+func (r *TemplateRepository) Duplicate(ctx context.Context, sourceID, teamID, createdBy uuid.UUID, name string) (*models.Template, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id := uuid.New()
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO templates (id, name, description, columns, is_built_in, team_id, created_by, mood_scale)
+		SELECT $2, $3, description, columns, false, $4, $5, mood_scale
+		FROM templates WHERE id = $1
+	`, sourceID, id, name, teamID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO template_phase_timers (template_id, phase, duration_seconds, is_optional)
+		SELECT $2, phase, duration_seconds, is_optional
+		FROM template_phase_timers WHERE template_id = $1
+	`, sourceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+// unmarshalMoodScale decodes a template's mood scale; NULL leaves it empty so the default applies
+func unmarshalMoodScale(data []byte, template *models.Template) error {
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &template.MoodScale)
+}
+
 // GetPhaseTimers gets the phase timers for a template
 func (r *TemplateRepository) GetPhaseTimers(ctx context.Context, templateID uuid.UUID) (map[models.RetroPhase]int, error) {
 	query := `
@@ -229,59 +379,165 @@ func (r *RetrospectiveRepository) FindByID(ctx context.Context, id uuid.UUID) (*
 		       allow_item_edit, allow_vote_change, phase_timer_overrides,
 		       timer_started_at, timer_duration_seconds, timer_paused_at, timer_remaining_seconds,
 		       scheduled_at, started_at, ended_at, created_at, updated_at,
-		       session_type, lc_current_topic_id, lc_topic_timebox_seconds
-		FROM retrospectives WHERE id = $1
+		       session_type, lc_current_topic_id, lc_topic_timebox_seconds, lc_queue_order,
+		       max_actions_per_retro, auto_advance_on_timer_end, vote_limit_per_group, deleted_at,
+		       batch_vote_updates, open_access, allow_guests,
+		       share_id, share_expires_at, share_spectator_only, focused_item_id
+		FROM retrospectives WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var retro models.Retrospective
 	var phaseTimerOverrides []byte
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.pool.QueryRow(ctx, query, id).Scan(retroScanDest(&retro, &phaseTimerOverrides)...)
+
+	if err == nil && phaseTimerOverrides != nil {
+		_ = json.Unmarshal(phaseTimerOverrides, &retro.PhaseTimerOverrides)
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &retro, nil
+}
+
+// retroScanDest lists the scan destinations for the retrospective columns selected by FindByID
+func retroScanDest(retro *models.Retrospective, phaseTimerOverrides *[]byte) []any {
+	return []any{
 		&retro.ID, &retro.Name, &retro.TeamID, &retro.TemplateID, &retro.FacilitatorID,
 		&retro.Status, &retro.CurrentPhase, &retro.MaxVotesPerUser, &retro.MaxVotesPerItem,
 		&retro.AnonymousVoting, &retro.AnonymousItems, &retro.AllowItemEdit, &retro.AllowVoteChange,
-		&phaseTimerOverrides, &retro.TimerStartedAt, &retro.TimerDurationSeconds, &retro.TimerPausedAt,
+		phaseTimerOverrides, &retro.TimerStartedAt, &retro.TimerDurationSeconds, &retro.TimerPausedAt,
 		&retro.TimerRemainingSeconds, &retro.ScheduledAt, &retro.StartedAt, &retro.EndedAt,
 		&retro.CreatedAt, &retro.UpdatedAt,
-		&retro.SessionType, &retro.LCCurrentTopicID, &retro.LCTopicTimeboxSeconds,
-	)
-
-	if err == nil && phaseTimerOverrides != nil {
-		_ = json.Unmarshal(phaseTimerOverrides, &retro.PhaseTimerOverrides)
+		&retro.SessionType, &retro.LCCurrentTopicID, &retro.LCTopicTimeboxSeconds, &retro.LCQueueOrder,
+		&retro.MaxActionsPerRetro, &retro.AutoAdvanceOnTimerEnd, &retro.VoteLimitPerGroup, &retro.DeletedAt,
+		&retro.BatchVoteUpdates, &retro.OpenAccess, &retro.AllowGuests,
+		&retro.ShareID, &retro.ShareExpiresAt, &retro.ShareSpectatorOnly, &retro.FocusedItemID,
 	}
+}
 
-	if err != nil {
+// FindByIDWithRelations finds a retrospective with its team, template (columns and phase timers
+// included) and facilitator in one query. Hot paths that only need the retro use FindByID.
+func (r *RetrospectiveRepository) FindByIDWithRelations(ctx context.Context, id uuid.UUID) (*models.Retrospective, error) {
+	query := `
+		SELECT r.id, r.name, r.team_id, r.template_id, r.facilitator_id, r.status, r.current_phase,
+		       r.max_votes_per_user, r.max_votes_per_item, r.anonymous_voting, r.anonymous_items,
+		       r.allow_item_edit, r.allow_vote_change, r.phase_timer_overrides,
+		       r.timer_started_at, r.timer_duration_seconds, r.timer_paused_at, r.timer_remaining_seconds,
+		       r.scheduled_at, r.started_at, r.ended_at, r.created_at, r.updated_at,
+		       r.session_type, r.lc_current_topic_id, r.lc_topic_timebox_seconds, r.lc_queue_order,
+		       r.max_actions_per_retro, r.auto_advance_on_timer_end, r.vote_limit_per_group, r.deleted_at,
+		       r.batch_vote_updates, r.open_access, r.allow_guests,
+		       r.share_id, r.share_expires_at, r.share_spectator_only, r.focused_item_id,
+		       t.id, t.name, t.slug, t.description, t.oidc_group_id, t.is_oidc_managed,
+		       t.max_open_actions, t.created_by, t.created_at, t.updated_at,
+		       tp.id, tp.name, tp.description, tp.columns, tp.is_built_in, tp.team_id, tp.created_by,
+		       tp.created_at, tp.mood_scale,
+		       (SELECT jsonb_object_agg(pt.phase, pt.duration_seconds)
+		        FROM template_phase_timers pt WHERE pt.template_id = tp.id),
+		       u.id, u.email, u.display_name, u.avatar_url, u.is_admin, u.last_login_at,
+		       u.created_at, u.updated_at
+		FROM retrospectives r
+		JOIN teams t ON t.id = r.team_id
+		JOIN templates tp ON tp.id = r.template_id
+		JOIN users u ON u.id = r.facilitator_id
+		WHERE r.id = $1 AND r.deleted_at IS NULL
+	`
+
+	var retro models.Retrospective
+	team, template, facilitator := &models.Team{}, &models.Template{}, &models.User{}
+	var phaseTimerOverrides, columnsJSON, moodScaleJSON, phaseTimesJSON []byte
+	dest := append(retroScanDest(&retro, &phaseTimerOverrides),
+		&team.ID, &team.Name, &team.Slug, &team.Description, &team.OIDCGroupID, &team.IsOIDCManaged,
+		&team.MaxOpenActions, &team.CreatedBy, &team.CreatedAt, &team.UpdatedAt,
+		&template.ID, &template.Name, &template.Description, &columnsJSON, &template.IsBuiltIn,
+		&template.TeamID, &template.CreatedBy, &template.CreatedAt, &moodScaleJSON,
+		&phaseTimesJSON,
+		&facilitator.ID, &facilitator.Email, &facilitator.DisplayName, &facilitator.AvatarURL,
+		&facilitator.IsAdmin, &facilitator.LastLoginAt, &facilitator.CreatedAt, &facilitator.UpdatedAt,
+	)
+	if err := r.pool.QueryRow(ctx, query, id).Scan(dest...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, err
 	}
 
+	if phaseTimerOverrides != nil {
+		_ = json.Unmarshal(phaseTimerOverrides, &retro.PhaseTimerOverrides)
+	}
+	if err := json.Unmarshal(columnsJSON, &template.Columns); err != nil {
+		return nil, err
+	}
+	if err := unmarshalMoodScale(moodScaleJSON, template); err != nil {
+		return nil, err
+	}
+	template.PhaseTimes = make(map[models.RetroPhase]int)
+	if phaseTimesJSON != nil {
+		if err := json.Unmarshal(phaseTimesJSON, &template.PhaseTimes); err != nil {
+			return nil, err
+		}
+	}
+
+	retro.Team, retro.Template, retro.Facilitator = team, template, facilitator
 	return &retro, nil
 }
 
-// ListByTeam lists retrospectives for a team
-func (r *RetrospectiveRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, status *models.RetroStatus) ([]*models.Retrospective, error) {
+// ListByTeam returns a page of a team's retrospectives and the total count. Soft-deleted ones
+// are only listed with IncludeDeleted, archived ones with IncludeArchived or their status.
+func (r *RetrospectiveRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, filter *models.RetroListFilter) ([]*models.Retrospective, int, error) {
+	where := " WHERE team_id = $1"
+	args := []any{teamID}
+
+	if !filter.IncludeDeleted {
+		where += " AND deleted_at IS NULL"
+	}
+
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	} else if !filter.IncludeArchived {
+		// Archived retros are only listed when explicitly requested
+		where += " AND status <> 'archived'"
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM retrospectives"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
 	query := `
 		SELECT id, name, team_id, template_id, facilitator_id, status, current_phase,
 		       max_votes_per_user, max_votes_per_item, anonymous_voting, anonymous_items,
 		       allow_item_edit, allow_vote_change, phase_timer_overrides,
 		       timer_started_at, timer_duration_seconds, timer_paused_at, timer_remaining_seconds,
 		       scheduled_at, started_at, ended_at, created_at, updated_at,
-		       session_type, lc_current_topic_id, lc_topic_timebox_seconds
-		FROM retrospectives WHERE team_id = $1
-	`
-	args := []any{teamID}
-
-	if status != nil {
-		query += " AND status = $2"
-		args = append(args, *status)
+		       session_type, lc_current_topic_id, lc_topic_timebox_seconds, lc_queue_order,
+		       max_actions_per_retro, auto_advance_on_timer_end, vote_limit_per_group, deleted_at,
+		       batch_vote_updates, open_access, allow_guests,
+		       share_id, share_expires_at, share_spectator_only, focused_item_id
+		FROM retrospectives` + where
+
+	if filter.Sort == models.RetroSortEndedAt {
+		query += " ORDER BY ended_at DESC NULLS LAST, created_at DESC"
+	} else {
+		query += " ORDER BY created_at DESC"
+	}
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	} else if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
 	}
-
-	query += " ORDER BY created_at DESC"
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -296,18 +552,21 @@ func (r *RetrospectiveRepository) ListByTeam(ctx context.Context, teamID uuid.UU
 			&phaseTimerOverrides, &retro.TimerStartedAt, &retro.TimerDurationSeconds, &retro.TimerPausedAt,
 			&retro.TimerRemainingSeconds, &retro.ScheduledAt, &retro.StartedAt, &retro.EndedAt,
 			&retro.CreatedAt, &retro.UpdatedAt,
-			&retro.SessionType, &retro.LCCurrentTopicID, &retro.LCTopicTimeboxSeconds,
+			&retro.SessionType, &retro.LCCurrentTopicID, &retro.LCTopicTimeboxSeconds, &retro.LCQueueOrder,
+			&retro.MaxActionsPerRetro, &retro.AutoAdvanceOnTimerEnd, &retro.VoteLimitPerGroup, &retro.DeletedAt,
+			&retro.BatchVoteUpdates, &retro.OpenAccess, &retro.AllowGuests,
+			&retro.ShareID, &retro.ShareExpiresAt, &retro.ShareSpectatorOnly, &retro.FocusedItemID,
 		)
 		if err == nil && phaseTimerOverrides != nil {
 			_ = json.Unmarshal(phaseTimerOverrides, &retro.PhaseTimerOverrides)
 		}
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		retros = append(retros, &retro)
 	}
 
-	return retros, nil
+	return retros, total, rows.Err()
 }
 
 // Create creates a new retrospective
@@ -316,8 +575,9 @@ func (r *RetrospectiveRepository) Create(ctx context.Context, retro *models.Retr
 		INSERT INTO retrospectives (id, name, team_id, template_id, facilitator_id, status,
 		                            current_phase, max_votes_per_user, max_votes_per_item, anonymous_voting,
 		                            anonymous_items, allow_item_edit, allow_vote_change, phase_timer_overrides,
-		                            scheduled_at, session_type, lc_topic_timebox_seconds)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		                            scheduled_at, session_type, lc_topic_timebox_seconds, max_actions_per_retro,
+		                            auto_advance_on_timer_end, vote_limit_per_group, batch_vote_updates, open_access, allow_guests)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -344,7 +604,8 @@ func (r *RetrospectiveRepository) Create(ctx context.Context, retro *models.Retr
 		retro.ID, retro.Name, retro.TeamID, retro.TemplateID, retro.FacilitatorID,
 		retro.Status, retro.CurrentPhase, retro.MaxVotesPerUser, retro.MaxVotesPerItem, retro.AnonymousVoting,
 		retro.AnonymousItems, retro.AllowItemEdit, retro.AllowVoteChange, phaseTimerOverrides,
-		retro.ScheduledAt, retro.SessionType, retro.LCTopicTimeboxSeconds,
+		retro.ScheduledAt, retro.SessionType, retro.LCTopicTimeboxSeconds, retro.MaxActionsPerRetro,
+		retro.AutoAdvanceOnTimerEnd, retro.VoteLimitPerGroup, retro.BatchVoteUpdates, retro.OpenAccess, retro.AllowGuests,
 	).Scan(&retro.ID, &retro.CreatedAt, &retro.UpdatedAt)
 
 	if err != nil {
@@ -354,7 +615,124 @@ func (r *RetrospectiveRepository) Create(ctx context.Context, retro *models.Retr
 	return retro, nil
 }
 
-// Update updates a retrospective
+// ArchiveCompleted archives a team's completed retrospectives in a single statement.
+// Retros are matched by ended_at older than olderThan and/or by explicit IDs.
+// It returns the IDs of the archived retrospectives.
+func (r *RetrospectiveRepository) ArchiveCompleted(ctx context.Context, teamID uuid.UUID, olderThan *time.Time, retroIDs []uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		UPDATE retrospectives
+		SET status = 'archived', updated_at = NOW()
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL
+	`
+	args := []interface{}{teamID}
+
+	if olderThan != nil {
+		args = append(args, *olderThan)
+		query += fmt.Sprintf(" AND ended_at < $%d", len(args))
+	}
+	if len(retroIDs) > 0 {
+		args = append(args, retroIDs)
+		query += fmt.Sprintf(" AND id = ANY($%d)", len(args))
+	}
+
+	query += " RETURNING id"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// scheduledStartLockKey is the advisory lock that lets a single pod start scheduled retros at a time
+const scheduledStartLockKey int64 = 0x7265_7472_6f73_7461 // "retrosta"
+
+// StartScheduled moves draft retrospectives scheduled at or before the given time to active in
+// the waiting phase and returns their IDs. When another pod holds the advisory lock it does
+// nothing and returns no IDs; a retro that is already started is never matched again.
+func (r *RetrospectiveRepository) StartScheduled(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, scheduledStartLockKey).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE retrospectives
+		SET status = 'active', current_phase = 'waiting', started_at = NOW(), updated_at = NOW()
+		WHERE status = 'draft' AND deleted_at IS NULL AND scheduled_at IS NOT NULL AND scheduled_at <= $1
+		RETURNING id
+	`, before)
+	if err != nil {
+		return nil, err
+	}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ClaimReminders marks draft retrospectives scheduled after now and at or before the given
+// time as reminded and returns their IDs. The reminded_at check is re-evaluated under the row
+// lock, so when several pods claim at once each retro is returned to only one of them.
+func (r *RetrospectiveRepository) ClaimReminders(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE retrospectives
+		SET reminded_at = NOW()
+		WHERE status = 'draft' AND deleted_at IS NULL AND reminded_at IS NULL
+		  AND scheduled_at > NOW() AND scheduled_at <= $1
+		RETURNING id
+	`, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Update updates a retrospective if it has not changed since it was read, as told by its UpdatedAt,
+// and refreshes UpdatedAt. It returns ErrConcurrentModification otherwise.
 func (r *RetrospectiveRepository) Update(ctx context.Context, retro *models.Retrospective) error {
 	query := `
 		UPDATE retrospectives
@@ -362,8 +740,11 @@ func (r *RetrospectiveRepository) Update(ctx context.Context, retro *models.Retr
 		    max_votes_per_item = $6, anonymous_voting = $7, anonymous_items = $8,
 		    allow_item_edit = $9, allow_vote_change = $10, phase_timer_overrides = $11,
 		    facilitator_id = $12, started_at = $13, ended_at = $14,
-		    lc_current_topic_id = $15, updated_at = NOW()
-		WHERE id = $1
+		    lc_current_topic_id = $15, max_actions_per_retro = $16,
+		    auto_advance_on_timer_end = $17, vote_limit_per_group = $18,
+		    batch_vote_updates = $19, open_access = $20, allow_guests = $21, updated_at = NOW()
+		WHERE id = $1 AND updated_at = $22 AND deleted_at IS NULL
+		RETURNING updated_at
 	`
 
 	var phaseTimerOverrides []byte
@@ -371,13 +752,75 @@ func (r *RetrospectiveRepository) Update(ctx context.Context, retro *models.Retr
 		phaseTimerOverrides, _ = json.Marshal(retro.PhaseTimerOverrides)
 	}
 
-	_, err := r.pool.Exec(ctx, query,
+	err := r.pool.QueryRow(ctx, query,
 		retro.ID, retro.Name, retro.Status, retro.CurrentPhase,
 		retro.MaxVotesPerUser, retro.MaxVotesPerItem, retro.AnonymousVoting, retro.AnonymousItems,
 		retro.AllowItemEdit, retro.AllowVoteChange, phaseTimerOverrides, retro.FacilitatorID,
 		retro.StartedAt, retro.EndedAt,
-		retro.LCCurrentTopicID,
-	)
+		retro.LCCurrentTopicID, retro.MaxActionsPerRetro, retro.AutoAdvanceOnTimerEnd,
+		retro.VoteLimitPerGroup, retro.BatchVoteUpdates, retro.OpenAccess, retro.AllowGuests, retro.UpdatedAt,
+	).Scan(&retro.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrConcurrentModification
+	}
+	return err
+}
+
+// MarkStarted moves a draft retrospective to active at the waiting phase. It reports false
+// when the retro is no longer a draft, e.g. a concurrent start got there first.
+func (r *RetrospectiveRepository) MarkStarted(ctx context.Context, retroID uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE retrospectives
+		SET status = 'active', started_at = $2, current_phase = 'waiting', updated_at = NOW()
+		WHERE id = $1 AND status = 'draft' AND deleted_at IS NULL
+	`
+	return r.transition(ctx, query, retroID, at)
+}
+
+// MarkEnded completes a draft or active retrospective. It reports false when the retro was
+// already completed or archived.
+func (r *RetrospectiveRepository) MarkEnded(ctx context.Context, retroID uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE retrospectives
+		SET status = 'completed', ended_at = $2, updated_at = NOW()
+		WHERE id = $1 AND status IN ('draft', 'active') AND deleted_at IS NULL
+	`
+	return r.transition(ctx, query, retroID, at)
+}
+
+// MarkReopened moves a completed retrospective back to active. It reports false when the retro
+// is no longer completed.
+func (r *RetrospectiveRepository) MarkReopened(ctx context.Context, retroID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE retrospectives
+		SET status = 'active', ended_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'completed' AND deleted_at IS NULL
+	`
+	return r.transition(ctx, query, retroID)
+}
+
+// UpdateStatus moves a retrospective from one status to another. It reports false when the
+// retro is not in the from status anymore.
+func (r *RetrospectiveRepository) UpdateStatus(ctx context.Context, retroID uuid.UUID, from, to models.RetroStatus) (bool, error) {
+	query := `
+		UPDATE retrospectives SET status = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+	`
+	return r.transition(ctx, query, retroID, from, to)
+}
+
+func (r *RetrospectiveRepository) transition(ctx context.Context, query string, retroID uuid.UUID, args ...any) (bool, error) {
+	tag, err := r.pool.Exec(ctx, query, append([]any{retroID}, args...)...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UpdateLCCurrentTopic sets the Lean Coffee topic being discussed (nil clears it)
+func (r *RetrospectiveRepository) UpdateLCCurrentTopic(ctx context.Context, retroID uuid.UUID, topicID *uuid.UUID) error {
+	query := `UPDATE retrospectives SET lc_current_topic_id = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, retroID, topicID)
 	return err
 }
 
@@ -387,84 +830,537 @@ func (r *RetrospectiveRepository) UpdateTimer(ctx context.Context, retroID uuid.
 		UPDATE retrospectives
 		SET timer_started_at = $2, timer_duration_seconds = $3,
 		    timer_paused_at = $4, timer_remaining_seconds = $5, updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	_, err := r.pool.Exec(ctx, query, retroID, startedAt, durationSeconds, pausedAt, remainingSeconds)
-	return err
-}
+	_, err := r.pool.Exec(ctx, query, retroID, startedAt, durationSeconds, pausedAt, remainingSeconds)
+	return err
+}
+
+// UpdateShare stores the retro's guest share link; a nil shareID revokes it
+func (r *RetrospectiveRepository) UpdateShare(ctx context.Context, retroID uuid.UUID, shareID *uuid.UUID, expiresAt *time.Time, spectatorOnly, allowGuests bool) error {
+	query := `
+		UPDATE retrospectives
+		SET share_id = $2, share_expires_at = $3, share_spectator_only = $4, allow_guests = $5, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.pool.Exec(ctx, query, retroID, shareID, expiresAt, spectatorOnly, allowGuests)
+	return err
+}
+
+// UpdatePhase updates the current phase and clears the focused item
+func (r *RetrospectiveRepository) UpdatePhase(ctx context.Context, retroID uuid.UUID, phase models.RetroPhase) error {
+	query := `UPDATE retrospectives SET current_phase = $2, focused_item_id = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, retroID, phase)
+	return err
+}
+
+// UpdateFocusedItem sets the item highlighted for everyone (nil clears it)
+func (r *RetrospectiveRepository) UpdateFocusedItem(ctx context.Context, retroID uuid.UUID, itemID *uuid.UUID) error {
+	query := `UPDATE retrospectives SET focused_item_id = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, retroID, itemID)
+	return err
+}
+
+// UpdateLCQueueOrder updates the manual Lean Coffee queue order (nil clears it)
+func (r *RetrospectiveRepository) UpdateLCQueueOrder(ctx context.Context, retroID uuid.UUID, topicIDs []uuid.UUID) error {
+	query := `UPDATE retrospectives SET lc_queue_order = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, retroID, topicIDs)
+	return err
+}
+
+// SoftDelete hides a retrospective and keeps its items, votes, actions and stats history
+func (r *RetrospectiveRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE retrospectives SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Restore brings back a soft-deleted retrospective
+func (r *RetrospectiveRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE retrospectives SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindTeamID returns the team of a retrospective, including soft-deleted ones
+func (r *RetrospectiveRepository) FindTeamID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	var teamID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT team_id FROM retrospectives WHERE id = $1`, id).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrNotFound
+	}
+	return teamID, err
+}
+
+// FindAccess returns the team of a retrospective and whether it is open to non-members,
+// including soft-deleted ones
+func (r *RetrospectiveRepository) FindAccess(ctx context.Context, id uuid.UUID) (uuid.UUID, bool, error) {
+	var teamID uuid.UUID
+	var openAccess bool
+	err := r.pool.QueryRow(ctx, `SELECT team_id, open_access FROM retrospectives WHERE id = $1`, id).Scan(&teamID, &openAccess)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, false, ErrNotFound
+	}
+	return teamID, openAccess, err
+}
+
+// CountContents counts the items, votes and action items of a retrospective, soft-deleted or not
+func (r *RetrospectiveRepository) CountContents(ctx context.Context, id uuid.UUID) (*models.RetroContentCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM items WHERE retro_id = $1),
+			(SELECT COUNT(*) FROM votes v JOIN items i ON i.id = v.item_id WHERE i.retro_id = $1),
+			(SELECT COUNT(*) FROM action_items WHERE retro_id = $1)
+	`
+	counts := &models.RetroContentCounts{}
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&counts.Items, &counts.Votes, &counts.Actions); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// Delete permanently deletes a retrospective, soft-deleted or not, with everything in it
+func (r *RetrospectiveRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM retrospectives WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+// ItemRepository handles item database operations
+type ItemRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewItemRepository creates a new item repository
+func NewItemRepository(pool *pgxpool.Pool) *ItemRepository {
+	return &ItemRepository{pool: pool}
+}
+
+// FindByID finds an item by ID
+func (r *ItemRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Item, error) {
+	query := `
+		SELECT id, retro_id, column_id, content, author_id, group_id, position, is_guest, discussed, created_at, updated_at
+		FROM items WHERE id = $1
+	`
+
+	var item models.Item
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&item.ID, &item.RetroID, &item.ColumnID, &item.Content, &item.AuthorID,
+		&item.GroupID, &item.Position, &item.IsGuest, &item.Discussed, &item.CreatedAt, &item.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// ListByRetro lists items for a retrospective
+func (r *ItemRepository) ListByRetro(ctx context.Context, retroID uuid.UUID) ([]*models.Item, error) {
+	return listItems(ctx, r.pool, retroID)
+}
+
+// itemQuerier runs queries on the pool or in a transaction
+type itemQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// listItems lists the items of a retrospective ordered by column then position
+func listItems(ctx context.Context, q itemQuerier, retroID uuid.UUID) ([]*models.Item, error) {
+	query := `
+		SELECT i.id, i.retro_id, i.column_id, i.content, i.author_id, i.group_id, i.position, i.is_guest,
+		       i.discussed, i.created_at, i.updated_at, COALESCE(COUNT(v.id), 0) as vote_count
+		FROM items i
+		LEFT JOIN votes v ON i.id = v.item_id
+		WHERE i.retro_id = $1
+		GROUP BY i.id
+		ORDER BY i.column_id, i.position
+	`
+
+	rows, err := q.Query(ctx, query, retroID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.Item
+	for rows.Next() {
+		var item models.Item
+		err := rows.Scan(
+			&item.ID, &item.RetroID, &item.ColumnID, &item.Content, &item.AuthorID,
+			&item.GroupID, &item.Position, &item.IsGuest, &item.Discussed, &item.CreatedAt, &item.UpdatedAt, &item.VoteCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	setGroupVoteCounts(items)
+	return items, nil
+}
+
+// ListByRetroTree lists the top-level items of a retrospective with grouped items nested under their parent
+func (r *ItemRepository) ListByRetroTree(ctx context.Context, retroID uuid.UUID) ([]*models.Item, error) {
+	items, err := r.ListByRetro(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	return buildItemTree(items), nil
+}
+
+// groupItems links items to their group parent, keeping the input order among siblings.
+// An item whose parent is missing or whose parent chain leads back to itself is kept at the top level.
+func groupItems(items []*models.Item) ([]*models.Item, map[uuid.UUID][]*models.Item) {
+	byID := make(map[uuid.UUID]*models.Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	var roots []*models.Item
+	children := make(map[uuid.UUID][]*models.Item)
+	for _, item := range items {
+		parent := validItemParent(item, byID)
+		if parent == nil {
+			roots = append(roots, item)
+			continue
+		}
+		children[parent.ID] = append(children[parent.ID], item)
+	}
+	return roots, children
+}
+
+// buildItemTree nests items under their group parent and returns the top-level items
+func buildItemTree(items []*models.Item) []*models.Item {
+	roots, children := groupItems(items)
+	for _, item := range items {
+		item.Children = children[item.ID]
+	}
+	for _, root := range roots {
+		sumGroupVotes(root, children)
+	}
+	return roots
+}
+
+// setGroupVoteCounts sets GroupVoteCount on a flat list of items
+func setGroupVoteCounts(items []*models.Item) {
+	roots, children := groupItems(items)
+	for _, root := range roots {
+		sumGroupVotes(root, children)
+	}
+}
+
+// validItemParent returns the item's group parent, or nil if it has none or grouping it would form a cycle
+func validItemParent(item *models.Item, byID map[uuid.UUID]*models.Item) *models.Item {
+	if item.GroupID == nil {
+		return nil
+	}
+	parent, ok := byID[*item.GroupID]
+	if !ok || parent.ID == item.ID {
+		return nil
+	}
+
+	seen := map[uuid.UUID]bool{parent.ID: true}
+	for ancestor := parent; ancestor.GroupID != nil; {
+		next, ok := byID[*ancestor.GroupID]
+		if !ok {
+			break
+		}
+		if next.ID == item.ID {
+			return nil
+		}
+		if seen[next.ID] {
+			// Cycle above the item that does not include it: the cycle members become roots
+			break
+		}
+		seen[next.ID] = true
+		ancestor = next
+	}
+	return parent
+}
+
+// sumGroupVotes sets GroupVoteCount on the item and its descendants and returns the item's total
+func sumGroupVotes(item *models.Item, children map[uuid.UUID][]*models.Item) int {
+	total := item.VoteCount
+	for _, child := range children[item.ID] {
+		total += sumGroupVotes(child, children)
+	}
+	item.GroupVoteCount = total
+	return total
+}
+
+// collectGroupIDs returns the IDs of the item and all its descendants
+func collectGroupIDs(item *models.Item, children map[uuid.UUID][]*models.Item) []uuid.UUID {
+	ids := []uuid.UUID{item.ID}
+	for _, child := range children[item.ID] {
+		ids = append(ids, collectGroupIDs(child, children)...)
+	}
+	return ids
+}
+
+// ListGroupItemIDs returns the IDs of every item in the top-level group containing the item
+func (r *ItemRepository) ListGroupItemIDs(ctx context.Context, retroID, itemID uuid.UUID) ([]uuid.UUID, error) {
+	items, err := r.ListByRetro(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, children := groupItems(items)
+	for _, root := range roots {
+		ids := collectGroupIDs(root, children)
+		for _, id := range ids {
+			if id == itemID {
+				return ids, nil
+			}
+		}
+	}
+	return []uuid.UUID{itemID}, nil
+}
+
+// MoveGroup moves an item and all its grouped descendants to a column, inserting them as one
+// block at the given position. Both the source and target columns are renumbered so positions
+// stay contiguous. It returns every item whose column or position changed.
+func (r *ItemRepository) MoveGroup(ctx context.Context, itemID uuid.UUID, columnID string, position int) ([]*models.Item, error) {
+	item, err := r.FindByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the columns the move touches, then plan it again under their locks: the group or the
+	// target column may have changed in between
+	items, err := r.ListByRetro(ctx, item.RetroID)
+	if err != nil {
+		return nil, err
+	}
+	columns := groupMoveColumns(items, itemID, columnID)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Columns are locked in a stable order so two moves cannot wait on each other
+	locked := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if err := lockColumn(ctx, tx, item.RetroID, col); err != nil {
+			return nil, err
+		}
+		locked[col] = true
+	}
+
+	items, err = listItems(ctx, tx, item.RetroID)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range groupMoveColumns(items, itemID, columnID) {
+		if locked[col] {
+			continue
+		}
+		if err := lockColumn(ctx, tx, item.RetroID, col); err != nil {
+			return nil, err
+		}
+	}
+
+	moved := planGroupMove(items, itemID, columnID, position)
+	if len(moved) == 0 {
+		return moved, nil
+	}
+
+	query := `UPDATE items SET column_id = $2, position = $3, updated_at = NOW() WHERE id = $1`
+	for _, m := range moved {
+		if _, err := tx.Exec(ctx, query, m.ID, m.ColumnID, m.Position); err != nil {
+			return nil, err
+		}
+	}
 
-// UpdatePhase updates the current phase
-func (r *RetrospectiveRepository) UpdatePhase(ctx context.Context, retroID uuid.UUID, phase models.RetroPhase) error {
-	query := `UPDATE retrospectives SET current_phase = $2, updated_at = NOW() WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, retroID, phase)
-	return err
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return moved, nil
 }
 
-// Delete deletes a retrospective
-func (r *RetrospectiveRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM retrospectives WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
-	return err
-}
+// groupMoveColumns returns, sorted, the target column and the columns holding the item's group
+func groupMoveColumns(items []*models.Item, itemID uuid.UUID, columnID string) []string {
+	columns := []string{columnID}
+	seen := map[string]bool{columnID: true}
 
-// ItemRepository handles item database operations
-type ItemRepository struct {
-	pool *pgxpool.Pool
+	byID := make(map[uuid.UUID]*models.Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	if target, ok := byID[itemID]; ok {
+		_, children := groupItems(items)
+		for _, id := range collectGroupIDs(target, children) {
+			if col := byID[id].ColumnID; !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
 }
 
-// NewItemRepository creates a new item repository
-func NewItemRepository(pool *pgxpool.Pool) *ItemRepository {
-	return &ItemRepository{pool: pool}
-}
+// planGroupMove computes the new column and position of every item after moving the item's
+// group to the target column. items must be ordered by column then position, as ListByRetro
+// returns them. The moved block keeps the item first, then its descendants in their current order.
+func planGroupMove(items []*models.Item, itemID uuid.UUID, columnID string, position int) []*models.Item {
+	var target *models.Item
+	for _, item := range items {
+		if item.ID == itemID {
+			target = item
+			break
+		}
+	}
+	if target == nil {
+		return nil
+	}
 
-// FindByID finds an item by ID
-func (r *ItemRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Item, error) {
-	query := `
-		SELECT id, retro_id, column_id, content, author_id, group_id, position, created_at, updated_at
-		FROM items WHERE id = $1
-	`
+	_, children := groupItems(items)
+	inGroup := make(map[uuid.UUID]bool)
+	for _, id := range collectGroupIDs(target, children) {
+		inGroup[id] = true
+	}
 
-	var item models.Item
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&item.ID, &item.RetroID, &item.ColumnID, &item.Content, &item.AuthorID,
-		&item.GroupID, &item.Position, &item.CreatedAt, &item.UpdatedAt,
-	)
+	block := []*models.Item{target}
+	for _, item := range items {
+		if inGroup[item.ID] && item.ID != itemID {
+			block = append(block, item)
+		}
+	}
 
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrNotFound
+	// Remaining items per column, in board order
+	columns := make(map[string][]*models.Item)
+	var columnOrder []string
+	touched := map[string]bool{columnID: true}
+	for _, item := range items {
+		if _, ok := columns[item.ColumnID]; !ok {
+			columnOrder = append(columnOrder, item.ColumnID)
+			columns[item.ColumnID] = nil
+		}
+		if inGroup[item.ID] {
+			touched[item.ColumnID] = true
+			continue
+		}
+		columns[item.ColumnID] = append(columns[item.ColumnID], item)
+	}
+	if _, ok := columns[columnID]; !ok {
+		columnOrder = append(columnOrder, columnID)
+	}
+
+	remaining := columns[columnID]
+	if position < 0 {
+		position = 0
+	}
+	if position > len(remaining) {
+		position = len(remaining)
+	}
+	reordered := make([]*models.Item, 0, len(remaining)+len(block))
+	reordered = append(reordered, remaining[:position]...)
+	reordered = append(reordered, block...)
+	reordered = append(reordered, remaining[position:]...)
+	columns[columnID] = reordered
+
+	var changed []*models.Item
+	for _, col := range columnOrder {
+		if !touched[col] {
+			continue
 		}
+		for i, item := range columns[col] {
+			if item.ColumnID == col && item.Position == i {
+				continue
+			}
+			item.ColumnID = col
+			item.Position = i
+			changed = append(changed, item)
+		}
+	}
+	return changed
+}
+
+// GetGroupedVoteSummary returns the vote total of every top-level item, including the votes of its nested children
+func (r *ItemRepository) GetGroupedVoteSummary(ctx context.Context, retroID uuid.UUID) (map[uuid.UUID]int, error) {
+	items, err := r.ListByRetro(ctx, retroID)
+	if err != nil {
 		return nil, err
 	}
 
-	return &item, nil
+	roots, _ := groupItems(items)
+	summary := make(map[uuid.UUID]int, len(roots))
+	for _, root := range roots {
+		summary[root.ID] = root.GroupVoteCount
+	}
+	return summary, nil
 }
 
-// ListByRetro lists items for a retrospective
-func (r *ItemRepository) ListByRetro(ctx context.Context, retroID uuid.UUID) ([]*models.Item, error) {
+// likeEscaper escapes LIKE wildcards so user input is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search lists the items of a retrospective matching the filter
+func (r *ItemRepository) Search(ctx context.Context, retroID uuid.UUID, filter *models.ItemFilter) ([]*models.Item, error) {
 	query := `
-		SELECT i.id, i.retro_id, i.column_id, i.content, i.author_id, i.group_id, i.position,
-		       i.created_at, i.updated_at, COALESCE(COUNT(v.id), 0) as vote_count
+		SELECT i.id, i.retro_id, i.column_id, i.content, i.author_id, i.group_id, i.position, i.is_guest,
+		       i.discussed, i.created_at, i.updated_at, COALESCE(COUNT(v.id), 0) as vote_count
 		FROM items i
 		LEFT JOIN votes v ON i.id = v.item_id
 		WHERE i.retro_id = $1
-		GROUP BY i.id
-		ORDER BY i.column_id, i.position
 	`
+	args := []interface{}{retroID}
 
-	rows, err := r.pool.Query(ctx, query, retroID)
+	if filter.Query != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Query)+"%")
+		query += fmt.Sprintf(" AND i.content ILIKE $%d", len(args))
+	}
+	if filter.ColumnID != "" {
+		args = append(args, filter.ColumnID)
+		query += fmt.Sprintf(" AND i.column_id = $%d", len(args))
+	}
+
+	query += " GROUP BY i.id"
+
+	if filter.MinVotes > 0 {
+		args = append(args, filter.MinVotes)
+		query += fmt.Sprintf(" HAVING COUNT(v.id) >= $%d", len(args))
+	}
+
+	switch filter.Sort {
+	case models.ItemSortVotes:
+		query += " ORDER BY vote_count DESC, i.created_at"
+	case models.ItemSortCreated:
+		query += " ORDER BY i.created_at"
+	default:
+		query += " ORDER BY i.column_id, i.position"
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var items []*models.Item
+	items := []*models.Item{}
 	for rows.Next() {
 		var item models.Item
 		err := rows.Scan(
 			&item.ID, &item.RetroID, &item.ColumnID, &item.Content, &item.AuthorID,
-			&item.GroupID, &item.Position, &item.CreatedAt, &item.UpdatedAt, &item.VoteCount,
+			&item.GroupID, &item.Position, &item.IsGuest, &item.Discussed, &item.CreatedAt, &item.UpdatedAt, &item.VoteCount,
 		)
 		if err != nil {
 			return nil, err
@@ -472,32 +1368,117 @@ func (r *ItemRepository) ListByRetro(ctx context.Context, retroID uuid.UUID) ([]
 		items = append(items, &item)
 	}
 
-	return items, nil
+	return items, rows.Err()
+}
+
+// lockColumn serializes the position changes of a column until the transaction ends
+func lockColumn(ctx context.Context, tx pgx.Tx, retroID uuid.UUID, columnID string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::text || '/' || $2, 0))`, retroID, columnID)
+	return err
 }
 
-// Create creates a new item
+// Create creates a new item after the last item of its column, marked as a guest's when its
+// author is a guest. The position is computed under the column's lock, so concurrent creates
+// never share one.
 func (r *ItemRepository) Create(ctx context.Context, item *models.Item) (*models.Item, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockColumn(ctx, tx, item.RetroID, item.ColumnID); err != nil {
+		return nil, err
+	}
+
 	query := `
-		INSERT INTO items (id, retro_id, column_id, content, author_id, position)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		INSERT INTO items (id, retro_id, column_id, content, author_id, position, is_guest)
+		VALUES ($1, $2, $3, $4, $5,
+		        (SELECT COALESCE(MAX(position), -1) + 1 FROM items WHERE retro_id = $2 AND column_id = $3),
+		        (SELECT is_guest FROM users WHERE id = $5))
+		RETURNING id, position, is_guest, created_at, updated_at
 	`
 
 	if item.ID == uuid.Nil {
 		item.ID = uuid.New()
 	}
 
-	err := r.pool.QueryRow(ctx, query,
-		item.ID, item.RetroID, item.ColumnID, item.Content, item.AuthorID, item.Position,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
-
+	err = tx.QueryRow(ctx, query,
+		item.ID, item.RetroID, item.ColumnID, item.Content, item.AuthorID,
+	).Scan(&item.ID, &item.Position, &item.IsGuest, &item.CreatedAt, &item.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
+// CreateBatch creates the items in one transaction, each placed after the last item of its
+// column in the order given. The columns are compacted first, in the same transaction, so the
+// existing and new items end up numbered 0..N-1.
+func (r *ItemRepository) CreateBatch(ctx context.Context, items []*models.Item) ([]*models.Item, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Columns are locked in a stable order so two batches cannot wait on each other
+	var columns []string
+	locked := make(map[string]bool)
+	for _, item := range items {
+		if !locked[item.ColumnID] {
+			locked[item.ColumnID] = true
+			columns = append(columns, item.ColumnID)
+		}
+	}
+	sort.Strings(columns)
+	for _, columnID := range columns {
+		if err := lockColumn(ctx, tx, items[0].RetroID, columnID); err != nil {
+			return nil, err
+		}
+		if err := reindexColumn(ctx, tx, items[0].RetroID, columnID); err != nil {
+			return nil, err
+		}
+	}
+
+	positionQuery := `SELECT COALESCE(MAX(position), -1) + 1 FROM items WHERE retro_id = $1 AND column_id = $2`
+	query := `
+		INSERT INTO items (id, retro_id, column_id, content, author_id, position, is_guest)
+		VALUES ($1, $2, $3, $4, $5, $6, (SELECT is_guest FROM users WHERE id = $5))
+		RETURNING id, is_guest, created_at, updated_at
+	`
+	next := make(map[string]int)
+	for _, item := range items {
+		position, ok := next[item.ColumnID]
+		if !ok {
+			if err := tx.QueryRow(ctx, positionQuery, item.RetroID, item.ColumnID).Scan(&position); err != nil {
+				return nil, err
+			}
+		}
+		item.Position = position
+		next[item.ColumnID] = position + 1
+
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+		err := tx.QueryRow(ctx, query,
+			item.ID, item.RetroID, item.ColumnID, item.Content, item.AuthorID, item.Position,
+		).Scan(&item.ID, &item.IsGuest, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 // Update updates an item
 func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
 	query := `
@@ -510,6 +1491,17 @@ func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
 	return err
 }
 
+// ToggleDiscussed flips whether the item was discussed and returns the new value
+func (r *ItemRepository) ToggleDiscussed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE items SET discussed = NOT discussed, updated_at = NOW() WHERE id = $1 RETURNING discussed`
+	var discussed bool
+	err := r.pool.QueryRow(ctx, query, id).Scan(&discussed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	return discussed, err
+}
+
 // Delete deletes an item
 func (r *ItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM items WHERE id = $1`
@@ -517,12 +1509,36 @@ func (r *ItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-// GetNextPosition gets the next position for a new item in a column
-func (r *ItemRepository) GetNextPosition(ctx context.Context, retroID uuid.UUID, columnID string) (int, error) {
-	query := `SELECT COALESCE(MAX(position), -1) + 1 FROM items WHERE retro_id = $1 AND column_id = $2`
-	var position int
-	err := r.pool.QueryRow(ctx, query, retroID, columnID).Scan(&position)
-	return position, err
+// ReindexColumn renumbers the positions of a column 0..N-1, keeping the items' order.
+// Moves and deletes leave gaps and ties behind otherwise.
+func (r *ItemRepository) ReindexColumn(ctx context.Context, retroID uuid.UUID, columnID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockColumn(ctx, tx, retroID, columnID); err != nil {
+		return err
+	}
+	if err := reindexColumn(ctx, tx, retroID, columnID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// reindexColumn renumbers a column the caller already locked
+func reindexColumn(ctx context.Context, tx pgx.Tx, retroID uuid.UUID, columnID string) error {
+	query := `
+		UPDATE items SET position = ranked.position, updated_at = NOW()
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, created_at, id) - 1 AS position
+			FROM items WHERE retro_id = $1 AND column_id = $2
+		) ranked
+		WHERE items.id = ranked.id AND items.position <> ranked.position
+	`
+	_, err := tx.Exec(ctx, query, retroID, columnID)
+	return err
 }
 
 // VoteRepository handles vote database operations
@@ -535,19 +1551,19 @@ func NewVoteRepository(pool *pgxpool.Pool) *VoteRepository {
 	return &VoteRepository{pool: pool}
 }
 
-// Create creates a new vote
+// Create creates a new vote, marked as a guest's when the voter is a guest
 func (r *VoteRepository) Create(ctx context.Context, vote *models.Vote) (*models.Vote, error) {
 	query := `
-		INSERT INTO votes (id, item_id, user_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at
+		INSERT INTO votes (id, item_id, user_id, is_guest)
+		VALUES ($1, $2, $3, (SELECT is_guest FROM users WHERE id = $3))
+		RETURNING id, is_guest, created_at
 	`
 
 	if vote.ID == uuid.Nil {
 		vote.ID = uuid.New()
 	}
 
-	err := r.pool.QueryRow(ctx, query, vote.ID, vote.ItemID, vote.UserID).Scan(&vote.ID, &vote.CreatedAt)
+	err := r.pool.QueryRow(ctx, query, vote.ID, vote.ItemID, vote.UserID).Scan(&vote.ID, &vote.IsGuest, &vote.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -591,6 +1607,49 @@ func (r *VoteRepository) CountByUserOnItem(ctx context.Context, itemID, userID u
 	return count, err
 }
 
+// CountByItem counts all votes on an item
+func (r *VoteRepository) CountByItem(ctx context.Context, itemID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM votes WHERE item_id = $1`
+	var count int
+	err := r.pool.QueryRow(ctx, query, itemID).Scan(&count)
+	return count, err
+}
+
+// CountByItems counts all votes on each of the items; items without votes are reported with 0
+func (r *VoteRepository) CountByItems(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT i.id, COUNT(v.item_id)
+		FROM items i
+		LEFT JOIN votes v ON v.item_id = i.id
+		WHERE i.id = ANY($1)
+		GROUP BY i.id
+	`
+	rows, err := r.pool.Query(ctx, query, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int, len(itemIDs))
+	for rows.Next() {
+		var itemID uuid.UUID
+		var count int
+		if err := rows.Scan(&itemID, &count); err != nil {
+			return nil, err
+		}
+		counts[itemID] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountByUserOnItems counts a user's votes across a set of items
+func (r *VoteRepository) CountByUserOnItems(ctx context.Context, itemIDs []uuid.UUID, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM votes WHERE item_id = ANY($1) AND user_id = $2`
+	var count int
+	err := r.pool.QueryRow(ctx, query, itemIDs, userID).Scan(&count)
+	return count, err
+}
+
 // HasVoted checks if a user has voted on an item
 func (r *VoteRepository) HasVoted(ctx context.Context, itemID, userID uuid.UUID) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM votes WHERE item_id = $1 AND user_id = $2)`
@@ -647,7 +1706,7 @@ func (r *ActionItemRepository) FindByID(ctx context.Context, id uuid.UUID) (*mod
 	query := `
 		SELECT id, retro_id, item_id, title, description, assignee_id, due_date,
 		       is_completed, status, completed_at, priority, external_id, external_url,
-		       created_by, created_at, updated_at
+		       created_by, created_at, updated_at, carried_from_id, origin_retro_id
 		FROM action_items WHERE id = $1
 	`
 
@@ -656,7 +1715,7 @@ func (r *ActionItemRepository) FindByID(ctx context.Context, id uuid.UUID) (*mod
 		&action.ID, &action.RetroID, &action.ItemID, &action.Title, &action.Description,
 		&action.AssigneeID, &action.DueDate, &action.IsCompleted, &action.Status, &action.CompletedAt,
 		&action.Priority, &action.ExternalID, &action.ExternalURL, &action.CreatedBy,
-		&action.CreatedAt, &action.UpdatedAt,
+		&action.CreatedAt, &action.UpdatedAt, &action.CarriedFromID, &action.OriginRetroID,
 	)
 
 	if err != nil {
@@ -674,7 +1733,7 @@ func (r *ActionItemRepository) ListByRetro(ctx context.Context, retroID uuid.UUI
 	query := `
 		SELECT id, retro_id, item_id, title, description, assignee_id, due_date,
 		       is_completed, status, completed_at, priority, external_id, external_url,
-		       created_by, created_at, updated_at
+		       created_by, created_at, updated_at, carried_from_id, origin_retro_id
 		FROM action_items WHERE retro_id = $1
 		ORDER BY priority DESC, created_at
 	`
@@ -692,7 +1751,7 @@ func (r *ActionItemRepository) ListByRetro(ctx context.Context, retroID uuid.UUI
 			&action.ID, &action.RetroID, &action.ItemID, &action.Title, &action.Description,
 			&action.AssigneeID, &action.DueDate, &action.IsCompleted, &action.Status, &action.CompletedAt,
 			&action.Priority, &action.ExternalID, &action.ExternalURL, &action.CreatedBy,
-			&action.CreatedAt, &action.UpdatedAt,
+			&action.CreatedAt, &action.UpdatedAt, &action.CarriedFromID, &action.OriginRetroID,
 		)
 		if err != nil {
 			return nil, err
@@ -703,8 +1762,52 @@ func (r *ActionItemRepository) ListByRetro(ctx context.Context, retroID uuid.UUI
 	return actions, nil
 }
 
-// Create creates a new action item
+// Create creates a new action item, unless the retro already has its maximum of actions or
+// the team its maximum of open actions (ErrLimitReached). The team row stays locked from the
+// counts to the insert, so concurrent creates cannot both take the last slot.
 func (r *ActionItemRepository) Create(ctx context.Context, action *models.ActionItem) (*models.ActionItem, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var teamID uuid.UUID
+	var maxPerRetro, maxOpen *int
+	err = tx.QueryRow(ctx, `
+		SELECT t.id, r.max_actions_per_retro, t.max_open_actions
+		FROM retrospectives r
+		JOIN teams t ON t.id = r.team_id
+		WHERE r.id = $1 AND r.deleted_at IS NULL
+		FOR NO KEY UPDATE OF t
+	`, action.RetroID).Scan(&teamID, &maxPerRetro, &maxOpen)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, limit := range []struct {
+		max   *int
+		query string
+		arg   uuid.UUID
+	}{
+		{maxPerRetro, countByRetroQuery, action.RetroID},
+		{maxOpen, countOpenByTeamQuery, teamID},
+	} {
+		if limit.max == nil {
+			continue
+		}
+		var count int
+		if err := tx.QueryRow(ctx, limit.query, limit.arg).Scan(&count); err != nil {
+			return nil, err
+		}
+		if count >= *limit.max {
+			return nil, ErrLimitReached
+		}
+	}
+
 	query := `
 		INSERT INTO action_items (id, retro_id, item_id, title, description, assignee_id,
 		                          due_date, priority, status, created_by)
@@ -716,18 +1819,84 @@ func (r *ActionItemRepository) Create(ctx context.Context, action *models.Action
 		action.ID = uuid.New()
 	}
 
-	err := r.pool.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		action.ID, action.RetroID, action.ItemID, action.Title, action.Description,
 		action.AssigneeID, action.DueDate, action.Priority, action.Status, action.CreatedBy,
 	).Scan(&action.ID, &action.CreatedAt, &action.UpdatedAt)
-
 	if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return action, nil
 }
 
+// CarryOver copies the unfinished actions of the team's most recently completed retrospective
+// into the retrospective toRetroID and returns the copies. Each action is carried over at most
+// once, so actions already continued in another retro are skipped; the copies keep the retro
+// the action was first raised in.
+func (r *ActionItemRepository) CarryOver(ctx context.Context, teamID, toRetroID uuid.UUID) ([]*models.ActionItem, error) {
+	source := `
+		SELECT id FROM retrospectives
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL AND id <> $2
+		ORDER BY ended_at DESC NULLS LAST, created_at DESC
+		LIMIT 1
+	`
+	return r.carryOver(ctx, source, teamID, toRetroID)
+}
+
+// CarryOverFrom copies the unfinished actions of the retrospective fromRetroID into toRetroID,
+// like CarryOver does with the team's last completed retro
+func (r *ActionItemRepository) CarryOverFrom(ctx context.Context, fromRetroID, toRetroID uuid.UUID) ([]*models.ActionItem, error) {
+	source := `SELECT id FROM retrospectives WHERE id = $1 AND id <> $2 AND deleted_at IS NULL`
+	return r.carryOver(ctx, source, fromRetroID, toRetroID)
+}
+
+// carryOver copies the unfinished actions of the retro selected by the source query, which
+// takes $1 and the target retro as $2
+func (r *ActionItemRepository) carryOver(ctx context.Context, source string, sourceArg, toRetroID uuid.UUID) ([]*models.ActionItem, error) {
+	query := `
+		WITH source AS (` + source + `)
+		INSERT INTO action_items (retro_id, title, description, assignee_id, due_date, priority, status,
+		                          external_id, external_url, created_by, carried_from_id, origin_retro_id)
+		SELECT $2, ai.title, ai.description, ai.assignee_id, ai.due_date, ai.priority, ai.status,
+		       ai.external_id, ai.external_url, ai.created_by, ai.id, COALESCE(ai.origin_retro_id, ai.retro_id)
+		FROM action_items ai
+		JOIN source ON source.id = ai.retro_id
+		WHERE ai.is_completed = false
+		ORDER BY ai.priority DESC, ai.created_at
+		ON CONFLICT (carried_from_id) WHERE carried_from_id IS NOT NULL DO NOTHING
+		RETURNING id, retro_id, item_id, title, description, assignee_id, due_date,
+		          is_completed, status, completed_at, priority, external_id, external_url,
+		          created_by, created_at, updated_at, carried_from_id, origin_retro_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, sourceArg, toRetroID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []*models.ActionItem{}
+	for rows.Next() {
+		var action models.ActionItem
+		err := rows.Scan(
+			&action.ID, &action.RetroID, &action.ItemID, &action.Title, &action.Description,
+			&action.AssigneeID, &action.DueDate, &action.IsCompleted, &action.Status, &action.CompletedAt,
+			&action.Priority, &action.ExternalID, &action.ExternalURL, &action.CreatedBy,
+			&action.CreatedAt, &action.UpdatedAt, &action.CarriedFromID, &action.OriginRetroID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, &action)
+	}
+
+	return actions, rows.Err()
+}
+
 // Update updates an action item
 func (r *ActionItemRepository) Update(ctx context.Context, action *models.ActionItem) error {
 	query := `
@@ -746,6 +1915,25 @@ func (r *ActionItemRepository) Update(ctx context.Context, action *models.Action
 	return err
 }
 
+// SetCompleted marks an action item completed (status done) or not, and reports whether this
+// changed it. Of concurrent calls making the same change, only one reports it.
+func (r *ActionItemRepository) SetCompleted(ctx context.Context, id uuid.UUID, completed bool) (bool, error) {
+	query := `
+		UPDATE action_items
+		SET is_completed = $2::boolean,
+		    completed_at = CASE WHEN $2::boolean THEN NOW() END,
+		    status = CASE WHEN $2::boolean THEN 'done' WHEN status = 'done' THEN 'todo' ELSE status END,
+		    updated_at = NOW()
+		WHERE id = $1 AND is_completed IS DISTINCT FROM $2::boolean
+	`
+
+	tag, err := r.pool.Exec(ctx, query, id, completed)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 // Delete deletes an action item
 func (r *ActionItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM action_items WHERE id = $1`
@@ -753,18 +1941,112 @@ func (r *ActionItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-// ListByTeam lists all action items for a team's completed retrospectives
+const countByRetroQuery = `SELECT COUNT(*) FROM action_items WHERE retro_id = $1 AND carried_from_id IS NULL`
+
+const countOpenByTeamQuery = `
+	SELECT COUNT(*)
+	FROM action_items ai
+	JOIN retrospectives r ON r.id = ai.retro_id
+	WHERE r.team_id = $1 AND r.deleted_at IS NULL AND ai.is_completed = false
+	  AND NOT EXISTS (
+	      SELECT 1 FROM action_items c
+	      JOIN retrospectives cr ON cr.id = c.retro_id
+	      WHERE c.carried_from_id = ai.id AND cr.deleted_at IS NULL
+	  )
+`
+
+// CountByRetro counts the action items raised in a retrospective, leaving out the carried over ones
+func (r *ActionItemRepository) CountByRetro(ctx context.Context, retroID uuid.UUID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, countByRetroQuery, retroID).Scan(&count)
+	return count, err
+}
+
+// CountOpenByTeam counts the not yet completed action items across a team's retrospectives.
+// An action carried over is counted once, through its copy.
+func (r *ActionItemRepository) CountOpenByTeam(ctx context.Context, teamID uuid.UUID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, countOpenByTeamQuery, teamID).Scan(&count)
+	return count, err
+}
+
+// ListByAssignee lists the action items assigned to the user in the teams they belong to,
+// with their retro and team names. An action carried over is listed once, through its copy.
+func (r *ActionItemRepository) ListByAssignee(ctx context.Context, userID uuid.UUID, filter *models.ActionListFilter) ([]*models.ActionItem, error) {
+	query := `
+		SELECT ai.id, ai.retro_id, ai.item_id, ai.title, ai.description, ai.assignee_id, ai.due_date,
+		       ai.is_completed, ai.status, ai.completed_at, ai.priority, ai.external_id, ai.external_url,
+		       ai.created_by, ai.created_at, ai.updated_at, ai.carried_from_id, ai.origin_retro_id,
+		       r.name as retro_name,
+		       t.name as team_name
+		FROM action_items ai
+		JOIN retrospectives r ON r.id = ai.retro_id
+		JOIN teams t ON t.id = r.team_id
+		JOIN team_members tm ON tm.team_id = r.team_id AND tm.user_id = $1
+		WHERE ai.assignee_id = $1 AND r.deleted_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM action_items c
+		      JOIN retrospectives cr ON cr.id = c.retro_id
+		      WHERE c.carried_from_id = ai.id AND cr.deleted_at IS NULL
+		  )
+	`
+	args := []any{userID}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND ai.status = $%d", len(args))
+	}
+
+	if filter.Sort == models.ActionSortDueDate {
+		query += " ORDER BY ai.due_date ASC NULLS LAST, ai.created_at DESC"
+	} else {
+		query += " ORDER BY ai.created_at DESC"
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []*models.ActionItem{}
+	for rows.Next() {
+		var action models.ActionItem
+		err := rows.Scan(
+			&action.ID, &action.RetroID, &action.ItemID, &action.Title, &action.Description,
+			&action.AssigneeID, &action.DueDate, &action.IsCompleted, &action.Status, &action.CompletedAt,
+			&action.Priority, &action.ExternalID, &action.ExternalURL, &action.CreatedBy,
+			&action.CreatedAt, &action.UpdatedAt, &action.CarriedFromID, &action.OriginRetroID,
+			&action.RetroName,
+			&action.TeamName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, &action)
+	}
+
+	return actions, rows.Err()
+}
+
+// ListByTeam lists all action items for a team's completed retrospectives. An action carried
+// over into a completed retrospective is listed once, through its copy.
 func (r *ActionItemRepository) ListByTeam(ctx context.Context, teamID uuid.UUID) ([]*models.ActionItem, error) {
 	query := `
 		SELECT ai.id, ai.retro_id, ai.item_id, ai.title, ai.description, ai.assignee_id, ai.due_date,
 		       ai.is_completed, ai.status, ai.completed_at, ai.priority, ai.external_id, ai.external_url,
-		       ai.created_by, ai.created_at, ai.updated_at,
+		       ai.created_by, ai.created_at, ai.updated_at, ai.carried_from_id, ai.origin_retro_id,
 		       r.name as retro_name,
 		       i.content as item_content
 		FROM action_items ai
 		JOIN retrospectives r ON r.id = ai.retro_id
 		LEFT JOIN items i ON i.id = ai.item_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM action_items c
+		      JOIN retrospectives cr ON cr.id = c.retro_id
+		      WHERE c.carried_from_id = ai.id AND cr.status = 'completed' AND cr.deleted_at IS NULL
+		  )
 		ORDER BY ai.priority DESC, ai.created_at
 	`
 
@@ -783,7 +2065,7 @@ func (r *ActionItemRepository) ListByTeam(ctx context.Context, teamID uuid.UUID)
 			&action.ID, &action.RetroID, &action.ItemID, &action.Title, &action.Description,
 			&action.AssigneeID, &action.DueDate, &action.IsCompleted, &action.Status, &action.CompletedAt,
 			&action.Priority, &action.ExternalID, &action.ExternalURL, &action.CreatedBy,
-			&action.CreatedAt, &action.UpdatedAt,
+			&action.CreatedAt, &action.UpdatedAt, &action.CarriedFromID, &action.OriginRetroID,
 			&retroName,
 			&itemContent,
 		)
@@ -0,0 +1,68 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestScheduledQueriesSkipDeletedDrafts(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	repo := env.Repos.Retros
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	due := time.Now().Add(-time.Hour)
+	soon := time.Now().Add(10 * time.Minute)
+	dueDraft := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "due", ScheduledAt: &due})
+	soonDraft := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "soon", ScheduledAt: &soon})
+	for _, retro := range []*models.Retrospective{dueDraft, soonDraft} {
+		if err := repo.SoftDelete(ctx, retro.ID); err != nil {
+			t.Fatalf("soft delete: %v", err)
+		}
+	}
+
+	started, err := repo.StartScheduled(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("start scheduled: %v", err)
+	}
+	if containsID(started, dueDraft.ID) {
+		t.Fatal("a deleted draft was started")
+	}
+
+	reminded, err := repo.ClaimReminders(ctx, time.Now().Add(15*time.Minute))
+	if err != nil {
+		t.Fatalf("claim reminders: %v", err)
+	}
+	if containsID(reminded, soonDraft.ID) {
+		t.Fatal("a reminder was claimed for a deleted draft")
+	}
+
+	// Restoring the due draft lets the scheduler start it
+	if err := repo.Restore(ctx, dueDraft.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	started, err = repo.StartScheduled(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("start scheduled: %v", err)
+	}
+	if !containsID(started, dueDraft.ID) {
+		t.Fatalf("started %v, want the restored draft %s", started, dueDraft.ID)
+	}
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, got := range ids {
+		if got == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevokedTokenRepository handles storage of access tokens revoked before they expire
+type RevokedTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRevokedTokenRepository creates a new revoked token repository
+func NewRevokedTokenRepository(pool *pgxpool.Pool) *RevokedTokenRepository {
+	return &RevokedTokenRepository{pool: pool}
+}
+
+// Revoke records a token as revoked until it expires, and purges the tokens that expired
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (token_id, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token_id) DO NOTHING
+	`
+	if _, err := r.pool.Exec(ctx, query, tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+
+	_, err := r.pool.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= NOW()`)
+	return err
+}
+
+// FindRevoked returns which of the tokens were revoked
+func (r *RevokedTokenRepository) FindRevoked(ctx context.Context, tokenIDs []string) (map[string]bool, error) {
+	rows, err := r.pool.Query(ctx, `SELECT token_id FROM revoked_tokens WHERE token_id = ANY($1)`, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]bool)
+	for rows.Next() {
+		var tokenID string
+		if err := rows.Scan(&tokenID); err != nil {
+			return nil, err
+		}
+		revoked[tokenID] = true
+	}
+	return revoked, rows.Err()
+}
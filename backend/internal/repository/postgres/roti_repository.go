@@ -21,22 +21,28 @@ func NewRotiRepository(pool *pgxpool.Pool) *RotiRepository {
 	return &RotiRepository{pool: pool}
 }
 
-// SetVote sets or updates a user's ROTI vote for a retrospective
-func (r *RotiRepository) SetVote(ctx context.Context, retroID, userID uuid.UUID, rating int) (*models.RotiVote, error) {
+// SetVote sets or updates a user's ROTI vote for a retrospective.
+// It returns ErrNotFound once the results have been revealed.
+func (r *RotiRepository) SetVote(ctx context.Context, retroID, userID uuid.UUID, rating int, comment *string) (*models.RotiVote, error) {
 	query := `
-		INSERT INTO roti_votes (id, retro_id, user_id, rating)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO roti_votes (id, retro_id, user_id, rating, comment)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (SELECT 1 FROM retrospectives WHERE id = $2 AND roti_revealed)
 		ON CONFLICT (retro_id, user_id)
-		DO UPDATE SET rating = $4
-		RETURNING id, retro_id, user_id, rating, created_at
+		DO UPDATE SET rating = $4, comment = $5
+		WHERE NOT EXISTS (SELECT 1 FROM retrospectives WHERE id = $2 AND roti_revealed)
+		RETURNING id, retro_id, user_id, rating, comment, created_at
 	`
 
 	var v models.RotiVote
-	err := r.pool.QueryRow(ctx, query, uuid.New(), retroID, userID, rating).Scan(
-		&v.ID, &v.RetroID, &v.UserID, &v.Rating, &v.CreatedAt,
+	err := r.pool.QueryRow(ctx, query, uuid.New(), retroID, userID, rating, comment).Scan(
+		&v.ID, &v.RetroID, &v.UserID, &v.Rating, &v.Comment, &v.CreatedAt,
 	)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 
@@ -102,7 +108,7 @@ func (r *RotiRepository) GetResults(ctx context.Context, retroID uuid.UUID) (*mo
 // ListVotes lists all ROTI votes for a retrospective
 func (r *RotiRepository) ListVotes(ctx context.Context, retroID uuid.UUID) ([]*models.RotiVote, error) {
 	query := `
-		SELECT rv.id, rv.retro_id, rv.user_id, rv.rating, rv.created_at,
+		SELECT rv.id, rv.retro_id, rv.user_id, rv.rating, rv.comment, rv.created_at,
 		       u.id, u.display_name, u.avatar_url
 		FROM roti_votes rv
 		JOIN users u ON u.id = rv.user_id
@@ -121,7 +127,7 @@ func (r *RotiRepository) ListVotes(ctx context.Context, retroID uuid.UUID) ([]*m
 		var v models.RotiVote
 		var user models.User
 		err := rows.Scan(
-			&v.ID, &v.RetroID, &v.UserID, &v.Rating, &v.CreatedAt,
+			&v.ID, &v.RetroID, &v.UserID, &v.Rating, &v.Comment, &v.CreatedAt,
 			&user.ID, &user.DisplayName, &user.AvatarURL,
 		)
 		if err != nil {
@@ -154,14 +160,14 @@ func (r *RotiRepository) CountVotes(ctx context.Context, retroID uuid.UUID) (int
 // GetVote gets a specific user's ROTI vote for a retrospective
 func (r *RotiRepository) GetVote(ctx context.Context, retroID, userID uuid.UUID) (*models.RotiVote, error) {
 	query := `
-		SELECT id, retro_id, user_id, rating, created_at
+		SELECT id, retro_id, user_id, rating, comment, created_at
 		FROM roti_votes
 		WHERE retro_id = $1 AND user_id = $2
 	`
 
 	var v models.RotiVote
 	err := r.pool.QueryRow(ctx, query, retroID, userID).Scan(
-		&v.ID, &v.RetroID, &v.UserID, &v.Rating, &v.CreatedAt,
+		&v.ID, &v.RetroID, &v.UserID, &v.Rating, &v.Comment, &v.CreatedAt,
 	)
 
 	if err != nil {
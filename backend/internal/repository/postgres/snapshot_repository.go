@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SnapshotRepository handles one-time retro_state snapshots
+type SnapshotRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSnapshotRepository creates a new snapshot repository
+func NewSnapshotRepository(pool *pgxpool.Pool) *SnapshotRepository {
+	return &SnapshotRepository{pool: pool}
+}
+
+// Create stores a snapshot under the hash of its token and purges expired snapshots
+func (r *SnapshotRepository) Create(ctx context.Context, tokenHash string, retroID, userID uuid.UUID, payload []byte, expiresAt time.Time) error {
+	query := `
+		INSERT INTO retro_state_snapshots (token_hash, retrospective_id, user_id, payload, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := r.pool.Exec(ctx, query, tokenHash, retroID, userID, payload, expiresAt); err != nil {
+		return err
+	}
+
+	_, err := r.pool.Exec(ctx, `DELETE FROM retro_state_snapshots WHERE expires_at <= NOW()`)
+	return err
+}
+
+// Consume deletes and returns the payload of an unexpired snapshot issued to the user for the retro
+func (r *SnapshotRepository) Consume(ctx context.Context, tokenHash string, retroID, userID uuid.UUID) ([]byte, error) {
+	query := `
+		DELETE FROM retro_state_snapshots
+		WHERE token_hash = $1 AND retrospective_id = $2 AND user_id = $3 AND expires_at > NOW()
+		RETURNING payload
+	`
+
+	var payload []byte
+	err := r.pool.QueryRow(ctx, query, tokenHash, retroID, userID).Scan(&payload)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return payload, nil
+}
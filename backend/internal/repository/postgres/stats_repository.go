@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -9,7 +10,8 @@ import (
 	"github.com/jycamier/retrotro/backend/internal/models"
 )
 
-// StatsRepository handles statistics database operations
+// StatsRepository handles statistics database operations.
+// Team figures leave out guests, who took part in a retro without being on the team.
 type StatsRepository struct {
 	pool *pgxpool.Pool
 }
@@ -31,7 +33,7 @@ func (r *StatsRepository) GetTeamRotiStats(ctx context.Context, teamID uuid.UUID
 	retrosQuery := `
 		SELECT id, name, ended_at
 		FROM retrospectives
-		WHERE team_id = $1 AND status = 'completed'
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL
 		ORDER BY ended_at DESC
 	` + limitClause
 
@@ -79,8 +81,8 @@ func (r *StatsRepository) GetTeamRotiStats(ctx context.Context, teamID uuid.UUID
 		SELECT COALESCE(AVG(rv.rating), 0), COUNT(*)
 		FROM roti_votes rv
 		JOIN retrospectives r ON r.id = rv.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
-		AND r.id = ANY($2)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
+		AND r.id = ANY($2) AND rv.user_id NOT IN (SELECT id FROM users WHERE is_guest)
 	`
 
 	var avg float64
@@ -95,8 +97,8 @@ func (r *StatsRepository) GetTeamRotiStats(ctx context.Context, teamID uuid.UUID
 		SELECT rv.rating, COUNT(*) as count
 		FROM roti_votes rv
 		JOIN retrospectives r ON r.id = rv.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
-		AND r.id = ANY($2)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
+		AND r.id = ANY($2) AND rv.user_id NOT IN (SELECT id FROM users WHERE is_guest)
 		GROUP BY rv.rating
 		ORDER BY rv.rating
 	`
@@ -122,9 +124,9 @@ func (r *StatsRepository) GetTeamRotiStats(ctx context.Context, teamID uuid.UUID
 			COUNT(DISTINCT rv.user_id) as voters,
 			COUNT(DISTINCT rp.user_id) as participants
 		FROM retrospectives r
-		LEFT JOIN roti_votes rv ON rv.retro_id = r.id
-		LEFT JOIN retro_participants rp ON rp.retro_id = r.id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		LEFT JOIN roti_votes rv ON rv.retro_id = r.id AND rv.user_id NOT IN (SELECT id FROM users WHERE is_guest)
+		LEFT JOIN retro_participants rp ON rp.retro_id = r.id AND rp.user_id NOT IN (SELECT id FROM users WHERE is_guest)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND r.id = ANY($2)
 	`
 
@@ -143,8 +145,8 @@ func (r *StatsRepository) GetTeamRotiStats(ctx context.Context, teamID uuid.UUID
 	evolutionQuery := `
 		SELECT r.id, r.name, r.ended_at, COALESCE(AVG(rv.rating), 0), COUNT(rv.id)
 		FROM retrospectives r
-		LEFT JOIN roti_votes rv ON rv.retro_id = r.id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		LEFT JOIN roti_votes rv ON rv.retro_id = r.id AND rv.user_id NOT IN (SELECT id FROM users WHERE is_guest)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND r.id = ANY($2)
 		GROUP BY r.id, r.name, r.ended_at
 		ORDER BY r.ended_at ASC
@@ -191,7 +193,7 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 	retrosQuery := `
 		SELECT id
 		FROM retrospectives
-		WHERE team_id = $1 AND status = 'completed'
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL
 		ORDER BY ended_at DESC
 	` + limitClause
 
@@ -218,7 +220,7 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 
 	if len(retroIDs) == 0 {
 		return &models.TeamMoodStats{
-			Distribution: make(map[models.MoodWeather]int),
+			Distribution: make(map[string]int),
 			Evolution:    []*models.MoodEvolutionPoint{},
 		}, nil
 	}
@@ -228,8 +230,8 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 		SELECT im.mood, COUNT(*) as count
 		FROM icebreaker_moods im
 		JOIN retrospectives r ON r.id = im.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
-		AND r.id = ANY($2)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
+		AND r.id = ANY($2) AND im.user_id NOT IN (SELECT id FROM users WHERE is_guest)
 		GROUP BY im.mood
 	`
 
@@ -239,10 +241,10 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 	}
 	defer distRows.Close()
 
-	distribution := make(map[models.MoodWeather]int)
+	distribution := make(map[string]int)
 	totalMoods := 0
 	for distRows.Next() {
-		var mood models.MoodWeather
+		var mood string
 		var cnt int
 		if err := distRows.Scan(&mood, &cnt); err != nil {
 			return nil, err
@@ -257,9 +259,9 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 			COUNT(DISTINCT im.user_id) as mood_submitters,
 			COUNT(DISTINCT rp.user_id) as participants
 		FROM retrospectives r
-		LEFT JOIN icebreaker_moods im ON im.retro_id = r.id
-		LEFT JOIN retro_participants rp ON rp.retro_id = r.id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		LEFT JOIN icebreaker_moods im ON im.retro_id = r.id AND im.user_id NOT IN (SELECT id FROM users WHERE is_guest)
+		LEFT JOIN retro_participants rp ON rp.retro_id = r.id AND rp.user_id NOT IN (SELECT id FROM users WHERE is_guest)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND r.id = ANY($2)
 	`
 
@@ -278,8 +280,8 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 	evolutionQuery := `
 		SELECT r.id, r.name, r.ended_at, im.mood, COUNT(im.id)
 		FROM retrospectives r
-		LEFT JOIN icebreaker_moods im ON im.retro_id = r.id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		LEFT JOIN icebreaker_moods im ON im.retro_id = r.id AND im.user_id NOT IN (SELECT id FROM users WHERE is_guest)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND r.id = ANY($2)
 		GROUP BY r.id, r.name, r.ended_at, im.mood
 		ORDER BY r.ended_at ASC
@@ -296,7 +298,7 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 		var retroID uuid.UUID
 		var retroName string
 		var date interface{}
-		var mood *models.MoodWeather
+		var mood *string
 		var cnt int
 		if err := evoRows.Scan(&retroID, &retroName, &date, &mood, &cnt); err != nil {
 			return nil, err
@@ -306,7 +308,7 @@ func (r *StatsRepository) GetTeamMoodStats(ctx context.Context, teamID uuid.UUID
 			point := &models.MoodEvolutionPoint{
 				RetroID:      retroID,
 				RetroName:    retroName,
-				Distribution: make(map[models.MoodWeather]int),
+				Distribution: make(map[string]int),
 			}
 			if t, ok := date.(interface{ Time() (interface{}, interface{}) }); ok {
 				// Handle pgx timestamp
@@ -384,7 +386,7 @@ func (r *StatsRepository) GetUserRotiStats(ctx context.Context, teamID, userID u
 	retrosQuery := `
 		SELECT id
 		FROM retrospectives
-		WHERE team_id = $1 AND status = 'completed'
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL
 		ORDER BY ended_at DESC
 	` + limitClause
 
@@ -422,7 +424,7 @@ func (r *StatsRepository) GetUserRotiStats(ctx context.Context, teamID, userID u
 		SELECT COALESCE(AVG(rv.rating), 0), COUNT(*)
 		FROM roti_votes rv
 		JOIN retrospectives r ON r.id = rv.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND rv.user_id = $2
 		AND r.id = ANY($3)
 	`
@@ -439,8 +441,8 @@ func (r *StatsRepository) GetUserRotiStats(ctx context.Context, teamID, userID u
 		SELECT COALESCE(AVG(rv.rating), 0)
 		FROM roti_votes rv
 		JOIN retrospectives r ON r.id = rv.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
-		AND r.id = ANY($2)
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
+		AND r.id = ANY($2) AND rv.user_id NOT IN (SELECT id FROM users WHERE is_guest)
 	`
 
 	var teamAvg float64
@@ -454,7 +456,7 @@ func (r *StatsRepository) GetUserRotiStats(ctx context.Context, teamID, userID u
 		SELECT rv.rating, COUNT(*) as count
 		FROM roti_votes rv
 		JOIN retrospectives r ON r.id = rv.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND rv.user_id = $2
 		AND r.id = ANY($3)
 		GROUP BY rv.rating
@@ -481,7 +483,7 @@ func (r *StatsRepository) GetUserRotiStats(ctx context.Context, teamID, userID u
 		SELECT COUNT(DISTINCT rp.retro_id)
 		FROM retro_participants rp
 		JOIN retrospectives r ON r.id = rp.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND rp.user_id = $2
 		AND r.id = ANY($3)
 	`
@@ -502,7 +504,7 @@ func (r *StatsRepository) GetUserRotiStats(ctx context.Context, teamID, userID u
 		SELECT r.id, r.name, COALESCE(r.ended_at, r.created_at), rv.rating
 		FROM retrospectives r
 		JOIN roti_votes rv ON rv.retro_id = r.id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND rv.user_id = $2
 		AND r.id = ANY($3)
 		ORDER BY r.ended_at ASC
@@ -554,7 +556,7 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 	retrosQuery := `
 		SELECT id
 		FROM retrospectives
-		WHERE team_id = $1 AND status = 'completed'
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL
 		ORDER BY ended_at DESC
 	` + limitClause
 
@@ -582,7 +584,7 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 	if len(retroIDs) == 0 {
 		return &models.UserMoodStats{
 			UserID:       userID,
-			Distribution: make(map[models.MoodWeather]int),
+			Distribution: make(map[string]int),
 			Evolution:    []*models.MoodEvolutionPoint{},
 		}, nil
 	}
@@ -592,7 +594,7 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 		SELECT im.mood, COUNT(*) as count
 		FROM icebreaker_moods im
 		JOIN retrospectives r ON r.id = im.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND im.user_id = $2
 		AND r.id = ANY($3)
 		GROUP BY im.mood
@@ -604,12 +606,12 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 	}
 	defer distRows.Close()
 
-	distribution := make(map[models.MoodWeather]int)
+	distribution := make(map[string]int)
 	totalMoods := 0
-	var mostCommonMood models.MoodWeather
+	var mostCommonMood string
 	maxCount := 0
 	for distRows.Next() {
-		var mood models.MoodWeather
+		var mood string
 		var cnt int
 		if err := distRows.Scan(&mood, &cnt); err != nil {
 			return nil, err
@@ -627,7 +629,7 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 		SELECT COUNT(DISTINCT rp.retro_id)
 		FROM retro_participants rp
 		JOIN retrospectives r ON r.id = rp.retro_id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND rp.user_id = $2
 		AND r.id = ANY($3)
 	`
@@ -648,7 +650,7 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 		SELECT r.id, r.name, COALESCE(r.ended_at, r.created_at), im.mood
 		FROM retrospectives r
 		JOIN icebreaker_moods im ON im.retro_id = r.id
-		WHERE r.team_id = $1 AND r.status = 'completed'
+		WHERE r.team_id = $1 AND r.status = 'completed' AND r.deleted_at IS NULL
 		AND im.user_id = $2
 		AND r.id = ANY($3)
 		ORDER BY r.ended_at ASC
@@ -663,11 +665,11 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 	var evolution []*models.MoodEvolutionPoint
 	for evoRows.Next() {
 		var point models.MoodEvolutionPoint
-		var mood models.MoodWeather
+		var mood string
 		if err := evoRows.Scan(&point.RetroID, &point.RetroName, &point.Date, &mood); err != nil {
 			return nil, err
 		}
-		point.Distribution = map[models.MoodWeather]int{mood: 1}
+		point.Distribution = map[string]int{mood: 1}
 		point.MoodCount = 1
 		evolution = append(evolution, &point)
 	}
@@ -686,3 +688,69 @@ func (r *StatsRepository) GetUserMoodStats(ctx context.Context, teamID, userID u
 		Evolution:         evolution,
 	}, nil
 }
+
+// GetTeamPhaseAnalytics aggregates planned vs actual phase durations over a team's completed retrospectives
+func (r *StatsRepository) GetTeamPhaseAnalytics(ctx context.Context, teamID uuid.UUID, filter *models.StatsFilter) (*models.TeamPhaseAnalytics, error) {
+	retrosQuery := `
+		SELECT id
+		FROM retrospectives
+		WHERE team_id = $1 AND status = 'completed' AND deleted_at IS NULL
+	`
+	args := []interface{}{teamID}
+
+	if filter != nil && filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		retrosQuery += fmt.Sprintf(" AND ended_at >= $%d", len(args))
+	}
+	if filter != nil && filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		retrosQuery += fmt.Sprintf(" AND ended_at <= $%d", len(args))
+	}
+	retrosQuery += " ORDER BY ended_at DESC"
+	if filter != nil && filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		retrosQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	// Only closed rows have an actual duration; the waiting room has no timebox
+	query := `
+		WITH recent AS (` + retrosQuery + `)
+		SELECT h.phase,
+		       COUNT(*),
+		       (SELECT COUNT(DISTINCT retro_id) FROM retro_phase_history
+		         WHERE retro_id IN (SELECT id FROM recent) AND actual_duration_seconds IS NOT NULL),
+		       AVG(h.planned_duration_seconds)::float8,
+		       AVG(h.actual_duration_seconds)::float8,
+		       COUNT(*) FILTER (WHERE h.actual_duration_seconds > h.planned_duration_seconds)
+		FROM retro_phase_history h
+		JOIN recent ON recent.id = h.retro_id
+		WHERE h.actual_duration_seconds IS NOT NULL AND h.phase <> 'waiting'
+		GROUP BY h.phase
+		ORDER BY h.phase
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	analytics := &models.TeamPhaseAnalytics{Phases: []*models.PhaseTimingStats{}}
+	for rows.Next() {
+		var stats models.PhaseTimingStats
+		var retroCount int
+		if err := rows.Scan(
+			&stats.Phase, &stats.Count, &retroCount,
+			&stats.AvgPlannedSeconds, &stats.AvgActualSeconds, &stats.OverrunCount,
+		); err != nil {
+			return nil, err
+		}
+		if stats.AvgPlannedSeconds > 0 {
+			stats.OverrunPercent = (stats.AvgActualSeconds - stats.AvgPlannedSeconds) / stats.AvgPlannedSeconds * 100
+		}
+		analytics.RetroCount = retroCount
+		analytics.Phases = append(analytics.Phases, &stats)
+	}
+
+	return analytics, rows.Err()
+}
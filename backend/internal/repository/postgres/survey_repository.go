@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// SurveyRepository handles end-of-session survey database operations
+type SurveyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSurveyRepository creates a new survey repository
+func NewSurveyRepository(pool *pgxpool.Pool) *SurveyRepository {
+	return &SurveyRepository{pool: pool}
+}
+
+// ReplaceQuestions replaces the survey questions of a retrospective and hides previous results
+func (r *SurveyRepository) ReplaceQuestions(ctx context.Context, retroID uuid.UUID, questions []*models.SurveyQuestion) ([]*models.SurveyQuestion, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM retro_survey_questions WHERE retro_id = $1`, retroID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE retrospectives SET survey_revealed = false WHERE id = $1`, retroID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO retro_survey_questions (id, retro_id, position, prompt, answer_type)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, retro_id, position, prompt, answer_type, created_at
+	`
+
+	created := make([]*models.SurveyQuestion, 0, len(questions))
+	for i, q := range questions {
+		var sq models.SurveyQuestion
+		err := tx.QueryRow(ctx, query, uuid.New(), retroID, i, q.Prompt, q.AnswerType).Scan(
+			&sq.ID, &sq.RetroID, &sq.Position, &sq.Prompt, &sq.AnswerType, &sq.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, &sq)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// ListQuestions lists the survey questions of a retrospective in order
+func (r *SurveyRepository) ListQuestions(ctx context.Context, retroID uuid.UUID) ([]*models.SurveyQuestion, error) {
+	query := `
+		SELECT id, retro_id, position, prompt, answer_type, created_at
+		FROM retro_survey_questions
+		WHERE retro_id = $1
+		ORDER BY position ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, retroID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []*models.SurveyQuestion{}
+	for rows.Next() {
+		var q models.SurveyQuestion
+		if err := rows.Scan(&q.ID, &q.RetroID, &q.Position, &q.Prompt, &q.AnswerType, &q.CreatedAt); err != nil {
+			return nil, err
+		}
+		questions = append(questions, &q)
+	}
+
+	return questions, nil
+}
+
+// FindQuestion finds a survey question by ID
+func (r *SurveyRepository) FindQuestion(ctx context.Context, id uuid.UUID) (*models.SurveyQuestion, error) {
+	query := `
+		SELECT id, retro_id, position, prompt, answer_type, created_at
+		FROM retro_survey_questions
+		WHERE id = $1
+	`
+
+	var q models.SurveyQuestion
+	err := r.pool.QueryRow(ctx, query, id).Scan(&q.ID, &q.RetroID, &q.Position, &q.Prompt, &q.AnswerType, &q.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// SetAnswer sets or updates a user's answer to a survey question
+func (r *SurveyRepository) SetAnswer(ctx context.Context, questionID, userID uuid.UUID, scaleValue *int, textValue *string) (*models.SurveyAnswer, error) {
+	query := `
+		INSERT INTO retro_survey_answers (id, question_id, user_id, scale_value, text_value)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (question_id, user_id)
+		DO UPDATE SET scale_value = $4, text_value = $5
+		RETURNING id, question_id, user_id, scale_value, text_value, created_at
+	`
+
+	var a models.SurveyAnswer
+	err := r.pool.QueryRow(ctx, query, uuid.New(), questionID, userID, scaleValue, textValue).Scan(
+		&a.ID, &a.QuestionID, &a.UserID, &a.ScaleValue, &a.TextValue, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// ListAnswers lists all survey answers of a retrospective with their authors
+func (r *SurveyRepository) ListAnswers(ctx context.Context, retroID uuid.UUID) ([]*models.SurveyAnswer, error) {
+	query := `
+		SELECT a.id, a.question_id, a.user_id, a.scale_value, a.text_value, a.created_at,
+		       u.id, u.display_name, u.avatar_url
+		FROM retro_survey_answers a
+		JOIN retro_survey_questions q ON q.id = a.question_id
+		JOIN users u ON u.id = a.user_id
+		WHERE q.retro_id = $1
+		ORDER BY a.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, retroID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := []*models.SurveyAnswer{}
+	for rows.Next() {
+		var a models.SurveyAnswer
+		var user models.User
+		err := rows.Scan(
+			&a.ID, &a.QuestionID, &a.UserID, &a.ScaleValue, &a.TextValue, &a.CreatedAt,
+			&user.ID, &user.DisplayName, &user.AvatarURL,
+		)
+		if err != nil {
+			return nil, err
+		}
+		a.User = &user
+		answers = append(answers, &a)
+	}
+
+	return answers, nil
+}
+
+// IsRevealed returns whether the survey results of a retrospective are revealed
+func (r *SurveyRepository) IsRevealed(ctx context.Context, retroID uuid.UUID) (bool, error) {
+	var revealed bool
+	err := r.pool.QueryRow(ctx, `SELECT survey_revealed FROM retrospectives WHERE id = $1`, retroID).Scan(&revealed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrNotFound
+		}
+		return false, err
+	}
+	return revealed, nil
+}
+
+// RevealResults sets the survey_revealed flag to true
+func (r *SurveyRepository) RevealResults(ctx context.Context, retroID uuid.UUID) error {
+	query := `UPDATE retrospectives SET survey_revealed = true WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, retroID)
+	return err
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// TeamInviteRepository handles pending team invitations
+type TeamInviteRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTeamInviteRepository creates a new team invite repository
+func NewTeamInviteRepository(pool *pgxpool.Pool) *TeamInviteRepository {
+	return &TeamInviteRepository{pool: pool}
+}
+
+// Create stores an invitation; inviting the same email again updates the role
+func (r *TeamInviteRepository) Create(ctx context.Context, invite *models.TeamInvite) (*models.TeamInvite, error) {
+	query := `
+		INSERT INTO team_invites (id, team_id, email, role, invited_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (team_id, email) DO UPDATE SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by
+		RETURNING id, created_at
+	`
+
+	invite.Email = strings.ToLower(strings.TrimSpace(invite.Email))
+	err := r.pool.QueryRow(ctx, query,
+		invite.ID, invite.TeamID, invite.Email, invite.Role, invite.InvitedBy,
+	).Scan(&invite.ID, &invite.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// ListByTeam lists pending invitations of a team
+func (r *TeamInviteRepository) ListByTeam(ctx context.Context, teamID uuid.UUID) ([]*models.TeamInvite, error) {
+	query := `
+		SELECT id, team_id, email, role, invited_by, created_at
+		FROM team_invites
+		WHERE team_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*models.TeamInvite{}
+	for rows.Next() {
+		var invite models.TeamInvite
+		if err := rows.Scan(&invite.ID, &invite.TeamID, &invite.Email, &invite.Role, &invite.InvitedBy, &invite.CreatedAt); err != nil {
+			return nil, err
+		}
+		invites = append(invites, &invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// Delete revokes an invitation of a team
+func (r *TeamInviteRepository) Delete(ctx context.Context, teamID, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM team_invites WHERE team_id = $1 AND id = $2`, teamID, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimForUser turns every invitation for the user's email into a membership and removes the invitations.
+// The email is matched case-insensitively; teams the user already belongs to are left unchanged.
+func (r *TeamInviteRepository) ClaimForUser(ctx context.Context, userID uuid.UUID, email string) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		DELETE FROM team_invites
+		WHERE email = $1
+		RETURNING team_id, role
+	`, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	}
+
+	type claimed struct {
+		teamID uuid.UUID
+		role   models.Role
+	}
+	var invites []claimed
+	for rows.Next() {
+		var c claimed
+		if err := rows.Scan(&c.teamID, &c.role); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		invites = append(invites, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	teamIDs := make([]uuid.UUID, 0, len(invites))
+	for _, c := range invites {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO team_members (id, team_id, user_id, role)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (team_id, user_id) DO NOTHING
+		`, uuid.New(), c.teamID, userID, c.role)
+		if err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, c.teamID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return teamIDs, nil
+}
@@ -25,14 +25,14 @@ func NewTeamRepository(pool *pgxpool.Pool) *TeamRepository {
 func (r *TeamRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Team, error) {
 	query := `
 		SELECT id, name, slug, description, oidc_group_id, is_oidc_managed,
-		       created_by, created_at, updated_at
+		       max_open_actions, created_by, created_at, updated_at
 		FROM teams WHERE id = $1
 	`
 
 	var team models.Team
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&team.ID, &team.Name, &team.Slug, &team.Description,
-		&team.OIDCGroupID, &team.IsOIDCManaged, &team.CreatedBy,
+		&team.OIDCGroupID, &team.IsOIDCManaged, &team.MaxOpenActions, &team.CreatedBy,
 		&team.CreatedAt, &team.UpdatedAt,
 	)
 
@@ -50,14 +50,14 @@ func (r *TeamRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Te
 func (r *TeamRepository) FindBySlug(ctx context.Context, slug string) (*models.Team, error) {
 	query := `
 		SELECT id, name, slug, description, oidc_group_id, is_oidc_managed,
-		       created_by, created_at, updated_at
+		       max_open_actions, created_by, created_at, updated_at
 		FROM teams WHERE slug = $1
 	`
 
 	var team models.Team
 	err := r.pool.QueryRow(ctx, query, slug).Scan(
 		&team.ID, &team.Name, &team.Slug, &team.Description,
-		&team.OIDCGroupID, &team.IsOIDCManaged, &team.CreatedBy,
+		&team.OIDCGroupID, &team.IsOIDCManaged, &team.MaxOpenActions, &team.CreatedBy,
 		&team.CreatedAt, &team.UpdatedAt,
 	)
 
@@ -75,14 +75,14 @@ func (r *TeamRepository) FindBySlug(ctx context.Context, slug string) (*models.T
 func (r *TeamRepository) FindByOIDCGroupID(ctx context.Context, groupID string) (*models.Team, error) {
 	query := `
 		SELECT id, name, slug, description, oidc_group_id, is_oidc_managed,
-		       created_by, created_at, updated_at
+		       max_open_actions, created_by, created_at, updated_at
 		FROM teams WHERE oidc_group_id = $1
 	`
 
 	var team models.Team
 	err := r.pool.QueryRow(ctx, query, groupID).Scan(
 		&team.ID, &team.Name, &team.Slug, &team.Description,
-		&team.OIDCGroupID, &team.IsOIDCManaged, &team.CreatedBy,
+		&team.OIDCGroupID, &team.IsOIDCManaged, &team.MaxOpenActions, &team.CreatedBy,
 		&team.CreatedAt, &team.UpdatedAt,
 	)
 
@@ -100,7 +100,7 @@ func (r *TeamRepository) FindByOIDCGroupID(ctx context.Context, groupID string)
 func (r *TeamRepository) ListAll(ctx context.Context) ([]*models.Team, error) {
 	query := `
 		SELECT id, name, slug, description, oidc_group_id, is_oidc_managed,
-		       created_by, created_at, updated_at
+		       max_open_actions, created_by, created_at, updated_at
 		FROM teams
 		ORDER BY name
 	`
@@ -116,7 +116,7 @@ func (r *TeamRepository) ListAll(ctx context.Context) ([]*models.Team, error) {
 		var team models.Team
 		err := rows.Scan(
 			&team.ID, &team.Name, &team.Slug, &team.Description,
-			&team.OIDCGroupID, &team.IsOIDCManaged, &team.CreatedBy,
+			&team.OIDCGroupID, &team.IsOIDCManaged, &team.MaxOpenActions, &team.CreatedBy,
 			&team.CreatedAt, &team.UpdatedAt,
 		)
 		if err != nil {
@@ -136,7 +136,7 @@ func (r *TeamRepository) ListAll(ctx context.Context) ([]*models.Team, error) {
 func (r *TeamRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Team, error) {
 	query := `
 		SELECT t.id, t.name, t.slug, t.description, t.oidc_group_id, t.is_oidc_managed,
-		       t.created_by, t.created_at, t.updated_at
+		       t.max_open_actions, t.created_by, t.created_at, t.updated_at
 		FROM teams t
 		INNER JOIN team_members tm ON t.id = tm.team_id
 		WHERE tm.user_id = $1
@@ -154,7 +154,7 @@ func (r *TeamRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*m
 		var team models.Team
 		err := rows.Scan(
 			&team.ID, &team.Name, &team.Slug, &team.Description,
-			&team.OIDCGroupID, &team.IsOIDCManaged, &team.CreatedBy,
+			&team.OIDCGroupID, &team.IsOIDCManaged, &team.MaxOpenActions, &team.CreatedBy,
 			&team.CreatedAt, &team.UpdatedAt,
 		)
 		if err != nil {
@@ -194,11 +194,11 @@ func (r *TeamRepository) Create(ctx context.Context, team *models.Team) (*models
 func (r *TeamRepository) Update(ctx context.Context, team *models.Team) error {
 	query := `
 		UPDATE teams
-		SET name = $2, slug = $3, description = $4, updated_at = NOW()
+		SET name = $2, slug = $3, description = $4, max_open_actions = $5, updated_at = NOW()
 		WHERE id = $1
 	`
 
-	_, err := r.pool.Exec(ctx, query, team.ID, team.Name, team.Slug, team.Description)
+	_, err := r.pool.Exec(ctx, query, team.ID, team.Name, team.Slug, team.Description, team.MaxOpenActions)
 	return err
 }
 
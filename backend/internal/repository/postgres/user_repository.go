@@ -14,6 +14,12 @@ import (
 
 var ErrNotFound = errors.New("record not found")
 
+// ErrConcurrentModification is returned when a record changed since the caller read it
+var ErrConcurrentModification = errors.New("record was modified concurrently")
+
+// ErrLimitReached is returned when a record would exceed a configured cap
+var ErrLimitReached = errors.New("limit reached")
+
 // UserRepository handles user database operations
 type UserRepository struct {
 	pool *pgxpool.Pool
@@ -28,14 +34,14 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
 		SELECT id, email, display_name, avatar_url, oidc_subject, oidc_issuer,
-		       is_admin, last_login_at, created_at, updated_at
+		       is_admin, is_guest, last_login_at, created_at, updated_at
 		FROM users WHERE id = $1
 	`
 
 	var user models.User
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.DisplayName, &user.AvatarURL,
-		&user.OIDCSubject, &user.OIDCIssuer, &user.IsAdmin,
+		&user.OIDCSubject, &user.OIDCIssuer, &user.IsAdmin, &user.IsGuest,
 		&user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 
@@ -74,12 +80,14 @@ func (r *UserRepository) FindByOIDC(ctx context.Context, subject, issuer string)
 	return &user, nil
 }
 
-// FindByEmail finds a user by email
+// FindByEmail finds a user by email, ignoring case
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
 		SELECT id, email, display_name, avatar_url, oidc_subject, oidc_issuer,
 		       is_admin, last_login_at, created_at, updated_at
-		FROM users WHERE email = $1
+		FROM users WHERE LOWER(email) = LOWER($1)
+		ORDER BY created_at
+		LIMIT 1
 	`
 
 	var user models.User
@@ -102,8 +110,8 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	query := `
-		INSERT INTO users (id, email, display_name, avatar_url, oidc_subject, oidc_issuer, is_admin)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, email, display_name, avatar_url, oidc_subject, oidc_issuer, is_admin, is_guest)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -113,7 +121,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models
 
 	err := r.pool.QueryRow(ctx, query,
 		user.ID, user.Email, user.DisplayName, user.AvatarURL,
-		user.OIDCSubject, user.OIDCIssuer, user.IsAdmin,
+		user.OIDCSubject, user.OIDCIssuer, user.IsAdmin, user.IsGuest,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
@@ -160,12 +168,13 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) erro
 	return err
 }
 
-// ListAll returns all users
+// ListAll returns all users with an account; guests are left out
 func (r *UserRepository) ListAll(ctx context.Context) ([]*models.User, error) {
 	query := `
 		SELECT id, email, display_name, avatar_url, oidc_subject, oidc_issuer,
 		       is_admin, last_login_at, created_at, updated_at
 		FROM users
+		WHERE NOT is_guest
 		ORDER BY display_name
 	`
 
@@ -196,6 +205,74 @@ func (r *UserRepository) ListAll(ctx context.Context) ([]*models.User, error) {
 	return users, nil
 }
 
+// Search finds users whose email or display name contains the query, case-insensitively.
+// Guests cannot be added to teams and are left out.
+func (r *UserRepository) Search(ctx context.Context, query string, limit int) ([]*models.User, error) {
+	sql := `
+		SELECT id, email, display_name, avatar_url, oidc_subject, oidc_issuer,
+		       is_admin, last_login_at, created_at, updated_at
+		FROM users
+		WHERE (email ILIKE $1 OR display_name ILIKE $1) AND NOT is_guest
+		ORDER BY display_name, email
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, sql, "%"+likeEscaper.Replace(query)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.DisplayName, &user.AvatarURL,
+			&user.OIDCSubject, &user.OIDCIssuer, &user.IsAdmin,
+			&user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
+// SearchByEmail finds the users, guests excepted, whose email is exactly email, ignoring case
+func (r *UserRepository) SearchByEmail(ctx context.Context, email string) ([]*models.User, error) {
+	sql := `
+		SELECT id, email, display_name, avatar_url, oidc_subject, oidc_issuer,
+		       is_admin, last_login_at, created_at, updated_at
+		FROM users
+		WHERE LOWER(email) = LOWER($1) AND NOT is_guest
+		ORDER BY created_at
+	`
+
+	rows, err := r.pool.Query(ctx, sql, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.DisplayName, &user.AvatarURL,
+			&user.OIDCSubject, &user.OIDCIssuer, &user.IsAdmin,
+			&user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
 // FindOrCreate finds a user by OIDC or creates a new one
 func (r *UserRepository) FindOrCreate(ctx context.Context, subject, issuer, email, name string, avatarURL *string) (*models.User, bool, error) {
 	user, err := r.FindByOIDC(ctx, subject, issuer)
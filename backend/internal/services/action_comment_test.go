@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestActionComments(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	received := make(chan models.ActionCommentedData, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data models.ActionCommentedData `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			received <- payload.Data
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	outsider := env.CreateUser(t, "Outsider")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	action, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Fix the flaky tests"})
+	if err != nil {
+		t.Fatalf("create action: %v", err)
+	}
+	if _, err := env.Services.Webhook.Create(ctx, alice.ID, services.CreateWebhookInput{
+		TeamID:    team.ID,
+		Name:      "test",
+		URL:       srv.URL,
+		Events:    []string{string(models.WebhookEventActionCommented)},
+		IsEnabled: true,
+	}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	comment, err := svc.AddActionComment(ctx, action.ID, bob.ID, "  Half of them are fixed ")
+	if err != nil {
+		t.Fatalf("add comment: %v", err)
+	}
+	if comment.Content != "Half of them are fixed" || comment.AuthorName != "Bob" {
+		t.Errorf("comment = %+v, want the trimmed content by Bob", comment)
+	}
+	select {
+	case data := <-received:
+		if data.CommentID != comment.ID || data.AuthorName != "Bob" || data.Title != action.Title {
+			t.Errorf("action.commented data = %+v, want the comment and the action", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("action.commented webhook not received")
+	}
+
+	if _, err := svc.AddActionComment(ctx, action.ID, alice.ID, "All fixed"); err != nil {
+		t.Fatalf("add second comment: %v", err)
+	}
+	comments, err := svc.ListActionComments(ctx, action.ID, alice.ID)
+	if err != nil || len(comments) != 2 || comments[0].ID != comment.ID || comments[1].AuthorName != "Alice" {
+		t.Fatalf("comments = %v, %v, want Bob's then Alice's", comments, err)
+	}
+
+	if _, err := svc.AddActionComment(ctx, action.ID, bob.ID, "   "); !errors.Is(err, services.ErrInvalidActionComment) {
+		t.Errorf("blank comment = %v, want ErrInvalidActionComment", err)
+	}
+	if _, err := svc.AddActionComment(ctx, action.ID, outsider.ID, "Hello"); !errors.Is(err, services.ErrNotTeamMember) {
+		t.Errorf("comment by an outsider = %v, want ErrNotTeamMember", err)
+	}
+	if _, err := svc.ListActionComments(ctx, action.ID, outsider.ID); !errors.Is(err, services.ErrNotTeamMember) {
+		t.Errorf("outsider listing comments = %v, want ErrNotTeamMember", err)
+	}
+}
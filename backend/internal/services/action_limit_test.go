@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestCreateActionEnforcesCapsUnderConcurrency(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	var delivered atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	maxActions := 2
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{MaxActionsPerRetro: &maxActions})
+	if _, err := env.Services.Webhook.Create(ctx, alice.ID, services.CreateWebhookInput{
+		TeamID:    team.ID,
+		Name:      "test",
+		URL:       srv.URL,
+		Events:    []string{string(models.WebhookEventActionCreated)},
+		IsEnabled: true,
+	}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	// Concurrent creates cannot both take the last slot
+	var created, rejected atomic.Int32
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Fix the build"})
+			switch {
+			case err == nil:
+				created.Add(1)
+			case errors.Is(err, services.ErrActionLimitReached):
+				rejected.Add(1)
+			default:
+				t.Errorf("create action: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if created.Load() != 2 || rejected.Load() != 4 {
+		t.Fatalf("created %d and rejected %d actions, want 2 and 4", created.Load(), rejected.Load())
+	}
+
+	// Only the created actions fire action.created
+	testenv.Eventually(t, 5*time.Second, func() bool { return delivered.Load() >= 2 }, "action.created webhooks not received")
+	time.Sleep(300 * time.Millisecond)
+	if got := delivered.Load(); got != 2 {
+		t.Fatalf("%d action.created webhooks delivered, want 2", got)
+	}
+
+	// The team's open actions cap applies across its retros
+	maxOpen := 3
+	if _, err := env.Services.Team.Update(ctx, alice.ID, team.ID, services.UpdateTeamInput{MaxOpenActions: &maxOpen}); err != nil {
+		t.Fatalf("update team: %v", err)
+	}
+	other := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if _, err := svc.CreateAction(ctx, other.ID, alice.ID, services.CreateActionInput{Title: "Write the docs"}); err != nil {
+		t.Fatalf("create action under the team cap: %v", err)
+	}
+	if _, err := svc.CreateAction(ctx, other.ID, alice.ID, services.CreateActionInput{Title: "One too many"}); !errors.Is(err, services.ErrActionLimitReached) {
+		t.Fatalf("create action over the team cap = %v, want ErrActionLimitReached", err)
+	}
+}
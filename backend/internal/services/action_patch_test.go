@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestPatchActionInputDueDate(t *testing.T) {
+	due := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		body  string
+		set   bool
+		value *time.Time
+	}{
+		{body: `{"status":"todo"}`},
+		{body: `{"dueDate":null}`, set: true},
+		{body: `{"dueDate":"2025-02-01"}`, set: true, value: &due},
+		{body: `{"dueDate":"2025-02-01T00:00:00Z"}`, set: true, value: &due},
+	}
+	for _, tt := range tests {
+		var input services.PatchActionInput
+		if err := json.Unmarshal([]byte(tt.body), &input); err != nil {
+			t.Fatalf("%s: decode: %v", tt.body, err)
+		}
+		got := input.DueDate
+		if got.Set != tt.set || (got.Value == nil) != (tt.value == nil) || (got.Value != nil && !got.Value.Equal(*tt.value)) {
+			t.Errorf("%s: dueDate = %+v, want set %v with %v", tt.body, got, tt.set, tt.value)
+		}
+	}
+
+	var input services.PatchActionInput
+	if err := json.Unmarshal([]byte(`{"dueDate":"next week"}`), &input); err == nil {
+		t.Error("decoding an invalid date succeeded")
+	}
+}
+
+func TestPatchActionDueDate(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	action, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Ship the release notes"})
+	if err != nil {
+		t.Fatalf("create action: %v", err)
+	}
+
+	patch := func(body string) {
+		t.Helper()
+		var input services.PatchActionInput
+		if err := json.Unmarshal([]byte(body), &input); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		if _, err := svc.PatchAction(ctx, action.ID, alice.ID, input); err != nil {
+			t.Fatalf("patch %s: %v", body, err)
+		}
+	}
+	dueDate := func() *time.Time {
+		t.Helper()
+		actions, err := svc.ListActions(ctx, retro.ID)
+		if err != nil || len(actions) != 1 {
+			t.Fatalf("list actions: %v, %v", actions, err)
+		}
+		return actions[0].DueDate
+	}
+
+	patch(`{"dueDate":"2025-02-01"}`)
+	if got := dueDate(); got == nil || got.Format(time.DateOnly) != "2025-02-01" {
+		t.Fatalf("due date = %v, want 2025-02-01", got)
+	}
+
+	// Leaving dueDate out keeps it
+	patch(`{"status":"in_progress"}`)
+	if got := dueDate(); got == nil {
+		t.Fatal("due date was cleared by a patch without dueDate")
+	}
+
+	patch(`{"dueDate":null}`)
+	if got := dueDate(); got != nil {
+		t.Fatalf("due date = %v, want it cleared", got)
+	}
+}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestActionPriority(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	for _, priority := range []models.ActionPriority{-1, 4} {
+		if _, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Out of range", Priority: priority}); !errors.Is(err, services.ErrInvalidPriority) {
+			t.Errorf("create with priority %d = %v, want ErrInvalidPriority", priority, err)
+		}
+	}
+
+	var created []*models.ActionItem
+	for _, priority := range []models.ActionPriority{models.PriorityLow, models.PriorityUrgent, models.PriorityMedium} {
+		action, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Action", Priority: priority})
+		if err != nil {
+			t.Fatalf("create with priority %d: %v", priority, err)
+		}
+		created = append(created, action)
+	}
+
+	if _, err := svc.UpdateAction(ctx, retro.ID, created[0].ID, services.CreateActionInput{Title: "Action", Priority: 7}); !errors.Is(err, services.ErrInvalidPriority) {
+		t.Errorf("update with priority 7 = %v, want ErrInvalidPriority", err)
+	}
+	if _, err := svc.UpdateAction(ctx, retro.ID, created[0].ID, services.CreateActionInput{Title: "Action", Priority: models.PriorityHigh}); err != nil {
+		t.Fatalf("update to high: %v", err)
+	}
+
+	actions, err := svc.ListActions(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list actions: %v", err)
+	}
+	var got []models.ActionPriority
+	for _, action := range actions {
+		got = append(got, action.Priority)
+	}
+	want := []models.ActionPriority{models.PriorityUrgent, models.PriorityHigh, models.PriorityMedium}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("priorities = %v, want the most urgent first %v", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+)
+
+// ActivityService handles team activity timeline operations
+type ActivityService struct {
+	activityRepo *postgres.ActivityRepository
+	memberRepo   *postgres.TeamMemberRepository
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(activityRepo *postgres.ActivityRepository, memberRepo *postgres.TeamMemberRepository) *ActivityService {
+	return &ActivityService{
+		activityRepo: activityRepo,
+		memberRepo:   memberRepo,
+	}
+}
+
+// GetTeamActivity retrieves a page of recent activity for a team and the total number of events
+func (s *ActivityService) GetTeamActivity(ctx context.Context, userID, teamID uuid.UUID, filter *models.ActivityFilter) ([]*models.ActivityEvent, int, error) {
+	isMember, err := s.memberRepo.IsMember(ctx, teamID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !isMember {
+		return nil, 0, ErrNotTeamMember
+	}
+
+	return s.activityRepo.ListByTeam(ctx, teamID, filter)
+}
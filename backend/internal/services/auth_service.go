@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 
@@ -16,6 +19,14 @@ import (
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidGuestName   = errors.New("guest display name must be 1 to 50 characters")
+)
+
+const (
+	// maxGuestNameLength is the longest display name a guest may pick, in characters
+	maxGuestNameLength = 50
+	// guestIssuer is the OIDC issuer recorded for guests, who never log in
+	guestIssuer = "guest"
 )
 
 // UserRepository interface for auth service
@@ -23,7 +34,27 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	FindByOIDC(ctx context.Context, subject, issuer string) (*models.User, error)
 	FindOrCreate(ctx context.Context, subject, issuer, email, name string, avatarURL *string) (*models.User, bool, error)
+	Create(ctx context.Context, user *models.User) (*models.User, error)
 	UpdateLastLogin(ctx context.Context, id uuid.UUID) error
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Search(ctx context.Context, query string, limit int) ([]*models.User, error)
+	SearchByEmail(ctx context.Context, email string) ([]*models.User, error)
+}
+
+// OIDCDebugStore stores redacted ID token claims for debugging
+type OIDCDebugStore interface {
+	Save(ctx context.Context, userID uuid.UUID, claims map[string]interface{}, expiresAt time.Time) error
+}
+
+// InviteClaimer turns pending team invitations into memberships on login
+type InviteClaimer interface {
+	ClaimForUser(ctx context.Context, userID uuid.UUID, email string) ([]uuid.UUID, error)
+}
+
+// TokenRevocationStore remembers the access tokens revoked before they expire
+type TokenRevocationStore interface {
+	Revoke(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error
+	FindRevoked(ctx context.Context, tokenIDs []string) (map[string]bool, error)
 }
 
 // AuthService handles authentication operations
@@ -32,15 +63,25 @@ type AuthService struct {
 	userRepo       UserRepository
 	jitProvisioner *auth.JITProvisioner
 	jwtManager     *auth.JWTManager
+	debugStore     OIDCDebugStore // nil when claims debugging is disabled
+	debugTTL       time.Duration
+	invites        InviteClaimer        // nil disables invitations
+	revocations    TokenRevocationStore // nil disables revocation
+	guestTokenTTL  time.Duration
 }
 
-// NewAuthService creates a new auth service
-func NewAuthService(oidcProvider *auth.OIDCProvider, userRepo UserRepository, jitProvisioner *auth.JITProvisioner, jwtConfig config.JWTConfig) *AuthService {
+// NewAuthService creates a new auth service. debugStore may be nil to disable claims debugging.
+func NewAuthService(oidcProvider *auth.OIDCProvider, userRepo UserRepository, jitProvisioner *auth.JITProvisioner, jwtConfig config.JWTConfig, debugStore OIDCDebugStore, debugTTL time.Duration, invites InviteClaimer, revocations TokenRevocationStore) *AuthService {
 	return &AuthService{
 		oidcProvider:   oidcProvider,
 		userRepo:       userRepo,
 		jitProvisioner: jitProvisioner,
 		jwtManager:     auth.NewJWTManager(jwtConfig.Secret, jwtConfig.AccessTokenTTL, jwtConfig.RefreshTokenTTL),
+		debugStore:     debugStore,
+		debugTTL:       debugTTL,
+		invites:        invites,
+		revocations:    revocations,
+		guestTokenTTL:  time.Duration(jwtConfig.GuestTokenTTL) * time.Minute,
 	}
 }
 
@@ -80,11 +121,19 @@ func (s *AuthService) HandleCallback(ctx context.Context, code string) (*models.
 		return nil, nil, err
 	}
 
+	redacted := auth.RedactClaims(claims.Raw)
+	if s.debugStore != nil {
+		if err := s.debugStore.Save(ctx, user.ID, redacted, time.Now().Add(s.debugTTL)); err != nil {
+			slog.Error("failed to store OIDC debug claims", "error", err, "user", user.Email)
+		}
+	}
+
 	// JIT provision teams if enabled
-	slog.Info("OIDC claims received", "user", user.Email, "groups_claim", claims.Raw["groups"], "all_claims", claims.Raw)
+	slog.Info("OIDC claims received", "user", user.Email, "groups_claim", claims.Raw["groups"], "all_claims", redacted)
 	if err := s.jitProvisioner.ProvisionUser(ctx, user, claims.Raw); err != nil {
 		slog.Error("JIT provisioning failed", "error", err, "user", user.Email)
 	}
+	s.claimInvites(ctx, user, claims.EmailVerified)
 
 	// Update last login
 	_ = s.userRepo.UpdateLastLogin(ctx, user.ID)
@@ -124,6 +173,106 @@ func (s *AuthService) ValidateToken(token string) (*auth.JWTClaims, error) {
 	return s.jwtManager.ValidateAccessToken(token)
 }
 
+// RevokeToken revokes an access token before it expires, e.g. on logout.
+// Expired tokens are already unusable and are not recorded.
+func (s *AuthService) RevokeToken(ctx context.Context, token string) error {
+	claims, err := s.jwtManager.ValidateAccessToken(token)
+	if errors.Is(err, auth.ErrExpiredToken) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.revocations == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return auth.ErrInvalidToken
+	}
+	return s.revocations.Revoke(ctx, claims.ID, userID, claims.ExpiresAt.Time)
+}
+
+// RevokedTokens returns which of the token IDs were revoked
+func (s *AuthService) RevokedTokens(ctx context.Context, tokenIDs []string) (map[string]bool, error) {
+	if s.revocations == nil || len(tokenIDs) == 0 {
+		return map[string]bool{}, nil
+	}
+	return s.revocations.FindRevoked(ctx, tokenIDs)
+}
+
+// GuestTokenTTL is how long guest share links stay valid unless the facilitator picks another expiry
+func (s *AuthService) GuestTokenTTL() time.Duration {
+	return s.guestTokenTTL
+}
+
+// CreateGuestLinkToken signs a guest link token for the retro's share link, valid until expiresAt
+func (s *AuthService) CreateGuestLinkToken(retroID, shareID uuid.UUID, expiresAt time.Time) (string, error) {
+	return s.jwtManager.GenerateGuestToken(retroID, shareID, uuid.Nil, expiresAt)
+}
+
+// ValidateGuestToken validates a guest link or session token and returns the claims
+func (s *AuthService) ValidateGuestToken(token string) (*auth.GuestClaims, error) {
+	return s.jwtManager.ValidateGuestToken(token)
+}
+
+// JoinAsGuest returns the guest behind a guest token. A link token creates a new guest with the
+// display name and returns their session token, which expires and is revoked with the link, so
+// that the guest keeps their identity when reconnecting. A session token returns its guest and no new token.
+func (s *AuthService) JoinAsGuest(ctx context.Context, claims *auth.GuestClaims, displayName string) (*models.User, string, error) {
+	if claims.Subject != "" {
+		guestID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return nil, "", auth.ErrInvalidToken
+		}
+		guest, err := s.userRepo.FindByID(ctx, guestID)
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, "", auth.ErrInvalidToken
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if !guest.IsGuest {
+			return nil, "", auth.ErrInvalidToken
+		}
+		return guest, "", nil
+	}
+
+	name := strings.TrimSpace(displayName)
+	if name == "" || utf8.RuneCountInString(name) > maxGuestNameLength {
+		return nil, "", ErrInvalidGuestName
+	}
+	retroID, err := uuid.Parse(claims.RetroID)
+	if err != nil || claims.ExpiresAt == nil {
+		return nil, "", auth.ErrInvalidToken
+	}
+	shareID, err := uuid.Parse(claims.ShareID)
+	if err != nil {
+		return nil, "", auth.ErrInvalidToken
+	}
+
+	// Guests have no email or OIDC identity; placeholders keep the users constraints satisfied
+	guestID := uuid.New()
+	guest, err := s.userRepo.Create(ctx, &models.User{
+		ID:          guestID,
+		Email:       guestID.String() + "@guest.invalid",
+		DisplayName: name,
+		OIDCSubject: guestID.String(),
+		OIDCIssuer:  guestIssuer,
+		IsGuest:     true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, err := s.jwtManager.GenerateGuestToken(retroID, shareID, guest.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, "", err
+	}
+	return guest, session, nil
+}
+
 // GetUserByID gets a user by ID
 func (s *AuthService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	return s.userRepo.FindByID(ctx, id)
@@ -145,6 +294,8 @@ func (s *AuthService) DevLogin(ctx context.Context, email, displayName string) (
 	if err != nil {
 		return nil, nil, err
 	}
+	// Dev mode trusts the email it is given
+	s.claimInvites(ctx, user, true)
 
 	// Update last login
 	_ = s.userRepo.UpdateLastLogin(ctx, user.ID)
@@ -157,3 +308,24 @@ func (s *AuthService) DevLogin(ctx context.Context, email, displayName string) (
 
 	return user, tokenPair, nil
 }
+
+// claimInvites adds the user to every team that invited their email. Nothing is claimed unless
+// the identity provider verified the email, so an invite cannot be taken with a look-alike account.
+// Failures are logged so they never block the login itself.
+func (s *AuthService) claimInvites(ctx context.Context, user *models.User, emailVerified bool) {
+	if s.invites == nil || user.Email == "" {
+		return
+	}
+	if !emailVerified {
+		slog.Info("email not verified, team invites not claimed", "user", user.Email)
+		return
+	}
+	teamIDs, err := s.invites.ClaimForUser(ctx, user.ID, user.Email)
+	if err != nil {
+		slog.Error("failed to claim team invites", "error", err, "user", user.Email)
+		return
+	}
+	if len(teamIDs) > 0 {
+		slog.Info("team invites claimed", "user", user.Email, "teams", teamIDs)
+	}
+}
@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/auth"
+	"github.com/jycamier/retrotro/backend/internal/config"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestRevokeTokenOnLogout(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	jwtConfig := config.JWTConfig{Secret: "test-secret", AccessTokenTTL: 15, RefreshTokenTTL: 1}
+	authService := services.NewAuthService(nil, env.Repos.Users, nil, jwtConfig, nil, 0, nil, env.Repos.RevokedTokens)
+	jwt := auth.NewJWTManager(jwtConfig.Secret, jwtConfig.AccessTokenTTL, jwtConfig.RefreshTokenTTL)
+
+	alice := env.CreateUser(t, "Alice")
+	loggedOut, err := jwt.GenerateTokenPair(alice.ID, alice.Email, alice.DisplayName, false)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	otherDevice, err := jwt.GenerateTokenPair(alice.ID, alice.Email, alice.DisplayName, false)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	if err := authService.RevokeToken(ctx, loggedOut.AccessToken); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	// Revoking twice, as a retried logout does, is fine
+	if err := authService.RevokeToken(ctx, loggedOut.AccessToken); err != nil {
+		t.Fatalf("revoke again: %v", err)
+	}
+
+	loggedOutClaims, _ := authService.ValidateToken(loggedOut.AccessToken)
+	otherClaims, _ := authService.ValidateToken(otherDevice.AccessToken)
+	revoked, err := authService.RevokedTokens(ctx, []string{loggedOutClaims.ID, otherClaims.ID})
+	if err != nil {
+		t.Fatalf("revoked tokens: %v", err)
+	}
+	if !revoked[loggedOutClaims.ID] || revoked[otherClaims.ID] {
+		t.Fatalf("got revoked %v, want only the logged out token", revoked)
+	}
+}
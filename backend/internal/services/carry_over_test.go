@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestCarryOverActions(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	endRetro := func(retro *models.Retrospective) {
+		t.Helper()
+		if _, err := svc.Start(ctx, retro.ID); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		if _, err := svc.End(ctx, retro.ID); err != nil {
+			t.Fatalf("end: %v", err)
+		}
+	}
+
+	first := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	open, err := svc.CreateAction(ctx, first.ID, alice.ID, services.CreateActionInput{Title: "Write the runbook", AssigneeID: &alice.ID})
+	if err != nil {
+		t.Fatalf("create action: %v", err)
+	}
+	done, _ := svc.CreateAction(ctx, first.ID, alice.ID, services.CreateActionInput{Title: "Rotate the keys"})
+	if _, err := svc.CompleteAction(ctx, first.ID, done.ID, alice.ID); err != nil {
+		t.Fatalf("complete action: %v", err)
+	}
+	endRetro(first)
+
+	// Without the flag nothing is carried over
+	plain := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if actions, _ := svc.ListActions(ctx, plain.ID); len(actions) != 0 {
+		t.Fatalf("retro without carry-over has %d actions, want none", len(actions))
+	}
+
+	maxActions := 1
+	second := env.CreateRetro(t, team, alice, services.CreateRetroInput{CarryOverActions: true, MaxActionsPerRetro: &maxActions})
+	actions, _ := svc.ListActions(ctx, second.ID)
+	if len(actions) != 1 {
+		t.Fatalf("second retro has %d actions, want the unfinished one", len(actions))
+	}
+	carried := actions[0]
+	if carried.Title != open.Title || carried.AssigneeID == nil || *carried.AssigneeID != alice.ID ||
+		carried.CarriedFromID == nil || *carried.CarriedFromID != open.ID ||
+		carried.OriginRetroID == nil || *carried.OriginRetroID != first.ID {
+		t.Fatalf("carried action = %+v, want a copy of %s from the first retro", carried, open.ID)
+	}
+	// The carried over action leaves the retro's own allowance untouched
+	if remaining, _ := svc.GetActionAllowance(ctx, second.ID); remaining == nil || *remaining != 1 {
+		t.Errorf("actions remaining = %v, want 1", remaining)
+	}
+
+	// The first retro is still the last completed one, but its action was carried over already
+	if again, err := svc.CarryOverActions(ctx, plain); err != nil || len(again) != 0 {
+		t.Fatalf("carrying over again = %v, %v, want nothing", again, err)
+	}
+
+	// The copy carries on, keeping the retro the action came from
+	endRetro(second)
+	third := env.CreateRetro(t, team, alice, services.CreateRetroInput{CarryOverActions: true})
+	actions, _ = svc.ListActions(ctx, third.ID)
+	if len(actions) != 1 || *actions[0].CarriedFromID != carried.ID || *actions[0].OriginRetroID != first.ID {
+		t.Fatalf("third retro actions = %+v, want the copy of the second retro's action", actions)
+	}
+
+	mine, err := svc.ListMyActions(ctx, alice.ID, &models.ActionListFilter{})
+	if err != nil || len(mine) != 1 || mine[0].ID != actions[0].ID {
+		t.Fatalf("my actions = %v, %v, want only the latest copy", mine, err)
+	}
+}
@@ -35,19 +35,28 @@ type DevUsersResponse struct {
 type DevSeeder struct {
 	teamRepo       *postgres.TeamRepository
 	teamMemberRepo *postgres.TeamMemberRepository
+	devRepo        *postgres.DevRepository
 }
 
 // NewDevSeeder creates a new DevSeeder
 func NewDevSeeder(
 	teamRepo *postgres.TeamRepository,
 	teamMemberRepo *postgres.TeamMemberRepository,
+	devRepo *postgres.DevRepository,
 ) *DevSeeder {
 	return &DevSeeder{
 		teamRepo:       teamRepo,
 		teamMemberRepo: teamMemberRepo,
+		devRepo:        devRepo,
 	}
 }
 
+// Reset deletes the retrospectives of the team, or of every team when teamID is nil, and
+// restores the dev team and users, so that each e2e run starts from the same state
+func (s *DevSeeder) Reset(ctx context.Context, teamID *uuid.UUID) (*models.DevResetSummary, error) {
+	return s.devRepo.Reset(ctx, teamID)
+}
+
 // GetDevUsersInfo returns the list of dev users with their IDs
 // Dev users are seeded via SQL migration (000004_dev_users_seed.up.sql)
 func (s *DevSeeder) GetDevUsersInfo(ctx context.Context) (*DevUsersResponse, error) {
@@ -0,0 +1,83 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestDevSeederReset(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+	seeder := services.NewDevSeeder(env.Repos.Teams, env.Repos.TeamMembers, env.Repos.Dev)
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	reset := env.CreateTeam(t, alice)
+	kept := env.CreateTeam(t, bob)
+
+	retro := env.CreateRetro(t, reset, alice, services.CreateRetroInput{})
+	template, err := svc.GetTemplate(ctx, retro.TemplateID)
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	item, err := svc.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: template.Columns[0].ID, Content: "Flaky tests"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	if err := svc.Vote(ctx, retro.ID, item.ID, alice.ID); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if _, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Quarantine them"}); err != nil {
+		t.Fatalf("create action: %v", err)
+	}
+	other := env.CreateRetro(t, kept, bob, services.CreateRetroInput{})
+
+	summary, err := seeder.Reset(ctx, &reset.ID)
+	if err != nil {
+		t.Fatalf("reset team: %v", err)
+	}
+	want := models.DevResetSummary{TeamID: &reset.ID, Retrospectives: 1, Items: 1, Votes: 1, Actions: 1}
+	if *summary != want {
+		t.Fatalf("got summary %+v, want %+v", *summary, want)
+	}
+	if _, err := env.Repos.Retros.FindByID(ctx, retro.ID); !errors.Is(err, postgres.ErrNotFound) {
+		t.Fatalf("reset retro lookup got %v, want not found", err)
+	}
+	if _, err := env.Repos.Retros.FindByID(ctx, other.ID); err != nil {
+		t.Fatalf("another team's retro was reset: %v", err)
+	}
+
+	// A global reset also restores the dev team to its seeded members
+	devTeam, err := env.Repos.Teams.FindBySlug(ctx, "dev-team")
+	if err != nil {
+		t.Fatalf("find dev team: %v", err)
+	}
+	env.AddMember(t, devTeam, bob, models.RoleMember)
+
+	summary, err = seeder.Reset(ctx, nil)
+	if err != nil {
+		t.Fatalf("reset all: %v", err)
+	}
+	if summary.TeamID != nil || summary.Retrospectives != 1 {
+		t.Fatalf("got summary %+v, want the remaining retro of every team", *summary)
+	}
+	info, err := seeder.GetDevUsersInfo(ctx)
+	if err != nil {
+		t.Fatalf("dev users: %v", err)
+	}
+	if len(info.Users) != 6 {
+		t.Fatalf("got %d dev team members, want the 6 seeded dev users", len(info.Users))
+	}
+	for _, user := range info.Users {
+		if user.ID == bob.ID {
+			t.Fatal("a member added during the run is still in the dev team")
+		}
+	}
+}
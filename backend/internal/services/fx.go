@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"time"
+
 	"go.uber.org/fx"
 
 	"github.com/jycamier/retrotro/backend/internal/auth"
@@ -20,31 +23,48 @@ var Module = fx.Module("service",
 		NewWebhookServiceFx,
 		NewLeanCoffeeServiceFx,
 		NewAnalysisServiceFx,
+		NewActivityServiceFx,
+		NewSurveyServiceFx,
+		NewRecurringRetroServiceFx,
+		NewSnapshotServiceFx,
+		NewIdempotencyServiceFx,
+		NewRetroSchedulerFx,
 	),
+	fx.Invoke(func(*RetroScheduler) {}),
 )
 
 // NewAuthServiceFx creates the auth service for fx
-func NewAuthServiceFx(oidc *auth.OIDCProvider, userRepo *postgres.UserRepository, jit *auth.JITProvisioner, cfg *config.Config) *AuthService {
-	return NewAuthService(oidc, userRepo, jit, cfg.JWT)
+func NewAuthServiceFx(oidc *auth.OIDCProvider, userRepo *postgres.UserRepository, jit *auth.JITProvisioner, debugRepo *postgres.OIDCDebugRepository, inviteRepo *postgres.TeamInviteRepository, revokedTokenRepo *postgres.RevokedTokenRepository, cfg *config.Config) *AuthService {
+	var debugStore OIDCDebugStore
+	if cfg.OIDC.DebugClaims {
+		debugStore = debugRepo
+	}
+	return NewAuthService(oidc, userRepo, jit, cfg.JWT, debugStore, time.Duration(cfg.OIDC.DebugClaimsTTL)*time.Minute, inviteRepo, revokedTokenRepo)
 }
 
 // NewTeamServiceFx creates the team service for fx
-func NewTeamServiceFx(teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository, userRepo *postgres.UserRepository) *TeamService {
-	return NewTeamService(teamRepo, teamMemberRepo, userRepo)
+func NewTeamServiceFx(teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository, userRepo *postgres.UserRepository, inviteRepo *postgres.TeamInviteRepository) *TeamService {
+	return NewTeamService(teamRepo, teamMemberRepo, userRepo, inviteRepo)
 }
 
 // NewRetrospectiveServiceFx creates the retrospective service for fx
 func NewRetrospectiveServiceFx(
 	retroRepo *postgres.RetrospectiveRepository,
+	teamRepo *postgres.TeamRepository,
 	templateRepo *postgres.TemplateRepository,
 	itemRepo *postgres.ItemRepository,
 	voteRepo *postgres.VoteRepository,
 	actionRepo *postgres.ActionItemRepository,
 	icebreakerRepo *postgres.IcebreakerRepository,
 	rotiRepo *postgres.RotiRepository,
+	teamMemberRepo *postgres.TeamMemberRepository,
+	phaseHistoryRepo *postgres.PhaseHistoryRepository,
+	attendeeRepo *postgres.AttendeeRepository,
+	itemHistoryRepo *postgres.ItemHistoryRepository,
+	actionCommentRepo *postgres.ActionCommentRepository,
 	webhookService *WebhookService,
 ) *RetrospectiveService {
-	return NewRetrospectiveService(retroRepo, templateRepo, itemRepo, voteRepo, actionRepo, icebreakerRepo, rotiRepo, webhookService)
+	return NewRetrospectiveService(retroRepo, teamRepo, templateRepo, itemRepo, voteRepo, actionRepo, icebreakerRepo, rotiRepo, teamMemberRepo, phaseHistoryRepo, attendeeRepo, itemHistoryRepo, actionCommentRepo, webhookService)
 }
 
 // NewTimerServiceFx creates the timer service for fx
@@ -58,16 +78,20 @@ func NewStatsServiceFx(statsRepo *postgres.StatsRepository, teamMemberRepo *post
 }
 
 // NewDevSeederFx creates the dev seeder for fx (nil if not in dev mode)
-func NewDevSeederFx(cfg *config.Config, teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository) *DevSeeder {
+func NewDevSeederFx(cfg *config.Config, teamRepo *postgres.TeamRepository, teamMemberRepo *postgres.TeamMemberRepository, devRepo *postgres.DevRepository) *DevSeeder {
 	if cfg.DevMode {
-		return NewDevSeeder(teamRepo, teamMemberRepo)
+		return NewDevSeeder(teamRepo, teamMemberRepo, devRepo)
 	}
 	return nil
 }
 
 // NewWebhookServiceFx creates the webhook service for fx
-func NewWebhookServiceFx(webhookRepo *postgres.WebhookRepository, deliveryRepo *postgres.WebhookDeliveryRepository) *WebhookService {
-	return NewWebhookService(webhookRepo, deliveryRepo)
+func NewWebhookServiceFx(webhookRepo *postgres.WebhookRepository, deliveryRepo *postgres.WebhookDeliveryRepository, cfg *config.Config) (*WebhookService, error) {
+	policy, err := NewWebhookURLPolicy(cfg.Webhooks.AllowedNetworks, cfg.Webhooks.DeniedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	return NewWebhookService(webhookRepo, deliveryRepo, policy), nil
 }
 
 // NewAnalysisServiceFx creates the analysis service for fx
@@ -75,6 +99,16 @@ func NewAnalysisServiceFx(lcService *LeanCoffeeService) *AnalysisService {
 	return NewAnalysisService(lcService)
 }
 
+// NewActivityServiceFx creates the activity service for fx
+func NewActivityServiceFx(activityRepo *postgres.ActivityRepository, teamMemberRepo *postgres.TeamMemberRepository) *ActivityService {
+	return NewActivityService(activityRepo, teamMemberRepo)
+}
+
+// NewSurveyServiceFx creates the survey service for fx
+func NewSurveyServiceFx(surveyRepo *postgres.SurveyRepository, retroRepo *postgres.RetrospectiveRepository) *SurveyService {
+	return NewSurveyService(surveyRepo, retroRepo)
+}
+
 // NewLeanCoffeeServiceFx creates the lean coffee service for fx
 func NewLeanCoffeeServiceFx(
 	retroRepo *postgres.RetrospectiveRepository,
@@ -84,3 +118,41 @@ func NewLeanCoffeeServiceFx(
 ) *LeanCoffeeService {
 	return NewLeanCoffeeService(retroRepo, itemRepo, voteRepo, topicHistoryRepo)
 }
+
+// NewSnapshotServiceFx creates the snapshot service for fx
+func NewSnapshotServiceFx(snapshotRepo *postgres.SnapshotRepository) *SnapshotService {
+	return NewSnapshotService(snapshotRepo)
+}
+
+// NewIdempotencyServiceFx creates the idempotency service for fx
+func NewIdempotencyServiceFx(idempotencyRepo *postgres.IdempotencyRepository) *IdempotencyService {
+	return NewIdempotencyService(idempotencyRepo)
+}
+
+// NewRecurringRetroServiceFx creates the recurring retrospective service for fx
+func NewRecurringRetroServiceFx(teamMemberRepo *postgres.TeamMemberRepository) *RecurringRetroService {
+	return NewRecurringRetroService(teamMemberRepo)
+}
+
+// NewRetroSchedulerFx creates the scheduled retro starter and reminder and runs it for the app's lifetime
+func NewRetroSchedulerFx(lc fx.Lifecycle, retroRepo *postgres.RetrospectiveRepository, retroService *RetrospectiveService, cfg *config.Config) *RetroScheduler {
+	var frontendURL string
+	if len(cfg.CORSOrigins) > 0 {
+		frontendURL = cfg.CORSOrigins[0]
+	}
+	scheduler := NewRetroScheduler(retroRepo, retroService,
+		time.Duration(cfg.ScheduledStartGrace)*time.Second,
+		time.Duration(cfg.RetroReminderMinutes)*time.Minute,
+		frontendURL)
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			scheduler.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			scheduler.Stop()
+			return nil
+		},
+	})
+	return scheduler
+}
@@ -0,0 +1,208 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/auth"
+	"github.com/jycamier/retrotro/backend/internal/config"
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+var guestJWTConfig = config.JWTConfig{Secret: "test-secret", AccessTokenTTL: 15, RefreshTokenTTL: 1, GuestTokenTTL: 60}
+
+// shareLink shares the retro as its facilitator and returns the guest link token
+func shareLink(t *testing.T, env *testenv.Env, authService *services.AuthService, retro *models.Retrospective) string {
+	t.Helper()
+	shared, err := env.Services.Retro.CreateShare(context.Background(), retro.ID, retro.FacilitatorID, time.Hour, false)
+	if err != nil {
+		t.Fatalf("share retro: %v", err)
+	}
+	link, err := authService.CreateGuestLinkToken(retro.ID, *shared.ShareID, *shared.ShareExpiresAt)
+	if err != nil {
+		t.Fatalf("create guest link: %v", err)
+	}
+	return link
+}
+
+// joinAsGuest creates a guest with a fresh guest link to the retro
+func joinAsGuest(t *testing.T, env *testenv.Env, authService *services.AuthService, retro *models.Retrospective, name string) (*models.User, string) {
+	t.Helper()
+	link := shareLink(t, env, authService, retro)
+	claims, err := authService.ValidateGuestToken(link)
+	if err != nil {
+		t.Fatalf("validate guest link: %v", err)
+	}
+	guest, session, err := authService.JoinAsGuest(context.Background(), claims, name)
+	if err != nil {
+		t.Fatalf("join as guest: %v", err)
+	}
+	return guest, session
+}
+
+func TestJoinAsGuest(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	authService := services.NewAuthService(nil, env.Repos.Users, nil, guestJWTConfig, nil, 0, nil, nil)
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{AllowGuests: true})
+
+	guest, session := joinAsGuest(t, env, authService, retro, "  Gina  ")
+	if !guest.IsGuest || guest.DisplayName != "Gina" {
+		t.Fatalf("guest = %+v, want a guest named Gina", guest)
+	}
+	if _, err := authService.ValidateToken(session); err == nil {
+		t.Fatal("guest session token was accepted as an access token")
+	}
+
+	// Reconnecting with the session token keeps the guest's identity
+	claims, err := authService.ValidateGuestToken(session)
+	if err != nil {
+		t.Fatalf("validate session: %v", err)
+	}
+	again, next, err := authService.JoinAsGuest(ctx, claims, "")
+	if err != nil || again.ID != guest.ID || next != "" {
+		t.Fatalf("rejoin = %v, %q, %v, want the same guest and no new token", again, next, err)
+	}
+
+	link := shareLink(t, env, authService, retro)
+	linkClaims, _ := authService.ValidateGuestToken(link)
+	if _, _, err := authService.JoinAsGuest(ctx, linkClaims, "   "); !errors.Is(err, services.ErrInvalidGuestName) {
+		t.Errorf("blank name = %v, want ErrInvalidGuestName", err)
+	}
+
+	// A session token naming a member must not log in as that member
+	forged, err := auth.NewJWTManager(guestJWTConfig.Secret, 15, 1).GenerateGuestToken(retro.ID, uuid.New(), alice.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	forgedClaims, _ := authService.ValidateGuestToken(forged)
+	if _, _, err := authService.JoinAsGuest(ctx, forgedClaims, ""); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("session token of a member = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestGuestContributionsAreMarkedAndLeftOutOfStats(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	authService := services.NewAuthService(nil, env.Repos.Users, nil, guestJWTConfig, nil, 0, nil, nil)
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{AllowGuests: true})
+	guest, _ := joinAsGuest(t, env, authService, retro, "Gina")
+
+	memberItem, err := env.Services.Retro.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: "start", Content: "member"})
+	if err != nil {
+		t.Fatalf("create member item: %v", err)
+	}
+	guestItem, err := env.Services.Retro.CreateItem(ctx, retro.ID, guest.ID, services.CreateItemInput{ColumnID: "start", Content: "guest"})
+	if err != nil {
+		t.Fatalf("create guest item: %v", err)
+	}
+	if memberItem.IsGuest || !guestItem.IsGuest {
+		t.Errorf("isGuest = %v for the member item and %v for the guest item, want false and true", memberItem.IsGuest, guestItem.IsGuest)
+	}
+	vote, err := env.Repos.Votes.Create(ctx, &models.Vote{ItemID: memberItem.ID, UserID: guest.ID})
+	if err != nil {
+		t.Fatalf("guest vote: %v", err)
+	}
+	if !vote.IsGuest {
+		t.Error("guest vote is not marked as a guest's")
+	}
+
+	if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseRoti); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+	for user, rating := range map[*models.User]int{alice: 4, guest: 1} {
+		if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, user.ID, rating, ""); err != nil {
+			t.Fatalf("roti vote: %v", err)
+		}
+	}
+	if _, err := env.Services.Retro.End(ctx, retro.ID); err != nil {
+		t.Fatalf("end retro: %v", err)
+	}
+
+	stats, err := env.Services.Stats.GetTeamRotiStats(ctx, alice.ID, team.ID, nil)
+	if err != nil {
+		t.Fatalf("team roti stats: %v", err)
+	}
+	if stats.TotalVotes != 1 || stats.Average != 4 {
+		t.Errorf("team roti stats = %d votes averaging %v, want only the member's vote", stats.TotalVotes, stats.Average)
+	}
+}
+
+func TestShareLinkRevocation(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	if _, err := env.Services.Retro.CreateShare(ctx, retro.ID, bob.ID, time.Hour, false); !errors.Is(err, services.ErrNotFacilitator) {
+		t.Errorf("share by a member = %v, want ErrNotFacilitator", err)
+	}
+	if _, err := env.Services.Retro.CreateShare(ctx, retro.ID, alice.ID, 0, false); !errors.Is(err, services.ErrInvalidShareExpiry) {
+		t.Errorf("share without expiry = %v, want ErrInvalidShareExpiry", err)
+	}
+
+	first, err := env.Services.Retro.CreateShare(ctx, retro.ID, alice.ID, time.Hour, true)
+	if err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	got, err := env.Services.Retro.CheckShare(ctx, retro.ID, *first.ShareID)
+	if err != nil || !got.AllowGuests || !got.ShareSpectatorOnly {
+		t.Fatalf("check share = %+v, %v, want a spectator-only share that allows guests", got, err)
+	}
+
+	// A new link replaces the previous one
+	second, err := env.Services.Retro.CreateShare(ctx, retro.ID, alice.ID, time.Hour, false)
+	if err != nil {
+		t.Fatalf("share again: %v", err)
+	}
+	if _, err := env.Services.Retro.CheckShare(ctx, retro.ID, *first.ShareID); !errors.Is(err, services.ErrShareRevoked) {
+		t.Errorf("replaced link = %v, want ErrShareRevoked", err)
+	}
+
+	if err := env.Services.Retro.RevokeShare(ctx, retro.ID, alice.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := env.Services.Retro.CheckShare(ctx, retro.ID, *second.ShareID); !errors.Is(err, services.ErrShareRevoked) {
+		t.Errorf("revoked link = %v, want ErrShareRevoked", err)
+	}
+	if err := env.Services.Retro.RevokeShare(ctx, retro.ID, alice.ID); !errors.Is(err, services.ErrShareNotFound) {
+		t.Errorf("second revoke = %v, want ErrShareNotFound", err)
+	}
+}
+
+func TestValidateShare(t *testing.T) {
+	shareID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+	active := &models.Retrospective{AllowGuests: true, ShareID: &shareID, ShareExpiresAt: &expiresAt}
+
+	if err := services.ValidateShare(active, shareID, time.Now()); err != nil {
+		t.Errorf("active link = %v, want nil", err)
+	}
+	if err := services.ValidateShare(active, uuid.New(), time.Now()); !errors.Is(err, services.ErrShareRevoked) {
+		t.Errorf("replaced link = %v, want ErrShareRevoked", err)
+	}
+	if err := services.ValidateShare(active, shareID, expiresAt.Add(time.Second)); !errors.Is(err, services.ErrShareExpired) {
+		t.Errorf("expired link = %v, want ErrShareExpired", err)
+	}
+	if err := services.ValidateShare(&models.Retrospective{AllowGuests: true}, shareID, time.Now()); !errors.Is(err, services.ErrShareRevoked) {
+		t.Errorf("revoked link = %v, want ErrShareRevoked", err)
+	}
+	if err := services.ValidateShare(&models.Retrospective{ShareID: &shareID}, shareID, time.Now()); !errors.Is(err, services.ErrGuestsNotAllowed) {
+		t.Errorf("retro closed to guests = %v, want ErrGuestsNotAllowed", err)
+	}
+}
@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestIcebreakerMoodSetChangeAndClear(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseIcebreaker); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+
+	count := func() int {
+		t.Helper()
+		n, err := env.Services.Retro.CountIcebreakerMoods(ctx, retro.ID)
+		if err != nil {
+			t.Fatalf("count moods: %v", err)
+		}
+		return n
+	}
+
+	if _, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, alice.ID, models.MoodSunny); err != nil {
+		t.Fatalf("set mood: %v", err)
+	}
+	if _, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, bob.ID, models.MoodCloudy); err != nil {
+		t.Fatalf("set mood: %v", err)
+	}
+
+	// Changing a mood replaces it rather than adding one
+	mood, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, alice.ID, models.MoodRainy)
+	if err != nil {
+		t.Fatalf("change mood: %v", err)
+	}
+	if mood.Mood != models.MoodRainy {
+		t.Errorf("mood = %q, want rainy", mood.Mood)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("moodCount after change = %d, want 2", n)
+	}
+
+	if err := env.Services.Retro.ClearIcebreakerMood(ctx, retro.ID, alice.ID); err != nil {
+		t.Fatalf("clear mood: %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("moodCount after clear = %d, want 1", n)
+	}
+	// Clearing twice is harmless and does not decrement again
+	if err := env.Services.Retro.ClearIcebreakerMood(ctx, retro.ID, alice.ID); err != nil {
+		t.Fatalf("clear mood again: %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("moodCount after second clear = %d, want 1", n)
+	}
+
+	if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseBrainstorm); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+	if _, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, alice.ID, models.MoodSunny); !errors.Is(err, services.ErrInvalidPhase) {
+		t.Errorf("set mood during brainstorm = %v, want ErrInvalidPhase", err)
+	}
+	if err := env.Services.Retro.ClearIcebreakerMood(ctx, retro.ID, bob.ID); !errors.Is(err, services.ErrInvalidPhase) {
+		t.Errorf("clear mood during brainstorm = %v, want ErrInvalidPhase", err)
+	}
+}
+
+func TestIcebreakerMoodFollowsTemplateScale(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+
+	if _, err := env.Services.Retro.CreateTemplate(ctx, &models.Template{
+		Name:      "Duplicate values",
+		TeamID:    &team.ID,
+		MoodScale: []models.MoodOption{{Value: "1"}, {Value: "1"}},
+	}); !errors.Is(err, services.ErrInvalidMoodScale) {
+		t.Fatalf("create template with duplicate moods = %v, want ErrInvalidMoodScale", err)
+	}
+
+	template, err := env.Services.Retro.CreateTemplate(ctx, &models.Template{
+		Name:   "Energy",
+		TeamID: &team.ID,
+		Columns: []models.TemplateColumn{
+			{ID: "went_well", Name: "Went well", Color: "#22c55e"},
+		},
+		MoodScale: []models.MoodOption{
+			{Value: "low", Label: "Low energy"},
+			{Value: "medium", Label: "Medium energy"},
+			{Value: "high", Label: "High energy"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{TemplateID: template.ID})
+
+	scale, err := env.Services.Retro.GetMoodScale(ctx, retro)
+	if err != nil {
+		t.Fatalf("get mood scale: %v", err)
+	}
+	if len(scale) != 3 || scale[2].Value != "high" {
+		t.Errorf("mood scale = %+v, want the template's energy scale", scale)
+	}
+
+	if _, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, alice.ID, "high"); err != nil {
+		t.Fatalf("set custom mood: %v", err)
+	}
+	if _, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, alice.ID, models.MoodSunny); !errors.Is(err, services.ErrInvalidMood) {
+		t.Errorf("weather mood on an energy scale = %v, want ErrInvalidMood", err)
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+)
+
+var (
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for another request")
+)
+
+// idempotencyKeyTTL is how long a response is replayed to retries with the same key
+const idempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyKeyStaleAfter frees a key whose request never completed, e.g. after a crash.
+// It is longer than the router's request timeout.
+const idempotencyKeyStaleAfter = 2 * time.Minute
+
+// IdempotencyService remembers the responses of requests sent with an Idempotency-Key header,
+// so a client retrying after a lost response does not create the resource twice
+type IdempotencyService struct {
+	idempotencyRepo *postgres.IdempotencyRepository
+}
+
+// NewIdempotencyService creates a new idempotency service
+func NewIdempotencyService(idempotencyRepo *postgres.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{idempotencyRepo: idempotencyRepo}
+}
+
+// Begin claims the user's key for the request. It returns nil when the request should be
+// processed, or the stored response to replay when the key was already used for it.
+func (s *IdempotencyService) Begin(ctx context.Context, userID uuid.UUID, key, requestHash string) (*models.IdempotencyKey, error) {
+	now := time.Now()
+	stored, err := s.idempotencyRepo.Reserve(ctx, userID, key, requestHash, now.Add(idempotencyKeyTTL), now.Add(-idempotencyKeyStaleAfter))
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+	if stored == nil {
+		return nil, nil
+	}
+	if stored.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return stored, nil
+}
+
+// Finish stores the response of the request that claimed the key
+func (s *IdempotencyService) Finish(ctx context.Context, userID uuid.UUID, key string, statusCode int, contentType string, body []byte) error {
+	return s.idempotencyRepo.Complete(ctx, userID, key, statusCode, contentType, body)
+}
+
+// Abandon frees the key after a failed request, so the client may retry with it
+func (s *IdempotencyService) Abandon(ctx context.Context, userID uuid.UUID, key string) error {
+	return s.idempotencyRepo.Release(ctx, userID, key)
+}
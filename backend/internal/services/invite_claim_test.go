@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+type recordingInviteClaimer struct {
+	emails []string
+}
+
+func (r *recordingInviteClaimer) ClaimForUser(ctx context.Context, userID uuid.UUID, email string) ([]uuid.UUID, error) {
+	r.emails = append(r.emails, email)
+	return nil, nil
+}
+
+func TestClaimInvitesRequiresVerifiedEmail(t *testing.T) {
+	invites := &recordingInviteClaimer{}
+	s := &AuthService{invites: invites}
+	user := &models.User{ID: uuid.New(), Email: "new.hire@example.com"}
+
+	s.claimInvites(context.Background(), user, false)
+	if len(invites.emails) != 0 {
+		t.Fatalf("claimed invites for an unverified email: %v", invites.emails)
+	}
+
+	s.claimInvites(context.Background(), user, true)
+	if len(invites.emails) != 1 || invites.emails[0] != user.Email {
+		t.Fatalf("claimed %v, want the invites of %s", invites.emails, user.Email)
+	}
+}
@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestImportItems(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	template, err := svc.GetTemplate(ctx, retro.TemplateID)
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	first, second := template.Columns[0].ID, template.Columns[1].ID
+
+	if _, err := svc.CreateItem(ctx, retro.ID, bob.ID, services.CreateItemInput{ColumnID: first, Content: "Already there"}); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	items, err := svc.ImportItems(ctx, retro.ID, alice.ID, []services.CreateItemInput{
+		{ColumnID: first, Content: "One"},
+		{ColumnID: second, Content: "Two"},
+		{ColumnID: first, Content: "  Three  "},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	wantPositions := []int{1, 0, 2}
+	for i, item := range items {
+		if item.Position != wantPositions[i] {
+			t.Errorf("item %d %q got position %d, want %d", i, item.Content, item.Position, wantPositions[i])
+		}
+	}
+	if items[2].Content != "Three" {
+		t.Errorf("got content %q, want it trimmed", items[2].Content)
+	}
+
+	// One invalid item rejects the whole batch
+	_, err = svc.ImportItems(ctx, retro.ID, alice.ID, []services.CreateItemInput{
+		{ColumnID: first, Content: "Fine"},
+		{ColumnID: "nope", Content: " "},
+	})
+	var importErr *services.ItemImportError
+	if !errors.As(err, &importErr) {
+		t.Fatalf("got %v, want an item import error", err)
+	}
+	if importErr.Fields["items[1].columnId"] == "" || importErr.Fields["items[1].content"] == "" || len(importErr.Fields) != 2 {
+		t.Fatalf("got fields %v, want the column and content of the second item", importErr.Fields)
+	}
+	all, err := svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("got %d items, want the rejected batch not to be created", len(all))
+	}
+
+	if _, err := svc.ImportItems(ctx, retro.ID, bob.ID, []services.CreateItemInput{{ColumnID: first, Content: "Mine"}}); !errors.Is(err, services.ErrNotFacilitator) {
+		t.Fatalf("import by a participant got %v, want ErrNotFacilitator", err)
+	}
+
+	tooMany := make([]services.CreateItemInput, services.MaxImportedItems+1)
+	for i := range tooMany {
+		tooMany[i] = services.CreateItemInput{ColumnID: first, Content: "x"}
+	}
+	if _, err := svc.ImportItems(ctx, retro.ID, alice.ID, tooMany); !errors.Is(err, services.ErrInvalidItemImport) {
+		t.Fatalf("oversized import got %v, want ErrInvalidItemImport", err)
+	}
+}
@@ -0,0 +1,186 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestConcurrentCreateItemsGetDistinctPositions(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	const creates = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, creates)
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: "start", Content: fmt.Sprintf("Idea %d", i)})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+
+	items, err := svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if seen[item.Position] {
+			t.Fatalf("two items at position %d", item.Position)
+		}
+		seen[item.Position] = true
+	}
+	for pos := 0; pos < creates; pos++ {
+		if !seen[pos] {
+			t.Fatalf("no item at position %d, want positions 0..%d", pos, creates-1)
+		}
+	}
+}
+
+func TestReindexColumn(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	for _, content := range []string{"A", "B", "C", "D"} {
+		if _, err := svc.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: "start", Content: content}); err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+	items, err := svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	// Deleting B and D leaves A at 0 and C at 2
+	for _, item := range items {
+		if item.Content == "B" || item.Content == "D" {
+			if err := svc.DeleteItem(ctx, retro.ID, item.ID); err != nil {
+				t.Fatalf("delete item: %v", err)
+			}
+		}
+	}
+
+	if err := env.Repos.Items.ReindexColumn(ctx, retro.ID, "start"); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	items, err = svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(items) != 2 || items[0].Content != "A" || items[0].Position != 0 || items[1].Content != "C" || items[1].Position != 1 {
+		t.Fatalf("got %+v, want A at 0 and C at 1", items)
+	}
+}
+
+func TestConcurrentMovesKeepPositionsContiguous(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	const moves = 10
+	var ids []uuid.UUID
+	for i := 0; i < moves; i++ {
+		item, err := svc.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: "start", Content: fmt.Sprintf("Idea %d", i)})
+		if err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+
+	// Every item moves to the top of the same column at once
+	var wg sync.WaitGroup
+	errs := make(chan error, moves)
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			_, err := svc.MoveItem(ctx, retro.ID, id, "stop", 0)
+			errs <- err
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("move item: %v", err)
+		}
+	}
+
+	items, err := svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	for i, item := range items {
+		if item.ColumnID != "stop" || item.Position != i {
+			t.Fatalf("item %q at %s/%d, want every item in stop at positions 0..%d", item.Content, item.ColumnID, item.Position, moves-1)
+		}
+	}
+}
+
+func TestImportItemsCompactsColumns(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	var deleted uuid.UUID
+	for _, content := range []string{"A", "B", "C"} {
+		item, err := svc.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: "start", Content: content})
+		if err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+		if content == "B" {
+			deleted = item.ID
+		}
+	}
+	// Deleting B leaves A at 0 and C at 2
+	if err := svc.DeleteItem(ctx, retro.ID, deleted); err != nil {
+		t.Fatalf("delete item: %v", err)
+	}
+
+	imported, err := svc.ImportItems(ctx, retro.ID, alice.ID, []services.CreateItemInput{{ColumnID: "start", Content: "D"}})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(imported) != 1 || imported[0].Position != 2 {
+		t.Fatalf("imported %+v, want D right after the compacted column at 2", imported)
+	}
+	items, err := svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	for i, want := range []string{"A", "C", "D"} {
+		if items[i].Content != want || items[i].Position != i {
+			t.Fatalf("got %+v, want A, C and D at 0, 1 and 2", items)
+		}
+	}
+}
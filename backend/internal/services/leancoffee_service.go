@@ -16,6 +16,7 @@ import (
 var (
 	ErrNoTopicsToDiscuss = errors.New("no topics to discuss")
 	ErrSessionNotLC      = errors.New("session is not a lean coffee")
+	ErrTopicNotInSession = errors.New("topic does not belong to this session")
 )
 
 // LCDiscussionState represents the current state of a Lean Coffee discussion
@@ -109,7 +110,7 @@ func (s *LeanCoffeeService) NextTopic(ctx context.Context, sessionID uuid.UUID)
 	if len(candidates) == 0 {
 		// No more topics - clear current topic
 		retro.LCCurrentTopicID = nil
-		if err := s.retroRepo.Update(ctx, retro); err != nil {
+		if err := s.retroRepo.UpdateLCCurrentTopic(ctx, sessionID, nil); err != nil {
 			return nil, nil, err
 		}
 		return nil, retro, ErrNoTopicsToDiscuss
@@ -146,7 +147,7 @@ func (s *LeanCoffeeService) NextTopic(ctx context.Context, sessionID uuid.UUID)
 
 	// Update current topic on retro
 	retro.LCCurrentTopicID = &nextTopic.ID
-	if err := s.retroRepo.Update(ctx, retro); err != nil {
+	if err := s.retroRepo.UpdateLCCurrentTopic(ctx, sessionID, retro.LCCurrentTopicID); err != nil {
 		return nil, nil, err
 	}
 
@@ -154,7 +155,9 @@ func (s *LeanCoffeeService) NextTopic(ctx context.Context, sessionID uuid.UUID)
 }
 
 // SetTopic sets a specific topic as the current discussion topic.
-// Used by discuss_set_item message handler.
+// Used by discuss_set_item message handler. Setting a topic that was already discussed
+// once the queue is empty ends the discussion: the current topic is cleared and
+// ErrNoTopicsToDiscuss is returned.
 func (s *LeanCoffeeService) SetTopic(ctx context.Context, sessionID, topicID uuid.UUID) (*models.LCTopicHistory, *models.Retrospective, error) {
 	retro, err := s.retroRepo.FindByID(ctx, sessionID)
 	if err != nil {
@@ -181,6 +184,19 @@ func (s *LeanCoffeeService) SetTopic(ctx context.Context, sessionID, topicID uui
 
 	// Check if topic already has history (resuming discussion)
 	history, err := s.topicHistoryRepo.FindByTopic(ctx, sessionID, topicID)
+	if err == nil && history.EndedAt != nil {
+		queued, err := s.hasQueuedTopics(ctx, sessionID, retro.LCCurrentTopicID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !queued {
+			retro.LCCurrentTopicID = nil
+			if err := s.retroRepo.UpdateLCCurrentTopic(ctx, sessionID, nil); err != nil {
+				return nil, nil, err
+			}
+			return nil, retro, ErrNoTopicsToDiscuss
+		}
+	}
 	if err != nil {
 		if !errors.Is(err, postgres.ErrNotFound) {
 			return nil, nil, err
@@ -206,13 +222,38 @@ func (s *LeanCoffeeService) SetTopic(ctx context.Context, sessionID, topicID uui
 
 	// Update current topic
 	retro.LCCurrentTopicID = &topicID
-	if err := s.retroRepo.Update(ctx, retro); err != nil {
+	if err := s.retroRepo.UpdateLCCurrentTopic(ctx, sessionID, retro.LCCurrentTopicID); err != nil {
 		return nil, nil, err
 	}
 
 	return history, retro, nil
 }
 
+// hasQueuedTopics reports whether a topic other than the current one is still waiting to be discussed
+func (s *LeanCoffeeService) hasQueuedTopics(ctx context.Context, sessionID uuid.UUID, currentTopicID *uuid.UUID) (bool, error) {
+	items, err := s.itemRepo.ListByRetro(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	histories, err := s.topicHistoryRepo.ListByRetro(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	discussedIDs := make(map[uuid.UUID]bool)
+	for _, h := range histories {
+		if h.EndedAt != nil {
+			discussedIDs[h.TopicID] = true
+		}
+	}
+	for _, item := range items {
+		if !discussedIDs[item.ID] && (currentTopicID == nil || item.ID != *currentTopicID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetDiscussionState returns the full discussion state for a Lean Coffee session
 func (s *LeanCoffeeService) GetDiscussionState(ctx context.Context, sessionID uuid.UUID) (*LCDiscussionState, error) {
 	retro, err := s.retroRepo.FindByID(ctx, sessionID)
@@ -264,6 +305,22 @@ func (s *LeanCoffeeService) GetDiscussionState(ctx context.Context, sessionID uu
 		return queue[i].CreatedAt.Before(queue[j].CreatedAt)
 	})
 
+	// Manually ordered topics come first, the rest keep the vote order
+	if len(retro.LCQueueOrder) > 0 {
+		manualPos := make(map[uuid.UUID]int, len(retro.LCQueueOrder))
+		for i, id := range retro.LCQueueOrder {
+			manualPos[id] = i
+		}
+		sort.SliceStable(queue, func(i, j int) bool {
+			pi, iManual := manualPos[queue[i].ID]
+			pj, jManual := manualPos[queue[j].ID]
+			if iManual && jManual {
+				return pi < pj
+			}
+			return iManual && !jManual
+		})
+	}
+
 	// Sort done by discussion order
 	doneOrderMap := make(map[uuid.UUID]int)
 	for _, h := range histories {
@@ -281,6 +338,48 @@ func (s *LeanCoffeeService) GetDiscussionState(ctx context.Context, sessionID uu
 	}, nil
 }
 
+// ReorderQueue stores a manual queue order for a Lean Coffee session.
+// An empty list clears the manual order and falls back to vote sorting.
+func (s *LeanCoffeeService) ReorderQueue(ctx context.Context, sessionID uuid.UUID, topicIDs []uuid.UUID) error {
+	retro, err := s.retroRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if retro.SessionType != models.SessionTypeLeanCoffee {
+		return ErrSessionNotLC
+	}
+
+	if len(topicIDs) == 0 {
+		return s.retroRepo.UpdateLCQueueOrder(ctx, sessionID, nil)
+	}
+
+	items, err := s.itemRepo.ListByRetro(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	itemIDs := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		itemIDs[item.ID] = true
+	}
+
+	seen := make(map[uuid.UUID]bool, len(topicIDs))
+	order := make([]uuid.UUID, 0, len(topicIDs))
+	for _, id := range topicIDs {
+		if !itemIDs[id] {
+			return ErrTopicNotInSession
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		order = append(order, id)
+	}
+
+	return s.retroRepo.UpdateLCQueueOrder(ctx, sessionID, order)
+}
+
 // GetTopicHistory returns the discussion history for a session
 func (s *LeanCoffeeService) GetTopicHistory(ctx context.Context, sessionID uuid.UUID) ([]*models.LCTopicHistory, error) {
 	return s.topicHistoryRepo.ListByRetro(ctx, sessionID)
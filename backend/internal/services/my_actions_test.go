@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func actionIDs(actions []*models.ActionItem) []uuid.UUID {
+	ids := make([]uuid.UUID, len(actions))
+	for i, action := range actions {
+		ids[i] = action.ID
+	}
+	return ids
+}
+
+func TestListMyActionsAcrossTeams(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	carol := env.CreateUser(t, "Carol")
+	platform := env.CreateTeam(t, alice, bob)
+	mobile := env.CreateTeam(t, bob, alice)
+	other := env.CreateTeam(t, carol)
+
+	createAction := func(team *models.Team, facilitator, assignee *models.User, due *time.Time) *models.ActionItem {
+		t.Helper()
+		retro := env.CreateRetro(t, team, facilitator, services.CreateRetroInput{})
+		action, err := svc.CreateAction(ctx, retro.ID, facilitator.ID, services.CreateActionInput{Title: "Action", AssigneeID: &assignee.ID, DueDate: due})
+		if err != nil {
+			t.Fatalf("create action: %v", err)
+		}
+		return action
+	}
+	soon, later := time.Now().Add(24*time.Hour), time.Now().Add(48*time.Hour)
+	dueLater := createAction(platform, alice, alice, &later)
+	dueSoon := createAction(mobile, bob, alice, &soon)
+	undated := createAction(platform, alice, alice, nil)
+	createAction(platform, alice, bob, &soon)
+	// Alice is not a member of that team
+	createAction(other, carol, alice, &soon)
+
+	done := "done"
+	if _, err := svc.PatchAction(ctx, undated.ID, alice.ID, services.PatchActionInput{Status: &done}); err != nil {
+		t.Fatalf("complete action: %v", err)
+	}
+
+	all, err := svc.ListMyActions(ctx, alice.ID, &models.ActionListFilter{Sort: models.ActionSortDueDate})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []uuid.UUID{dueSoon.ID, dueLater.ID, undated.ID}
+	if len(all) != len(want) {
+		t.Fatalf("actions = %v, want %v", actionIDs(all), want)
+	}
+	for i, id := range want {
+		if all[i].ID != id {
+			t.Fatalf("sorted by due date = %v, want %v", actionIDs(all), want)
+		}
+	}
+	if all[0].TeamName != mobile.Name || all[0].RetroName == "" {
+		t.Errorf("action has team %q and retro %q, want the names of its team and retro", all[0].TeamName, all[0].RetroName)
+	}
+
+	todo, err := svc.ListMyActions(ctx, alice.ID, &models.ActionListFilter{Status: "todo"})
+	if err != nil || len(todo) != 2 {
+		t.Errorf("todo actions = %v, %v, want the 2 open ones", actionIDs(todo), err)
+	}
+}
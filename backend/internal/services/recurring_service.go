@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+)
+
+const (
+	defaultPreviewCount = 5
+	maxPreviewCount     = 50
+)
+
+var (
+	ErrInvalidCron     = errors.New("invalid cron expression")
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// RecurringRetroService handles recurring retrospective operations
+type RecurringRetroService struct {
+	memberRepo *postgres.TeamMemberRepository
+}
+
+// NewRecurringRetroService creates a new recurring retrospective service
+func NewRecurringRetroService(memberRepo *postgres.TeamMemberRepository) *RecurringRetroService {
+	return &RecurringRetroService{memberRepo: memberRepo}
+}
+
+// PreviewOccurrencesInput represents input for previewing a recurring schedule
+type PreviewOccurrencesInput struct {
+	CronExpression string
+	Timezone       string
+	Count          int
+}
+
+// PreviewOccurrences returns the next occurrences of a cron schedule without persisting anything.
+// Occurrences are computed in the given timezone (UTC when empty).
+func (s *RecurringRetroService) PreviewOccurrences(ctx context.Context, userID, teamID uuid.UUID, input PreviewOccurrencesInput) ([]time.Time, error) {
+	isMember, err := s.memberRepo.IsMember(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotTeamMember
+	}
+
+	loc := time.UTC
+	if input.Timezone != "" {
+		loc, err = time.LoadLocation(input.Timezone)
+		if err != nil {
+			return nil, ErrInvalidTimezone
+		}
+	}
+
+	schedule, err := cron.ParseStandard(input.CronExpression)
+	if err != nil {
+		return nil, ErrInvalidCron
+	}
+
+	count := input.Count
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	if count > maxPreviewCount {
+		count = maxPreviewCount
+	}
+
+	occurrences := make([]time.Time, 0, count)
+	next := time.Now().In(loc)
+	for range count {
+		next = schedule.Next(next)
+		// Next returns the zero time when the schedule can never fire (e.g. Feb 30)
+		if next.IsZero() {
+			break
+		}
+		occurrences = append(occurrences, next)
+	}
+
+	return occurrences, nil
+}
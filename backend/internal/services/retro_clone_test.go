@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestCloneRetro(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	carol := env.CreateUser(t, "Carol")
+	team := env.CreateTeam(t, alice, bob)
+	env.CreateTeam(t, carol)
+
+	allowEdit := false
+	maxActions := 4
+	source := env.CreateRetro(t, team, alice, services.CreateRetroInput{
+		Name:                "Sprint 41",
+		MaxVotesPerUser:     7,
+		MaxVotesPerItem:     2,
+		AnonymousVoting:     true,
+		AnonymousItems:      true,
+		AllowItemEdit:       &allowEdit,
+		PhaseTimerOverrides: map[models.RetroPhase]int{models.PhaseBrainstorm: 420},
+		MaxActionsPerRetro:  &maxActions,
+		OpenAccess:          true,
+	})
+	template, err := svc.GetTemplate(ctx, source.TemplateID)
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	if _, err := svc.CreateItem(ctx, source.ID, alice.ID, services.CreateItemInput{ColumnID: template.Columns[0].ID, Content: "Slow CI"}); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	open, err := svc.CreateAction(ctx, source.ID, alice.ID, services.CreateActionInput{Title: "Cache the modules"})
+	if err != nil {
+		t.Fatalf("create action: %v", err)
+	}
+
+	clone, err := svc.Clone(ctx, source.ID, bob.ID, services.CloneRetroInput{})
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if clone.ID == source.ID || clone.Name != "Sprint 41 (copy)" || clone.FacilitatorID != bob.ID || clone.Status != models.StatusDraft {
+		t.Fatalf("got clone %+v, want a new draft named after the source and facilitated by Bob", clone)
+	}
+	if clone.TemplateID != source.TemplateID || clone.MaxVotesPerUser != 7 || clone.MaxVotesPerItem != 2 ||
+		!clone.AnonymousVoting || !clone.AnonymousItems || clone.AllowItemEdit || !clone.AllowVoteChange ||
+		clone.PhaseTimerOverrides[models.PhaseBrainstorm] != 420 || clone.MaxActionsPerRetro == nil || *clone.MaxActionsPerRetro != 4 {
+		t.Fatalf("got clone %+v, want the source's template and settings", clone)
+	}
+	if clone.OpenAccess {
+		t.Error("the clone copied open access")
+	}
+	if items, _ := svc.ListItems(ctx, clone.ID); len(items) != 0 {
+		t.Errorf("clone has %d items, want none", len(items))
+	}
+	if actions, _ := svc.ListActions(ctx, clone.ID); len(actions) != 0 {
+		t.Errorf("clone without carry-over has %d actions, want none", len(actions))
+	}
+
+	carried, err := svc.Clone(ctx, source.ID, alice.ID, services.CloneRetroInput{Name: "Sprint 42", CarryOverActions: true})
+	if err != nil {
+		t.Fatalf("clone with actions: %v", err)
+	}
+	actions, _ := svc.ListActions(ctx, carried.ID)
+	if carried.Name != "Sprint 42" || len(actions) != 1 || actions[0].CarriedFromID == nil || *actions[0].CarriedFromID != open.ID {
+		t.Fatalf("got %q with actions %+v, want the source's unfinished action carried over", carried.Name, actions)
+	}
+
+	// The source is open, but an outsider cannot facilitate a retro of the team
+	if _, err := svc.Clone(ctx, source.ID, carol.ID, services.CloneRetroInput{}); !errors.Is(err, services.ErrNotTeamMember) {
+		t.Fatalf("clone by a non-member got %v, want ErrNotTeamMember", err)
+	}
+}
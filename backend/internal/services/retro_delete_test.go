@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestDeleteRetroIsSoftAndRestorable(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	admin := env.CreateUser(t, "Admin")
+	member := env.CreateUser(t, "Member")
+	team := env.CreateTeam(t, admin, member)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	if _, err := svc.Start(ctx, retro.ID); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := svc.End(ctx, retro.ID); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+
+	if err := svc.Delete(ctx, retro.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.GetByID(ctx, retro.ID); !errors.Is(err, services.ErrRetroNotFound) {
+		t.Errorf("get deleted retro = %v, want ErrRetroNotFound", err)
+	}
+	if retros, _, err := svc.ListByTeam(ctx, team.ID, &models.RetroListFilter{}); err != nil || len(retros) != 0 {
+		t.Errorf("list = %d retros, %v; want none", len(retros), err)
+	}
+	stats, err := env.Services.Stats.GetTeamRotiStats(ctx, admin.ID, team.ID, nil)
+	if err != nil {
+		t.Fatalf("roti stats: %v", err)
+	}
+	if stats.TotalRetros != 0 {
+		t.Errorf("stats count %d retros, want the deleted one excluded", stats.TotalRetros)
+	}
+
+	if _, _, err := svc.ListByTeamIncludingDeleted(ctx, team.ID, member.ID, &models.RetroListFilter{}); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Errorf("member listing deleted retros = %v, want ErrNotAuthorized", err)
+	}
+	all, _, err := svc.ListByTeamIncludingDeleted(ctx, team.ID, admin.ID, &models.RetroListFilter{})
+	if err != nil || len(all) != 1 || all[0].DeletedAt == nil {
+		t.Fatalf("admin listing deleted retros = %v, %v", all, err)
+	}
+
+	if _, err := svc.Restore(ctx, retro.ID, member.ID); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Errorf("member restore = %v, want ErrNotAuthorized", err)
+	}
+	restored, err := svc.Restore(ctx, retro.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("restored retro still deleted at %v", restored.DeletedAt)
+	}
+	if _, err := svc.Restore(ctx, retro.ID, admin.ID); !errors.Is(err, services.ErrRetroNotFound) {
+		t.Errorf("restore a retro that is not deleted = %v, want ErrRetroNotFound", err)
+	}
+}
+
+func TestPurgeRetroRequiresTeamAdmin(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	admin := env.CreateUser(t, "Admin")
+	member := env.CreateUser(t, "Member")
+	team := env.CreateTeam(t, admin, member)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+
+	if err := svc.Delete(ctx, retro.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.PreviewPurge(ctx, retro.ID, member.ID); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Errorf("member purge preview = %v, want ErrNotAuthorized", err)
+	}
+	preview, err := svc.PreviewPurge(ctx, retro.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("purge preview: %v", err)
+	}
+	if err := svc.Purge(ctx, retro.ID, member.ID, preview.ConfirmationToken); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Errorf("member purge = %v, want ErrNotAuthorized", err)
+	}
+	if err := svc.Purge(ctx, retro.ID, admin.ID, preview.ConfirmationToken); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if _, err := svc.Restore(ctx, retro.ID, admin.ID); !errors.Is(err, services.ErrRetroNotFound) {
+		t.Errorf("restore a purged retro = %v, want ErrRetroNotFound", err)
+	}
+}
+
+func TestPurgeRetroRequiresCurrentToken(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	admin := env.CreateUser(t, "Admin")
+	team := env.CreateTeam(t, admin)
+	retro := env.CreateRetro(t, team, admin, services.CreateRetroInput{})
+	if _, err := svc.Start(ctx, retro.ID); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := svc.CreateItem(ctx, retro.ID, admin.ID, services.CreateItemInput{ColumnID: "went-well", Content: "Pairing"}); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	preview, err := svc.PreviewPurge(ctx, retro.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("purge preview: %v", err)
+	}
+	if preview.Items != 1 || preview.Votes != 0 || preview.Actions != 0 {
+		t.Errorf("preview counts = %+v, want one item", preview.RetroContentCounts)
+	}
+
+	if err := svc.Purge(ctx, retro.ID, admin.ID, ""); !errors.Is(err, services.ErrPurgeNotConfirmed) {
+		t.Errorf("purge without token = %v, want ErrPurgeNotConfirmed", err)
+	}
+	if _, err := svc.CreateItem(ctx, retro.ID, admin.ID, services.CreateItemInput{ColumnID: "went-well", Content: "Demos"}); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	if err := svc.Purge(ctx, retro.ID, admin.ID, preview.ConfirmationToken); !errors.Is(err, services.ErrPurgeNotConfirmed) {
+		t.Errorf("purge with a stale token = %v, want ErrPurgeNotConfirmed", err)
+	}
+	if _, err := svc.GetByID(ctx, retro.ID); err != nil {
+		t.Errorf("retro gone after a refused purge: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func retroIDs(retros []*models.Retrospective) []uuid.UUID {
+	ids := make([]uuid.UUID, len(retros))
+	for i, retro := range retros {
+		ids[i] = retro.ID
+	}
+	return ids
+}
+
+func TestListByTeamPagesAndSorts(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	// Created oldest first; the first one ends last and is then archived
+	archived := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	endedFirst := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	endedLast := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	draft := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	for _, retro := range []*models.Retrospective{endedFirst, endedLast, archived} {
+		if _, err := svc.Start(ctx, retro.ID); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		if _, err := svc.End(ctx, retro.ID); err != nil {
+			t.Fatalf("end: %v", err)
+		}
+	}
+	if _, err := svc.Archive(ctx, archived.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	page, total, err := svc.ListByTeam(ctx, team.ID, &models.RetroListFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if total != 3 || len(page) != 2 || page[0].ID != draft.ID || page[1].ID != endedLast.ID {
+		t.Fatalf("first page = %v of %d, want the two newest of 3 unarchived retros", retroIDs(page), total)
+	}
+	page, _, _ = svc.ListByTeam(ctx, team.ID, &models.RetroListFilter{Limit: 2, Offset: 2})
+	if len(page) != 1 || page[0].ID != endedFirst.ID {
+		t.Fatalf("second page = %v, want the oldest unarchived retro", retroIDs(page))
+	}
+
+	all, total, _ := svc.ListByTeam(ctx, team.ID, &models.RetroListFilter{IncludeArchived: true, Sort: models.RetroSortEndedAt})
+	want := []uuid.UUID{archived.ID, endedLast.ID, endedFirst.ID, draft.ID}
+	if total != 4 || len(all) != 4 {
+		t.Fatalf("with archived = %v of %d, want all 4 retros", retroIDs(all), total)
+	}
+	for i, id := range want {
+		if all[i].ID != id {
+			t.Fatalf("sorted by end = %v, want %v", retroIDs(all), want)
+		}
+	}
+
+	status := models.StatusCompleted
+	completed, total, _ := svc.ListByTeam(ctx, team.ID, &models.RetroListFilter{Status: &status})
+	if total != 2 || len(completed) != 2 {
+		t.Errorf("completed = %v of %d, want the 2 ended retros that are not archived", retroIDs(completed), total)
+	}
+}
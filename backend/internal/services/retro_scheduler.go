@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+)
+
+// scheduledStartInterval is how often the scheduler looks for retros to remind or start
+const scheduledStartInterval = 30 * time.Second
+
+// RetroScheduler sends reminders before scheduled retrospectives and starts them once their
+// scheduled time has come. Every pod runs it; the repository claims each retro for one pod.
+type RetroScheduler struct {
+	retroRepo    *postgres.RetrospectiveRepository
+	retroService *RetrospectiveService
+	grace        time.Duration // retros are started this long before scheduledAt
+	reminderLead time.Duration // reminders are sent this long before scheduledAt, 0 disables them
+	frontendURL  string
+	interval     time.Duration
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewRetroScheduler creates a scheduler that starts retros up to grace before their scheduled time
+// and reminds teams reminderLead before it. Join links in reminders point to frontendURL.
+func NewRetroScheduler(retroRepo *postgres.RetrospectiveRepository, retroService *RetrospectiveService, grace, reminderLead time.Duration, frontendURL string) *RetroScheduler {
+	return &RetroScheduler{
+		retroRepo:    retroRepo,
+		retroService: retroService,
+		grace:        grace,
+		reminderLead: reminderLead,
+		frontendURL:  strings.TrimRight(frontendURL, "/"),
+		interval:     scheduledStartInterval,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Stop
+func (s *RetroScheduler) Start() {
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			ctx := context.Background()
+			if _, err := s.StartDue(ctx, time.Now()); err != nil {
+				slog.Error("scheduler: failed to start scheduled retros", "error", err)
+			}
+			if _, err := s.SendReminders(ctx, time.Now()); err != nil {
+				slog.Error("scheduler: failed to send retro reminders", "error", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for the current run to finish
+func (s *RetroScheduler) Stop() {
+	close(s.stop)
+	s.done.Wait()
+}
+
+// StartDue starts the draft retros scheduled before now plus the grace window and returns them.
+// Nothing is broadcast: participants get the state when they join.
+func (s *RetroScheduler) StartDue(ctx context.Context, now time.Time) ([]*models.Retrospective, error) {
+	ids, err := s.retroRepo.StartScheduled(ctx, now.Add(s.grace))
+	if err != nil {
+		return nil, err
+	}
+
+	started := make([]*models.Retrospective, 0, len(ids))
+	for _, id := range ids {
+		retro, err := s.retroRepo.FindByID(ctx, id)
+		if err != nil {
+			slog.Error("scheduler: failed to load started retro", "error", err, "retroId", id)
+			continue
+		}
+		slog.Info("scheduler: started scheduled retro", "retroId", id, "scheduledAt", retro.ScheduledAt)
+		s.retroService.recordPhaseStart(ctx, retro, retro.CurrentPhase, *retro.StartedAt)
+		s.retroService.dispatchRetroStartedWebhook(ctx, retro, true)
+		started = append(started, retro)
+	}
+	return started, nil
+}
+
+// SendReminders fires retro.reminder for draft retros scheduled within the reminder lead
+// and returns them. Retros already started, manually or by StartDue, are skipped.
+func (s *RetroScheduler) SendReminders(ctx context.Context, now time.Time) ([]*models.Retrospective, error) {
+	if s.reminderLead <= 0 {
+		return nil, nil
+	}
+	ids, err := s.retroRepo.ClaimReminders(ctx, now.Add(s.reminderLead))
+	if err != nil {
+		return nil, err
+	}
+
+	reminded := make([]*models.Retrospective, 0, len(ids))
+	for _, id := range ids {
+		retro, err := s.retroRepo.FindByID(ctx, id)
+		if err != nil {
+			slog.Error("scheduler: failed to load reminded retro", "error", err, "retroId", id)
+			continue
+		}
+		reminded = append(reminded, retro)
+
+		if s.retroService.webhookService == nil {
+			continue
+		}
+		data := models.RetroReminderData{
+			Name:          retro.Name,
+			FacilitatorID: retro.FacilitatorID,
+			ScheduledAt:   *retro.ScheduledAt,
+			StartsIn:      int(math.Ceil(retro.ScheduledAt.Sub(now).Minutes())),
+			JoinURL:       s.joinURL(retro),
+		}
+		if team, err := s.retroService.teamRepo.FindByID(ctx, retro.TeamID); err == nil {
+			data.TeamName = team.Name
+		}
+		go s.retroService.webhookService.DispatchRetroReminder(context.WithoutCancel(ctx), retro, data)
+	}
+	return reminded, nil
+}
+
+// joinURL is the frontend page participants open to join the retro
+func (s *RetroScheduler) joinURL(retro *models.Retrospective) string {
+	if retro.SessionType == models.SessionTypeLeanCoffee {
+		return s.frontendURL + "/leancoffee/" + retro.ID.String()
+	}
+	return s.frontendURL + "/retro/" + retro.ID.String()
+}
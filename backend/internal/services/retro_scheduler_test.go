@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestSchedulerStartsDueRetrosOnce(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	past := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "past", ScheduledAt: at(-time.Hour)})
+	early := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "within grace", ScheduledAt: at(30 * time.Second)})
+	later := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "later", ScheduledAt: at(time.Hour)})
+	unscheduled := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "unscheduled"})
+
+	scheduler := services.NewRetroScheduler(env.Repos.Retros, env.Services.Retro, time.Minute, 0, "")
+	started, err := scheduler.StartDue(ctx, now)
+	if err != nil {
+		t.Fatalf("start due: %v", err)
+	}
+	if len(started) != 2 {
+		t.Fatalf("started %d retros, want 2", len(started))
+	}
+
+	want := map[string]models.RetroStatus{
+		past.Name:        models.StatusActive,
+		early.Name:       models.StatusActive,
+		later.Name:       models.StatusDraft,
+		unscheduled.Name: models.StatusDraft,
+	}
+	for _, retro := range []*models.Retrospective{past, early, later, unscheduled} {
+		got, err := env.Repos.Retros.FindByID(ctx, retro.ID)
+		if err != nil {
+			t.Fatalf("find retro: %v", err)
+		}
+		if got.Status != want[retro.Name] {
+			t.Errorf("%s: status = %s, want %s", retro.Name, got.Status, want[retro.Name])
+		}
+		if got.Status == models.StatusActive && (got.CurrentPhase != models.PhaseWaiting || got.StartedAt == nil) {
+			t.Errorf("%s: phase = %s, startedAt = %v", retro.Name, got.CurrentPhase, got.StartedAt)
+		}
+	}
+
+	again, err := scheduler.StartDue(ctx, now)
+	if err != nil {
+		t.Fatalf("start due again: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second run started %d retros, want 0", len(again))
+	}
+}
+
+func TestSchedulerRemindsScheduledDraftsOnce(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	soon := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "soon", ScheduledAt: at(10 * time.Minute)})
+	later := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "later", ScheduledAt: at(time.Hour)})
+	startedEarly := env.CreateRetro(t, team, alice, services.CreateRetroInput{Name: "started", ScheduledAt: at(5 * time.Minute)})
+	if _, err := env.Services.Retro.Start(ctx, startedEarly.ID); err != nil {
+		t.Fatalf("start retro: %v", err)
+	}
+
+	scheduler := services.NewRetroScheduler(env.Repos.Retros, env.Services.Retro, time.Minute, 15*time.Minute, "https://retro.example.com/")
+	reminded, err := scheduler.SendReminders(ctx, now)
+	if err != nil {
+		t.Fatalf("send reminders: %v", err)
+	}
+	if len(reminded) != 1 || reminded[0].ID != soon.ID {
+		t.Fatalf("reminded %v, want only %s", reminded, soon.Name)
+	}
+
+	again, err := scheduler.SendReminders(ctx, now)
+	if err != nil {
+		t.Fatalf("send reminders again: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second run reminded %d retros, want 0", len(again))
+	}
+
+	// The later retro is reminded once it gets within the lead
+	next, err := scheduler.SendReminders(ctx, now.Add(50*time.Minute))
+	if err != nil {
+		t.Fatalf("send reminders later: %v", err)
+	}
+	if len(next) != 1 || next[0].ID != later.ID {
+		t.Errorf("reminded %v, want only %s", next, later.Name)
+	}
+}
@@ -2,9 +2,17 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 
@@ -12,47 +20,146 @@ import (
 	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
 )
 
+// maxRotiCommentLength caps the free-text feedback attached to a ROTI vote
+const maxRotiCommentLength = 1000
+
+// maxActionCommentLength caps a follow-up note on an action item
+const maxActionCommentLength = 2000
+
+// MaxImportedItems caps the number of items created by one bulk import
+const MaxImportedItems = 200
+
+// maxImportedItemLength caps the content of an imported item
+const maxImportedItemLength = 2000
+
 var (
-	ErrRetroNotFound        = errors.New("retrospective not found")
-	ErrItemNotFound         = errors.New("item not found")
-	ErrActionNotFound       = errors.New("action item not found")
-	ErrTemplateNotFound     = errors.New("template not found")
-	ErrVoteLimitReached     = errors.New("vote limit reached")
-	ErrItemVoteLimitReached = errors.New("item vote limit reached")
-	ErrInvalidPhase         = errors.New("invalid phase for this operation")
+	ErrRetroNotFound          = errors.New("retrospective not found")
+	ErrItemNotFound           = errors.New("item not found")
+	ErrActionNotFound         = errors.New("action item not found")
+	ErrTemplateNotFound       = errors.New("template not found")
+	ErrVoteLimitReached       = errors.New("vote limit reached")
+	ErrItemVoteLimitReached   = errors.New("item vote limit reached")
+	ErrInvalidPhase           = errors.New("invalid phase for this operation")
+	ErrNotFacilitator         = errors.New("only the facilitator can do this")
+	ErrInvalidRotiRating      = errors.New("rating must be between 1 and 5")
+	ErrRotiRevealed           = errors.New("ROTI results are already revealed")
+	ErrRotiCommentTooLong     = errors.New("ROTI comment is too long")
+	ErrInvalidActionComment   = errors.New("comment must be 1 to 2000 characters")
+	ErrInvalidMood            = errors.New("mood is not part of the template's mood scale")
+	ErrInvalidMoodScale       = errors.New("mood scale values must be unique and not empty")
+	ErrRetroNotCompleted      = errors.New("only completed retrospectives can be archived")
+	ErrRetroNotArchived       = errors.New("retrospective is not archived")
+	ErrInvalidArchiveFilter   = errors.New("olderThan or retroIds is required")
+	ErrActionLimitReached     = errors.New("action limit reached")
+	ErrInvalidPriority        = errors.New("priority must be between 0 (low) and 3 (urgent)")
+	ErrRetroNotReopenable     = errors.New("only completed retrospectives can be reopened")
+	ErrRetroArchived          = errors.New("retrospective is archived, unarchive it first")
+	ErrTemplateBuiltIn        = errors.New("built-in templates cannot be modified")
+	ErrTemplateInUse          = errors.New("template is used by retrospectives")
+	ErrPurgeNotConfirmed      = errors.New("purge confirmation token is missing or out of date")
+	ErrConcurrentModification = errors.New("retrospective was modified by someone else, reload it")
+	ErrGuestsNotAllowed       = errors.New("guests are not allowed in this retrospective")
+	ErrShareNotFound          = errors.New("retrospective has no share link")
+	ErrShareRevoked           = errors.New("share link has been revoked")
+	ErrShareExpired           = errors.New("share link has expired")
+	ErrInvalidShareExpiry     = errors.New("share link expiry must be between 1 minute and 7 days")
+	ErrInvalidItemImport      = errors.New("an import needs 1 to 200 items")
+	ErrTemplateNotAccessible  = errors.New("template belongs to another team")
 )
 
+// maxShareExpiry is the longest a guest share link may stay valid
+const maxShareExpiry = 7 * 24 * time.Hour
+
 // RetrospectiveService handles retrospective operations
 type RetrospectiveService struct {
 	retroRepo      *postgres.RetrospectiveRepository
+	teamRepo       *postgres.TeamRepository
 	templateRepo   *postgres.TemplateRepository
 	itemRepo       *postgres.ItemRepository
 	voteRepo       *postgres.VoteRepository
 	actionRepo     *postgres.ActionItemRepository
 	icebreakerRepo *postgres.IcebreakerRepository
 	rotiRepo       *postgres.RotiRepository
+	memberRepo     *postgres.TeamMemberRepository
+	phaseHistory   *postgres.PhaseHistoryRepository
+	attendeeRepo   *postgres.AttendeeRepository
+	itemHistory    *postgres.ItemHistoryRepository
+	actionComments *postgres.ActionCommentRepository
 	webhookService *WebhookService
+
+	OnSettingsChanged func(retro *models.Retrospective, permissions RetroPermissions) // Callback when client-facing settings change
+}
+
+// RetroPermissions are the settings that decide which actions clients offer to participants
+type RetroPermissions struct {
+	AllowItemEdit      bool `json:"allowItemEdit"`
+	AllowVoteChange    bool `json:"allowVoteChange"`
+	MaxVotesPerUser    int  `json:"maxVotesPerUser"`
+	MaxVotesPerItem    int  `json:"maxVotesPerItem"`
+	VoteLimitPerGroup  bool `json:"voteLimitPerGroup"`
+	MaxActionsPerRetro *int `json:"maxActionsPerRetro"`
+	AnonymousVoting    bool `json:"anonymousVoting"`
+	AnonymousItems     bool `json:"anonymousItems"`
+}
+
+// PermissionsFor returns the effective client permissions of a retrospective
+func PermissionsFor(retro *models.Retrospective) RetroPermissions {
+	return RetroPermissions{
+		AllowItemEdit:      retro.AllowItemEdit,
+		AllowVoteChange:    retro.AllowVoteChange,
+		MaxVotesPerUser:    retro.MaxVotesPerUser,
+		MaxVotesPerItem:    retro.MaxVotesPerItem,
+		VoteLimitPerGroup:  retro.VoteLimitPerGroup,
+		MaxActionsPerRetro: retro.MaxActionsPerRetro,
+		AnonymousVoting:    retro.AnonymousVoting,
+		AnonymousItems:     retro.AnonymousItems,
+	}
+}
+
+func (p RetroPermissions) equal(o RetroPermissions) bool {
+	sameCap := (p.MaxActionsPerRetro == nil && o.MaxActionsPerRetro == nil) ||
+		(p.MaxActionsPerRetro != nil && o.MaxActionsPerRetro != nil && *p.MaxActionsPerRetro == *o.MaxActionsPerRetro)
+	return sameCap &&
+		p.AllowItemEdit == o.AllowItemEdit &&
+		p.AllowVoteChange == o.AllowVoteChange &&
+		p.MaxVotesPerUser == o.MaxVotesPerUser &&
+		p.MaxVotesPerItem == o.MaxVotesPerItem &&
+		p.VoteLimitPerGroup == o.VoteLimitPerGroup &&
+		p.AnonymousVoting == o.AnonymousVoting &&
+		p.AnonymousItems == o.AnonymousItems
 }
 
 // NewRetrospectiveService creates a new retrospective service
 func NewRetrospectiveService(
 	retroRepo *postgres.RetrospectiveRepository,
+	teamRepo *postgres.TeamRepository,
 	templateRepo *postgres.TemplateRepository,
 	itemRepo *postgres.ItemRepository,
 	voteRepo *postgres.VoteRepository,
 	actionRepo *postgres.ActionItemRepository,
 	icebreakerRepo *postgres.IcebreakerRepository,
 	rotiRepo *postgres.RotiRepository,
+	memberRepo *postgres.TeamMemberRepository,
+	phaseHistory *postgres.PhaseHistoryRepository,
+	attendeeRepo *postgres.AttendeeRepository,
+	itemHistory *postgres.ItemHistoryRepository,
+	actionComments *postgres.ActionCommentRepository,
 	webhookService *WebhookService,
 ) *RetrospectiveService {
 	return &RetrospectiveService{
 		retroRepo:      retroRepo,
+		teamRepo:       teamRepo,
 		templateRepo:   templateRepo,
 		itemRepo:       itemRepo,
 		voteRepo:       voteRepo,
 		actionRepo:     actionRepo,
 		icebreakerRepo: icebreakerRepo,
 		rotiRepo:       rotiRepo,
+		memberRepo:     memberRepo,
+		phaseHistory:   phaseHistory,
+		attendeeRepo:   attendeeRepo,
+		itemHistory:    itemHistory,
+		actionComments: actionComments,
 		webhookService: webhookService,
 	}
 }
@@ -72,10 +179,26 @@ type CreateRetroInput struct {
 	PhaseTimerOverrides   map[models.RetroPhase]int
 	ScheduledAt           *time.Time
 	LCTopicTimeboxSeconds *int
+	MaxActionsPerRetro    *int
+	AutoAdvanceOnTimerEnd bool
+	VoteLimitPerGroup     bool
+	BatchVoteUpdates      bool
+	OpenAccess            bool
+	AllowGuests           bool
+	CarryOverActions      bool // copy the unfinished actions of the team's last completed retro
 }
 
 // Create creates a new retrospective
 func (s *RetrospectiveService) Create(ctx context.Context, facilitatorID uuid.UUID, input CreateRetroInput) (*models.Retrospective, error) {
+	// The creator facilitates the retro, so they must belong to its team
+	isMember, err := s.memberRepo.IsMember(ctx, input.TeamID, facilitatorID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotTeamMember
+	}
+
 	// For Lean Coffee sessions, use the built-in LC template if no template specified
 	if input.SessionType == models.SessionTypeLeanCoffee && input.TemplateID == uuid.Nil {
 		lcTemplate, err := s.templateRepo.FindBuiltInByName(ctx, "Lean Coffee")
@@ -85,13 +208,17 @@ func (s *RetrospectiveService) Create(ctx context.Context, facilitatorID uuid.UU
 		input.TemplateID = lcTemplate.ID
 	} else {
 		// Verify template exists
-		_, err := s.templateRepo.FindByID(ctx, input.TemplateID)
+		template, err := s.templateRepo.FindByID(ctx, input.TemplateID)
 		if err != nil {
 			if errors.Is(err, postgres.ErrNotFound) {
 				return nil, ErrTemplateNotFound
 			}
 			return nil, err
 		}
+		// A team-owned template may only be used by its own team
+		if !template.IsBuiltIn && (template.TeamID == nil || *template.TeamID != input.TeamID) {
+			return nil, ErrTemplateNotAccessible
+		}
 	}
 
 	maxVotes := input.MaxVotesPerUser
@@ -99,6 +226,11 @@ func (s *RetrospectiveService) Create(ctx context.Context, facilitatorID uuid.UU
 		maxVotes = 5
 	}
 
+	maxActions := input.MaxActionsPerRetro
+	if maxActions != nil && *maxActions <= 0 {
+		maxActions = nil
+	}
+
 	maxVotesPerItem := input.MaxVotesPerItem
 	if maxVotesPerItem <= 0 {
 		maxVotesPerItem = 3
@@ -145,9 +277,87 @@ func (s *RetrospectiveService) Create(ctx context.Context, facilitatorID uuid.UU
 		ScheduledAt:           input.ScheduledAt,
 		SessionType:           sessionType,
 		LCTopicTimeboxSeconds: input.LCTopicTimeboxSeconds,
+		MaxActionsPerRetro:    maxActions,
+		AutoAdvanceOnTimerEnd: input.AutoAdvanceOnTimerEnd,
+		VoteLimitPerGroup:     input.VoteLimitPerGroup,
+		BatchVoteUpdates:      input.BatchVoteUpdates,
+		OpenAccess:            input.OpenAccess,
+		AllowGuests:           input.AllowGuests,
+	}
+
+	created, err := s.retroRepo.Create(ctx, retro)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.CarryOverActions {
+		if _, err := s.CarryOverActions(ctx, created); err != nil {
+			return nil, err
+		}
+	}
+
+	return created, nil
+}
+
+// CloneRetroInput is what changes from the source retro when cloning it
+type CloneRetroInput struct {
+	Name             string // empty defaults to the source name with " (copy)"
+	CarryOverActions bool   // copy the source's unfinished actions
+}
+
+// Clone creates a draft retro for the same team with the source's template and settings, but
+// none of its items, votes or schedule. The caller facilitates it and must be a team member.
+// Guest and open access are not copied, so sharing the new retro stays a deliberate choice.
+func (s *RetrospectiveService) Clone(ctx context.Context, sourceID, userID uuid.UUID, input CloneRetroInput) (*models.Retrospective, error) {
+	source, err := s.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	created, err := s.Create(ctx, userID, CreateRetroInput{
+		Name:                  name,
+		TeamID:                source.TeamID,
+		TemplateID:            source.TemplateID,
+		SessionType:           source.SessionType,
+		MaxVotesPerUser:       source.MaxVotesPerUser,
+		MaxVotesPerItem:       source.MaxVotesPerItem,
+		AnonymousVoting:       source.AnonymousVoting,
+		AnonymousItems:        source.AnonymousItems,
+		AllowItemEdit:         &source.AllowItemEdit,
+		AllowVoteChange:       &source.AllowVoteChange,
+		PhaseTimerOverrides:   source.PhaseTimerOverrides,
+		LCTopicTimeboxSeconds: source.LCTopicTimeboxSeconds,
+		MaxActionsPerRetro:    source.MaxActionsPerRetro,
+		AutoAdvanceOnTimerEnd: source.AutoAdvanceOnTimerEnd,
+		VoteLimitPerGroup:     source.VoteLimitPerGroup,
+		BatchVoteUpdates:      source.BatchVoteUpdates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if input.CarryOverActions {
+		if _, err := s.actionRepo.CarryOverFrom(ctx, source.ID, created.ID); err != nil {
+			// Drop the new draft so that a retry does not leave a second copy behind
+			if derr := s.retroRepo.Delete(context.WithoutCancel(ctx), created.ID); derr != nil {
+				log.Printf("Clone: failed to delete retro %s after a failed carry-over: %v", created.ID, derr)
+			}
+			return nil, err
+		}
 	}
+	return created, nil
+}
 
-	return s.retroRepo.Create(ctx, retro)
+// CarryOverActions copies the unfinished actions of the team's most recently completed retro
+// into the retro, so they come up again in the new session. Actions already carried over into
+// another retro are skipped. Carried over actions do not count against MaxActionsPerRetro.
+func (s *RetrospectiveService) CarryOverActions(ctx context.Context, retro *models.Retrospective) ([]*models.ActionItem, error) {
+	return s.actionRepo.CarryOver(ctx, retro.TeamID, retro.ID)
 }
 
 // GetByID gets a retrospective by ID
@@ -162,9 +372,32 @@ func (s *RetrospectiveService) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	return retro, nil
 }
 
-// ListByTeam lists retrospectives for a team
-func (s *RetrospectiveService) ListByTeam(ctx context.Context, teamID uuid.UUID, status *models.RetroStatus) ([]*models.Retrospective, error) {
-	return s.retroRepo.ListByTeam(ctx, teamID, status)
+// GetDetail gets a retrospective with its team, template and facilitator
+func (s *RetrospectiveService) GetDetail(ctx context.Context, id uuid.UUID) (*models.Retrospective, error) {
+	retro, err := s.retroRepo.FindByIDWithRelations(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrRetroNotFound
+		}
+		return nil, err
+	}
+	return retro, nil
+}
+
+// ListByTeam returns a page of a team's retrospectives and the total count
+func (s *RetrospectiveService) ListByTeam(ctx context.Context, teamID uuid.UUID, filter *models.RetroListFilter) ([]*models.Retrospective, int, error) {
+	return s.retroRepo.ListByTeam(ctx, teamID, filter)
+}
+
+// ListByTeamIncludingDeleted lists a team's retrospectives with the soft-deleted ones.
+// Only team admins can see deleted retrospectives.
+func (s *RetrospectiveService) ListByTeamIncludingDeleted(ctx context.Context, teamID, userID uuid.UUID, filter *models.RetroListFilter) ([]*models.Retrospective, int, error) {
+	if err := s.checkTeamAdmin(ctx, teamID, userID); err != nil {
+		return nil, 0, err
+	}
+	withDeleted := *filter
+	withDeleted.IncludeDeleted = true
+	return s.retroRepo.ListByTeam(ctx, teamID, &withDeleted)
 }
 
 var ErrRetroAlreadyStarted = errors.New("retrospective already started")
@@ -190,20 +423,45 @@ func (s *RetrospectiveService) Start(ctx context.Context, id uuid.UUID) (*models
 		return nil, ErrRetroAlreadyStarted
 	}
 
+	// Only a draft is started, so of concurrent starts one does it and the others see it active
 	now := time.Now()
-	retro.Status = models.StatusActive
-	retro.StartedAt = &now
-	retro.CurrentPhase = models.PhaseWaiting
-
-	if err := s.retroRepo.Update(ctx, retro); err != nil {
+	started, err := s.retroRepo.MarkStarted(ctx, id, now)
+	if err != nil {
 		log.Printf("Start: failed to update retro %s: %v", id, err)
 		return nil, err
 	}
+	retro, err = s.retroRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		if retro.Status == models.StatusActive {
+			return retro, nil
+		}
+		return nil, ErrRetroAlreadyStarted
+	}
+
+	s.recordPhaseStart(ctx, retro, retro.CurrentPhase, now)
+	s.dispatchRetroStartedWebhook(ctx, retro, false)
 
 	log.Printf("Start: retro %s successfully started", id)
 	return retro, nil
 }
 
+// dispatchRetroStartedWebhook sends retro.started; autoStarted is set when the scheduler started the retro
+func (s *RetrospectiveService) dispatchRetroStartedWebhook(ctx context.Context, retro *models.Retrospective, autoStarted bool) {
+	if s.webhookService == nil || retro.StartedAt == nil {
+		return
+	}
+	go s.webhookService.DispatchRetroStarted(context.WithoutCancel(ctx), retro, models.RetroStartedData{
+		Name:          retro.Name,
+		FacilitatorID: retro.FacilitatorID,
+		ScheduledAt:   retro.ScheduledAt,
+		StartedAt:     *retro.StartedAt,
+		AutoStarted:   autoStarted,
+	})
+}
+
 // End ends a retrospective
 func (s *RetrospectiveService) End(ctx context.Context, id uuid.UUID) (*models.Retrospective, error) {
 	retro, err := s.retroRepo.FindByID(ctx, id)
@@ -211,13 +469,33 @@ func (s *RetrospectiveService) End(ctx context.Context, id uuid.UUID) (*models.R
 		return nil, err
 	}
 
-	now := time.Now()
-	retro.Status = models.StatusCompleted
-	retro.EndedAt = &now
+	// If already ended, return the current state without touching ended_at
+	// or re-dispatching the webhook (idempotent behavior)
+	if retro.Status == models.StatusCompleted || retro.Status == models.StatusArchived {
+		return retro, nil
+	}
 
-	if err := s.retroRepo.Update(ctx, retro); err != nil {
+	// Only a draft or active retro is ended, so of concurrent ends one does it and dispatches
+	// the webhook while the others take the idempotent path
+	now := time.Now()
+	ended, err := s.retroRepo.MarkEnded(ctx, id, now)
+	if err != nil {
+		return nil, err
+	}
+	retro, err = s.retroRepo.FindByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	if !ended {
+		if retro.Status == models.StatusCompleted || retro.Status == models.StatusArchived {
+			return retro, nil
+		}
+		return nil, ErrConcurrentModification
+	}
+
+	if err := s.phaseHistory.CloseOpen(ctx, retro.ID, now); err != nil {
+		log.Printf("phase history: failed to close last phase of retro %s: %v", retro.ID, err)
+	}
 
 	// Dispatch retro.completed webhook asynchronously
 	if s.webhookService != nil {
@@ -227,6 +505,134 @@ func (s *RetrospectiveService) End(ctx context.Context, id uuid.UUID) (*models.R
 	return retro, nil
 }
 
+// Reopen moves a completed retrospective back to active at its last phase.
+// Only the facilitator or a team admin can reopen a retrospective.
+func (s *RetrospectiveService) Reopen(ctx context.Context, id, userID uuid.UUID) (*models.Retrospective, error) {
+	retro, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if retro.FacilitatorID != userID {
+		role, err := s.memberRepo.GetUserRole(ctx, retro.TeamID, userID)
+		if err != nil {
+			if errors.Is(err, postgres.ErrNotFound) {
+				return nil, ErrNotTeamMember
+			}
+			return nil, err
+		}
+		if role != models.RoleAdmin {
+			return nil, ErrNotAuthorized
+		}
+	}
+
+	if retro.Status == models.StatusArchived {
+		return nil, ErrRetroArchived
+	}
+	if retro.Status != models.StatusCompleted {
+		return nil, ErrRetroNotReopenable
+	}
+
+	reopened, err := s.retroRepo.MarkReopened(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !reopened {
+		return nil, ErrRetroNotReopenable
+	}
+	retro.Status = models.StatusActive
+	retro.EndedAt = nil
+
+	s.recordPhaseStart(ctx, retro, retro.CurrentPhase, time.Now())
+
+	return retro, nil
+}
+
+// Archive archives a completed retrospective
+func (s *RetrospectiveService) Archive(ctx context.Context, id uuid.UUID) (*models.Retrospective, error) {
+	retro, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Already archived (idempotent behavior)
+	if retro.Status == models.StatusArchived {
+		return retro, nil
+	}
+
+	if retro.Status != models.StatusCompleted {
+		return nil, ErrRetroNotCompleted
+	}
+
+	archived, err := s.retroRepo.UpdateStatus(ctx, id, models.StatusCompleted, models.StatusArchived)
+	if err != nil {
+		return nil, err
+	}
+	if !archived {
+		// Archived concurrently, or reopened in between
+		if retro, err = s.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		if retro.Status == models.StatusArchived {
+			return retro, nil
+		}
+		return nil, ErrRetroNotCompleted
+	}
+	retro.Status = models.StatusArchived
+
+	return retro, nil
+}
+
+// Unarchive moves an archived retrospective back to completed
+func (s *RetrospectiveService) Unarchive(ctx context.Context, id uuid.UUID) (*models.Retrospective, error) {
+	retro, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if retro.Status != models.StatusArchived {
+		return nil, ErrRetroNotArchived
+	}
+
+	unarchived, err := s.retroRepo.UpdateStatus(ctx, id, models.StatusArchived, models.StatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	if !unarchived {
+		return nil, ErrRetroNotArchived
+	}
+	retro.Status = models.StatusCompleted
+
+	return retro, nil
+}
+
+// BulkArchiveInput selects which completed retrospectives of a team to archive
+type BulkArchiveInput struct {
+	OlderThan *time.Time
+	RetroIDs  []uuid.UUID
+}
+
+// ArchiveByTeam archives matching completed retrospectives of a team (team admin only)
+// and returns the IDs of the archived retrospectives
+func (s *RetrospectiveService) ArchiveByTeam(ctx context.Context, teamID, userID uuid.UUID, input BulkArchiveInput) ([]uuid.UUID, error) {
+	if input.OlderThan == nil && len(input.RetroIDs) == 0 {
+		return nil, ErrInvalidArchiveFilter
+	}
+
+	role, err := s.memberRepo.GetUserRole(ctx, teamID, userID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrNotTeamMember
+		}
+		return nil, err
+	}
+	if role != models.RoleAdmin {
+		return nil, ErrNotAuthorized
+	}
+
+	return s.retroRepo.ArchiveCompleted(ctx, teamID, input.OlderThan, input.RetroIDs)
+}
+
 // dispatchRetroCompletedWebhook gathers data and dispatches the retro.completed webhook
 func (s *RetrospectiveService) dispatchRetroCompletedWebhook(ctx context.Context, retro *models.Retrospective) {
 	// Gather items
@@ -250,6 +656,13 @@ func (s *RetrospectiveService) dispatchRetroCompletedWebhook(ctx context.Context
 		moods = []*models.IcebreakerMood{}
 	}
 
+	// Gather attendance
+	attendees, err := s.attendeeRepo.GetByRetro(ctx, retro.ID)
+	if err != nil {
+		log.Printf("webhook: failed to list attendees for retro %s: %v", retro.ID, err)
+		attendees = []*models.RetroAttendee{}
+	}
+
 	// Gather ROTI votes
 	rotiVotes, err := s.rotiRepo.ListVotes(ctx, retro.ID)
 	if err != nil {
@@ -276,6 +689,21 @@ func (s *RetrospectiveService) dispatchRetroCompletedWebhook(ctx context.Context
 		})
 	}
 
+	// Participants are the distinct users who attended
+	participants := make([]models.ParticipantData, 0, len(attendees))
+	seen := make(map[uuid.UUID]bool, len(attendees))
+	for _, a := range attendees {
+		if !a.Attended || seen[a.UserID] {
+			continue
+		}
+		seen[a.UserID] = true
+		p := models.ParticipantData{UserID: a.UserID}
+		if a.User != nil {
+			p.DisplayName = a.User.DisplayName
+		}
+		participants = append(participants, p)
+	}
+
 	// Convert ROTI votes to webhook format
 	webhookRotiVotes := make([]models.RotiVoteData, 0, len(rotiVotes))
 	for _, v := range rotiVotes {
@@ -285,6 +713,15 @@ func (s *RetrospectiveService) dispatchRetroCompletedWebhook(ctx context.Context
 		})
 	}
 
+	// ROTI comments follow the retro's anonymity
+	webhookRotiComments := make([]models.RotiCommentData, 0)
+	for _, c := range rotiComments(rotiVotes, retro.AnonymousVoting) {
+		webhookRotiComments = append(webhookRotiComments, models.RotiCommentData{
+			UserID:  c.UserID,
+			Comment: c.Comment,
+		})
+	}
+
 	// Dispatch webhook
 	var avgRotiPtr *float64
 	if len(rotiVotes) > 0 {
@@ -294,88 +731,320 @@ func (s *RetrospectiveService) dispatchRetroCompletedWebhook(ctx context.Context
 	s.webhookService.DispatchRetroCompleted(ctx, retro, models.RetroCompletedData{
 		Name:             retro.Name,
 		FacilitatorID:    retro.FacilitatorID,
-		ParticipantCount: len(moods), // Use mood count as participant proxy
+		ParticipantCount: len(participants),
 		ItemCount:        len(items),
 		ActionCount:      len(actions),
 		AverageRoti:      avgRotiPtr,
 		Moods:            webhookMoods,
 		RotiVotes:        webhookRotiVotes,
+		RotiComments:     webhookRotiComments,
+		Participants:     participants,
 	})
 }
 
 // Update updates a retrospective
 func (s *RetrospectiveService) Update(ctx context.Context, retro *models.Retrospective) error {
-	return s.retroRepo.Update(ctx, retro)
+	previous, err := s.retroRepo.FindByID(ctx, retro.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.retroRepo.Update(ctx, retro); err != nil {
+		if errors.Is(err, postgres.ErrConcurrentModification) {
+			return ErrConcurrentModification
+		}
+		return err
+	}
+
+	permissions := PermissionsFor(retro)
+	if s.OnSettingsChanged != nil && !permissions.equal(PermissionsFor(previous)) {
+		s.OnSettingsChanged(retro, permissions)
+	}
+	return nil
 }
 
 // Delete deletes a retrospective
 func (s *RetrospectiveService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.retroRepo.Delete(ctx, id)
+	if err := s.retroRepo.SoftDelete(ctx, id); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrRetroNotFound
+		}
+		return err
+	}
+	return nil
 }
 
-// SetPhase sets the current phase
-func (s *RetrospectiveService) SetPhase(ctx context.Context, id uuid.UUID, phase models.RetroPhase) error {
-	return s.retroRepo.UpdatePhase(ctx, id, phase)
+// Restore brings back a soft-deleted retrospective. Only team admins can restore.
+func (s *RetrospectiveService) Restore(ctx context.Context, id, userID uuid.UUID) (*models.Retrospective, error) {
+	if err := s.checkRetroTeamAdmin(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if err := s.retroRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrRetroNotFound
+		}
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
 }
 
-// GetPhaseSequence returns the phase sequence for a given session type
-func GetPhaseSequence(sessionType models.SessionType) []models.RetroPhase {
-	if sessionType == models.SessionTypeLeanCoffee {
-		return []models.RetroPhase{
-			models.PhaseWaiting,
-			models.PhaseIcebreaker,
-			models.PhasePropose,
-			models.PhaseVote,
-			models.PhaseDiscuss,
-			models.PhaseRoti,
-		}
+// PurgePreview is what purging a retrospective would remove and the token confirming it
+type PurgePreview struct {
+	models.RetroContentCounts
+	ConfirmationToken string `json:"confirmationToken"`
+}
+
+// PreviewPurge counts what purging the retrospective would remove. Only team admins can purge.
+func (s *RetrospectiveService) PreviewPurge(ctx context.Context, id, userID uuid.UUID) (*PurgePreview, error) {
+	if err := s.checkRetroTeamAdmin(ctx, id, userID); err != nil {
+		return nil, err
 	}
-	// Default retro phases
-	return []models.RetroPhase{
-		models.PhaseWaiting,
-		models.PhaseIcebreaker,
-		models.PhaseBrainstorm,
-		models.PhaseGroup,
-		models.PhaseVote,
-		models.PhaseDiscuss,
-		models.PhaseRoti,
+	counts, err := s.retroRepo.CountContents(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	return &PurgePreview{RetroContentCounts: *counts, ConfirmationToken: purgeToken(id, userID, counts)}, nil
 }
 
-// NextPhase advances to the next phase
-func (s *RetrospectiveService) NextPhase(ctx context.Context, id uuid.UUID) (models.RetroPhase, error) {
-	retro, err := s.retroRepo.FindByID(ctx, id)
+// Purge permanently deletes a retrospective and everything in it, soft-deleted or not.
+// Only team admins can purge, with the token of a preview that still matches the retro's contents.
+func (s *RetrospectiveService) Purge(ctx context.Context, id, userID uuid.UUID, token string) error {
+	preview, err := s.PreviewPurge(ctx, id, userID)
 	if err != nil {
-		return "", err
+		return err
 	}
-
-	phases := GetPhaseSequence(retro.SessionType)
-
-	currentIdx := -1
-	for i, p := range phases {
-		if p == retro.CurrentPhase {
-			currentIdx = i
-			break
-		}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(preview.ConfirmationToken)) != 1 {
+		return ErrPurgeNotConfirmed
 	}
+	return s.retroRepo.Delete(ctx, id)
+}
 
-	if currentIdx == -1 || currentIdx >= len(phases)-1 {
-		return retro.CurrentPhase, nil // Already at last phase
-	}
+// purgeToken ties a purge confirmation to the admin and to the counts they were shown.
+// It guards against accidental deletes rather than forgery: the caller is already a team admin.
+func purgeToken(retroID, userID uuid.UUID, counts *models.RetroContentCounts) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("purge:%s:%s:%d:%d:%d", retroID, userID, counts.Items, counts.Votes, counts.Actions)))
+	return hex.EncodeToString(sum[:16])
+}
 
-	nextPhase := phases[currentIdx+1]
-	if err := s.retroRepo.UpdatePhase(ctx, id, nextPhase); err != nil {
-		return "", err
-	}
+// CheckAccess requires the user to be a member of the retro's team; the retro may be soft-deleted
+func (s *RetrospectiveService) CheckAccess(ctx context.Context, retroID, userID uuid.UUID) error {
+	return s.checkAccess(ctx, retroID, userID, false)
+}
 
-	return nextPhase, nil
+// CheckParticipantAccess requires the user to be a member of the retro's team, unless the retro
+// is open to any signed-in user; the retro may be soft-deleted
+func (s *RetrospectiveService) CheckParticipantAccess(ctx context.Context, retroID, userID uuid.UUID) error {
+	return s.checkAccess(ctx, retroID, userID, true)
 }
 
-// GetPhaseDuration gets the default duration for a phase
-func (s *RetrospectiveService) GetPhaseDuration(ctx context.Context, templateID uuid.UUID, phase models.RetroPhase) (int, error) {
-	template, err := s.templateRepo.FindByID(ctx, templateID)
+func (s *RetrospectiveService) checkAccess(ctx context.Context, retroID, userID uuid.UUID, allowOpen bool) error {
+	teamID, openAccess, err := s.retroRepo.FindAccess(ctx, retroID)
 	if err != nil {
-		return 0, err
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrRetroNotFound
+		}
+		return err
+	}
+	if openAccess && allowOpen {
+		return nil
+	}
+	isMember, err := s.memberRepo.IsMember(ctx, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrNotTeamMember
+	}
+	return nil
+}
+
+// CreateShare opens the retro to guests with a new share link valid for expiresIn, replacing
+// and so revoking any previous link. Only the facilitator can share the retro.
+func (s *RetrospectiveService) CreateShare(ctx context.Context, retroID, userID uuid.UUID, expiresIn time.Duration, spectatorOnly bool) (*models.Retrospective, error) {
+	if expiresIn < time.Minute || expiresIn > maxShareExpiry {
+		return nil, ErrInvalidShareExpiry
+	}
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	if retro.FacilitatorID != userID {
+		return nil, ErrNotFacilitator
+	}
+
+	shareID := uuid.New()
+	expiresAt := time.Now().Add(expiresIn)
+	if err := s.retroRepo.UpdateShare(ctx, retroID, &shareID, &expiresAt, spectatorOnly, true); err != nil {
+		return nil, err
+	}
+	retro.ShareID = &shareID
+	retro.ShareExpiresAt = &expiresAt
+	retro.ShareSpectatorOnly = spectatorOnly
+	retro.AllowGuests = true
+	return retro, nil
+}
+
+// RevokeShare revokes the retro's share link. Guests can no longer join with it, and the
+// connections opened with it are closed at the next token re-check.
+func (s *RetrospectiveService) RevokeShare(ctx context.Context, retroID, userID uuid.UUID) error {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return err
+	}
+	if retro.FacilitatorID != userID {
+		return ErrNotFacilitator
+	}
+	if retro.ShareID == nil {
+		return ErrShareNotFound
+	}
+	return s.retroRepo.UpdateShare(ctx, retroID, nil, nil, false, retro.AllowGuests)
+}
+
+// CheckShare returns the retro if guests may still join it with the share link
+func (s *RetrospectiveService) CheckShare(ctx context.Context, retroID, shareID uuid.UUID) (*models.Retrospective, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateShare(retro, shareID, time.Now()); err != nil {
+		return nil, err
+	}
+	return retro, nil
+}
+
+// ValidateShare checks that the retro allows guests and that shareID is its active share link at now
+func ValidateShare(retro *models.Retrospective, shareID uuid.UUID, now time.Time) error {
+	if !retro.AllowGuests {
+		return ErrGuestsNotAllowed
+	}
+	if retro.ShareID == nil || *retro.ShareID != shareID {
+		return ErrShareRevoked
+	}
+	if retro.ShareExpiresAt != nil && now.After(*retro.ShareExpiresAt) {
+		return ErrShareExpired
+	}
+	return nil
+}
+
+// checkRetroTeamAdmin requires the user to be an admin of the retro's team; the retro may be soft-deleted
+func (s *RetrospectiveService) checkRetroTeamAdmin(ctx context.Context, retroID, userID uuid.UUID) error {
+	teamID, err := s.retroRepo.FindTeamID(ctx, retroID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrRetroNotFound
+		}
+		return err
+	}
+	return s.checkTeamAdmin(ctx, teamID, userID)
+}
+
+// checkTeamAdmin requires the user to be an admin of the team
+func (s *RetrospectiveService) checkTeamAdmin(ctx context.Context, teamID, userID uuid.UUID) error {
+	role, err := s.memberRepo.GetUserRole(ctx, teamID, userID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrNotTeamMember
+		}
+		return err
+	}
+	if role != models.RoleAdmin {
+		return ErrNotAuthorized
+	}
+	return nil
+}
+
+// SetPhase sets the current phase
+func (s *RetrospectiveService) SetPhase(ctx context.Context, id uuid.UUID, phase models.RetroPhase) error {
+	retro, err := s.retroRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.retroRepo.UpdatePhase(ctx, id, phase); err != nil {
+		return err
+	}
+
+	s.recordPhaseStart(ctx, retro, phase, time.Now())
+	return nil
+}
+
+// GetPhaseSequence returns the phase sequence for a given session type
+func GetPhaseSequence(sessionType models.SessionType) []models.RetroPhase {
+	if sessionType == models.SessionTypeLeanCoffee {
+		return []models.RetroPhase{
+			models.PhaseWaiting,
+			models.PhaseIcebreaker,
+			models.PhasePropose,
+			models.PhaseVote,
+			models.PhaseDiscuss,
+			models.PhaseRoti,
+		}
+	}
+	// Default retro phases
+	return []models.RetroPhase{
+		models.PhaseWaiting,
+		models.PhaseIcebreaker,
+		models.PhaseBrainstorm,
+		models.PhaseGroup,
+		models.PhaseVote,
+		models.PhaseDiscuss,
+		models.PhaseRoti,
+	}
+}
+
+// NextPhase advances to the next phase
+func (s *RetrospectiveService) NextPhase(ctx context.Context, id uuid.UUID) (models.RetroPhase, error) {
+	retro, err := s.retroRepo.FindByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	phases := GetPhaseSequence(retro.SessionType)
+
+	currentIdx := -1
+	for i, p := range phases {
+		if p == retro.CurrentPhase {
+			currentIdx = i
+			break
+		}
+	}
+
+	if currentIdx == -1 || currentIdx >= len(phases)-1 {
+		return retro.CurrentPhase, nil // Already at last phase
+	}
+
+	nextPhase := phases[currentIdx+1]
+	if err := s.retroRepo.UpdatePhase(ctx, id, nextPhase); err != nil {
+		return "", err
+	}
+
+	s.recordPhaseStart(ctx, retro, nextPhase, time.Now())
+	return nextPhase, nil
+}
+
+// recordPhaseStart closes the open phase history row and opens one for the new phase
+// with its planned duration. Failures are logged since history is only used for analytics.
+func (s *RetrospectiveService) recordPhaseStart(ctx context.Context, retro *models.Retrospective, phase models.RetroPhase, at time.Time) {
+	if err := s.phaseHistory.CloseOpen(ctx, retro.ID, at); err != nil {
+		log.Printf("phase history: failed to close previous phase of retro %s: %v", retro.ID, err)
+		return
+	}
+
+	planned, err := s.GetPhaseDuration(ctx, retro.TemplateID, phase)
+	if err != nil {
+		log.Printf("phase history: failed to get planned duration of %s for retro %s: %v", phase, retro.ID, err)
+	}
+
+	if err := s.phaseHistory.Open(ctx, retro.ID, phase, planned, at); err != nil {
+		log.Printf("phase history: failed to open phase %s for retro %s: %v", phase, retro.ID, err)
+	}
+}
+
+// GetPhaseDuration gets the default duration for a phase
+func (s *RetrospectiveService) GetPhaseDuration(ctx context.Context, templateID uuid.UUID, phase models.RetroPhase) (int, error) {
+	template, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return 0, err
 	}
 
 	if duration, ok := template.PhaseTimes[phase]; ok {
@@ -403,70 +1072,261 @@ type CreateItemInput struct {
 	Content  string
 }
 
-// CreateItem creates a new item
+// CreateItem creates a new item at the end of its column
 func (s *RetrospectiveService) CreateItem(ctx context.Context, retroID, authorID uuid.UUID, input CreateItemInput) (*models.Item, error) {
-	position, err := s.itemRepo.GetNextPosition(ctx, retroID, input.ColumnID)
-	if err != nil {
-		return nil, err
-	}
-
 	item := &models.Item{
 		ID:       uuid.New(),
 		RetroID:  retroID,
 		ColumnID: input.ColumnID,
 		Content:  input.Content,
 		AuthorID: authorID,
-		Position: position,
 	}
 
 	return s.itemRepo.Create(ctx, item)
 }
 
-// UpdateItem updates an item
-func (s *RetrospectiveService) UpdateItem(ctx context.Context, id uuid.UUID, content string) (*models.Item, error) {
-	item, err := s.itemRepo.FindByID(ctx, id)
+// ItemImportError lists the invalid fields of a bulk import, keyed by JSON path
+type ItemImportError struct {
+	Fields map[string]string
+}
+
+func (e *ItemImportError) Error() string {
+	paths := make([]string, 0, len(e.Fields))
+	for path := range e.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return "invalid items: " + strings.Join(paths, ", ")
+}
+
+// ImportItems creates the facilitator's items at once, after the existing items of their
+// columns and in the order given. Nothing is created if any item is invalid.
+func (s *RetrospectiveService) ImportItems(ctx context.Context, retroID, userID uuid.UUID, inputs []CreateItemInput) ([]*models.Item, error) {
+	if len(inputs) == 0 || len(inputs) > MaxImportedItems {
+		return nil, ErrInvalidItemImport
+	}
+
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	if retro.FacilitatorID != userID {
+		return nil, ErrNotFacilitator
+	}
+	template, err := s.templateRepo.FindByID(ctx, retro.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]bool, len(template.Columns))
+	for _, column := range template.Columns {
+		columns[column.ID] = true
+	}
+
+	fields := make(map[string]string)
+	items := make([]*models.Item, 0, len(inputs))
+	for i, input := range inputs {
+		path := fmt.Sprintf("items[%d]", i)
+		if !columns[input.ColumnID] {
+			fields[path+".columnId"] = "is not a column of the retrospective"
+		}
+		content := strings.TrimSpace(input.Content)
+		switch {
+		case content == "":
+			fields[path+".content"] = "must not be empty"
+		case utf8.RuneCountInString(content) > maxImportedItemLength:
+			fields[path+".content"] = fmt.Sprintf("must be at most %d characters", maxImportedItemLength)
+		}
+		items = append(items, &models.Item{
+			ID:       uuid.New(),
+			RetroID:  retroID,
+			ColumnID: input.ColumnID,
+			Content:  content,
+			AuthorID: userID,
+		})
+	}
+	if len(fields) > 0 {
+		return nil, &ItemImportError{Fields: fields}
+	}
+
+	// The imported columns are compacted in the same transaction, closing the gaps earlier
+	// moves and deletes left
+	return s.itemRepo.CreateBatch(ctx, items)
+}
+
+// findRetroItem loads an item of the retro; items of another retro are reported as not found
+func (s *RetrospectiveService) findRetroItem(ctx context.Context, retroID, itemID uuid.UUID) (*models.Item, error) {
+	item, err := s.itemRepo.FindByID(ctx, itemID)
 	if err != nil {
 		if errors.Is(err, postgres.ErrNotFound) {
 			return nil, ErrItemNotFound
 		}
 		return nil, err
 	}
+	if item.RetroID != retroID {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
+
+// findRetroAction loads an action of the retro; actions of another retro are reported as not found
+func (s *RetrospectiveService) findRetroAction(ctx context.Context, retroID, actionID uuid.UUID) (*models.ActionItem, error) {
+	action, err := s.actionRepo.FindByID(ctx, actionID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrActionNotFound
+		}
+		return nil, err
+	}
+	if action.RetroID != retroID {
+		return nil, ErrActionNotFound
+	}
+	return action, nil
+}
+
+// UpdateItem updates the content of an item of the retro and records the change in its history
+func (s *RetrospectiveService) UpdateItem(ctx context.Context, retroID, id, editorID uuid.UUID, content string) (*models.Item, error) {
+	item, err := s.findRetroItem(ctx, retroID, id)
+	if err != nil {
+		return nil, err
+	}
 
+	previous := item.Content
 	item.Content = content
 	if err := s.itemRepo.Update(ctx, item); err != nil {
 		return nil, err
 	}
 
+	if previous != content {
+		// History is written in the background so it does not slow down editing
+		edit := &models.ItemEdit{
+			ItemID:     item.ID,
+			EditorID:   &editorID,
+			OldContent: previous,
+			NewContent: content,
+			EditedAt:   time.Now(),
+		}
+		go func() {
+			if err := s.itemHistory.Record(context.WithoutCancel(ctx), edit); err != nil {
+				log.Printf("item history: failed to record edit of item %s: %v", edit.ItemID, err)
+			}
+		}()
+	}
+
 	return item, nil
 }
 
-// DeleteItem deletes an item
-func (s *RetrospectiveService) DeleteItem(ctx context.Context, id uuid.UUID) error {
-	return s.itemRepo.Delete(ctx, id)
-}
+// GetItemHistory lists the content changes of an item of the retrospective.
+// In retros with anonymous items only the facilitator may see who edited what.
+func (s *RetrospectiveService) GetItemHistory(ctx context.Context, retroID, itemID, userID uuid.UUID) ([]*models.ItemEdit, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	if retro.AnonymousItems && retro.FacilitatorID != userID {
+		return nil, ErrNotFacilitator
+	}
 
-// MoveItem moves an item to a new position
-func (s *RetrospectiveService) MoveItem(ctx context.Context, id uuid.UUID, columnID string, position int) (*models.Item, error) {
-	item, err := s.itemRepo.FindByID(ctx, id)
+	item, err := s.itemRepo.FindByID(ctx, itemID)
 	if err != nil {
 		if errors.Is(err, postgres.ErrNotFound) {
 			return nil, ErrItemNotFound
 		}
 		return nil, err
 	}
+	if item.RetroID != retroID {
+		return nil, ErrItemNotFound
+	}
 
-	item.ColumnID = columnID
-	item.Position = position
-	if err := s.itemRepo.Update(ctx, item); err != nil {
-		return nil, err
+	return s.itemHistory.ListByItem(ctx, itemID)
+}
+
+// ToggleItemDiscussed marks an item of a retro as discussed, or not anymore when it already
+// was, and returns the new value. Only the facilitator can tick items off.
+func (s *RetrospectiveService) ToggleItemDiscussed(ctx context.Context, retroID, itemID, userID uuid.UUID) (bool, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return false, err
+	}
+	if retro.FacilitatorID != userID {
+		return false, ErrNotFacilitator
 	}
 
-	return item, nil
+	item, err := s.itemRepo.FindByID(ctx, itemID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return false, ErrItemNotFound
+		}
+		return false, err
+	}
+	if item.RetroID != retroID {
+		return false, ErrItemNotFound
+	}
+
+	discussed, err := s.itemRepo.ToggleDiscussed(ctx, itemID)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return false, ErrItemNotFound
+	}
+	return discussed, err
 }
 
-// GroupItems groups items together
-func (s *RetrospectiveService) GroupItems(ctx context.Context, parentID uuid.UUID, childIDs []uuid.UUID) ([]uuid.UUID, error) {
+// FocusItem highlights an item of a retro on every screen until the phase changes.
+// Only the facilitator can focus items.
+func (s *RetrospectiveService) FocusItem(ctx context.Context, retroID, itemID, userID uuid.UUID) error {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return err
+	}
+	if retro.FacilitatorID != userID {
+		return ErrNotFacilitator
+	}
+
+	item, err := s.itemRepo.FindByID(ctx, itemID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrItemNotFound
+		}
+		return err
+	}
+	if item.RetroID != retroID {
+		return ErrItemNotFound
+	}
+
+	return s.retroRepo.UpdateFocusedItem(ctx, retroID, &itemID)
+}
+
+// DeleteItem deletes an item of the retro
+func (s *RetrospectiveService) DeleteItem(ctx context.Context, retroID, id uuid.UUID) error {
+	if _, err := s.findRetroItem(ctx, retroID, id); err != nil {
+		return err
+	}
+	return s.itemRepo.Delete(ctx, id)
+}
+
+// MoveItem moves an item of the retro to a new position. Items grouped under it follow it to the
+// new column, keeping their relative order. It returns every item whose column or position changed.
+func (s *RetrospectiveService) MoveItem(ctx context.Context, retroID, id uuid.UUID, columnID string, position int) ([]*models.Item, error) {
+	if _, err := s.findRetroItem(ctx, retroID, id); err != nil {
+		return nil, err
+	}
+	moved, err := s.itemRepo.MoveGroup(ctx, id, columnID, position)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	return moved, nil
+}
+
+// GroupItems groups items of the retro together. Nothing is grouped when the parent or any child
+// belongs to another retro.
+func (s *RetrospectiveService) GroupItems(ctx context.Context, retroID, parentID uuid.UUID, childIDs []uuid.UUID) ([]uuid.UUID, error) {
 	log.Printf("GroupItems: parentID=%s, childIDs=%v", parentID, childIDs)
+	for _, id := range append([]uuid.UUID{parentID}, childIDs...) {
+		if _, err := s.findRetroItem(ctx, retroID, id); err != nil {
+			return nil, err
+		}
+	}
 	allAffected := make([]uuid.UUID, 0, len(childIDs))
 	for _, childID := range childIDs {
 		item, err := s.itemRepo.FindByID(ctx, childID)
@@ -508,12 +1368,30 @@ func (s *RetrospectiveService) ListItems(ctx context.Context, retroID uuid.UUID)
 	return s.itemRepo.ListByRetro(ctx, retroID)
 }
 
+// ListItemsTree lists the items of a retrospective as a tree, with grouped items nested under their parent
+func (s *RetrospectiveService) ListItemsTree(ctx context.Context, retroID uuid.UUID) ([]*models.Item, error) {
+	return s.itemRepo.ListByRetroTree(ctx, retroID)
+}
+
+// GetGroupedVoteSummary returns the vote total of each top-level item, with child votes summed into their parent
+func (s *RetrospectiveService) GetGroupedVoteSummary(ctx context.Context, retroID uuid.UUID) (map[uuid.UUID]int, error) {
+	return s.itemRepo.GetGroupedVoteSummary(ctx, retroID)
+}
+
+// SearchItems lists the items of a retrospective matching the filter
+func (s *RetrospectiveService) SearchItems(ctx context.Context, retroID uuid.UUID, filter *models.ItemFilter) ([]*models.Item, error) {
+	return s.itemRepo.Search(ctx, retroID, filter)
+}
+
 // Vote adds a vote to an item
 func (s *RetrospectiveService) Vote(ctx context.Context, retroID, itemID, userID uuid.UUID) error {
 	retro, err := s.retroRepo.FindByID(ctx, retroID)
 	if err != nil {
 		return err
 	}
+	if _, err := s.findRetroItem(ctx, retroID, itemID); err != nil {
+		return err
+	}
 
 	// Check total vote limit per user in the retro
 	currentVotes, err := s.voteRepo.CountByUser(ctx, retroID, userID)
@@ -525,10 +1403,22 @@ func (s *RetrospectiveService) Vote(ctx context.Context, retroID, itemID, userID
 		return ErrVoteLimitReached
 	}
 
-	// Check vote limit per item
-	votesOnItem, err := s.voteRepo.CountByUserOnItem(ctx, itemID, userID)
-	if err != nil {
-		return err
+	// Check vote limit per item, or per group when the retro counts a group as one card
+	var votesOnItem int
+	if retro.VoteLimitPerGroup {
+		groupIDs, err := s.itemRepo.ListGroupItemIDs(ctx, retroID, itemID)
+		if err != nil {
+			return err
+		}
+		votesOnItem, err = s.voteRepo.CountByUserOnItems(ctx, groupIDs, userID)
+		if err != nil {
+			return err
+		}
+	} else {
+		votesOnItem, err = s.voteRepo.CountByUserOnItem(ctx, itemID, userID)
+		if err != nil {
+			return err
+		}
 	}
 
 	if votesOnItem >= retro.MaxVotesPerItem {
@@ -560,16 +1450,80 @@ func (s *RetrospectiveService) GetUserVoteCount(ctx context.Context, retroID, us
 	return s.voteRepo.CountByUser(ctx, retroID, userID)
 }
 
-// GetUserVoteCountOnItem gets the number of votes a user has on a specific item
-func (s *RetrospectiveService) GetUserVoteCountOnItem(ctx context.Context, itemID, userID uuid.UUID) (int, error) {
+// GetUserVoteCountOnItem gets the number of votes a user has on a specific item of the retro
+func (s *RetrospectiveService) GetUserVoteCountOnItem(ctx context.Context, retroID, itemID, userID uuid.UUID) (int, error) {
+	if _, err := s.findRetroItem(ctx, retroID, itemID); err != nil {
+		return 0, err
+	}
 	return s.voteRepo.CountByUserOnItem(ctx, itemID, userID)
 }
 
+// GetItemVoteCount gets the number of votes on an item, from all users
+func (s *RetrospectiveService) GetItemVoteCount(ctx context.Context, itemID uuid.UUID) (int, error) {
+	return s.voteRepo.CountByItem(ctx, itemID)
+}
+
+// GetItemVoteCounts gets the number of votes on each of the items
+func (s *RetrospectiveService) GetItemVoteCounts(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	return s.voteRepo.CountByItems(ctx, itemIDs)
+}
+
 // GetVoteSummary returns the vote summary for a retrospective: map[userID]map[itemID]count
 func (s *RetrospectiveService) GetVoteSummary(ctx context.Context, retroID uuid.UUID) (map[uuid.UUID]map[uuid.UUID]int, error) {
 	return s.voteRepo.GetVoteSummaryByRetro(ctx, retroID)
 }
 
+// VoteSummaryView is a vote summary shaped for a given viewer
+type VoteSummaryView struct {
+	Anonymous  bool                      `json:"anonymous"`
+	ByUser     map[string]map[string]int `json:"voteSummary"`
+	ItemTotals map[string]int            `json:"itemTotals"`
+	// GroupTotals holds the totals of top-level items with the votes of their grouped children included
+	GroupTotals map[string]int `json:"groupTotals"`
+}
+
+// GetVoteSummaryFor returns the vote summary of a retrospective as the viewer may see it.
+// With anonymous voting, only the viewer's own votes are returned per user alongside
+// per-item totals, so nobody can tell who voted for what.
+func (s *RetrospectiveService) GetVoteSummaryFor(ctx context.Context, retro *models.Retrospective, viewerID uuid.UUID) (*VoteSummaryView, error) {
+	summary, err := s.voteRepo.GetVoteSummaryByRetro(ctx, retro.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupTotals, err := s.itemRepo.GetGroupedVoteSummary(ctx, retro.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &VoteSummaryView{
+		Anonymous:   retro.AnonymousVoting,
+		ByUser:      make(map[string]map[string]int),
+		ItemTotals:  make(map[string]int),
+		GroupTotals: make(map[string]int, len(groupTotals)),
+	}
+	for itemID, total := range groupTotals {
+		view.GroupTotals[itemID.String()] = total
+	}
+	for userID, itemVotes := range summary {
+		visible := !retro.AnonymousVoting || userID == viewerID
+
+		var userVotes map[string]int
+		if visible {
+			userVotes = make(map[string]int, len(itemVotes))
+			view.ByUser[userID.String()] = userVotes
+		}
+		for itemID, count := range itemVotes {
+			view.ItemTotals[itemID.String()] += count
+			if visible {
+				userVotes[itemID.String()] = count
+			}
+		}
+	}
+
+	return view, nil
+}
+
 // CreateActionInput represents input for creating an action item
 type CreateActionInput struct {
 	Title       string
@@ -577,18 +1531,89 @@ type CreateActionInput struct {
 	AssigneeID  *uuid.UUID
 	DueDate     *time.Time
 	ItemID      *uuid.UUID
-	Priority    int
+	Priority    models.ActionPriority
 }
 
-// PatchActionInput represents input for partially updating an action item
-type PatchActionInput struct {
-	Status      *string    `json:"status"`
-	AssigneeID  *uuid.UUID `json:"assigneeId"`
-	Description *string    `json:"description"`
+// PatchActionInput represents input for partially updating an action item
+type PatchActionInput struct {
+	Status      *string      `json:"status"`
+	AssigneeID  *uuid.UUID   `json:"assigneeId"`
+	Description *string      `json:"description"`
+	DueDate     NullableTime `json:"dueDate"` // null clears the due date
+}
+
+// NullableTime is a PATCH field that tells a missing value apart from an explicit null.
+// It accepts RFC 3339 timestamps and plain dates (2006-01-02).
+type NullableTime struct {
+	Set   bool       // the field was in the request
+	Value *time.Time // nil when the field was null
+}
+
+// UnmarshalJSON records that the field was set, and its value unless it is null
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	n.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, raw); err != nil {
+			return fmt.Errorf("invalid date %q: %w", raw, err)
+		}
+	}
+	n.Value = &t
+	return nil
+}
+
+// GetActionAllowance returns how many more action items can be created in a retrospective,
+// taking both the retro cap and the team's open actions cap into account.
+// It returns nil when no cap applies.
+func (s *RetrospectiveService) GetActionAllowance(ctx context.Context, retroID uuid.UUID) (*int, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining *int
+	if retro.MaxActionsPerRetro != nil {
+		count, err := s.actionRepo.CountByRetro(ctx, retroID)
+		if err != nil {
+			return nil, err
+		}
+		left := max(*retro.MaxActionsPerRetro-count, 0)
+		remaining = &left
+	}
+
+	team, err := s.teamRepo.FindByID(ctx, retro.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.MaxOpenActions != nil {
+		count, err := s.actionRepo.CountOpenByTeam(ctx, retro.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		left := max(*team.MaxOpenActions-count, 0)
+		if remaining == nil || left < *remaining {
+			remaining = &left
+		}
+	}
+
+	return remaining, nil
 }
 
 // CreateAction creates a new action item
 func (s *RetrospectiveService) CreateAction(ctx context.Context, retroID, createdBy uuid.UUID, input CreateActionInput) (*models.ActionItem, error) {
+	if !input.Priority.IsValid() {
+		return nil, ErrInvalidPriority
+	}
+
 	action := &models.ActionItem{
 		ID:          uuid.New(),
 		RetroID:     retroID,
@@ -602,8 +1627,15 @@ func (s *RetrospectiveService) CreateAction(ctx context.Context, retroID, create
 		Status:      "todo",
 	}
 
+	// The caps are checked in the same transaction as the insert
 	createdAction, err := s.actionRepo.Create(ctx, action)
 	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrLimitReached):
+			return nil, ErrActionLimitReached
+		case errors.Is(err, postgres.ErrNotFound):
+			return nil, ErrRetroNotFound
+		}
 		return nil, err
 	}
 
@@ -638,13 +1670,14 @@ func (s *RetrospectiveService) dispatchActionCreatedWebhook(ctx context.Context,
 	s.webhookService.DispatchActionCreated(ctx, action, retro.TeamID, data)
 }
 
-// UpdateAction updates an action item
-func (s *RetrospectiveService) UpdateAction(ctx context.Context, id uuid.UUID, input CreateActionInput) (*models.ActionItem, error) {
-	action, err := s.actionRepo.FindByID(ctx, id)
+// UpdateAction updates an action item of the retro
+func (s *RetrospectiveService) UpdateAction(ctx context.Context, retroID, id uuid.UUID, input CreateActionInput) (*models.ActionItem, error) {
+	if !input.Priority.IsValid() {
+		return nil, ErrInvalidPriority
+	}
+
+	action, err := s.findRetroAction(ctx, retroID, id)
 	if err != nil {
-		if errors.Is(err, postgres.ErrNotFound) {
-			return nil, ErrActionNotFound
-		}
 		return nil, err
 	}
 
@@ -661,29 +1694,25 @@ func (s *RetrospectiveService) UpdateAction(ctx context.Context, id uuid.UUID, i
 	return action, nil
 }
 
-// CompleteAction marks an action item as completed
-func (s *RetrospectiveService) CompleteAction(ctx context.Context, id uuid.UUID) (*models.ActionItem, error) {
-	action, err := s.actionRepo.FindByID(ctx, id)
-	if err != nil {
-		if errors.Is(err, postgres.ErrNotFound) {
-			return nil, ErrActionNotFound
-		}
-		return nil, err
-	}
+// CompleteAction marks an action item of the retro as completed
+func (s *RetrospectiveService) CompleteAction(ctx context.Context, retroID, id, userID uuid.UUID) (*models.ActionItem, error) {
+	return s.setActionCompleted(ctx, retroID, id, userID, true)
+}
 
-	now := time.Now()
-	action.IsCompleted = true
-	action.CompletedAt = &now
+// UncompleteAction marks an action item of the retro as not completed
+func (s *RetrospectiveService) UncompleteAction(ctx context.Context, retroID, id, userID uuid.UUID) (*models.ActionItem, error) {
+	return s.setActionCompleted(ctx, retroID, id, userID, false)
+}
 
-	if err := s.actionRepo.Update(ctx, action); err != nil {
+func (s *RetrospectiveService) setActionCompleted(ctx context.Context, retroID, id, userID uuid.UUID, completed bool) (*models.ActionItem, error) {
+	if _, err := s.findRetroAction(ctx, retroID, id); err != nil {
+		return nil, err
+	}
+	changed, err := s.actionRepo.SetCompleted(ctx, id, completed)
+	if err != nil {
 		return nil, err
 	}
 
-	return action, nil
-}
-
-// UncompleteAction marks an action item as not completed
-func (s *RetrospectiveService) UncompleteAction(ctx context.Context, id uuid.UUID) (*models.ActionItem, error) {
 	action, err := s.actionRepo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, postgres.ErrNotFound) {
@@ -692,18 +1721,26 @@ func (s *RetrospectiveService) UncompleteAction(ctx context.Context, id uuid.UUI
 		return nil, err
 	}
 
-	action.IsCompleted = false
-	action.CompletedAt = nil
-
-	if err := s.actionRepo.Update(ctx, action); err != nil {
-		return nil, err
+	if changed && s.webhookService != nil {
+		go s.dispatchActionCompletionWebhook(context.Background(), action, userID)
 	}
 
 	return action, nil
 }
 
-// PatchAction partially updates an action item (status, assignee)
-func (s *RetrospectiveService) PatchAction(ctx context.Context, id uuid.UUID, input PatchActionInput) (*models.ActionItem, error) {
+// PatchAction partially updates an action item (status, assignee, description, due date).
+// Moving it to or out of done dispatches action.completed or action.reopened.
+func (s *RetrospectiveService) PatchAction(ctx context.Context, id, userID uuid.UUID, input PatchActionInput) (*models.ActionItem, error) {
+	// Completion changes go through SetCompleted so they are only notified once
+	var completionChanged bool
+	if input.Status != nil {
+		changed, err := s.actionRepo.SetCompleted(ctx, id, *input.Status == "done")
+		if err != nil {
+			return nil, err
+		}
+		completionChanged = changed
+	}
+
 	action, err := s.actionRepo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, postgres.ErrNotFound) {
@@ -714,14 +1751,6 @@ func (s *RetrospectiveService) PatchAction(ctx context.Context, id uuid.UUID, in
 
 	if input.Status != nil {
 		action.Status = *input.Status
-		if *input.Status == "done" {
-			now := time.Now()
-			action.IsCompleted = true
-			action.CompletedAt = &now
-		} else {
-			action.IsCompleted = false
-			action.CompletedAt = nil
-		}
 	}
 	if input.AssigneeID != nil {
 		action.AssigneeID = input.AssigneeID
@@ -729,16 +1758,53 @@ func (s *RetrospectiveService) PatchAction(ctx context.Context, id uuid.UUID, in
 	if input.Description != nil {
 		action.Description = input.Description
 	}
+	if input.DueDate.Set {
+		action.DueDate = input.DueDate.Value
+	}
 
 	if err := s.actionRepo.Update(ctx, action); err != nil {
 		return nil, err
 	}
 
+	if completionChanged && s.webhookService != nil {
+		go s.dispatchActionCompletionWebhook(context.Background(), action, userID)
+	}
+
 	return action, nil
 }
 
-// DeleteAction deletes an action item
-func (s *RetrospectiveService) DeleteAction(ctx context.Context, id uuid.UUID) error {
+// dispatchActionCompletionWebhook dispatches action.completed, or action.reopened when the
+// action is no longer completed
+func (s *RetrospectiveService) dispatchActionCompletionWebhook(ctx context.Context, action *models.ActionItem, changedBy uuid.UUID) {
+	retro, err := s.retroRepo.FindByID(ctx, action.RetroID)
+	if err != nil {
+		log.Printf("webhook: failed to find retro %s for action webhook: %v", action.RetroID, err)
+		return
+	}
+
+	data := models.ActionCompletedData{
+		ActionID:    action.ID,
+		Title:       action.Title,
+		Status:      action.Status,
+		AssigneeID:  action.AssigneeID,
+		CompletedAt: action.CompletedAt,
+		ChangedBy:   changedBy,
+		ExternalID:  action.ExternalID,
+		ExternalURL: action.ExternalURL,
+	}
+
+	if action.IsCompleted {
+		s.webhookService.DispatchActionCompleted(ctx, action, retro.TeamID, data)
+	} else {
+		s.webhookService.DispatchActionReopened(ctx, action, retro.TeamID, data)
+	}
+}
+
+// DeleteAction deletes an action item of the retro
+func (s *RetrospectiveService) DeleteAction(ctx context.Context, retroID, id uuid.UUID) error {
+	if _, err := s.findRetroAction(ctx, retroID, id); err != nil {
+		return err
+	}
 	return s.actionRepo.Delete(ctx, id)
 }
 
@@ -752,6 +1818,74 @@ func (s *RetrospectiveService) ListActionsByTeam(ctx context.Context, teamID uui
 	return s.actionRepo.ListByTeam(ctx, teamID)
 }
 
+// ListMyActions lists the action items assigned to the user across their teams
+func (s *RetrospectiveService) ListMyActions(ctx context.Context, userID uuid.UUID, filter *models.ActionListFilter) ([]*models.ActionItem, error) {
+	return s.actionRepo.ListByAssignee(ctx, userID, filter)
+}
+
+// AddActionComment adds a follow-up note to an action item; only members of the action's team may comment
+func (s *RetrospectiveService) AddActionComment(ctx context.Context, actionID, userID uuid.UUID, content string) (*models.ActionComment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > maxActionCommentLength {
+		return nil, ErrInvalidActionComment
+	}
+	action, teamID, err := s.checkActionTeamMember(ctx, actionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.actionComments.Create(ctx, &models.ActionComment{ActionID: actionID, AuthorID: &userID, Content: content})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.webhookService != nil {
+		go s.webhookService.DispatchActionCommented(context.Background(), action, teamID, models.ActionCommentedData{
+			ActionID:   action.ID,
+			Title:      action.Title,
+			CommentID:  comment.ID,
+			Content:    comment.Content,
+			AuthorID:   comment.AuthorID,
+			AuthorName: comment.AuthorName,
+		})
+	}
+
+	return comment, nil
+}
+
+// ListActionComments lists the comments on an action item, oldest first
+func (s *RetrospectiveService) ListActionComments(ctx context.Context, actionID, userID uuid.UUID) ([]*models.ActionComment, error) {
+	if _, _, err := s.checkActionTeamMember(ctx, actionID, userID); err != nil {
+		return nil, err
+	}
+	return s.actionComments.ListByAction(ctx, actionID)
+}
+
+// checkActionTeamMember requires the user to be a member of the action's team and returns the action and team
+func (s *RetrospectiveService) checkActionTeamMember(ctx context.Context, actionID, userID uuid.UUID) (*models.ActionItem, uuid.UUID, error) {
+	action, err := s.actionRepo.FindByID(ctx, actionID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, uuid.Nil, ErrActionNotFound
+		}
+		return nil, uuid.Nil, err
+	}
+	teamID, err := s.retroRepo.FindTeamID(ctx, action.RetroID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, uuid.Nil, ErrActionNotFound
+		}
+		return nil, uuid.Nil, err
+	}
+	if _, err := s.memberRepo.GetUserRole(ctx, teamID, userID); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, uuid.Nil, ErrNotTeamMember
+		}
+		return nil, uuid.Nil, err
+	}
+	return action, teamID, nil
+}
+
 // ListTemplates lists templates (built-in and team-specific)
 func (s *RetrospectiveService) ListTemplates(ctx context.Context, teamID *uuid.UUID) ([]*models.Template, error) {
 	if teamID != nil {
@@ -760,6 +1894,11 @@ func (s *RetrospectiveService) ListTemplates(ctx context.Context, teamID *uuid.U
 	return s.templateRepo.ListBuiltIn(ctx)
 }
 
+// ListTeamTemplates lists only the templates a team created, which it can edit
+func (s *RetrospectiveService) ListTeamTemplates(ctx context.Context, teamID uuid.UUID) ([]*models.Template, error) {
+	return s.templateRepo.ListTeamOwned(ctx, teamID)
+}
+
 // GetTemplate gets a template by ID
 func (s *RetrospectiveService) GetTemplate(ctx context.Context, id uuid.UUID) (*models.Template, error) {
 	template, err := s.templateRepo.FindByID(ctx, id)
@@ -774,14 +1913,198 @@ func (s *RetrospectiveService) GetTemplate(ctx context.Context, id uuid.UUID) (*
 
 // CreateTemplate creates a new template
 func (s *RetrospectiveService) CreateTemplate(ctx context.Context, template *models.Template) (*models.Template, error) {
+	if err := validateTemplate(template.Name, template.Columns, template.PhaseTimes); err != nil {
+		return nil, err
+	}
+	if err := validateMoodScale(template.MoodScale); err != nil {
+		return nil, err
+	}
 	return s.templateRepo.Create(ctx, template)
 }
 
-// SetIcebreakerMood sets a user's mood in the icebreaker phase
-func (s *RetrospectiveService) SetIcebreakerMood(ctx context.Context, retroID, userID uuid.UUID, mood models.MoodWeather) (*models.IcebreakerMood, error) {
+// UpdateTemplateInput represents input for updating a template
+type UpdateTemplateInput struct {
+	Name        string
+	Description *string
+	Columns     []models.TemplateColumn
+	PhaseTimes  map[models.RetroPhase]int
+	MoodScale   []models.MoodOption
+}
+
+// UpdateTemplate replaces a template's name, description, columns, phase timers and mood scale
+func (s *RetrospectiveService) UpdateTemplate(ctx context.Context, id, userID uuid.UUID, input UpdateTemplateInput) (*models.Template, error) {
+	if err := validateTemplate(input.Name, input.Columns, input.PhaseTimes); err != nil {
+		return nil, err
+	}
+	if err := validateMoodScale(input.MoodScale); err != nil {
+		return nil, err
+	}
+
+	template, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanEditTemplate(ctx, template, userID); err != nil {
+		return nil, err
+	}
+
+	template.Name = input.Name
+	template.Description = input.Description
+	template.Columns = input.Columns
+	template.PhaseTimes = input.PhaseTimes
+	template.MoodScale = input.MoodScale
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return s.GetTemplate(ctx, id)
+}
+
+// DeleteTemplate deletes a template that no retrospective uses
+func (s *RetrospectiveService) DeleteTemplate(ctx context.Context, id, userID uuid.UUID) error {
+	template, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCanEditTemplate(ctx, template, userID); err != nil {
+		return err
+	}
+
+	count, err := s.templateRepo.CountRetrospectives(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTemplateInUse
+	}
+
+	if err := s.templateRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkCanEditTemplate refuses built-in templates; team templates need a team admin
+// and templates without a team their creator
+func (s *RetrospectiveService) checkCanEditTemplate(ctx context.Context, template *models.Template, userID uuid.UUID) error {
+	if template.IsBuiltIn {
+		return ErrTemplateBuiltIn
+	}
+	if template.TeamID == nil {
+		if template.CreatedBy == nil || *template.CreatedBy != userID {
+			return ErrNotAuthorized
+		}
+		return nil
+	}
+	return s.checkTeamAdmin(ctx, *template.TeamID, userID)
+}
+
+func validateMoodScale(scale []models.MoodOption) error {
+	seen := make(map[string]bool, len(scale))
+	for _, option := range scale {
+		if option.Value == "" || seen[option.Value] {
+			return ErrInvalidMoodScale
+		}
+		seen[option.Value] = true
+	}
+	return nil
+}
+
+// DuplicateTemplate copies a built-in template or one of the team's templates into a new
+// editable template owned by the team. An empty name defaults to the source name with " (copy)".
+func (s *RetrospectiveService) DuplicateTemplate(ctx context.Context, templateID, teamID, userID uuid.UUID, name string) (*models.Template, error) {
+	isMember, err := s.memberRepo.IsMember(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotTeamMember
+	}
+
+	source, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	// Another team's templates are not visible to this team
+	if !source.IsBuiltIn && (source.TeamID == nil || *source.TeamID != teamID) {
+		return nil, ErrTemplateNotFound
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	template, err := s.templateRepo.Duplicate(ctx, templateID, teamID, userID, name)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// SetIcebreakerMood sets or changes a user's mood in the icebreaker phase
+// The mood must be a value of the retro template's mood scale.
+func (s *RetrospectiveService) SetIcebreakerMood(ctx context.Context, retroID, userID uuid.UUID, mood string) (*models.IcebreakerMood, error) {
+	retro, err := s.requireMoodPhase(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	scale, err := s.GetMoodScale(ctx, retro)
+	if err != nil {
+		return nil, err
+	}
+	if !models.MoodScaleContains(scale, mood) {
+		return nil, ErrInvalidMood
+	}
+
 	return s.icebreakerRepo.SetMood(ctx, retroID, userID, mood)
 }
 
+// GetMoodScale returns the icebreaker mood scale of the retro's template
+func (s *RetrospectiveService) GetMoodScale(ctx context.Context, retro *models.Retrospective) ([]models.MoodOption, error) {
+	template, err := s.templateRepo.FindByID(ctx, retro.TemplateID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return models.DefaultMoodScale, nil
+		}
+		return nil, err
+	}
+	return template.Moods(), nil
+}
+
+// ClearIcebreakerMood removes a user's mood in the icebreaker phase. Clearing a mood that
+// was never set is a no-op.
+func (s *RetrospectiveService) ClearIcebreakerMood(ctx context.Context, retroID, userID uuid.UUID) error {
+	if _, err := s.requireMoodPhase(ctx, retroID); err != nil {
+		return err
+	}
+	if err := s.icebreakerRepo.DeleteMood(ctx, retroID, userID); err != nil && !errors.Is(err, postgres.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// requireMoodPhase allows moods while waiting for the retro to start and during the icebreaker
+func (s *RetrospectiveService) requireMoodPhase(ctx context.Context, retroID uuid.UUID) (*models.Retrospective, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	if retro.CurrentPhase != models.PhaseIcebreaker && retro.CurrentPhase != models.PhaseWaiting {
+		return nil, ErrInvalidPhase
+	}
+	return retro, nil
+}
+
 // GetIcebreakerMoods gets all moods for a retrospective
 func (s *RetrospectiveService) GetIcebreakerMoods(ctx context.Context, retroID uuid.UUID) ([]*models.IcebreakerMood, error) {
 	return s.icebreakerRepo.ListMoods(ctx, retroID)
@@ -792,12 +2115,26 @@ func (s *RetrospectiveService) CountIcebreakerMoods(ctx context.Context, retroID
 	return s.icebreakerRepo.CountMoods(ctx, retroID)
 }
 
-// SetRotiVote sets a user's ROTI vote
-func (s *RetrospectiveService) SetRotiVote(ctx context.Context, retroID, userID uuid.UUID, rating int) (*models.RotiVote, error) {
+// SetRotiVote sets or changes a user's ROTI vote and optional comment; votes are final once the results are revealed
+func (s *RetrospectiveService) SetRotiVote(ctx context.Context, retroID, userID uuid.UUID, rating int, comment string) (*models.RotiVote, error) {
 	if rating < 1 || rating > 5 {
-		return nil, errors.New("rating must be between 1 and 5")
+		return nil, ErrInvalidRotiRating
+	}
+	var commentPtr *string
+	if comment = strings.TrimSpace(comment); comment != "" {
+		if len(comment) > maxRotiCommentLength {
+			return nil, ErrRotiCommentTooLong
+		}
+		commentPtr = &comment
+	}
+	vote, err := s.rotiRepo.SetVote(ctx, retroID, userID, rating, commentPtr)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrRotiRevealed
+		}
+		return nil, err
 	}
-	return s.rotiRepo.SetVote(ctx, retroID, userID, rating)
+	return vote, nil
 }
 
 // GetRotiResults gets the aggregated ROTI results
@@ -807,20 +2144,61 @@ func (s *RetrospectiveService) GetRotiResults(ctx context.Context, retroID uuid.
 		return nil, err
 	}
 
-	// Include individual votes only if revealed
-	if results.Revealed {
-		votes, err := s.rotiRepo.ListVotes(ctx, retroID)
-		if err != nil {
-			return nil, err
-		}
-		results.Votes = votes
+	// Until the reveal only the number of votes is shown
+	if !results.Revealed {
+		results.Average = 0
+		results.Distribution = map[int]int{}
+		return results, nil
+	}
+
+	votes, err := s.rotiRepo.ListVotes(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	retro, err := s.retroRepo.FindByID(ctx, retroID)
+	if err != nil {
+		return nil, err
 	}
+	results.Votes = votes
+	results.Comments = rotiComments(votes, retro.AnonymousVoting)
 
 	return results, nil
 }
 
-// RevealRotiResults reveals the ROTI results
-func (s *RetrospectiveService) RevealRotiResults(ctx context.Context, retroID uuid.UUID) (*models.RotiResults, error) {
+// rotiComments collects the comments of the votes. For anonymous retros the comments are
+// detached from the votes and sorted so they cannot be matched back to a voter or a rating.
+func rotiComments(votes []*models.RotiVote, anonymous bool) []*models.RotiComment {
+	comments := make([]*models.RotiComment, 0)
+	for _, v := range votes {
+		if v.Comment == nil {
+			continue
+		}
+		c := &models.RotiComment{Comment: *v.Comment}
+		if anonymous {
+			v.Comment = nil
+		} else {
+			userID := v.UserID
+			c.UserID = &userID
+			c.User = v.User
+		}
+		comments = append(comments, c)
+	}
+	if anonymous {
+		sort.Slice(comments, func(i, j int) bool { return comments[i].Comment < comments[j].Comment })
+	}
+	return comments
+}
+
+// RevealRotiResults reveals the ROTI results; only the facilitator may reveal them
+func (s *RetrospectiveService) RevealRotiResults(ctx context.Context, retroID, userID uuid.UUID) (*models.RotiResults, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+	if retro.FacilitatorID != userID {
+		return nil, ErrNotFacilitator
+	}
+
 	if err := s.rotiRepo.RevealResults(ctx, retroID); err != nil {
 		return nil, err
 	}
@@ -831,3 +2209,39 @@ func (s *RetrospectiveService) RevealRotiResults(ctx context.Context, retroID uu
 func (s *RetrospectiveService) CountRotiVotes(ctx context.Context, retroID uuid.UUID) (int, error) {
 	return s.rotiRepo.CountVotes(ctx, retroID)
 }
+
+// RecordPresence marks a team member as having attended a retrospective they joined while it was running.
+// Joins during the waiting phase are covered by the attendance snapshot taken when the retro starts.
+func (s *RetrospectiveService) RecordPresence(ctx context.Context, retro *models.Retrospective, userID uuid.UUID) error {
+	if retro.Status != models.StatusActive || retro.CurrentPhase == models.PhaseWaiting {
+		return nil
+	}
+
+	isMember, err := s.memberRepo.IsMember(ctx, retro.TeamID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return nil
+	}
+
+	return s.attendeeRepo.MarkPresent(ctx, retro.ID, userID)
+}
+
+// ListAttendees lists the attendance records of a retrospective (team members only)
+func (s *RetrospectiveService) ListAttendees(ctx context.Context, retroID, userID uuid.UUID) ([]*models.RetroAttendee, error) {
+	retro, err := s.GetByID(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := s.memberRepo.IsMember(ctx, retro.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotTeamMember
+	}
+
+	return s.attendeeRepo.GetByRetro(ctx, retroID)
+}
@@ -0,0 +1,336 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestMoveItemMovesGroupedChildren(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	svc := env.Services.Retro
+
+	create := func(column, content string) uuid.UUID {
+		t.Helper()
+		item, err := svc.CreateItem(ctx, retro.ID, alice.ID, services.CreateItemInput{ColumnID: column, Content: content})
+		if err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+		return item.ID
+	}
+
+	first := create("start", "first")
+	parent := create("start", "parent")
+	child := create("start", "child")
+	grandchild := create("start", "grandchild")
+	other := create("stop", "other")
+
+	if _, err := svc.GroupItems(ctx, retro.ID, parent, []uuid.UUID{child}); err != nil {
+		t.Fatalf("group child: %v", err)
+	}
+	if _, err := svc.GroupItems(ctx, retro.ID, child, []uuid.UUID{grandchild}); err != nil {
+		t.Fatalf("group grandchild: %v", err)
+	}
+
+	if _, err := svc.MoveItem(ctx, retro.ID, parent, "stop", 0); err != nil {
+		t.Fatalf("move item: %v", err)
+	}
+
+	items, err := svc.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+
+	want := map[uuid.UUID]struct {
+		column   string
+		position int
+	}{
+		first:      {"start", 0},
+		parent:     {"stop", 0},
+		child:      {"stop", 1},
+		grandchild: {"stop", 2},
+		other:      {"stop", 3},
+	}
+	for _, item := range items {
+		w := want[item.ID]
+		if item.ColumnID != w.column || item.Position != w.position {
+			t.Errorf("item %q: got %s/%d, want %s/%d", item.Content, item.ColumnID, item.Position, w.column, w.position)
+		}
+	}
+}
+
+func TestGetDetailIncludesRelations(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	template, err := env.Repos.Templates.FindByID(ctx, retro.TemplateID)
+	if err != nil {
+		t.Fatalf("find template: %v", err)
+	}
+
+	detail, err := env.Services.Retro.GetDetail(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get detail: %v", err)
+	}
+	if detail.Team == nil || detail.Team.ID != team.ID {
+		t.Errorf("team = %+v, want %s", detail.Team, team.ID)
+	}
+	if detail.Facilitator == nil || detail.Facilitator.ID != alice.ID {
+		t.Errorf("facilitator = %+v, want %s", detail.Facilitator, alice.ID)
+	}
+	if detail.Template == nil || detail.Template.ID != template.ID {
+		t.Fatalf("template = %+v, want %s", detail.Template, template.ID)
+	}
+	if len(detail.Template.Columns) != len(template.Columns) {
+		t.Errorf("template has %d columns, want %d", len(detail.Template.Columns), len(template.Columns))
+	}
+	if len(detail.Template.PhaseTimes) != len(template.PhaseTimes) {
+		t.Errorf("template has %d phase timers, want %d", len(detail.Template.PhaseTimes), len(template.PhaseTimes))
+	}
+}
+
+func TestUpdateRejectsStaleRetro(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	first, err := svc.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	second, err := svc.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	first.MaxVotesPerUser = 7
+	if err := svc.Update(ctx, first); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// The same copy carries the new version, so it can be saved again
+	first.MaxVotesPerUser = 8
+	if err := svc.Update(ctx, first); err != nil {
+		t.Fatalf("second update of a fresh copy: %v", err)
+	}
+
+	second.Name = "Stale edit"
+	if err := svc.Update(ctx, second); !errors.Is(err, services.ErrConcurrentModification) {
+		t.Fatalf("stale update = %v, want ErrConcurrentModification", err)
+	}
+
+	saved, err := svc.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if saved.MaxVotesPerUser != 8 || saved.Name == "Stale edit" {
+		t.Errorf("saved retro = %d votes, name %q; the stale update should be ignored", saved.MaxVotesPerUser, saved.Name)
+	}
+}
+
+func TestUpdateItemRecordsHistory(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	item, err := svc.CreateItem(ctx, retro.ID, bob.ID, services.CreateItemInput{ColumnID: "start", Content: "Pair more"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	if _, err := svc.UpdateItem(ctx, retro.ID, item.ID, bob.ID, "Pair more often"); err != nil {
+		t.Fatalf("update item: %v", err)
+	}
+
+	var history []*models.ItemEdit
+	testenv.Eventually(t, 2*time.Second, func() bool {
+		history, err = svc.GetItemHistory(ctx, retro.ID, item.ID, bob.ID)
+		return err == nil && len(history) == 1
+	}, "history has %d edits (%v), want 1", len(history), err)
+
+	edit := history[0]
+	if edit.OldContent != "Pair more" || edit.NewContent != "Pair more often" || edit.EditorID == nil || *edit.EditorID != bob.ID {
+		t.Errorf("unexpected edit: %+v", edit)
+	}
+
+	if _, err := svc.GetItemHistory(ctx, uuid.New(), item.ID, bob.ID); !errors.Is(err, services.ErrRetroNotFound) {
+		t.Errorf("history in another retro = %v, want ErrRetroNotFound", err)
+	}
+}
+
+func TestItemHistoryOfAnonymousRetroIsFacilitatorOnly(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{AnonymousItems: true})
+
+	item, err := svc.CreateItem(ctx, retro.ID, bob.ID, services.CreateItemInput{ColumnID: "start", Content: "Pair more"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	if _, err := svc.GetItemHistory(ctx, retro.ID, item.ID, bob.ID); !errors.Is(err, services.ErrNotFacilitator) {
+		t.Errorf("participant history = %v, want ErrNotFacilitator", err)
+	}
+	if _, err := svc.GetItemHistory(ctx, retro.ID, item.ID, alice.ID); err != nil {
+		t.Errorf("facilitator history: %v", err)
+	}
+}
+
+func TestCreateChecksTeamAndTemplate(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice)
+	other := env.CreateTeam(t, bob)
+	builtIn, err := env.Repos.Templates.ListBuiltIn(ctx)
+	if err != nil || len(builtIn) == 0 {
+		t.Fatalf("no built-in template: %v", err)
+	}
+
+	_, err = svc.Create(ctx, bob.ID, services.CreateRetroInput{Name: "Intrusion", TeamID: team.ID, TemplateID: builtIn[0].ID})
+	if !errors.Is(err, services.ErrNotTeamMember) {
+		t.Fatalf("create by a non-member got %v, want ErrNotTeamMember", err)
+	}
+
+	foreign, err := svc.CreateTemplate(ctx, &models.Template{
+		Name:      "Bob's",
+		Columns:   []models.TemplateColumn{{ID: "a", Name: "A"}},
+		TeamID:    &other.ID,
+		CreatedBy: &bob.ID,
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	_, err = svc.Create(ctx, alice.ID, services.CreateRetroInput{Name: "Borrowed", TeamID: team.ID, TemplateID: foreign.ID})
+	if !errors.Is(err, services.ErrTemplateNotAccessible) {
+		t.Fatalf("create with another team's template got %v, want ErrTemplateNotAccessible", err)
+	}
+
+	own, err := svc.CreateTemplate(ctx, &models.Template{
+		Name:      "Alice's",
+		Columns:   []models.TemplateColumn{{ID: "a", Name: "A"}},
+		TeamID:    &team.ID,
+		CreatedBy: &alice.ID,
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	for name, templateID := range map[string]uuid.UUID{"built-in": builtIn[0].ID, "same-team": own.ID} {
+		if _, err := svc.Create(ctx, alice.ID, services.CreateRetroInput{Name: "Allowed", TeamID: team.ID, TemplateID: templateID}); err != nil {
+			t.Fatalf("create with a %s template: %v", name, err)
+		}
+	}
+}
+
+func TestToggleItemDiscussed(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	other := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	item, err := svc.CreateItem(ctx, retro.ID, bob.ID, services.CreateItemInput{ColumnID: "start", Content: "Pairing"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	for _, want := range []bool{true, false} {
+		discussed, err := svc.ToggleItemDiscussed(ctx, retro.ID, item.ID, alice.ID)
+		if err != nil {
+			t.Fatalf("toggle: %v", err)
+		}
+		items, err := svc.ListItems(ctx, retro.ID)
+		if err != nil {
+			t.Fatalf("list items: %v", err)
+		}
+		if discussed != want || items[0].Discussed != want {
+			t.Fatalf("got %v and listed %v, want %v", discussed, items[0].Discussed, want)
+		}
+	}
+
+	if _, err := svc.ToggleItemDiscussed(ctx, retro.ID, item.ID, bob.ID); !errors.Is(err, services.ErrNotFacilitator) {
+		t.Fatalf("toggle by a participant got %v, want ErrNotFacilitator", err)
+	}
+	if _, err := svc.ToggleItemDiscussed(ctx, other.ID, item.ID, alice.ID); !errors.Is(err, services.ErrItemNotFound) {
+		t.Fatalf("toggle through another retro got %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestFocusItem(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	other := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	item, err := svc.CreateItem(ctx, retro.ID, bob.ID, services.CreateItemInput{ColumnID: "start", Content: "Pairing"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	if err := svc.FocusItem(ctx, retro.ID, item.ID, bob.ID); !errors.Is(err, services.ErrNotFacilitator) {
+		t.Fatalf("focus by a participant got %v, want ErrNotFacilitator", err)
+	}
+	if err := svc.FocusItem(ctx, other.ID, item.ID, alice.ID); !errors.Is(err, services.ErrItemNotFound) {
+		t.Fatalf("focus through another retro got %v, want ErrItemNotFound", err)
+	}
+
+	if err := svc.FocusItem(ctx, retro.ID, item.ID, alice.ID); err != nil {
+		t.Fatalf("focus: %v", err)
+	}
+	got, err := svc.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get retro: %v", err)
+	}
+	if got.FocusedItemID == nil || *got.FocusedItemID != item.ID {
+		t.Fatalf("got focused item %v, want %s", got.FocusedItemID, item.ID)
+	}
+
+	// Changing the phase clears the focus
+	if err := svc.SetPhase(ctx, retro.ID, models.PhaseRoti); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+	if got, err = svc.GetByID(ctx, retro.ID); err != nil {
+		t.Fatalf("get retro: %v", err)
+	}
+	if got.FocusedItemID != nil {
+		t.Fatalf("got focused item %s after the phase changed, want none", got.FocusedItemID)
+	}
+}
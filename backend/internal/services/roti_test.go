@@ -0,0 +1,148 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestRotiRevealIsFacilitatorOnly(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseRoti); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+
+	for user, rating := range map[*models.User]int{alice: 4, bob: 2} {
+		if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, user.ID, rating, ""); err != nil {
+			t.Fatalf("roti vote: %v", err)
+		}
+	}
+
+	if _, err := env.Services.Retro.RevealRotiResults(ctx, retro.ID, bob.ID); !errors.Is(err, services.ErrNotFacilitator) {
+		t.Fatalf("participant reveal = %v, want ErrNotFacilitator", err)
+	}
+
+	hidden, err := env.Services.Retro.GetRotiResults(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get results: %v", err)
+	}
+	if hidden.Revealed || hidden.Average != 0 || len(hidden.Distribution) != 0 || len(hidden.Votes) != 0 {
+		t.Errorf("results leaked before reveal: %+v", hidden)
+	}
+	if hidden.TotalVotes != 2 {
+		t.Errorf("totalVotes = %d, want 2", hidden.TotalVotes)
+	}
+
+	revealed, err := env.Services.Retro.RevealRotiResults(ctx, retro.ID, alice.ID)
+	if err != nil {
+		t.Fatalf("facilitator reveal: %v", err)
+	}
+	if !revealed.Revealed || revealed.Average != 3 || len(revealed.Votes) != 2 {
+		t.Errorf("revealed results = %+v, want average 3 with 2 votes", revealed)
+	}
+}
+
+func TestRotiVoteCanChangeUntilReveal(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseRoti); err != nil {
+		t.Fatalf("set phase: %v", err)
+	}
+
+	if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, alice.ID, 2, ""); err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	vote, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, alice.ID, 5, "")
+	if err != nil {
+		t.Fatalf("changed vote: %v", err)
+	}
+	if vote.Rating != 5 {
+		t.Errorf("rating = %d, want 5", vote.Rating)
+	}
+	count, err := env.Services.Retro.CountRotiVotes(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("count votes: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("vote count = %d, want 1 after a change", count)
+	}
+
+	if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, alice.ID, 0, ""); !errors.Is(err, services.ErrInvalidRotiRating) {
+		t.Errorf("rating 0 = %v, want ErrInvalidRotiRating", err)
+	}
+
+	if _, err := env.Services.Retro.RevealRotiResults(ctx, retro.ID, alice.ID); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, alice.ID, 1, ""); !errors.Is(err, services.ErrRotiRevealed) {
+		t.Fatalf("vote after reveal = %v, want ErrRotiRevealed", err)
+	}
+
+	results, err := env.Services.Retro.GetRotiResults(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get results: %v", err)
+	}
+	if results.Average != 5 {
+		t.Errorf("average = %v, want 5", results.Average)
+	}
+}
+
+func TestRotiCommentsFollowAnonymousVoting(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+
+	for _, anonymous := range []bool{false, true} {
+		retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{AnonymousVoting: anonymous})
+		if err := env.Services.Retro.SetPhase(ctx, retro.ID, models.PhaseRoti); err != nil {
+			t.Fatalf("set phase: %v", err)
+		}
+		if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, alice.ID, 4, "  Good pace  "); err != nil {
+			t.Fatalf("roti vote: %v", err)
+		}
+		if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, bob.ID, 2, ""); err != nil {
+			t.Fatalf("roti vote: %v", err)
+		}
+		if _, err := env.Services.Retro.SetRotiVote(ctx, retro.ID, bob.ID, 2, strings.Repeat("x", 1001)); !errors.Is(err, services.ErrRotiCommentTooLong) {
+			t.Fatalf("long comment = %v, want ErrRotiCommentTooLong", err)
+		}
+
+		results, err := env.Services.Retro.RevealRotiResults(ctx, retro.ID, alice.ID)
+		if err != nil {
+			t.Fatalf("reveal: %v", err)
+		}
+		if len(results.Comments) != 1 || results.Comments[0].Comment != "Good pace" {
+			t.Fatalf("anonymous=%v: comments = %+v, want one trimmed comment", anonymous, results.Comments)
+		}
+		comment := results.Comments[0]
+		if anonymous && (comment.UserID != nil || comment.User != nil) {
+			t.Errorf("anonymous comment is attributed: %+v", comment)
+		}
+		if !anonymous && (comment.UserID == nil || *comment.UserID != alice.ID) {
+			t.Errorf("comment userId = %v, want %s", comment.UserID, alice.ID)
+		}
+		for _, v := range results.Votes {
+			if anonymous && v.Comment != nil {
+				t.Errorf("anonymous vote of %s still carries its comment", v.UserID)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found or expired")
+)
+
+// snapshotTTL is how long a client has to fetch a retro_state snapshot
+const snapshotTTL = 30 * time.Second
+
+// SnapshotService hands out one-time REST snapshots of states too large for a WebSocket frame
+type SnapshotService struct {
+	snapshotRepo *postgres.SnapshotRepository
+}
+
+// NewSnapshotService creates a new snapshot service
+func NewSnapshotService(snapshotRepo *postgres.SnapshotRepository) *SnapshotService {
+	return &SnapshotService{snapshotRepo: snapshotRepo}
+}
+
+// Create stores the payload for the user and returns the token to fetch it with and its expiry.
+// Only a hash of the token is stored.
+func (s *SnapshotService) Create(ctx context.Context, retroID, userID uuid.UUID, payload []byte) (string, time.Time, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	expiresAt := time.Now().Add(snapshotTTL)
+
+	if err := s.snapshotRepo.Create(ctx, hashSnapshotToken(token), retroID, userID, payload, expiresAt); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Consume returns the payload once; the token must have been issued to this user for this retro
+func (s *SnapshotService) Consume(ctx context.Context, token string, retroID, userID uuid.UUID) ([]byte, error) {
+	if token == "" {
+		return nil, ErrSnapshotNotFound
+	}
+	payload, err := s.snapshotRepo.Consume(ctx, hashSnapshotToken(token), retroID, userID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+func hashSnapshotToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -123,3 +123,17 @@ func (s *StatsService) GetMyStats(ctx context.Context, userID, teamID uuid.UUID,
 		MoodStats: moodStats,
 	}, nil
 }
+
+// GetTeamPhaseAnalytics retrieves planned vs actual phase duration statistics for a team
+func (s *StatsService) GetTeamPhaseAnalytics(ctx context.Context, userID, teamID uuid.UUID, filter *models.StatsFilter) (*models.TeamPhaseAnalytics, error) {
+	// Check if user is a member of the team
+	isMember, err := s.memberRepo.IsMember(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotTeamMember
+	}
+
+	return s.statsRepo.GetTeamPhaseAnalytics(ctx, teamID, filter)
+}
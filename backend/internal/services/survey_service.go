@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/repository/postgres"
+)
+
+const (
+	maxSurveyQuestions    = 10
+	maxSurveyPromptLength = 500
+	maxSurveyTextLength   = 2000
+)
+
+var (
+	ErrSurveyInvalidQuestions = errors.New("survey must have between 1 and 10 questions with a prompt and a valid answer type")
+	ErrSurveyQuestionNotFound = errors.New("survey question not found")
+	ErrSurveyInvalidAnswer    = errors.New("answer does not match the question type")
+)
+
+// SurveyQuestionInput represents a survey question to create
+type SurveyQuestionInput struct {
+	Prompt     string                  `json:"prompt"`
+	AnswerType models.SurveyAnswerType `json:"answerType"`
+}
+
+// SurveyService handles end-of-session survey operations
+type SurveyService struct {
+	surveyRepo *postgres.SurveyRepository
+	retroRepo  *postgres.RetrospectiveRepository
+}
+
+// NewSurveyService creates a new survey service
+func NewSurveyService(surveyRepo *postgres.SurveyRepository, retroRepo *postgres.RetrospectiveRepository) *SurveyService {
+	return &SurveyService{
+		surveyRepo: surveyRepo,
+		retroRepo:  retroRepo,
+	}
+}
+
+// StartSurvey validates and stores the survey questions of a retrospective, replacing previous ones
+func (s *SurveyService) StartSurvey(ctx context.Context, retroID uuid.UUID, inputs []SurveyQuestionInput) ([]*models.SurveyQuestion, error) {
+	if len(inputs) == 0 || len(inputs) > maxSurveyQuestions {
+		return nil, ErrSurveyInvalidQuestions
+	}
+
+	questions := make([]*models.SurveyQuestion, 0, len(inputs))
+	for _, input := range inputs {
+		prompt := strings.TrimSpace(input.Prompt)
+		if prompt == "" || len(prompt) > maxSurveyPromptLength {
+			return nil, ErrSurveyInvalidQuestions
+		}
+		if input.AnswerType != models.SurveyAnswerScale && input.AnswerType != models.SurveyAnswerText {
+			return nil, ErrSurveyInvalidQuestions
+		}
+		questions = append(questions, &models.SurveyQuestion{
+			Prompt:     prompt,
+			AnswerType: input.AnswerType,
+		})
+	}
+
+	return s.surveyRepo.ReplaceQuestions(ctx, retroID, questions)
+}
+
+// SubmitAnswer sets a user's answer to a survey question, validating it against the question type
+func (s *SurveyService) SubmitAnswer(ctx context.Context, retroID, userID, questionID uuid.UUID, scaleValue *int, textValue *string) (*models.SurveyAnswer, error) {
+	question, err := s.surveyRepo.FindQuestion(ctx, questionID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrSurveyQuestionNotFound
+		}
+		return nil, err
+	}
+	if question.RetroID != retroID {
+		return nil, ErrSurveyQuestionNotFound
+	}
+
+	switch question.AnswerType {
+	case models.SurveyAnswerScale:
+		if scaleValue == nil || *scaleValue < 1 || *scaleValue > 5 {
+			return nil, ErrSurveyInvalidAnswer
+		}
+		textValue = nil
+	case models.SurveyAnswerText:
+		if textValue == nil {
+			return nil, ErrSurveyInvalidAnswer
+		}
+		text := strings.TrimSpace(*textValue)
+		if text == "" || len(text) > maxSurveyTextLength {
+			return nil, ErrSurveyInvalidAnswer
+		}
+		textValue = &text
+		scaleValue = nil
+	}
+
+	return s.surveyRepo.SetAnswer(ctx, questionID, userID, scaleValue, textValue)
+}
+
+// GetResults gets the aggregated survey results of a retrospective.
+// Until they are revealed only the answer counts are included, like ROTI; the answers come
+// without authors when voting is anonymous.
+func (s *SurveyService) GetResults(ctx context.Context, retroID uuid.UUID) (*models.SurveyResults, error) {
+	retro, err := s.retroRepo.FindByID(ctx, retroID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, ErrRetroNotFound
+		}
+		return nil, err
+	}
+
+	revealed, err := s.surveyRepo.IsRevealed(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.surveyRepo.ListQuestions(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := s.surveyRepo.ListAnswers(ctx, retroID)
+	if err != nil {
+		return nil, err
+	}
+
+	byQuestion := make(map[uuid.UUID][]*models.SurveyAnswer)
+	for _, a := range answers {
+		if retro.AnonymousVoting {
+			a.UserID = nil
+			a.User = nil
+		}
+		byQuestion[a.QuestionID] = append(byQuestion[a.QuestionID], a)
+	}
+
+	results := &models.SurveyResults{
+		Questions: make([]*models.SurveyQuestionResult, 0, len(questions)),
+		Revealed:  revealed,
+	}
+	for _, q := range questions {
+		qAnswers := byQuestion[q.ID]
+		result := &models.SurveyQuestionResult{
+			Question:    q,
+			AnswerCount: len(qAnswers),
+		}
+
+		// Until the reveal only the number of answers is shown
+		if !revealed {
+			results.Questions = append(results.Questions, result)
+			continue
+		}
+
+		if q.AnswerType == models.SurveyAnswerScale {
+			result.Distribution = make(map[int]int)
+			total := 0
+			for _, a := range qAnswers {
+				if a.ScaleValue != nil {
+					result.Distribution[*a.ScaleValue]++
+					total += *a.ScaleValue
+				}
+			}
+			if len(qAnswers) > 0 {
+				avg := float64(total) / float64(len(qAnswers))
+				result.Average = &avg
+			}
+		}
+
+		result.Answers = qAnswers
+		results.Questions = append(results.Questions, result)
+	}
+
+	return results, nil
+}
+
+// RevealResults reveals the survey results
+func (s *SurveyService) RevealResults(ctx context.Context, retroID uuid.UUID) (*models.SurveyResults, error) {
+	if err := s.surveyRepo.RevealResults(ctx, retroID); err != nil {
+		return nil, err
+	}
+	return s.GetResults(ctx, retroID)
+}
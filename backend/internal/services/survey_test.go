@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestSurveyResultsHiddenUntilRevealed(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Survey
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	questions, err := svc.StartSurvey(ctx, retro.ID, []services.SurveyQuestionInput{
+		{Prompt: "How clear were our goals?", AnswerType: models.SurveyAnswerScale},
+		{Prompt: "Anything else?", AnswerType: models.SurveyAnswerText},
+	})
+	if err != nil {
+		t.Fatalf("start survey: %v", err)
+	}
+	for user, value := range map[*models.User]int{alice: 2, bob: 4} {
+		if _, err := svc.SubmitAnswer(ctx, retro.ID, user.ID, questions[0].ID, &value, nil); err != nil {
+			t.Fatalf("answer scale question: %v", err)
+		}
+	}
+	text := "More focus time"
+	if _, err := svc.SubmitAnswer(ctx, retro.ID, alice.ID, questions[1].ID, nil, &text); err != nil {
+		t.Fatalf("answer text question: %v", err)
+	}
+
+	// Before the reveal only the answer counts are returned
+	results, err := svc.GetResults(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get results: %v", err)
+	}
+	if results.Revealed || len(results.Questions) != 2 {
+		t.Fatalf("results = %+v, want both questions unrevealed", results)
+	}
+	for i, want := range []int{2, 1} {
+		q := results.Questions[i]
+		if q.AnswerCount != want || q.Average != nil || q.Distribution != nil || q.Answers != nil {
+			t.Errorf("question %d before the reveal = %+v, want only %d answers counted", i, q, want)
+		}
+	}
+
+	results, err = svc.RevealResults(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("reveal results: %v", err)
+	}
+	scale := results.Questions[0]
+	if scale.Average == nil || *scale.Average != 3 || scale.Distribution[2] != 1 || scale.Distribution[4] != 1 || len(scale.Answers) != 2 {
+		t.Errorf("scale question after the reveal = %+v, want the average, distribution and answers", scale)
+	}
+	if len(results.Questions[1].Answers) != 1 {
+		t.Errorf("text question after the reveal = %+v, want its answer", results.Questions[1])
+	}
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"errors"
+	"net/mail"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -15,6 +17,9 @@ var (
 	ErrNotTeamMember    = errors.New("not a team member")
 	ErrNotAuthorized    = errors.New("not authorized")
 	ErrCannotLeaveTeam  = errors.New("cannot leave team as last admin")
+	ErrInviteNotFound   = errors.New("invite not found")
+	ErrInvalidEmail     = errors.New("invalid email")
+	ErrUserExists       = errors.New("a user with this email already exists")
 )
 
 // TeamService handles team operations
@@ -22,14 +27,16 @@ type TeamService struct {
 	teamRepo       *postgres.TeamRepository
 	memberRepo     *postgres.TeamMemberRepository
 	userRepo       UserRepository
+	inviteRepo     *postgres.TeamInviteRepository
 }
 
 // NewTeamService creates a new team service
-func NewTeamService(teamRepo *postgres.TeamRepository, memberRepo *postgres.TeamMemberRepository, userRepo UserRepository) *TeamService {
+func NewTeamService(teamRepo *postgres.TeamRepository, memberRepo *postgres.TeamMemberRepository, userRepo UserRepository, inviteRepo *postgres.TeamInviteRepository) *TeamService {
 	return &TeamService{
 		teamRepo:   teamRepo,
 		memberRepo: memberRepo,
 		userRepo:   userRepo,
+		inviteRepo: inviteRepo,
 	}
 }
 
@@ -101,8 +108,9 @@ func (s *TeamService) ListByUser(ctx context.Context, userID uuid.UUID) ([]*mode
 
 // UpdateTeamInput represents input for updating a team
 type UpdateTeamInput struct {
-	Name        *string
-	Description *string
+	Name           *string
+	Description    *string
+	MaxOpenActions *int // 0 removes the cap
 }
 
 // Update updates a team
@@ -126,6 +134,13 @@ func (s *TeamService) Update(ctx context.Context, userID, teamID uuid.UUID, inpu
 	if input.Description != nil {
 		team.Description = input.Description
 	}
+	if input.MaxOpenActions != nil {
+		if *input.MaxOpenActions > 0 {
+			team.MaxOpenActions = input.MaxOpenActions
+		} else {
+			team.MaxOpenActions = nil
+		}
+	}
 
 	if err := s.teamRepo.Update(ctx, team); err != nil {
 		return nil, err
@@ -182,6 +197,78 @@ func (s *TeamService) AddMember(ctx context.Context, userID, teamID uuid.UUID, m
 	return err
 }
 
+// CreateInvite invites an email that has no account yet; the person joins the team with the
+// given role on their first login. Inviting the same email again updates the role.
+func (s *TeamService) CreateInvite(ctx context.Context, userID, teamID uuid.UUID, email string, role models.Role) (*models.TeamInvite, error) {
+	if err := s.requireRole(ctx, teamID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	// Existing users are added directly with AddMember
+	if _, err := s.userRepo.FindByEmail(ctx, email); err == nil {
+		return nil, ErrUserExists
+	} else if !errors.Is(err, postgres.ErrNotFound) {
+		return nil, err
+	}
+
+	invite := &models.TeamInvite{
+		ID:        uuid.New(),
+		TeamID:    teamID,
+		Email:     email,
+		Role:      role,
+		InvitedBy: &userID,
+	}
+	return s.inviteRepo.Create(ctx, invite)
+}
+
+// ListInvites lists the pending invitations of a team
+func (s *TeamService) ListInvites(ctx context.Context, userID, teamID uuid.UUID) ([]*models.TeamInvite, error) {
+	if err := s.requireRole(ctx, teamID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.inviteRepo.ListByTeam(ctx, teamID)
+}
+
+// RevokeInvite deletes a pending invitation
+func (s *TeamService) RevokeInvite(ctx context.Context, userID, teamID, inviteID uuid.UUID) error {
+	if err := s.requireRole(ctx, teamID, userID, models.RoleAdmin); err != nil {
+		return err
+	}
+	if err := s.inviteRepo.Delete(ctx, teamID, inviteID); err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrInviteNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// SearchUsers finds users to add to the team teamID by email or display name. Instance admins
+// search every user; team admins may only look up a user by their exact email, to prevent user
+// enumeration. Guests are never returned.
+func (s *TeamService) SearchUsers(ctx context.Context, userID, teamID uuid.UUID, query string, limit int) ([]*models.User, error) {
+	caller, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if caller.IsAdmin {
+		return s.userRepo.Search(ctx, query, limit)
+	}
+
+	if teamID == uuid.Nil {
+		return nil, ErrNotAuthorized
+	}
+	if err := s.requireRole(ctx, teamID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.userRepo.SearchByEmail(ctx, query)
+}
+
 // RemoveMember removes a member from a team
 func (s *TeamService) RemoveMember(ctx context.Context, userID, teamID, memberUserID uuid.UUID) error {
 	// Check authorization (admin or self)
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestTeamInviteIsClaimedOnFirstLogin(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+
+	suffix := uuid.NewString()[:8]
+	email := "New.Hire+" + suffix + "@Example.com"
+	if _, err := env.Services.Team.CreateInvite(ctx, bob.ID, team.ID, email, models.RoleMember); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Fatalf("member invite = %v, want ErrNotAuthorized", err)
+	}
+	if _, err := env.Services.Team.CreateInvite(ctx, alice.ID, team.ID, bob.Email, models.RoleMember); !errors.Is(err, services.ErrUserExists) {
+		t.Fatalf("invite of existing user = %v, want ErrUserExists", err)
+	}
+
+	invite, err := env.Services.Team.CreateInvite(ctx, alice.ID, team.ID, email, models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("create invite: %v", err)
+	}
+	if invite.Email != "new.hire+"+suffix+"@example.com" {
+		t.Errorf("invite email = %q, want it lowercased", invite.Email)
+	}
+
+	// The identity provider reports the email with a different case
+	newcomer := env.CreateUser(t, "New Hire")
+	teamIDs, err := env.Repos.TeamInvites.ClaimForUser(ctx, newcomer.ID, "NEW.HIRE+"+suffix+"@EXAMPLE.COM")
+	if err != nil {
+		t.Fatalf("claim invites: %v", err)
+	}
+	if len(teamIDs) != 1 || teamIDs[0] != team.ID {
+		t.Fatalf("claimed teams = %v, want [%s]", teamIDs, team.ID)
+	}
+
+	role, err := env.Services.Team.GetUserRole(ctx, team.ID, newcomer.ID)
+	if err != nil || role != models.RoleAdmin {
+		t.Fatalf("role = %q, %v; want admin", role, err)
+	}
+
+	invites, err := env.Services.Team.ListInvites(ctx, alice.ID, team.ID)
+	if err != nil {
+		t.Fatalf("list invites: %v", err)
+	}
+	if len(invites) != 0 {
+		t.Errorf("claimed invite still pending: %+v", invites)
+	}
+}
+
+func TestSearchUsersLimitsTeamAdminsToExactEmail(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Team
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice)
+
+	if _, err := svc.SearchUsers(ctx, alice.ID, uuid.Nil, bob.Email, 10); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Fatalf("search without a team = %v, want ErrNotAuthorized", err)
+	}
+	if _, err := svc.SearchUsers(ctx, bob.ID, team.ID, alice.Email, 10); !errors.Is(err, services.ErrNotTeamMember) {
+		t.Fatalf("search by a non-member = %v, want ErrNotTeamMember", err)
+	}
+
+	// A fragment of the name or email finds nobody
+	users, err := svc.SearchUsers(ctx, alice.ID, team.ID, "Bob", 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("partial search returned %d users, want none", len(users))
+	}
+
+	users, err = svc.SearchUsers(ctx, alice.ID, team.ID, strings.ToUpper(bob.Email), 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != bob.ID {
+		t.Fatalf("got %v, want Bob", users)
+	}
+}
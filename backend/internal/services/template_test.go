@@ -0,0 +1,229 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestDuplicateTemplateCopiesColumnsAndPhaseTimers(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	source, err := env.Repos.Templates.FindBuiltInByName(ctx, "Mad/Sad/Glad")
+	if err != nil {
+		t.Fatalf("find built-in template: %v", err)
+	}
+
+	copied, err := svc.DuplicateTemplate(ctx, source.ID, team.ID, alice.ID, "")
+	if err != nil {
+		t.Fatalf("duplicate template: %v", err)
+	}
+	if copied.ID == source.ID || copied.IsBuiltIn || copied.TeamID == nil || *copied.TeamID != team.ID {
+		t.Fatalf("copy is not a team template: %+v", copied)
+	}
+	if copied.Name != "Mad/Sad/Glad (copy)" || copied.CreatedBy == nil || *copied.CreatedBy != alice.ID {
+		t.Errorf("name = %q, createdBy = %v", copied.Name, copied.CreatedBy)
+	}
+	if len(copied.Columns) != len(source.Columns) || copied.Columns[0].ID != source.Columns[0].ID {
+		t.Errorf("columns = %+v, want %+v", copied.Columns, source.Columns)
+	}
+	if len(copied.PhaseTimes) == 0 {
+		t.Fatal("phase timers were not copied")
+	}
+	for phase, duration := range source.PhaseTimes {
+		if copied.PhaseTimes[phase] != duration {
+			t.Errorf("%s timer = %d, want %d", phase, copied.PhaseTimes[phase], duration)
+		}
+	}
+
+	renamed, err := svc.DuplicateTemplate(ctx, copied.ID, team.ID, alice.ID, "  Our MSG  ")
+	if err != nil {
+		t.Fatalf("duplicate team template: %v", err)
+	}
+	if renamed.Name != "Our MSG" {
+		t.Errorf("name = %q, want Our MSG", renamed.Name)
+	}
+}
+
+func TestDuplicateTemplateRequiresAccess(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	aliceTeam := env.CreateTeam(t, alice)
+	bobTeam := env.CreateTeam(t, bob)
+
+	private, err := env.Repos.Templates.Create(ctx, &models.Template{
+		Name:    "Alice only",
+		Columns: []models.TemplateColumn{{ID: "a", Name: "A"}},
+		TeamID:  &aliceTeam.ID,
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	if _, err := svc.DuplicateTemplate(ctx, private.ID, bobTeam.ID, bob.ID, ""); !errors.Is(err, services.ErrTemplateNotFound) {
+		t.Errorf("duplicate another team's template = %v, want ErrTemplateNotFound", err)
+	}
+	if _, err := svc.DuplicateTemplate(ctx, private.ID, aliceTeam.ID, bob.ID, ""); !errors.Is(err, services.ErrNotTeamMember) {
+		t.Errorf("duplicate into a team the user is not in = %v, want ErrNotTeamMember", err)
+	}
+	if _, err := svc.DuplicateTemplate(ctx, uuid.New(), bobTeam.ID, bob.ID, ""); !errors.Is(err, services.ErrTemplateNotFound) {
+		t.Errorf("duplicate unknown template = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestCreateTemplatePersistsPhaseTimers(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	created, err := env.Services.Retro.CreateTemplate(ctx, &models.Template{
+		Name:       "Timed",
+		Columns:    []models.TemplateColumn{{ID: "a", Name: "A"}},
+		TeamID:     &team.ID,
+		CreatedBy:  &alice.ID,
+		PhaseTimes: map[models.RetroPhase]int{models.PhaseBrainstorm: 420, models.PhaseVote: 90},
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	found, err := env.Repos.Templates.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("find template: %v", err)
+	}
+	if len(found.PhaseTimes) != 2 || found.PhaseTimes[models.PhaseBrainstorm] != 420 || found.PhaseTimes[models.PhaseVote] != 90 {
+		t.Errorf("phase times = %v, want brainstorm 420 and vote 90", found.PhaseTimes)
+	}
+
+	found.PhaseTimes = map[models.RetroPhase]int{models.PhaseBrainstorm: 600, models.PhaseDiscuss: 1200}
+	if err := env.Repos.Templates.Update(ctx, found); err != nil {
+		t.Fatalf("update template: %v", err)
+	}
+	updated, err := env.Repos.Templates.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("find template: %v", err)
+	}
+	want := map[models.RetroPhase]int{models.PhaseBrainstorm: 600, models.PhaseDiscuss: 1200}
+	if len(updated.PhaseTimes) != len(want) {
+		t.Errorf("phase times after update = %v, want %v", updated.PhaseTimes, want)
+	}
+	for phase, duration := range want {
+		if updated.PhaseTimes[phase] != duration {
+			t.Errorf("%s timer after update = %d, want %d", phase, updated.PhaseTimes[phase], duration)
+		}
+	}
+}
+
+func TestUpdateAndDeleteTemplateGuards(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	admin := env.CreateUser(t, "Admin")
+	member := env.CreateUser(t, "Member")
+	team := env.CreateTeam(t, admin, member)
+	builtIn, err := env.Repos.Templates.FindBuiltInByName(ctx, "Mad/Sad/Glad")
+	if err != nil {
+		t.Fatalf("find built-in template: %v", err)
+	}
+	template, err := svc.DuplicateTemplate(ctx, builtIn.ID, team.ID, admin.ID, "Ours")
+	if err != nil {
+		t.Fatalf("duplicate template: %v", err)
+	}
+
+	input := services.UpdateTemplateInput{
+		Name:       "Renamed",
+		Columns:    []models.TemplateColumn{{ID: "keep", Name: "Keep"}},
+		PhaseTimes: map[models.RetroPhase]int{models.PhaseBrainstorm: 60},
+	}
+	if _, err := svc.UpdateTemplate(ctx, builtIn.ID, admin.ID, input); !errors.Is(err, services.ErrTemplateBuiltIn) {
+		t.Errorf("update built-in = %v, want ErrTemplateBuiltIn", err)
+	}
+	if _, err := svc.UpdateTemplate(ctx, template.ID, member.ID, input); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Errorf("update by a member = %v, want ErrNotAuthorized", err)
+	}
+	updated, err := svc.UpdateTemplate(ctx, template.ID, admin.ID, input)
+	if err != nil {
+		t.Fatalf("update by an admin: %v", err)
+	}
+	if updated.Name != "Renamed" || len(updated.Columns) != 1 || len(updated.PhaseTimes) != 1 || updated.PhaseTimes[models.PhaseBrainstorm] != 60 {
+		t.Errorf("updated template = %+v", updated)
+	}
+
+	if err := svc.DeleteTemplate(ctx, builtIn.ID, admin.ID); !errors.Is(err, services.ErrTemplateBuiltIn) {
+		t.Errorf("delete built-in = %v, want ErrTemplateBuiltIn", err)
+	}
+	env.CreateRetro(t, team, admin, services.CreateRetroInput{TemplateID: template.ID})
+	if err := svc.DeleteTemplate(ctx, template.ID, admin.ID); !errors.Is(err, services.ErrTemplateInUse) {
+		t.Errorf("delete a template in use = %v, want ErrTemplateInUse", err)
+	}
+
+	unused, err := svc.DuplicateTemplate(ctx, builtIn.ID, team.ID, admin.ID, "Unused")
+	if err != nil {
+		t.Fatalf("duplicate template: %v", err)
+	}
+	if err := svc.DeleteTemplate(ctx, unused.ID, member.ID); !errors.Is(err, services.ErrNotAuthorized) {
+		t.Errorf("delete by a member = %v, want ErrNotAuthorized", err)
+	}
+	if err := svc.DeleteTemplate(ctx, unused.ID, admin.ID); err != nil {
+		t.Fatalf("delete by an admin: %v", err)
+	}
+	if _, err := svc.GetTemplate(ctx, unused.ID); !errors.Is(err, services.ErrTemplateNotFound) {
+		t.Errorf("get deleted template = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestListTeamTemplatesExcludesBuiltIns(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice)
+	other := env.CreateTeam(t, bob)
+	for _, tc := range []struct {
+		name   string
+		teamID uuid.UUID
+	}{{"Ours", team.ID}, {"Theirs", other.ID}} {
+		if _, err := svc.CreateTemplate(ctx, &models.Template{
+			Name:    tc.name,
+			Columns: []models.TemplateColumn{{ID: "a", Name: "A"}},
+			TeamID:  &tc.teamID,
+		}); err != nil {
+			t.Fatalf("create template: %v", err)
+		}
+	}
+
+	owned, err := svc.ListTeamTemplates(ctx, team.ID)
+	if err != nil {
+		t.Fatalf("list team templates: %v", err)
+	}
+	if len(owned) != 1 || owned[0].Name != "Ours" {
+		t.Fatalf("got %d templates, want only the team's own", len(owned))
+	}
+
+	// The merged listing stays the default
+	merged, err := svc.ListTemplates(ctx, &team.ID)
+	if err != nil {
+		t.Fatalf("list templates: %v", err)
+	}
+	if len(merged) <= len(owned) || !merged[0].IsBuiltIn {
+		t.Fatalf("got %d templates, want the built-ins first then the team's", len(merged))
+	}
+}
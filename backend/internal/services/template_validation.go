@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+// TemplateValidationError lists the invalid fields of a template, keyed by JSON path
+type TemplateValidationError struct {
+	Fields map[string]string
+}
+
+func (e *TemplateValidationError) Error() string {
+	paths := make([]string, 0, len(e.Fields))
+	for path := range e.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return "invalid template: " + strings.Join(paths, ", ")
+}
+
+// DefaultColumnColor is the neutral color of columns created without one
+const DefaultColumnColor = "#6b7280"
+
+// columnPalette maps the color names a column may use to the hex value the board renders
+var columnPalette = map[string]string{
+	"green":  "#22c55e",
+	"red":    "#ef4444",
+	"amber":  "#f59e0b",
+	"blue":   "#3b82f6",
+	"indigo": "#6366f1",
+	"violet": "#8b5cf6",
+	"pink":   "#ec4899",
+	"gray":   DefaultColumnColor,
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// columnIcons is the icon set the board knows how to render
+var columnIcons = map[string]bool{
+	"alert-triangle": true, "anchor": true, "angry": true, "check": true, "flag": true,
+	"frown": true, "heart": true, "lightbulb": true, "list": true, "message-circle": true,
+	"play": true, "repeat": true, "smile": true, "star": true, "stop": true,
+	"thumbs-up": true, "thumbs-down": true, "wind": true, "x-circle": true, "zap": true,
+}
+
+// normalizeColumnColor returns the lowercase hex color of a column, the palette color for a
+// palette name and the default when empty; ok is false for anything else
+func normalizeColumnColor(color string) (string, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return DefaultColumnColor, true
+	}
+	if hex, ok := columnPalette[color]; ok {
+		return hex, true
+	}
+	return color, hexColor.MatchString(color)
+}
+
+// validateTemplate checks the fields that retros depend on: a name, at least one column
+// with a unique ID, a name and a unique non-negative order, and timers for known phases only.
+// Column colors and icons are normalized in place.
+func validateTemplate(name string, columns []models.TemplateColumn, phaseTimes map[models.RetroPhase]int) error {
+	fields := make(map[string]string)
+
+	if strings.TrimSpace(name) == "" {
+		fields["name"] = "is required"
+	}
+
+	if len(columns) == 0 {
+		fields["columns"] = "at least one column is required"
+	}
+	ids := make(map[string]bool, len(columns))
+	orders := make(map[int]bool, len(columns))
+	for i, column := range columns {
+		path := fmt.Sprintf("columns[%d]", i)
+		switch {
+		case strings.TrimSpace(column.ID) == "":
+			fields[path+".id"] = "is required"
+		case ids[column.ID]:
+			fields[path+".id"] = "duplicates another column"
+		}
+		ids[column.ID] = true
+
+		if strings.TrimSpace(column.Name) == "" {
+			fields[path+".name"] = "is required"
+		}
+
+		switch {
+		case column.Order < 0:
+			fields[path+".order"] = "must not be negative"
+		case orders[column.Order]:
+			fields[path+".order"] = "duplicates another column"
+		}
+		orders[column.Order] = true
+
+		color, ok := normalizeColumnColor(column.Color)
+		if !ok {
+			fields[path+".color"] = "must be a hex color or a palette color"
+		}
+		columns[i].Color = color
+
+		columns[i].Icon = strings.ToLower(strings.TrimSpace(column.Icon))
+		if columns[i].Icon != "" && !columnIcons[columns[i].Icon] {
+			fields[path+".icon"] = "is not a known icon"
+		}
+	}
+
+	for phase, duration := range phaseTimes {
+		path := "phaseTimes." + string(phase)
+		switch {
+		case !phase.IsValid():
+			fields[path] = "is not a known phase"
+		case duration <= 0:
+			fields[path] = "must be a positive number of seconds"
+		}
+	}
+
+	if len(fields) > 0 {
+		return &TemplateValidationError{Fields: fields}
+	}
+	return nil
+}
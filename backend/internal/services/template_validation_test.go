@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	valid := func() []models.TemplateColumn {
+		return []models.TemplateColumn{
+			{ID: "start", Name: "Start", Order: 0},
+			{ID: "stop", Name: "Stop", Order: 1},
+		}
+	}
+	if err := validateTemplate("Retro", valid(), map[models.RetroPhase]int{models.PhaseBrainstorm: 300}); err != nil {
+		t.Fatalf("valid template rejected: %v", err)
+	}
+
+	cases := map[string]struct {
+		name       string
+		columns    func() []models.TemplateColumn
+		phaseTimes map[models.RetroPhase]int
+		field      string
+	}{
+		"missing name": {name: " ", columns: valid, field: "name"},
+		"no columns":   {columns: func() []models.TemplateColumn { return nil }, field: "columns"},
+		"empty column ID": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[1].ID = ""
+			return c
+		}, field: "columns[1].id"},
+		"duplicate column ID": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[1].ID = "start"
+			return c
+		}, field: "columns[1].id"},
+		"empty column name": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[0].Name = "  "
+			return c
+		}, field: "columns[0].name"},
+		"negative order": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[1].Order = -1
+			return c
+		}, field: "columns[1].order"},
+		"duplicate order": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[1].Order = 0
+			return c
+		}, field: "columns[1].order"},
+		"unknown phase": {columns: valid, phaseTimes: map[models.RetroPhase]int{"retro": 60}, field: "phaseTimes.retro"},
+		"invalid color": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[0].Color = "#12345g"
+			return c
+		}, field: "columns[0].color"},
+		"unknown icon": {columns: func() []models.TemplateColumn {
+			c := valid()
+			c[1].Icon = "rocket-ship"
+			return c
+		}, field: "columns[1].icon"},
+		"zero duration": {columns: valid, phaseTimes: map[models.RetroPhase]int{models.PhaseVote: 0}, field: "phaseTimes.vote"},
+	}
+	for name, tc := range cases {
+		templateName := tc.name
+		if templateName == "" {
+			templateName = "Retro"
+		}
+		err := validateTemplate(templateName, tc.columns(), tc.phaseTimes)
+
+		var validationErr *TemplateValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: got %v, want a TemplateValidationError", name, err)
+			continue
+		}
+		if _, ok := validationErr.Fields[tc.field]; !ok || len(validationErr.Fields) != 1 {
+			t.Errorf("%s: fields = %v, want only %s", name, validationErr.Fields, tc.field)
+		}
+	}
+}
+
+func TestValidateTemplateNormalizesColumnStyle(t *testing.T) {
+	columns := []models.TemplateColumn{
+		{ID: "a", Name: "A", Order: 0},
+		{ID: "b", Name: "B", Order: 1, Color: " Green ", Icon: "Smile"},
+		{ID: "c", Name: "C", Order: 2, Color: "#ABC"},
+	}
+	if err := validateTemplate("Retro", columns, nil); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	want := []struct{ color, icon string }{
+		{DefaultColumnColor, ""},
+		{"#22c55e", "smile"},
+		{"#abc", ""},
+	}
+	for i, w := range want {
+		if columns[i].Color != w.color || columns[i].Icon != w.icon {
+			t.Errorf("column %d: color %q icon %q, want %q and %q", i, columns[i].Color, columns[i].Icon, w.color, w.icon)
+		}
+	}
+}
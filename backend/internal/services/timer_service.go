@@ -19,10 +19,23 @@ var (
 	ErrTimerPaused   = errors.New("timer is paused")
 )
 
+// TimerKind tells what a timer was started for
+type TimerKind string
+
+const (
+	// TimerKindManual is a timer started by the facilitator
+	TimerKindManual TimerKind = "manual"
+	// TimerKindPhase is the timebox of a phase, started when the retro enters it
+	TimerKindPhase TimerKind = "phase"
+	// TimerKindTopic is the timebox of a Lean Coffee topic
+	TimerKindTopic TimerKind = "topic"
+)
+
 // RetroTimer represents an active timer for a retrospective
 type RetroTimer struct {
 	RetroID          uuid.UUID
 	Phase            models.RetroPhase
+	Kind             TimerKind
 	Duration         time.Duration
 	StartedAt        time.Time
 	PausedAt         *time.Time
@@ -46,6 +59,8 @@ type TimerService struct {
 	templateRepo *postgres.TemplateRepository
 	timers       map[uuid.UUID]*RetroTimer
 	mu           sync.RWMutex
+
+	OnTimerEnded func(retroID uuid.UUID, phase models.RetroPhase, kind TimerKind) // Callback when a timer runs out
 }
 
 // NewTimerService creates a new timer service
@@ -60,6 +75,20 @@ func NewTimerService(bridge bus.MessageBus, retroRepo *postgres.RetrospectiveRep
 
 // StartTimer starts a timer for a retrospective
 func (s *TimerService) StartTimer(ctx context.Context, retroID uuid.UUID, durationSec int) error {
+	return s.startTimer(ctx, retroID, durationSec, TimerKindManual)
+}
+
+// StartPhaseTimer starts the timebox of the retro's current phase
+func (s *TimerService) StartPhaseTimer(ctx context.Context, retroID uuid.UUID, durationSec int) error {
+	return s.startTimer(ctx, retroID, durationSec, TimerKindPhase)
+}
+
+// StartTopicTimer starts the timebox of the Lean Coffee topic being discussed
+func (s *TimerService) StartTopicTimer(ctx context.Context, retroID uuid.UUID, durationSec int) error {
+	return s.startTimer(ctx, retroID, durationSec, TimerKindTopic)
+}
+
+func (s *TimerService) startTimer(ctx context.Context, retroID uuid.UUID, durationSec int, kind TimerKind) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -83,6 +112,7 @@ func (s *TimerService) StartTimer(ctx context.Context, retroID uuid.UUID, durati
 	timer := &RetroTimer{
 		RetroID:   retroID,
 		Phase:     retro.CurrentPhase,
+		Kind:      kind,
 		Duration:  time.Duration(durationSec) * time.Second,
 		StartedAt: now,
 		done:      make(chan struct{}),
@@ -98,8 +128,10 @@ func (s *TimerService) StartTimer(ctx context.Context, retroID uuid.UUID, durati
 		Type: "timer_started",
 		Payload: map[string]interface{}{
 			"phase":            timer.Phase,
+			"kind":             timer.Kind,
 			"duration_seconds": durationSec,
 			"end_at":           timer.StartedAt.Add(timer.Duration).Format(time.RFC3339),
+			"server_now":       ServerNow(),
 		},
 	})
 
@@ -128,6 +160,7 @@ func (s *TimerService) runTimer(timer *RetroTimer) {
 					Payload: map[string]interface{}{
 						"remaining_seconds": int(remaining.Seconds()),
 						"phase":             timer.Phase,
+						"server_now":        ServerNow(),
 					},
 				})
 			}
@@ -137,12 +170,17 @@ func (s *TimerService) runTimer(timer *RetroTimer) {
 				s.bridge.BroadcastToRoom(timer.RetroID.String(), websocket.Message{
 					Type: "timer_ended",
 					Payload: map[string]interface{}{
-						"phase": timer.Phase,
+						"phase":      timer.Phase,
+						"server_now": ServerNow(),
 					},
 				})
 				s.mu.Lock()
 				delete(s.timers, timer.RetroID)
 				s.mu.Unlock()
+
+				if s.OnTimerEnded != nil {
+					s.OnTimerEnded(timer.RetroID, timer.Phase, timer.Kind)
+				}
 				return
 			}
 		}
@@ -175,6 +213,7 @@ func (s *TimerService) PauseTimer(ctx context.Context, retroID uuid.UUID) error
 		Type: "timer_paused",
 		Payload: map[string]interface{}{
 			"remaining_seconds": remaining,
+			"server_now":        ServerNow(),
 		},
 	})
 
@@ -211,6 +250,7 @@ func (s *TimerService) ResumeTimer(ctx context.Context, retroID uuid.UUID) error
 		Payload: map[string]interface{}{
 			"remaining_seconds": int(timer.RemainingAtPause.Seconds()),
 			"end_at":            timer.StartedAt.Add(timer.Duration).Format(time.RFC3339),
+			"server_now":        ServerNow(),
 		},
 	})
 
@@ -227,9 +267,9 @@ func (s *TimerService) ResetTimer(ctx context.Context, retroID uuid.UUID) error
 		return ErrNoActiveTimer
 	}
 
-	// Stop and restart
+	// Stop and restart, keeping what the timer is for
 	timer.Stop()
-	return s.StartTimer(ctx, retroID, int(timer.Duration.Seconds()))
+	return s.startTimer(ctx, retroID, int(timer.Duration.Seconds()), timer.Kind)
 }
 
 // AddTime adds time to a running timer
@@ -251,9 +291,10 @@ func (s *TimerService) AddTime(ctx context.Context, retroID uuid.UUID, secondsTo
 	s.bridge.BroadcastToRoom(retroID.String(), websocket.Message{
 		Type: "timer_extended",
 		Payload: map[string]interface{}{
-			"added_seconds":  secondsToAdd,
-			"new_remaining":  int(newRemaining.Seconds()),
-			"new_end_at":     timer.StartedAt.Add(timer.Duration).Format(time.RFC3339),
+			"added_seconds": secondsToAdd,
+			"new_remaining": int(newRemaining.Seconds()),
+			"new_end_at":    timer.StartedAt.Add(timer.Duration).Format(time.RFC3339),
+			"server_now":    ServerNow(),
 		},
 	})
 
@@ -305,6 +346,12 @@ func (s *TimerService) IsTimerRunning(retroID uuid.UUID) bool {
 	return timer.PausedAt == nil
 }
 
+// ServerNow returns the server's current time with millisecond precision,
+// sent alongside timer messages so clients can correct for clock skew
+func ServerNow() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
+
 // getRemainingTime calculates remaining time for a timer
 func (s *TimerService) getRemainingTime(timer *RetroTimer) time.Duration {
 	if timer.PausedAt != nil {
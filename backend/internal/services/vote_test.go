@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+// createItems creates one item per content in the first column of the retro's template
+func createItems(t *testing.T, env *testenv.Env, retro *models.Retrospective, author *models.User, contents ...string) []*models.Item {
+	t.Helper()
+	ctx := context.Background()
+
+	template, err := env.Services.Retro.GetTemplate(ctx, retro.TemplateID)
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	items := make([]*models.Item, 0, len(contents))
+	for _, content := range contents {
+		item, err := env.Services.Retro.CreateItem(ctx, retro.ID, author.ID, services.CreateItemInput{ColumnID: template.Columns[0].ID, Content: content})
+		if err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestVoteLimitPerGroupCountsNestedChildren(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{
+		MaxVotesPerUser:   10,
+		MaxVotesPerItem:   2,
+		VoteLimitPerGroup: true,
+	})
+	items := createItems(t, env, retro, alice, "Slow CI", "Flaky tests", "Timeouts on the runners", "Unclear goals")
+	root, child, grandchild, other := items[0], items[1], items[2], items[3]
+
+	// grandchild → child → root
+	if _, err := svc.GroupItems(ctx, retro.ID, root.ID, []uuid.UUID{child.ID}); err != nil {
+		t.Fatalf("group child: %v", err)
+	}
+	if _, err := svc.GroupItems(ctx, retro.ID, child.ID, []uuid.UUID{grandchild.ID}); err != nil {
+		t.Fatalf("group grandchild: %v", err)
+	}
+
+	for _, item := range []*models.Item{root, grandchild} {
+		if err := svc.Vote(ctx, retro.ID, item.ID, alice.ID); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+	}
+	if err := svc.Vote(ctx, retro.ID, child.ID, alice.ID); !errors.Is(err, services.ErrItemVoteLimitReached) {
+		t.Fatalf("third vote in the group = %v, want ErrItemVoteLimitReached", err)
+	}
+	if err := svc.Vote(ctx, retro.ID, other.ID, alice.ID); err != nil {
+		t.Fatalf("vote outside the group: %v", err)
+	}
+	if err := svc.Vote(ctx, retro.ID, grandchild.ID, bob.ID); err != nil {
+		t.Fatalf("another user's vote in the group: %v", err)
+	}
+
+	summary, err := svc.GetGroupedVoteSummary(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("grouped summary: %v", err)
+	}
+	if summary[root.ID] != 3 || summary[other.ID] != 1 {
+		t.Errorf("grouped totals = %v, want 3 for the group and 1 for the other item", summary)
+	}
+	for _, item := range []*models.Item{child, grandchild} {
+		if _, ok := summary[item.ID]; ok {
+			t.Errorf("grouped summary lists the grouped item %s on its own", item.Content)
+		}
+	}
+}
+
+func TestVoteSummaryHidesVotersInAnonymousRetros(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+
+	for _, anonymous := range []bool{true, false} {
+		retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{AnonymousVoting: anonymous})
+		item := createItems(t, env, retro, alice, "Slow CI")[0]
+		for _, user := range []*models.User{alice, bob} {
+			if err := svc.Vote(ctx, retro.ID, item.ID, user.ID); err != nil {
+				t.Fatalf("vote: %v", err)
+			}
+		}
+
+		view, err := svc.GetVoteSummaryFor(ctx, retro, alice.ID)
+		if err != nil {
+			t.Fatalf("vote summary: %v", err)
+		}
+		if view.Anonymous != anonymous || view.ItemTotals[item.ID.String()] != 2 {
+			t.Errorf("anonymous=%v: summary = %+v, want the flag and 2 votes on the item", anonymous, view)
+		}
+		if view.ByUser[alice.ID.String()][item.ID.String()] != 1 {
+			t.Errorf("anonymous=%v: the viewer's own vote is missing from %v", anonymous, view.ByUser)
+		}
+		_, bobListed := view.ByUser[bob.ID.String()]
+		if bobListed == anonymous {
+			t.Errorf("anonymous=%v: other voters listed = %v, want %v", anonymous, bobListed, !anonymous)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrInvalidWebhookURL = errors.New("invalid webhook URL")
+)
+
+// WebhookURLPolicy decides which destinations webhooks may be delivered to.
+// Loopback, private, link-local and other non-public addresses are refused
+// unless they fall in an allowed network; denied networks are always refused.
+type WebhookURLPolicy struct {
+	allowed  []*net.IPNet
+	denied   []*net.IPNet
+	resolver *net.Resolver
+}
+
+// NewWebhookURLPolicy creates a policy from lists of CIDRs or single IPs
+func NewWebhookURLPolicy(allowed, denied []string) (*WebhookURLPolicy, error) {
+	allowNets, err := parseNetworks(allowed)
+	if err != nil {
+		return nil, fmt.Errorf("webhook allowed networks: %w", err)
+	}
+	denyNets, err := parseNetworks(denied)
+	if err != nil {
+		return nil, fmt.Errorf("webhook denied networks: %w", err)
+	}
+	return &WebhookURLPolicy{
+		allowed:  allowNets,
+		denied:   denyNets,
+		resolver: net.DefaultResolver,
+	}, nil
+}
+
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// ValidateURL checks the URL shape and that every address its host resolves to is permitted
+func (p *WebhookURLPolicy) ValidateURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidWebhookURL)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidWebhookURL)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return p.checkIP(ip)
+	}
+
+	addrs, err := p.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", ErrInvalidWebhookURL, host)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("%w: %s has no addresses", ErrInvalidWebhookURL, host)
+	}
+	for _, addr := range addrs {
+		if err := p.checkIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIP refuses denied addresses and non-public addresses that are not explicitly allowed
+func (p *WebhookURLPolicy) checkIP(ip net.IP) error {
+	if containsIP(p.denied, ip) {
+		return fmt.Errorf("%w: address %s is denied", ErrInvalidWebhookURL, ip)
+	}
+	if containsIP(p.allowed, ip) {
+		return nil
+	}
+	if isNonPublicIP(ip) {
+		return fmt.Errorf("%w: address %s is not publicly routable", ErrInvalidWebhookURL, ip)
+	}
+	return nil
+}
+
+// dialControl re-checks the address actually being connected to, so a DNS answer
+// that changed after validation (rebinding) or a redirect cannot reach a refused address
+func (p *WebhookURLPolicy) dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: unexpected dial address %s", ErrInvalidWebhookURL, address)
+	}
+	return p.checkIP(ip)
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598)
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+func isNonPublicIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
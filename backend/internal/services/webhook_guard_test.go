@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookURLPolicy_RefusesNonPublicDestinations(t *testing.T) {
+	policy, err := NewWebhookURLPolicy(nil, nil)
+	if err != nil {
+		t.Fatalf("NewWebhookURLPolicy: %v", err)
+	}
+
+	refused := []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[fd00:ec2::254]/latest/meta-data/",
+		"http://127.0.0.1:8080/admin",
+		"http://localhost/",
+		"http://[::1]/",
+		"http://10.0.0.5/hook",
+		"http://172.16.3.4/hook",
+		"http://192.168.1.1/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/",
+		"http://[::ffff:127.0.0.1]/",
+		"ftp://93.184.216.34/",
+		"file:///etc/passwd",
+		"http:///no-host",
+	}
+	for _, u := range refused {
+		if err := policy.ValidateURL(context.Background(), u); !errors.Is(err, ErrInvalidWebhookURL) {
+			t.Errorf("ValidateURL(%q) = %v, want ErrInvalidWebhookURL", u, err)
+		}
+	}
+
+	if err := policy.ValidateURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+}
+
+func TestWebhookURLPolicy_AllowAndDenyLists(t *testing.T) {
+	policy, err := NewWebhookURLPolicy([]string{"10.1.0.0/16", "127.0.0.1", ""}, []string{"10.1.2.3", "93.184.216.0/24"})
+	if err != nil {
+		t.Fatalf("NewWebhookURLPolicy: %v", err)
+	}
+
+	cases := map[string]bool{
+		"http://10.1.5.5/hook":        true,
+		"http://127.0.0.1:9000/hook":  true,
+		"http://10.1.2.3/hook":        false, // denied wins over allowed
+		"http://10.2.0.1/hook":        false,
+		"http://169.254.169.254/":     false,
+		"https://93.184.216.34/hook":  false,
+		"https://198.51.100.200/hook": true,
+	}
+	for u, ok := range cases {
+		err := policy.ValidateURL(context.Background(), u)
+		if ok && err != nil {
+			t.Errorf("ValidateURL(%q) = %v, want allowed", u, err)
+		}
+		if !ok && !errors.Is(err, ErrInvalidWebhookURL) {
+			t.Errorf("ValidateURL(%q) = %v, want ErrInvalidWebhookURL", u, err)
+		}
+	}
+
+	if _, err := NewWebhookURLPolicy([]string{"not-a-network"}, nil); err == nil {
+		t.Error("expected an error for an invalid allowed network")
+	}
+}
+
+// The delivery client re-checks the dialed address, so a host that resolved to a public
+// address at validation time cannot be rebound to an internal one.
+func TestWebhookService_DeliveryClientRefusesInternalDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	blocked := NewWebhookService(nil, nil, nil)
+	if _, err := blocked.httpClient.Get(srv.URL); !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Fatalf("dial to %s = %v, want ErrInvalidWebhookURL", srv.URL, err)
+	}
+
+	policy, err := NewWebhookURLPolicy([]string{"127.0.0.0/8"}, nil)
+	if err != nil {
+		t.Fatalf("NewWebhookURLPolicy: %v", err)
+	}
+	allowed := NewWebhookService(nil, nil, policy)
+	resp, err := allowed.httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("allowed dial failed: %v", err)
+	}
+	_ = resp.Body.Close()
+}
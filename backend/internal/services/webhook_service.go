@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
@@ -21,26 +22,45 @@ import (
 )
 
 var (
-	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrInvalidWebhookEvent = errors.New("unknown webhook event")
 )
 
 // WebhookService handles webhook operations
 type WebhookService struct {
 	webhookRepo  *postgres.WebhookRepository
 	deliveryRepo *postgres.WebhookDeliveryRepository
+	urlPolicy    *WebhookURLPolicy
 	httpClient   *http.Client
 }
 
-// NewWebhookService creates a new webhook service
+// NewWebhookService creates a new webhook service.
+// A nil policy refuses every non-public destination.
 func NewWebhookService(
 	webhookRepo *postgres.WebhookRepository,
 	deliveryRepo *postgres.WebhookDeliveryRepository,
+	urlPolicy *WebhookURLPolicy,
 ) *WebhookService {
+	if urlPolicy == nil {
+		urlPolicy, _ = NewWebhookURLPolicy(nil, nil)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Dial directly so the address check applies to the real destination
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   urlPolicy.dialControl,
+	}).DialContext
+
 	return &WebhookService{
 		webhookRepo:  webhookRepo,
 		deliveryRepo: deliveryRepo,
+		urlPolicy:    urlPolicy,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
 	}
 }
@@ -57,6 +77,13 @@ type CreateWebhookInput struct {
 
 // Create creates a new webhook
 func (s *WebhookService) Create(ctx context.Context, createdBy uuid.UUID, input CreateWebhookInput) (*models.Webhook, error) {
+	if err := validateWebhookEvents(input.Events); err != nil {
+		return nil, err
+	}
+	if err := s.urlPolicy.ValidateURL(ctx, input.URL); err != nil {
+		return nil, err
+	}
+
 	webhook := &models.Webhook{
 		ID:        uuid.New(),
 		TeamID:    input.TeamID,
@@ -99,6 +126,10 @@ type UpdateWebhookInput struct {
 
 // Update updates a webhook
 func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, input UpdateWebhookInput) (*models.Webhook, error) {
+	if err := validateWebhookEvents(input.Events); err != nil {
+		return nil, err
+	}
+
 	webhook, err := s.webhookRepo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, postgres.ErrNotFound) {
@@ -111,6 +142,9 @@ func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, input UpdateW
 		webhook.Name = *input.Name
 	}
 	if input.URL != nil {
+		if err := s.urlPolicy.ValidateURL(ctx, *input.URL); err != nil {
+			return nil, err
+		}
 		webhook.URL = *input.URL
 	}
 	if input.Secret != nil {
@@ -130,6 +164,16 @@ func (s *WebhookService) Update(ctx context.Context, id uuid.UUID, input UpdateW
 	return webhook, nil
 }
 
+// validateWebhookEvents rejects events webhooks cannot subscribe to
+func validateWebhookEvents(events []string) error {
+	for _, event := range events {
+		if !models.WebhookEvent(event).IsValid() {
+			return fmt.Errorf("%w: %s", ErrInvalidWebhookEvent, event)
+		}
+	}
+	return nil
+}
+
 // Delete deletes a webhook
 func (s *WebhookService) Delete(ctx context.Context, id uuid.UUID) error {
 	if err := s.webhookRepo.Delete(ctx, id); err != nil {
@@ -146,6 +190,62 @@ func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID uuid.UUID
 	return s.deliveryRepo.ListByWebhook(ctx, webhookID, limit)
 }
 
+// DispatchRetroReminder dispatches retro.reminder webhooks
+func (s *WebhookService) DispatchRetroReminder(ctx context.Context, retro *models.Retrospective, data models.RetroReminderData) {
+	event := string(models.WebhookEventRetroReminder)
+
+	webhooks, err := s.webhookRepo.ListByTeamAndEvent(ctx, retro.TeamID, event)
+	if err != nil {
+		slog.Error("failed to list webhooks for retro.reminder", "error", err, "teamId", retro.TeamID)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload := models.WebhookPayload{
+		Event:     models.WebhookEventRetroReminder,
+		Timestamp: time.Now().UTC(),
+		RetroID:   retro.ID,
+		TeamID:    retro.TeamID,
+		Data:      data,
+	}
+
+	// Dispatch asynchronously
+	for _, webhook := range webhooks {
+		go s.dispatch(ctx, webhook, event, payload)
+	}
+}
+
+// DispatchRetroStarted dispatches retro.started webhooks
+func (s *WebhookService) DispatchRetroStarted(ctx context.Context, retro *models.Retrospective, data models.RetroStartedData) {
+	event := string(models.WebhookEventRetroStarted)
+
+	webhooks, err := s.webhookRepo.ListByTeamAndEvent(ctx, retro.TeamID, event)
+	if err != nil {
+		slog.Error("failed to list webhooks for retro.started", "error", err, "teamId", retro.TeamID)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload := models.WebhookPayload{
+		Event:     models.WebhookEventRetroStarted,
+		Timestamp: time.Now().UTC(),
+		RetroID:   retro.ID,
+		TeamID:    retro.TeamID,
+		Data:      data,
+	}
+
+	// Dispatch asynchronously
+	for _, webhook := range webhooks {
+		go s.dispatch(ctx, webhook, event, payload)
+	}
+}
+
 // DispatchRetroCompleted dispatches retro.completed webhooks
 func (s *WebhookService) DispatchRetroCompleted(ctx context.Context, retro *models.Retrospective, data models.RetroCompletedData) {
 	event := string(models.WebhookEventRetroCompleted)
@@ -202,6 +302,70 @@ func (s *WebhookService) DispatchActionCreated(ctx context.Context, action *mode
 	}
 }
 
+// DispatchActionCommented dispatches action.commented webhooks
+func (s *WebhookService) DispatchActionCommented(ctx context.Context, action *models.ActionItem, teamID uuid.UUID, data models.ActionCommentedData) {
+	event := string(models.WebhookEventActionCommented)
+
+	webhooks, err := s.webhookRepo.ListByTeamAndEvent(ctx, teamID, event)
+	if err != nil {
+		slog.Error("failed to list webhooks for action.commented", "error", err, "teamId", teamID)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload := models.WebhookPayload{
+		Event:     models.WebhookEventActionCommented,
+		Timestamp: time.Now().UTC(),
+		RetroID:   action.RetroID,
+		TeamID:    teamID,
+		Data:      data,
+	}
+
+	// Dispatch asynchronously
+	for _, webhook := range webhooks {
+		go s.dispatch(ctx, webhook, event, payload)
+	}
+}
+
+// DispatchActionCompleted dispatches action.completed webhooks
+func (s *WebhookService) DispatchActionCompleted(ctx context.Context, action *models.ActionItem, teamID uuid.UUID, data models.ActionCompletedData) {
+	s.dispatchActionEvent(ctx, models.WebhookEventActionCompleted, action, teamID, data)
+}
+
+// DispatchActionReopened dispatches action.reopened webhooks
+func (s *WebhookService) DispatchActionReopened(ctx context.Context, action *models.ActionItem, teamID uuid.UUID, data models.ActionCompletedData) {
+	s.dispatchActionEvent(ctx, models.WebhookEventActionReopened, action, teamID, data)
+}
+
+// dispatchActionEvent sends an action event to the team's webhooks subscribed to it
+func (s *WebhookService) dispatchActionEvent(ctx context.Context, event models.WebhookEvent, action *models.ActionItem, teamID uuid.UUID, data interface{}) {
+	webhooks, err := s.webhookRepo.ListByTeamAndEvent(ctx, teamID, string(event))
+	if err != nil {
+		slog.Error("failed to list webhooks for "+string(event), "error", err, "teamId", teamID)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload := models.WebhookPayload{
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		RetroID:   action.RetroID,
+		TeamID:    teamID,
+		Data:      data,
+	}
+
+	// Dispatch asynchronously
+	for _, webhook := range webhooks {
+		go s.dispatch(ctx, webhook, string(event), payload)
+	}
+}
+
 // dispatch sends a webhook and records the delivery
 func (s *WebhookService) dispatch(ctx context.Context, webhook *models.Webhook, eventType string, payload models.WebhookPayload) {
 	payloadBytes, err := json.Marshal(payload)
@@ -217,6 +381,15 @@ func (s *WebhookService) dispatch(ctx context.Context, webhook *models.Webhook,
 		AttemptCount: 1,
 	}
 
+	// Re-validate at delivery time: DNS may have changed since the webhook was saved
+	if err := s.urlPolicy.ValidateURL(ctx, webhook.URL); err != nil {
+		errMsg := err.Error()
+		delivery.ErrorMessage = &errMsg
+		_, _ = s.deliveryRepo.Create(ctx, delivery)
+		slog.Warn("webhook destination refused", "error", err, "webhookId", webhook.ID)
+		return
+	}
+
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payloadBytes))
 	if err != nil {
@@ -0,0 +1,304 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+	"github.com/jycamier/retrotro/backend/internal/testenv"
+)
+
+func TestRetroCompletedParticipantCountFollowsAttendance(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	received := make(chan models.RetroCompletedData, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data models.RetroCompletedData `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			received <- payload.Data
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	carol := env.CreateUser(t, "Carol")
+	team := env.CreateTeam(t, alice, bob, carol)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	if _, err := env.Services.Webhook.Create(ctx, alice.ID, services.CreateWebhookInput{
+		TeamID:    team.ID,
+		Name:      "test",
+		URL:       srv.URL,
+		Events:    []string{string(models.WebhookEventRetroCompleted)},
+		IsEnabled: true,
+	}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	// Alice and Bob attended, Carol did not; only Alice shared a mood
+	for user, attended := range map[*models.User]bool{alice: true, bob: true, carol: false} {
+		if err := env.Repos.Attendees.Record(ctx, retro.ID, user.ID, attended); err != nil {
+			t.Fatalf("record attendance: %v", err)
+		}
+	}
+	if _, err := env.Services.Retro.SetIcebreakerMood(ctx, retro.ID, alice.ID, models.MoodSunny); err != nil {
+		t.Fatalf("set mood: %v", err)
+	}
+
+	if _, err := env.Services.Retro.End(ctx, retro.ID); err != nil {
+		t.Fatalf("end retro: %v", err)
+	}
+
+	select {
+	case data := <-received:
+		if data.ParticipantCount != 2 {
+			t.Errorf("participantCount = %d, want 2", data.ParticipantCount)
+		}
+		if len(data.Participants) != 2 {
+			t.Errorf("participants = %v, want 2 entries", data.Participants)
+		}
+		for _, p := range data.Participants {
+			if p.UserID == carol.ID {
+				t.Errorf("absent member listed as participant")
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retro.completed webhook not received")
+	}
+}
+
+func TestEndTwiceDispatchesRetroCompletedOnce(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	received := make(chan models.RetroCompletedData, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data models.RetroCompletedData `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			received <- payload.Data
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if _, err := env.Services.Webhook.Create(ctx, alice.ID, services.CreateWebhookInput{
+		TeamID:    team.ID,
+		Name:      "test",
+		URL:       srv.URL,
+		Events:    []string{string(models.WebhookEventRetroCompleted)},
+		IsEnabled: true,
+	}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	if _, err := env.Services.Retro.End(ctx, retro.ID); err != nil {
+		t.Fatalf("end retro: %v", err)
+	}
+	select {
+	case data := <-received:
+		if data.Name != retro.Name {
+			t.Fatalf("retro.completed for %q, want %q", data.Name, retro.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retro.completed webhook not received")
+	}
+
+	first, err := env.Services.Retro.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get retro: %v", err)
+	}
+	second, err := env.Services.Retro.End(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("end retro again: %v", err)
+	}
+	if second.Status != models.StatusCompleted || second.EndedAt == nil || !second.EndedAt.Equal(*first.EndedAt) {
+		t.Errorf("second end = %s ended at %v, want the first result (%s ended at %v)",
+			second.Status, second.EndedAt, first.Status, first.EndedAt)
+	}
+	select {
+	case <-received:
+		t.Fatal("second end dispatched another retro.completed webhook")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestConcurrentEndsDispatchRetroCompletedOnce(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+
+	var delivered atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	alice := env.CreateUser(t, "Alice")
+	team := env.CreateTeam(t, alice)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	if _, err := env.Services.Webhook.Create(ctx, alice.ID, services.CreateWebhookInput{
+		TeamID:    team.ID,
+		Name:      "test",
+		URL:       srv.URL,
+		Events:    []string{string(models.WebhookEventRetroCompleted)},
+		IsEnabled: true,
+	}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	if _, err := env.Services.Retro.Start(ctx, retro.ID); err != nil {
+		t.Fatalf("start retro: %v", err)
+	}
+
+	// Every end succeeds; the ones that lose the race take the idempotent path
+	results := make([]*models.Retrospective, 6)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ended, err := env.Services.Retro.End(ctx, retro.ID)
+			if err != nil {
+				t.Errorf("end retro: %v", err)
+				return
+			}
+			results[i] = ended
+		}()
+	}
+	wg.Wait()
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	saved, err := env.Services.Retro.GetByID(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("get retro: %v", err)
+	}
+	for _, ended := range results {
+		if ended.Status != models.StatusCompleted || ended.EndedAt == nil || !ended.EndedAt.Equal(*saved.EndedAt) {
+			t.Errorf("end = %s ended at %v, want completed at %v", ended.Status, ended.EndedAt, saved.EndedAt)
+		}
+	}
+
+	testenv.Eventually(t, 5*time.Second, func() bool { return delivered.Load() >= 1 }, "retro.completed webhook not received")
+	time.Sleep(300 * time.Millisecond)
+	if got := delivered.Load(); got != 1 {
+		t.Fatalf("%d retro.completed webhooks delivered, want 1", got)
+	}
+}
+
+func TestActionCompletionWebhooks(t *testing.T) {
+	env := testenv.NewTestEnv(t)
+	ctx := context.Background()
+	svc := env.Services.Retro
+
+	type event struct {
+		Event models.WebhookEvent        `json:"event"`
+		Data  models.ActionCompletedData `json:"data"`
+	}
+	received := make(chan event, 8)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload event
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			received <- payload
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+	action, err := svc.CreateAction(ctx, retro.ID, alice.ID, services.CreateActionInput{Title: "Close the incident"})
+	if err != nil {
+		t.Fatalf("create action: %v", err)
+	}
+	if _, err := env.Services.Webhook.Create(ctx, alice.ID, services.CreateWebhookInput{
+		TeamID:    team.ID,
+		Name:      "tracker",
+		URL:       srv.URL,
+		Events:    []string{string(models.WebhookEventActionCompleted), string(models.WebhookEventActionReopened)},
+		IsEnabled: true,
+	}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	expect := func(want models.WebhookEvent, changedBy *models.User) {
+		t.Helper()
+		select {
+		case got := <-received:
+			if got.Event != want || got.Data.ActionID != action.ID || got.Data.ChangedBy != changedBy.ID {
+				t.Fatalf("got %s %+v, want %s by %s", got.Event, got.Data, want, changedBy.DisplayName)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s webhook not received", want)
+		}
+	}
+
+	if _, err := svc.CompleteAction(ctx, retro.ID, action.ID, bob.ID); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	expect(models.WebhookEventActionCompleted, bob)
+
+	// Already done: neither call completes it again
+	done := "done"
+	if _, err := svc.PatchAction(ctx, action.ID, alice.ID, services.PatchActionInput{Status: &done}); err != nil {
+		t.Fatalf("patch to done: %v", err)
+	}
+	if _, err := svc.CompleteAction(ctx, retro.ID, action.ID, alice.ID); err != nil {
+		t.Fatalf("complete again: %v", err)
+	}
+
+	inProgress := "in_progress"
+	if _, err := svc.PatchAction(ctx, action.ID, alice.ID, services.PatchActionInput{Status: &inProgress}); err != nil {
+		t.Fatalf("patch to in_progress: %v", err)
+	}
+	expect(models.WebhookEventActionReopened, alice)
+
+	if _, err := svc.PatchAction(ctx, action.ID, bob.ID, services.PatchActionInput{Status: &done}); err != nil {
+		t.Fatalf("patch to done: %v", err)
+	}
+	expect(models.WebhookEventActionCompleted, bob)
+
+	select {
+	case got := <-received:
+		t.Fatalf("unexpected %s webhook", got.Event)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebhookRejectsUnknownEvents(t *testing.T) {
+	svc := services.NewWebhookService(nil, nil, nil)
+
+	_, err := svc.Create(context.Background(), uuid.New(), services.CreateWebhookInput{
+		Name:   "test",
+		URL:    "https://example.com/hook",
+		Events: []string{string(models.WebhookEventActionCompleted), "action.deleted"},
+	})
+	if !errors.Is(err, services.ErrInvalidWebhookEvent) {
+		t.Fatalf("create with an unknown event = %v, want ErrInvalidWebhookEvent", err)
+	}
+	if _, err := svc.Update(context.Background(), uuid.New(), services.UpdateWebhookInput{Events: []string{"retro.deleted"}}); !errors.Is(err, services.ErrInvalidWebhookEvent) {
+		t.Fatalf("update with an unknown event = %v, want ErrInvalidWebhookEvent", err)
+	}
+}
@@ -0,0 +1,174 @@
+package testenv
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/bus"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+// Cluster simulates several backend pods sharing one in-memory message bus
+type Cluster struct {
+	pubSub *gochannel.GoChannel
+}
+
+// sharedPubSub lets every pod of a cluster use the same GoChannel while the
+// cluster alone decides when it is closed (WatermillBus.Stop closes its pub/sub)
+type sharedPubSub struct {
+	*gochannel.GoChannel
+}
+
+func (sharedPubSub) Close() error { return nil }
+
+// NewCluster creates an in-memory bus that pods created with NewPod share
+func NewCluster(t testing.TB) *Cluster {
+	t.Helper()
+
+	pubSub := gochannel.NewGoChannel(
+		gochannel.Config{OutputChannelBuffer: 256},
+		watermill.NewSlogLogger(slog.Default()),
+	)
+	t.Cleanup(func() { _ = pubSub.Close() })
+
+	return &Cluster{pubSub: pubSub}
+}
+
+// Pod is one simulated backend instance: its own hub, connected to the cluster bus
+type Pod struct {
+	Hub *ws.Hub
+	Bus bus.MessageBus
+}
+
+// NewPod starts a hub and a bus relay on the cluster, stopped when the test ends
+func (c *Cluster) NewPod(t testing.TB) *Pod {
+	t.Helper()
+
+	hub := ws.NewHub()
+	go hub.Run()
+
+	shared := sharedPubSub{c.pubSub}
+	b := bus.NewWatermillBus(hub, shared, shared)
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("testenv: start bus: %v", err)
+	}
+	t.Cleanup(b.Stop)
+
+	return &Pod{Hub: hub, Bus: b}
+}
+
+// NewLocalPod starts a single pod on a LocalBus, for tests that need no cross-pod relay
+func NewLocalPod(t testing.TB) *Pod {
+	t.Helper()
+
+	hub := ws.NewHub()
+	go hub.Run()
+
+	return &Pod{Hub: hub, Bus: bus.NewLocalBus(hub)}
+}
+
+// Client is a websocket client without a network connection whose outgoing
+// messages are captured for assertions
+type Client struct {
+	*ws.Client
+
+	disconnect sync.Once
+}
+
+// Connect registers a client for the user on the pod, already in the room.
+// Registration is processed by the hub loop, so broadcasts sent afterwards reach it.
+func (p *Pod) Connect(t testing.TB, userID uuid.UUID, userName, roomID string) *Client {
+	t.Helper()
+
+	client := &Client{Client: &ws.Client{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		UserName:    userName,
+		RoomID:      roomID,
+		Hub:         p.Hub,
+		Send:        make(chan []byte, 256),
+		ConnectedAt: time.Now(),
+	}}
+	p.Hub.Register(client.Client)
+	t.Cleanup(client.Disconnect)
+
+	return client
+}
+
+// Disconnect unregisters the client from its hub, as a closed connection would
+func (c *Client) Disconnect() {
+	c.disconnect.Do(func() { c.Hub.Unregister(c.Client) })
+}
+
+// Expect waits for the next message of the given type, skipping any other message,
+// and returns its decoded payload. It fails the test when the timeout expires.
+func (c *Client) Expect(t testing.TB, msgType string, timeout time.Duration) json.RawMessage {
+	t.Helper()
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case data, ok := <-c.Send:
+			if !ok {
+				t.Fatalf("testenv: client disconnected while waiting for %q", msgType)
+			}
+			var msg struct {
+				Type    string          `json:"type"`
+				Payload json.RawMessage `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("testenv: decode message: %v", err)
+			}
+			if msg.Type == msgType {
+				return msg.Payload
+			}
+		case <-deadline:
+			t.Fatalf("testenv: no %q message received within %s", msgType, timeout)
+			return nil
+		}
+	}
+}
+
+// ExpectNone fails the test if a message of the given type arrives within the window
+func (c *Client) ExpectNone(t testing.TB, msgType string, window time.Duration) {
+	t.Helper()
+
+	deadline := time.After(window)
+	for {
+		select {
+		case data, ok := <-c.Send:
+			if !ok {
+				return
+			}
+			var msg struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(data, &msg); err == nil && msg.Type == msgType {
+				t.Fatalf("testenv: unexpected %q message", msgType)
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+// Eventually polls the condition until it holds, failing the test after the timeout
+func Eventually(t testing.TB, timeout time.Duration, condition func() bool, format string, args ...interface{}) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf(format, args...)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
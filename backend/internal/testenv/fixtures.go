@@ -0,0 +1,95 @@
+package testenv
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/models"
+	"github.com/jycamier/retrotro/backend/internal/services"
+)
+
+// CreateUser inserts a user with a unique email
+func (e *Env) CreateUser(t testing.TB, name string) *models.User {
+	t.Helper()
+
+	id := uuid.New()
+	user, err := e.Repos.Users.Create(context.Background(), &models.User{
+		ID:          id,
+		Email:       strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "+" + id.String()[:8] + "@example.com",
+		DisplayName: name,
+		OIDCSubject: "test-" + id.String(),
+		OIDCIssuer:  "testenv",
+	})
+	if err != nil {
+		t.Fatalf("testenv: create user: %v", err)
+	}
+	return user
+}
+
+// CreateTeam inserts a team with the owner as admin and the other users as members
+func (e *Env) CreateTeam(t testing.TB, owner *models.User, members ...*models.User) *models.Team {
+	t.Helper()
+	ctx := context.Background()
+
+	id := uuid.New()
+	team, err := e.Repos.Teams.Create(ctx, &models.Team{
+		ID:        id,
+		Name:      "Team " + id.String()[:8],
+		Slug:      "team-" + id.String()[:8],
+		CreatedBy: &owner.ID,
+	})
+	if err != nil {
+		t.Fatalf("testenv: create team: %v", err)
+	}
+
+	e.AddMember(t, team, owner, models.RoleAdmin)
+	for _, member := range members {
+		e.AddMember(t, team, member, models.RoleMember)
+	}
+	return team
+}
+
+// AddMember adds a user to a team with the given role
+func (e *Env) AddMember(t testing.TB, team *models.Team, user *models.User, role models.Role) {
+	t.Helper()
+
+	_, err := e.Repos.TeamMembers.Create(context.Background(), &models.TeamMember{
+		ID:     uuid.New(),
+		TeamID: team.ID,
+		UserID: user.ID,
+		Role:   role,
+	})
+	if err != nil {
+		t.Fatalf("testenv: add team member: %v", err)
+	}
+}
+
+// CreateRetro creates a retrospective on the first built-in template, facilitated by the user.
+// The input's TeamID and TemplateID are filled in when left empty.
+func (e *Env) CreateRetro(t testing.TB, team *models.Team, facilitator *models.User, input services.CreateRetroInput) *models.Retrospective {
+	t.Helper()
+	ctx := context.Background()
+
+	if input.Name == "" {
+		input.Name = "Retro " + uuid.NewString()[:8]
+	}
+	if input.TeamID == uuid.Nil {
+		input.TeamID = team.ID
+	}
+	if input.TemplateID == uuid.Nil && input.SessionType != models.SessionTypeLeanCoffee {
+		templates, err := e.Repos.Templates.ListBuiltIn(ctx)
+		if err != nil || len(templates) == 0 {
+			t.Fatalf("testenv: no built-in template available: %v", err)
+		}
+		input.TemplateID = templates[0].ID
+	}
+
+	retro, err := e.Services.Retro.Create(ctx, facilitator.ID, input)
+	if err != nil {
+		t.Fatalf("testenv: create retrospective: %v", err)
+	}
+	return retro
+}
@@ -0,0 +1,60 @@
+package testenv
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jycamier/retrotro/backend/internal/services"
+	ws "github.com/jycamier/retrotro/backend/internal/websocket"
+)
+
+func TestEnvFixtures(t *testing.T) {
+	env := NewTestEnv(t)
+	ctx := context.Background()
+
+	alice := env.CreateUser(t, "Alice")
+	bob := env.CreateUser(t, "Bob")
+	team := env.CreateTeam(t, alice, bob)
+	retro := env.CreateRetro(t, team, alice, services.CreateRetroInput{})
+
+	isMember, err := env.Repos.TeamMembers.IsMember(ctx, team.ID, bob.ID)
+	if err != nil || !isMember {
+		t.Fatalf("expected bob to be a team member, got %v (err %v)", isMember, err)
+	}
+
+	item, err := env.Services.Retro.CreateItem(ctx, retro.ID, bob.ID, services.CreateItemInput{ColumnID: "start", Content: "Pair more"})
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	if err := env.Services.Retro.Vote(ctx, retro.ID, item.ID, alice.ID); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+
+	items, err := env.Services.Retro.ListItems(ctx, retro.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(items) != 1 || items[0].VoteCount != 1 {
+		t.Fatalf("expected one item with one vote, got %+v", items)
+	}
+}
+
+func TestPodDeliversRoomBroadcast(t *testing.T) {
+	pod := NewCluster(t).NewPod(t)
+	roomID := uuid.NewString()
+
+	client := pod.Connect(t, uuid.New(), "Alice", roomID)
+	pod.Bus.BroadcastToRoom(roomID, ws.Message{Type: "ping", Payload: map[string]string{"hello": "world"}})
+
+	var payload map[string]string
+	if err := json.Unmarshal(client.Expect(t, "ping", time.Second), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload["hello"] != "world" {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 
 	"go.uber.org/fx"
+
+	"github.com/jycamier/retrotro/backend/internal/config"
 )
 
 var Module = fx.Module("websocket",
@@ -12,8 +14,9 @@ var Module = fx.Module("websocket",
 )
 
 // NewHubFx creates the WebSocket hub with lifecycle management
-func NewHubFx(lc fx.Lifecycle) *Hub {
+func NewHubFx(lc fx.Lifecycle, cfg *config.Config) *Hub {
 	hub := NewHub()
+	hub.SetLimits(cfg.WSMaxConnections, cfg.WSMaxRooms)
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
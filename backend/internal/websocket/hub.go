@@ -2,9 +2,11 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,11 +18,20 @@ const (
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 8192
+	// Messages at least this large are sent with permessage-deflate when the client negotiated it
+	compressionThreshold = 1024
 	// Grace period before broadcasting participant_left to handle page reloads
 	// Increased from 2s to 10s to handle high-latency networks (150ms+) and slow page loads
 	disconnectGracePeriod = 10 * time.Second
 )
 
+var (
+	// ErrTooManyConnections is returned when registering a client beyond the hub's connection limit
+	ErrTooManyConnections = errors.New("too many connections")
+	// ErrTooManyRooms is returned when a client would open a room beyond the hub's room limit
+	ErrTooManyRooms = errors.New("too many rooms")
+)
+
 // Message represents a WebSocket message
 type Message struct {
 	Type    string      `json:"type"`
@@ -29,13 +40,22 @@ type Message struct {
 
 // Client represents a WebSocket client
 type Client struct {
-	ID       string
-	UserID   uuid.UUID
-	UserName string
-	RoomID   string
-	Hub      *Hub
-	Conn     *websocket.Conn
-	Send     chan []byte
+	ID          string
+	UserID      uuid.UUID
+	UserName    string
+	RoomID      string
+	Hub         *Hub
+	Conn        *websocket.Conn
+	Send        chan []byte
+	ConnectedAt time.Time
+	Spectator   bool // watches the retro without participating
+	Guest       bool // joined with a guest link, without an account
+	// GuestRetroID is the only retro a guest may join, with the share link GuestShareID; only
+	// set on the guest's own pod
+	GuestRetroID uuid.UUID
+	GuestShareID uuid.UUID
+
+	slow atomic.Bool // set once the client is being evicted for not keeping up
 }
 
 // PendingDisconnect tracks a user who disconnected but may reconnect (page reload)
@@ -56,6 +76,26 @@ type Hub struct {
 	mu                 sync.RWMutex
 	pendingDisconnects map[string]*PendingDisconnect         // key: "roomID-userID"
 	OnUserLeftRoom     func(roomID string, userID uuid.UUID) // Callback when user leaves room
+
+	sendHighWater atomic.Int64  // most messages queued for one client at once
+	sendOverflows atomic.Uint64 // messages that found a client's send buffer full
+
+	unknownMessages atomic.Uint64 // incoming messages of a type the server does not handle
+
+	maxConnections      int          // most clients registered at once, 0 for no limit
+	maxRooms            int          // most rooms open at once, 0 for no limit
+	connections         atomic.Int64 // clients registered or being registered
+	rejectedConnections atomic.Uint64
+	rejectedRooms       atomic.Uint64
+
+	broadcastsMu sync.Mutex
+	broadcasts   map[string]uint64 // message type -> room broadcasts from this pod
+}
+
+// SendBufferStats describes how full client send buffers get, to tune their size
+type SendBufferStats struct {
+	HighWater int64  `json:"highWater"`
+	Overflows uint64 `json:"overflows"`
 }
 
 // RoomMessage is a message to broadcast to a room
@@ -74,9 +114,17 @@ func NewHub() *Hub {
 		unregister:         make(chan *Client),
 		broadcast:          make(chan *RoomMessage, 256),
 		pendingDisconnects: make(map[string]*PendingDisconnect),
+		broadcasts:         make(map[string]uint64),
 	}
 }
 
+// SetLimits caps the clients and rooms of this hub; 0 means no limit. It must be called
+// before the hub is used.
+func (h *Hub) SetLimits(maxConnections, maxRooms int) {
+	h.maxConnections = maxConnections
+	h.maxRooms = maxRooms
+}
+
 // Run starts the hub
 func (h *Hub) Run() {
 	for {
@@ -126,6 +174,7 @@ func (h *Hub) Run() {
 				roomID := client.RoomID
 				userID := client.UserID
 				delete(h.clients, client)
+				h.connections.Add(-1)
 				slog.Debug("hub: client removed from clients map",
 					"clientId", client.ID,
 					"remainingClients", len(h.clients),
@@ -258,27 +307,25 @@ func (h *Hub) Run() {
 					"clientCount", clientCount,
 				)
 				for client := range clients {
-					if roomMsg.Exclude != nil && client == roomMsg.Exclude {
+					if (roomMsg.Exclude != nil && client == roomMsg.Exclude) || client.slow.Load() {
 						continue
 					}
 					select {
 					case client.Send <- roomMsg.Message:
+						h.recordSendDepth(client)
 						slog.Debug("hub: message sent to client",
 							"roomID", roomMsg.RoomID,
 							"clientID", client.ID,
 						)
 					default:
-						slog.Warn("hub: client send channel full, removing client",
-							"roomID", roomMsg.RoomID,
-							"clientID", client.ID,
-						)
-						h.mu.RUnlock()
-						h.mu.Lock()
-						delete(h.clients, client)
-						delete(h.rooms[roomMsg.RoomID], client)
-						close(client.Send)
-						h.mu.Unlock()
-						h.mu.RLock()
+						h.sendOverflows.Add(1)
+						if client.slow.CompareAndSwap(false, true) {
+							slog.Warn("hub: client send channel full, disconnecting slow client",
+								"roomID", roomMsg.RoomID,
+								"clientID", client.ID,
+							)
+							go h.evictSlowClient(client)
+						}
 					}
 				}
 			} else {
@@ -291,9 +338,26 @@ func (h *Hub) Run() {
 	}
 }
 
-// Register registers a client
-func (h *Hub) Register(client *Client) {
+// evictSlowClient closes the connection of a client whose send buffer is full with a
+// slow_consumer close frame and unregisters it through the hub loop, so the usual
+// leave handling and grace period apply. It runs outside the loop to not block broadcasts.
+func (h *Hub) evictSlowClient(client *Client) {
+	if client.Conn != nil {
+		closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow_consumer")
+		_ = client.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
+	}
+	h.Unregister(client)
+}
+
+// Register registers a client, or returns ErrTooManyConnections when the hub is full
+func (h *Hub) Register(client *Client) error {
+	if n := h.connections.Add(1); h.maxConnections > 0 && n > int64(h.maxConnections) {
+		h.connections.Add(-1)
+		h.rejectedConnections.Add(1)
+		return ErrTooManyConnections
+	}
 	h.register <- client
+	return nil
 }
 
 // Unregister unregisters a client
@@ -308,7 +372,7 @@ func (h *Hub) BroadcastToRoom(roomID string, msg Message) {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
-	h.broadcast <- &RoomMessage{RoomID: roomID, Message: data}
+	h.BroadcastData(roomID, msg.Type, data, nil)
 }
 
 // BroadcastToRoomExcept broadcasts a message to all clients in a room except one
@@ -318,11 +382,22 @@ func (h *Hub) BroadcastToRoomExcept(roomID string, msg Message, exclude *Client)
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
+	h.BroadcastData(roomID, msg.Type, data, exclude)
+}
+
+// BroadcastData broadcasts a message of the given type that was already marshaled
+// to all clients in a room except exclude, which may be nil
+func (h *Hub) BroadcastData(roomID, msgType string, data []byte, exclude *Client) {
+	h.broadcastsMu.Lock()
+	h.broadcasts[msgType]++
+	h.broadcastsMu.Unlock()
+
 	h.broadcast <- &RoomMessage{RoomID: roomID, Message: data, Exclude: exclude}
 }
 
-// JoinRoom moves a client to a room
-func (h *Hub) JoinRoom(client *Client, roomID string) {
+// JoinRoom moves a client to a room. It returns ErrTooManyRooms, leaving the client where
+// it was, when the room is not open yet and the hub already has its maximum of rooms.
+func (h *Hub) JoinRoom(client *Client, roomID string) error {
 	slog.Debug("hub: client joining room",
 		"clientId", client.ID,
 		"userId", client.UserID.String(),
@@ -333,6 +408,15 @@ func (h *Hub) JoinRoom(client *Client, roomID string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.maxRooms > 0 && h.rooms[roomID] == nil && len(h.rooms) >= h.maxRooms {
+		h.rejectedRooms.Add(1)
+		slog.Warn("hub: room limit reached, refusing to open a room",
+			"roomId", roomID,
+			"maxRooms", h.maxRooms,
+		)
+		return ErrTooManyRooms
+	}
+
 	// Leave current room
 	if client.RoomID != "" {
 		delete(h.rooms[client.RoomID], client)
@@ -351,6 +435,7 @@ func (h *Hub) JoinRoom(client *Client, roomID string) {
 		"roomId", roomID,
 		"roomClientCount", len(h.rooms[roomID]),
 	)
+	return nil
 }
 
 // LeaveRoom removes a client from a room
@@ -400,7 +485,8 @@ func (h *Hub) IsUserInRoom(roomID string, userID uuid.UUID) bool {
 	return false
 }
 
-// BroadcastRaw broadcasts pre-serialized data to all clients in a room
+// BroadcastRaw broadcasts pre-serialized data to all clients in a room.
+// It is used for relayed messages, which are not counted as broadcasts of this pod.
 func (h *Hub) BroadcastRaw(roomID string, data []byte) {
 	h.broadcast <- &RoomMessage{RoomID: roomID, Message: data}
 }
@@ -422,6 +508,15 @@ func (h *Hub) CancelPendingDisconnect(roomID string, userID uuid.UUID) {
 	}
 }
 
+// HasPendingDisconnect reports whether a participant_left is scheduled for the user in the room
+func (h *Hub) HasPendingDisconnect(roomID string, userID uuid.UUID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	_, exists := h.pendingDisconnects[roomID+"-"+userID.String()]
+	return exists
+}
+
 // SendToClient sends a message to a specific client
 func (h *Hub) SendToClient(client *Client, msg Message) {
 	data, err := json.Marshal(msg)
@@ -429,13 +524,91 @@ func (h *Hub) SendToClient(client *Client, msg Message) {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
+	h.SendRawToClient(client, data)
+}
+
+// SendRawToClient sends an already marshaled message to a specific client
+func (h *Hub) SendRawToClient(client *Client, data []byte) {
 	select {
 	case client.Send <- data:
+		h.recordSendDepth(client)
 	default:
+		h.sendOverflows.Add(1)
 		log.Printf("Client send buffer full, dropping message")
 	}
 }
 
+// recordSendDepth raises the send buffer high-water mark to the client's queue length
+func (h *Hub) recordSendDepth(client *Client) {
+	depth := int64(len(client.Send))
+	for {
+		high := h.sendHighWater.Load()
+		if depth <= high || h.sendHighWater.CompareAndSwap(high, depth) {
+			return
+		}
+	}
+}
+
+// HubMetrics is a point-in-time view of the hub for monitoring
+type HubMetrics struct {
+	Connections        int
+	Rooms              int
+	PendingDisconnects int
+	Broadcasts         map[string]uint64 // message type -> room broadcasts since start
+	SendBuffer         SendBufferStats
+	UnknownMessages    uint64 // incoming messages of an unknown type since start
+	Limits             HubLimits
+}
+
+// HubLimits are the hub's connection and room caps and how often they were hit
+type HubLimits struct {
+	MaxConnections      int // 0 for no limit
+	MaxRooms            int // 0 for no limit
+	RejectedConnections uint64
+	RejectedRooms       uint64
+}
+
+// Metrics returns the current connection and room counts and the broadcast counters
+func (h *Hub) Metrics() HubMetrics {
+	h.mu.RLock()
+	m := HubMetrics{
+		Connections:        len(h.clients),
+		Rooms:              len(h.rooms),
+		PendingDisconnects: len(h.pendingDisconnects),
+	}
+	h.mu.RUnlock()
+
+	h.broadcastsMu.Lock()
+	m.Broadcasts = make(map[string]uint64, len(h.broadcasts))
+	for msgType, n := range h.broadcasts {
+		m.Broadcasts[msgType] = n
+	}
+	h.broadcastsMu.Unlock()
+
+	m.SendBuffer = h.SendBufferStats()
+	m.UnknownMessages = h.unknownMessages.Load()
+	m.Limits = HubLimits{
+		MaxConnections:      h.maxConnections,
+		MaxRooms:            h.maxRooms,
+		RejectedConnections: h.rejectedConnections.Load(),
+		RejectedRooms:       h.rejectedRooms.Load(),
+	}
+	return m
+}
+
+// CountUnknownMessage records an incoming message of a type the server does not handle
+func (h *Hub) CountUnknownMessage() {
+	h.unknownMessages.Add(1)
+}
+
+// SendBufferStats returns the send buffer high-water mark and overflow count since start
+func (h *Hub) SendBufferStats() SendBufferStats {
+	return SendBufferStats{
+		HighWater: h.sendHighWater.Load(),
+		Overflows: h.sendOverflows.Load(),
+	}
+}
+
 // ReadPump pumps messages from the WebSocket connection to the hub
 func (c *Client) ReadPump(handler func(*Client, []byte)) {
 	defer func() {
@@ -479,20 +652,10 @@ func (c *Client) WritePump() {
 				return
 			}
 
-			w, err := c.Conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			_, _ = w.Write(message)
-
-			// Add queued messages
-			n := len(c.Send)
-			for i := 0; i < n; i++ {
-				_, _ = w.Write([]byte{'\n'})
-				_, _ = w.Write(<-c.Send)
-			}
-
-			if err := w.Close(); err != nil {
+			// Each message is its own text frame holding exactly one JSON object
+			// Compressing small frames costs more CPU than it saves bandwidth
+			c.Conn.EnableWriteCompression(len(message) >= compressionThreshold)
+			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				return
 			}
 
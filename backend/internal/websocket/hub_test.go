@@ -0,0 +1,179 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// pumpedClient connects a client whose WritePump writes to the returned connection
+func pumpedClient(t *testing.T, queued ...Message) *websocket.Conn {
+	t.Helper()
+
+	client := &Client{ID: uuid.NewString(), UserID: uuid.New(), Send: make(chan []byte, 16)}
+	for _, msg := range queued {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		client.Send <- data
+	}
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		client.Conn = conn
+		go client.WritePump()
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		close(client.Send)
+	})
+	return conn
+}
+
+func TestWritePumpSendsOneMessagePerFrame(t *testing.T) {
+	// Both messages are queued before the pump starts, as with two rapid broadcasts
+	conn := pumpedClient(t,
+		Message{Type: "item_created", Payload: map[string]string{"id": "1"}},
+		Message{Type: "vote_updated", Payload: map[string]string{"id": "2"}},
+	)
+
+	for _, want := range []string{"item_created", "vote_updated"} {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		frameType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read %s: %v", want, err)
+		}
+		var msg Message
+		if frameType != websocket.TextMessage || json.Unmarshal(data, &msg) != nil {
+			t.Fatalf("frame %q is not a single JSON message", data)
+		}
+		if msg.Type != want {
+			t.Errorf("got %s, want %s", msg.Type, want)
+		}
+	}
+}
+
+func TestSlowClientIsEvictedWithoutBlockingBroadcasts(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	roomID := uuid.NewString()
+	const broadcasts = 1000
+
+	// The slow client never drains its buffer; the fast one reads everything
+	slow := &Client{ID: "slow", UserID: uuid.New(), RoomID: roomID, Hub: hub, Send: make(chan []byte, 1)}
+	fast := &Client{ID: "fast", UserID: uuid.New(), RoomID: roomID, Hub: hub, Send: make(chan []byte, broadcasts)}
+	hub.Register(slow)
+	hub.Register(fast)
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range fast.Send {
+			if n++; n == broadcasts {
+				received <- n
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < broadcasts; i++ {
+			hub.BroadcastRaw(roomID, []byte(`{"type":"item_updated"}`))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcasts blocked behind the slow client")
+	}
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fast client did not receive every broadcast")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.IsUserInRoom(roomID, slow.UserID) {
+		if time.Now().After(deadline) {
+			t.Fatal("the slow client was never evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Unregistering closed the send channel, which stops the client's write pump
+	for range slow.Send {
+	}
+	if !hub.IsUserInRoom(roomID, fast.UserID) {
+		t.Error("the fast client was evicted too")
+	}
+	if stats := hub.SendBufferStats(); stats.Overflows == 0 || stats.HighWater < 1 {
+		t.Errorf("send buffer stats = %+v, want the overflow and a high-water mark recorded", stats)
+	}
+}
+
+func TestHubRefusesClientsBeyondItsLimits(t *testing.T) {
+	hub := NewHub()
+	hub.SetLimits(1, 1)
+	go hub.Run()
+
+	alice := &Client{ID: "alice", UserID: uuid.New(), Hub: hub, Send: make(chan []byte, 1)}
+	bob := &Client{ID: "bob", UserID: uuid.New(), Hub: hub, Send: make(chan []byte, 1)}
+	if err := hub.Register(alice); err != nil {
+		t.Fatalf("register under the limit: %v", err)
+	}
+	if err := hub.Register(bob); !errors.Is(err, ErrTooManyConnections) {
+		t.Fatalf("register beyond the limit got %v, want ErrTooManyConnections", err)
+	}
+
+	roomID := uuid.NewString()
+	if err := hub.JoinRoom(alice, roomID); err != nil {
+		t.Fatalf("join under the limit: %v", err)
+	}
+	if err := hub.JoinRoom(alice, uuid.NewString()); !errors.Is(err, ErrTooManyRooms) {
+		t.Fatalf("opening a second room got %v, want ErrTooManyRooms", err)
+	}
+	if alice.RoomID != roomID || !hub.IsUserInRoom(roomID, alice.UserID) {
+		t.Fatal("a refused join moved the client out of its room")
+	}
+
+	m := hub.Metrics()
+	if m.Connections != 1 || m.Rooms != 1 || m.Limits.RejectedConnections != 1 || m.Limits.RejectedRooms != 1 {
+		t.Fatalf("got metrics %+v, want one connection and room and one rejection of each", m)
+	}
+
+	// The slot is freed once the client is gone
+	hub.Unregister(alice)
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Metrics().Connections != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the client was never unregistered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := hub.Register(bob); err != nil {
+		t.Fatalf("register after a client left: %v", err)
+	}
+	if err := hub.JoinRoom(bob, uuid.NewString()); err != nil {
+		t.Fatalf("join after the room closed: %v", err)
+	}
+}
@@ -121,20 +121,41 @@ export const teamsApi = {
   updateMemberRole: (teamId: string, userId: string, role: string) =>
     api.put(`/teams/${teamId}/members/${userId}/role`, { role }),
   getActions: (teamId: string) => api.get<ActionItem[]>(`/teams/${teamId}/actions`),
-  patchAction: (teamId: string, actionId: string, data: { status?: string; assigneeId?: string | null; description?: string }) =>
+  patchAction: (teamId: string, actionId: string, data: { status?: string; assigneeId?: string | null; description?: string; dueDate?: string | null }) =>
     api.patch<ActionItem>(`/teams/${teamId}/actions/${actionId}`, data),
   getTopics: (teamId: string) => api.get<DiscussedTopic[]>(`/teams/${teamId}/topics`),
 }
 
 export const templatesApi = {
   list: (teamId?: string) => api.get<Template[]>(`/templates${teamId ? `?teamId=${teamId}` : ''}`),
+  listTeamOwned: (teamId: string) => api.get<Template[]>(`/templates?teamId=${teamId}&builtIn=false`),
   get: (id: string) => api.get<Template>(`/templates/${id}`),
   create: (data: Partial<Template>) => api.post<Template>('/templates', data),
+  update: (id: string, data: Partial<Template>) => api.put<Template>(`/templates/${id}`, data),
+  delete: (id: string) => api.delete<void>(`/templates/${id}`),
+  duplicate: (id: string, teamId: string, name?: string) =>
+    api.post<Template>(`/templates/${id}/duplicate?teamId=${teamId}`, name ? { name } : {}),
 }
 
 export const retrosApi = {
-  list: (teamId: string, status?: string) =>
-    api.get<Retrospective[]>(`/retrospectives?teamId=${teamId}${status ? `&status=${status}` : ''}`),
+  list: (
+    teamId: string,
+    options: {
+      status?: string
+      includeArchived?: boolean
+      sort?: 'created_at' | 'ended_at'
+      limit?: number
+      offset?: number
+    } = {}
+  ) => {
+    const params = new URLSearchParams({ teamId })
+    if (options.status) params.set('status', options.status)
+    if (options.includeArchived) params.set('includeArchived', 'true')
+    if (options.sort) params.set('sort', options.sort)
+    if (options.limit) params.set('limit', String(options.limit))
+    if (options.offset) params.set('offset', String(options.offset))
+    return api.get<Retrospective[]>(`/retrospectives?${params}`)
+  },
   get: (id: string) => api.get<Retrospective>(`/retrospectives/${id}`),
   create: (data: {
     name: string
@@ -148,19 +169,40 @@ export const retrosApi = {
   update: (id: string, data: Partial<Retrospective>) =>
     api.put<Retrospective>(`/retrospectives/${id}`, data),
   delete: (id: string) => api.delete(`/retrospectives/${id}`),
+  purgePreview: (id: string) =>
+    api.delete<{ items: number; votes: number; actions: number; confirmationToken: string }>(
+      `/retrospectives/${id}?purge=true&dryRun=true`
+    ),
+  purge: (id: string, token: string) =>
+    api.delete(`/retrospectives/${id}?purge=true&token=${encodeURIComponent(token)}`),
+  restore: (id: string) => api.post<Retrospective>(`/retrospectives/${id}/restore`),
+  clone: (id: string, data?: { name?: string; carryOverActions?: boolean }) =>
+    api.post<Retrospective>(`/retrospectives/${id}/clone`, data),
   start: (id: string) => api.post<Retrospective>(`/retrospectives/${id}/start`),
   end: (id: string) => api.post<Retrospective>(`/retrospectives/${id}/end`),
+  share: (id: string, data?: { expiresInMinutes?: number; spectatorOnly?: boolean }) =>
+    api.post<{ url: string; token: string; expiresAt: string; spectatorOnly: boolean }>(
+      `/retrospectives/${id}/share`,
+      data
+    ),
+  unshare: (id: string) => api.delete(`/retrospectives/${id}/share`),
   getItems: (id: string) => api.get<Item[]>(`/retrospectives/${id}/items`),
   createItem: (retroId: string, data: { columnId: string; content: string }) =>
     api.post<Item>(`/retrospectives/${retroId}/items`, data),
+  importItems: (retroId: string, items: { columnId: string; content: string }[]) =>
+    api.post<Item[]>(`/retrospectives/${retroId}/items/bulk`, { items }),
   updateItem: (retroId: string, itemId: string, data: { content: string }) =>
     api.put<Item>(`/retrospectives/${retroId}/items/${itemId}`, data),
+  getItemHistory: (retroId: string, itemId: string) =>
+    api.get<ItemEdit[]>(`/retrospectives/${retroId}/items/${itemId}/history`),
   deleteItem: (retroId: string, itemId: string) =>
     api.delete(`/retrospectives/${retroId}/items/${itemId}`),
   vote: (retroId: string, itemId: string) =>
     api.post(`/retrospectives/${retroId}/items/${itemId}/vote`),
   unvote: (retroId: string, itemId: string) =>
     api.delete(`/retrospectives/${retroId}/items/${itemId}/vote`),
+  getMyVotes: (retroId: string, itemId: string) =>
+    api.get<{ itemId: string; count: number }>(`/retrospectives/${retroId}/items/${itemId}/myvotes`),
   getActions: (id: string) => api.get<ActionItem[]>(`/retrospectives/${id}/actions`),
   createAction: (retroId: string, data: Partial<ActionItem>) =>
     api.post<ActionItem>(`/retrospectives/${retroId}/actions`, data),
@@ -168,6 +210,9 @@ export const retrosApi = {
     api.put<ActionItem>(`/retrospectives/${retroId}/actions/${actionId}`, data),
   deleteAction: (retroId: string, actionId: string) =>
     api.delete(`/retrospectives/${retroId}/actions/${actionId}`),
+  getActionComments: (actionId: string) => api.get<ActionComment[]>(`/actions/${actionId}/comments`),
+  addActionComment: (actionId: string, content: string) =>
+    api.post<ActionComment>(`/actions/${actionId}/comments`, { content }),
   // Timer
   startTimer: (retroId: string, durationSeconds?: number) =>
     api.post(`/retrospectives/${retroId}/timer/start`, { duration_seconds: durationSeconds }),
@@ -190,6 +235,13 @@ export const retrosApi = {
 
 export const userApi = {
   me: () => api.get<User>('/me'),
+  myActions: (options: { status?: ActionItem['status']; sort?: 'created_at' | 'due_date' } = {}) => {
+    const params = new URLSearchParams()
+    if (options.status) params.set('status', options.status)
+    if (options.sort) params.set('sort', options.sort)
+    const query = params.toString()
+    return api.get<ActionItem[]>(`/me/actions${query ? `?${query}` : ''}`)
+  },
 }
 
 export const adminApi = {
@@ -231,7 +283,13 @@ export const authApi = {
     return response.json()
   },
   logout: async (): Promise<void> => {
-    await fetch('/auth/logout', { method: 'POST', credentials: 'include' })
+    // Sending the access token revokes it, which also closes the WebSocket opened with it
+    const token = useAuthStore.getState().accessToken
+    await fetch('/auth/logout', {
+      method: 'POST',
+      credentials: 'include',
+      headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    })
   },
   getDevUsers: async (): Promise<DevUsersResponse> => {
     const response = await fetch('/auth/dev-users')
@@ -243,4 +301,4 @@ export const authApi = {
 }
 
 // Import types
-import type { Team, TeamMember, TeamWithMemberCount, Template, Retrospective, Item, ActionItem, User, RotiResults, IcebreakerMood, TeamRotiStats, TeamMoodStats, UserRotiStats, UserMoodStats, CombinedUserStats, DevUsersResponse, DiscussedTopic } from '../types'
+import type { Team, TeamMember, TeamWithMemberCount, Template, Retrospective, Item, ItemEdit, ActionItem, ActionComment, User, RotiResults, IcebreakerMood, TeamRotiStats, TeamMoodStats, UserRotiStats, UserMoodStats, CombinedUserStats, DevUsersResponse, DiscussedTopic } from '../types'
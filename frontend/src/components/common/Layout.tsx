@@ -18,7 +18,8 @@ export default function Layout() {
       .catch(() => setDevMode(false))
   }, [])
 
-  const handleLogout = () => {
+  const handleLogout = async () => {
+    await authApi.logout().catch(() => undefined)
     logout()
     navigate('/login')
   }
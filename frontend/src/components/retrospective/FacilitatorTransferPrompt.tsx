@@ -0,0 +1,61 @@
+import { useRetroStore } from '../../store/retroStore'
+import { Crown, Check, X } from 'lucide-react'
+
+interface FacilitatorTransferPromptProps {
+  currentUserId: string
+  send: (type: string, payload: Record<string, unknown>) => void
+}
+
+export default function FacilitatorTransferPrompt({ currentUserId, send }: FacilitatorTransferPromptProps) {
+  const { pendingTransfer } = useRetroStore()
+
+  if (!pendingTransfer) {
+    return null
+  }
+
+  const isTarget = pendingTransfer.toUserId === currentUserId
+  const isRequester = pendingTransfer.fromUserId === currentUserId
+  if (!isTarget && !isRequester) {
+    return null
+  }
+
+  return (
+    <div className="flex items-center gap-3 px-3 py-2 bg-amber-50 border border-amber-200 rounded-lg text-sm">
+      <Crown className="w-4 h-4 text-amber-600" />
+      {isTarget ? (
+        <>
+          <span className="text-amber-900">
+            {pendingTransfer.fromUserName} vous propose le rôle de facilitateur
+          </span>
+          <button
+            onClick={() => send('facilitator_transfer_accept', {})}
+            className="flex items-center gap-1 px-2 py-1 bg-green-600 text-white rounded hover:bg-green-700"
+          >
+            <Check className="w-4 h-4" />
+            Accepter
+          </button>
+          <button
+            onClick={() => send('facilitator_transfer_decline', {})}
+            className="flex items-center gap-1 px-2 py-1 text-gray-600 hover:bg-gray-100 rounded"
+          >
+            <X className="w-4 h-4" />
+            Refuser
+          </button>
+        </>
+      ) : (
+        <>
+          <span className="text-amber-900">
+            En attente de la réponse de {pendingTransfer.toUserName}
+          </span>
+          <button
+            onClick={() => send('facilitator_transfer_decline', {})}
+            className="flex items-center gap-1 px-2 py-1 text-gray-600 hover:bg-gray-100 rounded"
+          >
+            <X className="w-4 h-4" />
+            Annuler
+          </button>
+        </>
+      )}
+    </div>
+  )
+}
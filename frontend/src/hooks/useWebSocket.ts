@@ -2,7 +2,8 @@ import { useEffect, useRef, useCallback, useState } from 'react'
 import { useAuthStore } from '../store/authStore'
 import { useRetroStore } from '../store/retroStore'
 import { useLeanCoffeeStore } from '../store/leanCoffeeStore'
-import type { WSMessage, Item, RetroPhase, IcebreakerMood, RotiResults, MoodWeather, TeamMemberStatus, DraftItem, Participant, LCDiscussionState } from '../types'
+import { api } from '../api/client'
+import type { WSMessage, Item, RetroPhase, IcebreakerMood, RotiResults, MoodWeather, TeamMemberStatus, DraftItem, Participant, LCDiscussionState, FacilitatorTransferRequest } from '../types'
 
 interface ExtendedRetroState {
   retro: import('../types').Retrospective
@@ -20,6 +21,23 @@ interface ExtendedRetroState {
 
 const WS_URL = `${window.location.protocol === 'https:' ? 'wss:' : 'ws:'}//${window.location.host}/ws`
 
+// Server errors are identified by their code; its message is an English fallback
+const ERROR_MESSAGES: Record<string, string> = {
+  vote_limit_reached: 'Vous avez atteint la limite de votes',
+  item_vote_limit_reached: 'Limite de votes atteinte pour cet item',
+  action_limit_reached: "Le nombre maximum d'actions est atteint",
+  retro_closed: 'Cette rétrospective est terminée',
+  spectator_readonly: 'Les spectateurs ne peuvent pas participer à la rétrospective',
+  not_facilitator: 'Seul le facilitateur peut faire cela',
+  join_failed: 'Impossible de rejoindre la rétrospective. Veuillez réessayer.',
+  item_not_found: "Cet item n'existe pas dans cette rétrospective",
+  action_not_found: "Cette action n'existe pas dans cette rétrospective",
+  no_pending_transfer: "Cette demande de transfert n'est plus valable",
+  transfer_outdated: 'Le facilitateur a changé depuis la demande de transfert',
+  transfer_failed: 'Impossible de demander le transfert du rôle de facilitateur',
+  validation: 'Requête invalide, veuillez recharger la page',
+}
+
 // Session storage keys for backup state during reload
 const PARTICIPANTS_BACKUP_KEY = 'retro-participants-backup'
 const STATE_RECEIVED_KEY = 'retro-state-received'
@@ -75,6 +93,8 @@ export function useWebSocket(retroId: string | undefined) {
   const heartbeatIntervalMs = 30_000 // Send heartbeat every 30 seconds
 
   const connect = useCallback(() => {
+    // Read at connect time: a refreshed token is handed over with token_refresh instead of reconnecting
+    const accessToken = useAuthStore.getState().accessToken
     if (!retroId || !accessToken) return
 
     console.log('[WS] connect() called, reconnectAttempts:', reconnectAttempts.current)
@@ -86,7 +106,8 @@ export function useWebSocket(retroId: string | undefined) {
       retroStore.setParticipants(backupParticipants)
     }
 
-    const ws = new WebSocket(`${WS_URL}?token=${accessToken}`)
+    // The token travels in Sec-WebSocket-Protocol so it stays out of URLs and access logs
+    const ws = new WebSocket(WS_URL, ['retrotro', `bearer.${accessToken}`])
     wsRef.current = ws
 
     ws.onopen = () => {
@@ -130,11 +151,18 @@ export function useWebSocket(retroId: string | undefined) {
       }, 3000)
     }
 
-    ws.onclose = () => {
+    ws.onclose = (event) => {
       console.log('[WS] onclose triggered, intentionalDisconnect:', intentionalDisconnectRef.current)
       setIsConnected(false)
       wsRef.current = null
 
+      // The token expired or was revoked: reconnecting with it would be refused
+      if (event.code === 4001) {
+        console.log('[WS] token expired, skipping reconnect')
+        setConnectionError('Votre session a expiré. Veuillez vous reconnecter.')
+        return
+      }
+
       // Don't reconnect if disconnect was intentional (user clicked Leave)
       if (intentionalDisconnectRef.current) {
         console.log('[WS] intentional disconnect, skipping reconnect')
@@ -158,18 +186,15 @@ export function useWebSocket(retroId: string | undefined) {
     }
 
     ws.onmessage = (event) => {
-      // Backend may batch multiple messages separated by newlines
-      const messages = event.data.split('\n').filter((line: string) => line.trim())
-      for (const msgStr of messages) {
-        try {
-          const message: WSMessage = JSON.parse(msgStr)
-          handleMessage(message)
-        } catch (error) {
-          console.error('Failed to parse WebSocket message:', error, msgStr)
-        }
+      // The backend sends exactly one JSON message per frame
+      try {
+        const message: WSMessage = JSON.parse(event.data)
+        handleMessage(message)
+      } catch (error) {
+        console.error('Failed to parse WebSocket message:', error, event.data)
       }
     }
-  }, [retroId, accessToken])
+  }, [retroId])
 
   const handleMessage = useCallback((message: WSMessage) => {
     const { type, payload } = message
@@ -225,10 +250,44 @@ export function useWebSocket(retroId: string | undefined) {
         break
       }
 
+      case 'retro_state_ref': {
+        // The state was too large for a WebSocket frame: fetch the one-time snapshot over REST
+        const { token } = payload as { url: string; token: string; size: number; expiresAt: string }
+        api.get<ExtendedRetroState>(`/retrospectives/${retroId}/snapshot?token=${encodeURIComponent(token)}`)
+          .then((state) => handleMessage({ type: 'retro_state', payload: state }))
+          .catch((error) => {
+            console.error('[WS] failed to fetch retro_state snapshot:', error)
+            setConnectionError('Failed to load retrospective state')
+          })
+        break
+      }
+
+      case 'resync': {
+        // A message was too large to reach this pod: reload the whole state
+        console.log('[WS] resync received, reloading retro_state:', payload)
+        send('join_retro', { retroId })
+        break
+      }
+
       case 'error': {
         const { code, message: errorMessage } = payload as { code: string; message: string }
         console.error('[WS] Server error:', code, errorMessage)
-        setConnectionError(errorMessage)
+        // The server closes the connection after refusing the join: retrying would be refused too
+        if (code === 'not_team_member') {
+          intentionalDisconnectRef.current = true
+          setConnectionError("Seuls les membres de l'équipe peuvent rejoindre cette rétrospective.")
+          break
+        }
+        // The backend is older than this frontend; the feature is simply unavailable
+        if (code === 'unknown_message_type') {
+          break
+        }
+        if (code === 'guests_not_allowed') {
+          intentionalDisconnectRef.current = true
+          setConnectionError("Les invités ne peuvent plus rejoindre cette rétrospective.")
+          break
+        }
+        setConnectionError(ERROR_MESSAGES[code] ?? errorMessage)
         break
       }
 
@@ -237,6 +296,12 @@ export function useWebSocket(retroId: string | undefined) {
         retroStore.addItem(payload as Item)
         break
 
+      case 'items_imported': {
+        const { items } = payload as { items: Item[] }
+        items.forEach((item) => retroStore.addItem(item))
+        break
+      }
+
       case 'item_updated':
         retroStore.updateItem(payload as Item)
         break
@@ -248,19 +313,36 @@ export function useWebSocket(retroId: string | undefined) {
       }
 
       case 'vote_updated': {
-        const { itemId, action, userId, userVoteCount } = payload as { itemId: string; action: 'add' | 'remove'; userId: string; userVoteCount: number }
-        retroStore.updateVote(itemId, action, userId, userVoteCount)
+        // userId and the user's counts are only sent to the voter in anonymous retros
+        const { itemId, action, voteCount, userId, userVoteCount, userItemVoteCount } = payload as { itemId: string; action: 'add' | 'remove'; voteCount?: number; userId?: string; userVoteCount?: number; userItemVoteCount?: number }
+        retroStore.updateVote(itemId, action, userId, userVoteCount, voteCount)
         // Track personal votes
         const currentUserId = useAuthStore.getState().user?.id
         if (userId === currentUserId) {
-          retroStore.updateMyVoteOnItem(itemId, action)
+          retroStore.updateMyVoteOnItem(itemId, action, userItemVoteCount)
+        }
+        break
+      }
+
+      case 'votes_updated': {
+        // Batched totals of the items voted on since the last batch
+        const { votes, userVoteCounts } = payload as { votes: Record<string, number>; userVoteCounts?: Record<string, number> }
+        retroStore.setVoteTotals(votes, userVoteCounts)
+        break
+      }
+
+      case 'vote_summary': {
+        const { voteSummary } = payload as { voteSummary: Record<string, Record<string, number>> }
+        const currentUserId = useAuthStore.getState().user?.id
+        if (currentUserId) {
+          retroStore.setVoteSummary(voteSummary, currentUserId)
         }
         break
       }
 
       case 'participant_joined': {
-        const { userId, name } = payload as { userId: string; name: string }
-        retroStore.addParticipant({ userId, name })
+        const { userId, name, spectator, guest } = payload as { userId: string; name: string; spectator?: boolean; guest?: boolean }
+        retroStore.addParticipant({ userId, name, spectator, guest })
         // Update backup (use store directly to get current state)
         if (retroId) {
           const currentParticipants = useRetroStore.getState().participants
@@ -328,6 +410,18 @@ export function useWebSocket(retroId: string | undefined) {
         break
       }
 
+      case 'item_discussed_updated': {
+        const { itemId, discussed } = payload as { itemId: string; discussed: boolean }
+        retroStore.setItemDiscussed(itemId, discussed)
+        break
+      }
+
+      case 'items_moved': {
+        const { items } = payload as { items: import('../types').Item[] }
+        retroStore.moveItems(items)
+        break
+      }
+
       case 'action_created':
         retroStore.addAction(payload as import('../types').ActionItem)
         break
@@ -351,12 +445,33 @@ export function useWebSocket(retroId: string | undefined) {
         break
       }
 
+      case 'retro_reopened': {
+        const { retro } = payload as { retro: import('../types').Retrospective }
+        retroStore.setRetro(retro)
+        break
+      }
+
+      case 'retro_settings_changed': {
+        const { permissions } = payload as { permissions: Partial<import('../types').Retrospective> }
+        const current = useRetroStore.getState().retro
+        if (current) {
+          retroStore.setRetro({ ...current, ...permissions })
+        }
+        break
+      }
+
       case 'mood_updated': {
         const { userId, mood } = payload as { userId: string; mood: MoodWeather }
         retroStore.updateMood(userId, mood)
         break
       }
 
+      case 'mood_cleared': {
+        const { userId } = payload as { userId: string }
+        retroStore.clearMood(userId)
+        break
+      }
+
       case 'roti_vote_submitted': {
         const { userId } = payload as { userId: string }
         retroStore.setRotiVoteSubmitted(userId)
@@ -382,6 +497,17 @@ export function useWebSocket(retroId: string | undefined) {
         break
       }
 
+      case 'facilitator_transfer_requested': {
+        retroStore.setPendingTransfer(payload as FacilitatorTransferRequest)
+        break
+      }
+
+      case 'facilitator_transfer_declined':
+      case 'facilitator_transfer_expired': {
+        retroStore.setPendingTransfer(null)
+        break
+      }
+
       case 'draft_typing': {
         const draft = payload as DraftItem
         retroStore.setDraft(draft)
@@ -395,6 +521,12 @@ export function useWebSocket(retroId: string | undefined) {
       }
 
       // Lean Coffee messages
+      case 'discuss_focused': {
+        const { itemId } = payload as { itemId: string }
+        retroStore.setFocusedItemId(itemId)
+        break
+      }
+
       case 'discuss_item_changed': {
         const { itemId } = payload as { itemId: string; itemIndex: number; totalItems: number }
         // Sync retro carousel (for retro discuss phase)
@@ -404,7 +536,7 @@ export function useWebSocket(retroId: string | undefined) {
         break
       }
 
-      case 'lc_discussion_state': {
+      case 'lc_discussion_updated': {
         const lcState = payload as LCDiscussionState
         useLeanCoffeeStore.getState().setDiscussionState(lcState)
         break
@@ -455,6 +587,18 @@ export function useWebSocket(retroId: string | undefined) {
     }
   }, [send, retroId])
 
+  // Hand a refreshed access token to the open connection so it outlives the previous one
+  const connectedTokenRef = useRef(accessToken)
+  useEffect(() => {
+    if (!accessToken || accessToken === connectedTokenRef.current) return
+    connectedTokenRef.current = accessToken
+    if (wsRef.current?.readyState === WebSocket.OPEN) {
+      send('token_refresh', { token: accessToken })
+    } else if (!wsRef.current) {
+      connect()
+    }
+  }, [accessToken, connect, send])
+
   useEffect(() => {
     connect()
 
@@ -5,6 +5,7 @@ import { useRetroStore } from '../store/retroStore'
 import { useLeanCoffeeStore } from '../store/leanCoffeeStore'
 import { useAuthStore } from '../store/authStore'
 import PhaseTimer from '../components/retrospective/PhaseTimer'
+import FacilitatorTransferPrompt from '../components/retrospective/FacilitatorTransferPrompt'
 import ParticipantList from '../components/retrospective/ParticipantList'
 import IcebreakerPhaseView from '../components/retrospective/IcebreakerPhaseView'
 import RotiPhaseView from '../components/retrospective/RotiPhaseView'
@@ -110,6 +111,7 @@ export default function LeanCoffeeBoardPage() {
           </div>
 
           <div className="flex items-center gap-4">
+            <FacilitatorTransferPrompt currentUserId={user?.id || ''} send={send} />
             {!isDiscussPhase && (
               <PhaseTimer isFacilitator={isFacilitator} send={send} />
             )}
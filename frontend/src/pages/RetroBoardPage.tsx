@@ -7,6 +7,7 @@ import { useRetroStore } from '../store/retroStore'
 import { useAuthStore } from '../store/authStore'
 import RetroBoard from '../components/retrospective/RetroBoard'
 import PhaseTimer from '../components/retrospective/PhaseTimer'
+import FacilitatorTransferPrompt from '../components/retrospective/FacilitatorTransferPrompt'
 import ParticipantList from '../components/retrospective/ParticipantList'
 import DiscussionCarousel from '../components/retrospective/DiscussionCarousel'
 import IcebreakerPhaseView from '../components/retrospective/IcebreakerPhaseView'
@@ -120,6 +121,7 @@ export default function RetroBoardPage() {
           </div>
 
           <div className="flex items-center gap-4">
+            <FacilitatorTransferPrompt currentUserId={user?.id || ''} send={send} />
             <PhaseTimer isFacilitator={isFacilitator} send={send} />
 
             <div className="flex items-center gap-2">
@@ -28,7 +28,7 @@ import {
   MessageSquare,
   StickyNote,
 } from 'lucide-react'
-import type { ActionItem, TeamMember } from '../types'
+import type { ActionItem, ActionPriority, TeamMember } from '../types'
 
 type KanbanColumn = 'todo' | 'in_progress' | 'done'
 
@@ -44,6 +44,13 @@ const COLUMNS: Column[] = [
   { id: 'done', title: 'Terminé', color: '#10B981' },
 ]
 
+const PRIORITY_LABELS: Record<ActionPriority, string> = {
+  0: 'Basse',
+  1: 'Moyenne',
+  2: 'Haute',
+  3: 'Urgente',
+}
+
 function getActionsByStatus(actions: ActionItem[], status: KanbanColumn): ActionItem[] {
   return actions.filter(action => action.status === status)
 }
@@ -329,7 +336,7 @@ function ActionCard({
       {action.priority > 0 && (
         <div className="flex items-center gap-1 mt-3 text-xs text-gray-500">
           <ArrowUp className="w-3 h-3" />
-          Priorité {action.priority}
+          Priorité {PRIORITY_LABELS[action.priority]}
         </div>
       )}
     </div>
@@ -1,5 +1,5 @@
 import { create } from 'zustand'
-import type { Retrospective, Item, ActionItem, Participant, RetroPhase, MoodWeather, IcebreakerMood, RotiResults, TeamMemberStatus, DraftItem } from '../types'
+import type { Retrospective, Item, ActionItem, Participant, RetroPhase, MoodWeather, IcebreakerMood, RotiResults, TeamMemberStatus, DraftItem, FacilitatorTransferRequest } from '../types'
 
 interface RetroState {
   retro: Retrospective | null
@@ -26,11 +26,18 @@ interface RetroState {
   // Synced discussion item (from discuss_item_changed)
   syncDiscussItemId: string | null
 
+  // Item the facilitator highlights for everyone (from discuss_focused), cleared on phase change
+  focusedItemId: string | null
+
+  // Facilitator transfer awaiting its target's answer
+  pendingTransfer: FacilitatorTransferRequest | null
+
   // Actions
   setRetro: (retro: Retrospective) => void
   setItems: (items: Item[]) => void
   addItem: (item: Item) => void
   updateItem: (item: Item) => void
+  setItemDiscussed: (itemId: string, discussed: boolean) => void
   removeItem: (itemId: string) => void
   setActions: (actions: ActionItem[]) => void
   addAction: (action: ActionItem) => void
@@ -52,16 +59,19 @@ interface RetroState {
   setPhase: (phase: RetroPhase) => void
 
   // Vote
-  updateVote: (itemId: string, action: 'add' | 'remove', userId?: string, userVoteCount?: number) => void
-  updateMyVoteOnItem: (itemId: string, action: 'add' | 'remove') => void
+  updateVote: (itemId: string, action: 'add' | 'remove', userId?: string, userVoteCount?: number, itemVoteCount?: number) => void
+  updateMyVoteOnItem: (itemId: string, action: 'add' | 'remove', count?: number) => void
+  setVoteTotals: (votes: Record<string, number>, userVoteCounts?: Record<string, number>) => void
   setVoteSummary: (summary: Record<string, Record<string, number>>, currentUserId: string) => void
 
   // Grouping
   groupItems: (parentId: string, childIds: string[]) => void
+  moveItems: (moved: Pick<Item, 'id' | 'columnId' | 'position'>[]) => void
 
   // Icebreaker
   setMoods: (moods: IcebreakerMood[]) => void
   updateMood: (userId: string, mood: MoodWeather) => void
+  clearMood: (userId: string) => void
 
   // ROTI
   setRotiVoteSubmitted: (userId: string) => void
@@ -71,6 +81,7 @@ interface RetroState {
   setTeamMembers: (members: TeamMemberStatus[]) => void
   updateTeamMemberStatus: (userId: string, isConnected: boolean) => void
   setFacilitator: (facilitatorId: string) => void
+  setPendingTransfer: (transfer: FacilitatorTransferRequest | null) => void
 
   // Drafts (anonymous typing)
   setDraft: (draft: DraftItem) => void
@@ -78,6 +89,7 @@ interface RetroState {
 
   // Discussion sync
   setSyncDiscussItemId: (itemId: string | null) => void
+  setFocusedItemId: (itemId: string | null) => void
 
   reset: () => void
 }
@@ -98,12 +110,14 @@ const initialState = {
   myVotesOnItems: new Map<string, number>(),
   drafts: new Map<string, DraftItem>(),
   syncDiscussItemId: null as string | null,
+  focusedItemId: null as string | null,
+  pendingTransfer: null as FacilitatorTransferRequest | null,
 }
 
 export const useRetroStore = create<RetroState>((set) => ({
   ...initialState,
 
-  setRetro: (retro) => set({ retro, currentPhase: retro.currentPhase }),
+  setRetro: (retro) => set({ retro, currentPhase: retro.currentPhase, focusedItemId: retro.focusedItemId ?? null }),
 
   setItems: (items) => set({ items }),
 
@@ -115,6 +129,10 @@ export const useRetroStore = create<RetroState>((set) => ({
     items: state.items.map((i) => i.id === item.id ? item : i),
   })),
 
+  setItemDiscussed: (itemId, discussed) => set((state) => ({
+    items: state.items.map((i) => i.id === itemId ? { ...i, discussed } : i),
+  })),
+
   removeItem: (itemId) => set((state) => ({
     items: state.items.filter((i) => i.id !== itemId),
   })),
@@ -176,9 +194,9 @@ export const useRetroStore = create<RetroState>((set) => ({
     timerRemainingSeconds: remaining,
   }),
 
-  setPhase: (phase) => set({ currentPhase: phase }),
+  setPhase: (phase) => set({ currentPhase: phase, focusedItemId: null }),
 
-  updateVote: (itemId, action, userId, userVoteCount) => set((state) => {
+  updateVote: (itemId, action, userId, userVoteCount, itemVoteCount) => set((state) => {
     // Update participant voteCount if userId and userVoteCount provided
     const newParticipants = (userId !== undefined && userVoteCount !== undefined)
       ? state.participants.map(p =>
@@ -191,7 +209,7 @@ export const useRetroStore = create<RetroState>((set) => ({
         if (item.id === itemId) {
           return {
             ...item,
-            voteCount: action === 'add' ? item.voteCount + 1 : Math.max(0, item.voteCount - 1),
+            voteCount: itemVoteCount ?? (action === 'add' ? item.voteCount + 1 : Math.max(0, item.voteCount - 1)),
           }
         }
         return item
@@ -200,11 +218,13 @@ export const useRetroStore = create<RetroState>((set) => ({
     }
   }),
 
-  // Update myVotesOnItems when the current user votes
-  updateMyVoteOnItem: (itemId: string, action: 'add' | 'remove') => set((state) => {
+  // Update myVotesOnItems when the current user votes, using the server's count when given
+  updateMyVoteOnItem: (itemId: string, action: 'add' | 'remove', count?: number) => set((state) => {
     const newMyVotes = new Map(state.myVotesOnItems)
     const current = newMyVotes.get(itemId) || 0
-    if (action === 'add') {
+    if (count !== undefined) {
+      newMyVotes.set(itemId, count)
+    } else if (action === 'add') {
       newMyVotes.set(itemId, current + 1)
     } else {
       newMyVotes.set(itemId, Math.max(0, current - 1))
@@ -212,6 +232,17 @@ export const useRetroStore = create<RetroState>((set) => ({
     return { myVotesOnItems: newMyVotes }
   }),
 
+  setVoteTotals: (votes, userVoteCounts) => set((state) => ({
+    items: state.items.map((item) =>
+      votes[item.id] !== undefined ? { ...item, voteCount: votes[item.id] } : item
+    ),
+    participants: userVoteCounts
+      ? state.participants.map(p =>
+          userVoteCounts[p.userId] !== undefined ? { ...p, voteCount: userVoteCounts[p.userId] } : p
+        )
+      : state.participants,
+  })),
+
   setVoteSummary: (summary, currentUserId) => set((state) => {
     // Initialize myVotesOnItems from the summary for the current user
     const newMyVotes = new Map<string, number>()
@@ -247,6 +278,13 @@ export const useRetroStore = create<RetroState>((set) => ({
     }),
   })),
 
+  moveItems: (moved) => set((state) => ({
+    items: state.items.map((item) => {
+      const m = moved.find((x) => x.id === item.id)
+      return m ? { ...item, columnId: m.columnId, position: m.position } : item
+    }),
+  })),
+
   // Icebreaker
   setMoods: (moods) => set(() => {
     const moodMap = new Map<string, MoodWeather>()
@@ -260,6 +298,12 @@ export const useRetroStore = create<RetroState>((set) => ({
     return { moods: newMoods }
   }),
 
+  clearMood: (userId) => set((state) => {
+    const newMoods = new Map(state.moods)
+    newMoods.delete(userId)
+    return { moods: newMoods }
+  }),
+
   // ROTI
   setRotiVoteSubmitted: (userId) => set((state) => {
     const newVotedIds = new Set(state.rotiVotedUserIds)
@@ -280,8 +324,11 @@ export const useRetroStore = create<RetroState>((set) => ({
 
   setFacilitator: (facilitatorId) => set((state) => ({
     retro: state.retro ? { ...state.retro, facilitatorId } : null,
+    pendingTransfer: null,
   })),
 
+  setPendingTransfer: (transfer) => set({ pendingTransfer: transfer }),
+
   // Drafts (anonymous typing)
   setDraft: (draft) => set((state) => {
     const newDrafts = new Map(state.drafts)
@@ -299,6 +346,8 @@ export const useRetroStore = create<RetroState>((set) => ({
 
   setSyncDiscussItemId: (itemId) => set({ syncDiscussItemId: itemId }),
 
+  setFocusedItemId: (itemId) => set({ focusedItemId: itemId }),
+
   reset: () => set({
     ...initialState,
     moods: new Map<string, MoodWeather>(),
@@ -308,5 +357,7 @@ export const useRetroStore = create<RetroState>((set) => ({
     myVotesOnItems: new Map<string, number>(),
     drafts: new Map<string, DraftItem>(),
     syncDiscussItemId: null,
+    focusedItemId: null,
+    pendingTransfer: null,
   }),
 }))
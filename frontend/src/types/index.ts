@@ -10,6 +10,7 @@ export interface User {
   displayName: string
   avatarUrl?: string
   isAdmin: boolean
+  isGuest?: boolean
   lastLoginAt?: string
   createdAt: string
   updatedAt: string
@@ -58,9 +59,16 @@ export interface Template {
   isBuiltIn: boolean
   teamId?: string
   phaseTimes?: Record<RetroPhase, number>
+  moodScale?: MoodOption[] // weather scale when absent
   createdAt: string
 }
 
+export interface MoodOption {
+  value: string
+  label: string
+  icon?: string
+}
+
 export interface Retrospective {
   id: string
   name: string
@@ -73,6 +81,11 @@ export interface Retrospective {
   maxVotesPerUser: number
   maxVotesPerItem: number
   anonymousVoting: boolean
+  batchVoteUpdates?: boolean
+  openAccess?: boolean
+  allowGuests?: boolean
+  shareExpiresAt?: string
+  shareSpectatorOnly?: boolean
   timerStartedAt?: string
   timerDurationSeconds?: number
   timerPausedAt?: string
@@ -80,12 +93,16 @@ export interface Retrospective {
   scheduledAt?: string
   startedAt?: string
   endedAt?: string
+  deletedAt?: string
   rotiRevealed: boolean
+  focusedItemId?: string
   lcCurrentTopicId?: string
   lcTopicTimeboxSeconds?: number
   createdAt: string
   updatedAt: string
+  team?: Team
   template?: Template
+  facilitator?: User
 }
 
 export interface Item {
@@ -97,12 +114,24 @@ export interface Item {
   groupId?: string
   position: number
   voteCount: number
+  isGuest?: boolean
+  discussed?: boolean
   createdAt: string
   updatedAt: string
   author?: User
   children?: Item[]
 }
 
+export interface ItemEdit {
+  id: string
+  itemId: string
+  editorId?: string
+  editorName?: string
+  oldContent: string
+  newContent: string
+  editedAt: string
+}
+
 export interface ActionItem {
   id: string
   retroId: string
@@ -114,7 +143,7 @@ export interface ActionItem {
   isCompleted: boolean
   status: 'todo' | 'in_progress' | 'done'
   completedAt?: string
-  priority: number
+  priority: ActionPriority
   externalId?: string
   externalUrl?: string
   createdBy: string
@@ -123,12 +152,29 @@ export interface ActionItem {
   assignee?: User
   itemContent?: string
   retroName?: string
+  teamName?: string
+  carriedFromId?: string
+  originRetroId?: string
+}
+
+// 0 low, 1 medium, 2 high, 3 urgent
+export type ActionPriority = 0 | 1 | 2 | 3
+
+export interface ActionComment {
+  id: string
+  actionId: string
+  authorId?: string
+  authorName?: string
+  content: string
+  createdAt: string
 }
 
 export interface Participant {
   userId: string
   name: string
   voteCount?: number  // number of votes used (during vote phase)
+  spectator?: boolean // watches without participating
+  guest?: boolean     // joined from a share link, without an account
 }
 
 // Team member with connection status (for waiting room)
@@ -148,6 +194,15 @@ export interface DraftItem {
   contentLength: number  // Length of the actual content (for generating masked version)
 }
 
+// Mid-session facilitator transfer awaiting the target's answer (from facilitator_transfer_requested)
+export interface FacilitatorTransferRequest {
+  fromUserId: string
+  fromUserName: string
+  toUserId: string
+  toUserName: string
+  expiresAt: string
+}
+
 // WebSocket message types
 export interface WSMessage<T = unknown> {
   type: string
@@ -179,16 +234,24 @@ export interface RotiVote {
   retroId: string
   userId: string
   rating: number
+  comment?: string
   createdAt: string
   user?: User
 }
 
+export interface RotiComment {
+  comment: string
+  userId?: string
+  user?: User
+}
+
 export interface RotiResults {
   average: number
   totalVotes: number
   distribution: Record<number, number>
   revealed: boolean
   votes?: RotiVote[]
+  comments?: RotiComment[]
 }
 
 // API response types